@@ -4,16 +4,27 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/1119-Labs/perpx-load-test/pkg/accounts"
 	"github.com/1119-Labs/perpx-load-test/pkg/client"
 	"github.com/1119-Labs/perpx-load-test/pkg/loadtest"
 	"github.com/1119-Labs/perpx-load-test/pkg/seed"
+	"github.com/1119-Labs/perpx-load-test/pkg/smoke"
 )
 
 func main() {
-	// Lightweight subcommand shim: if the first arg is "seed", run the seeder.
-	// Otherwise, defer to cometbft-load-test's CLI handling.
+	// Lightweight subcommand shim: if the first arg is "seed", run the seeder
+	// (or if it's "addresses", print the worker addresses). Otherwise, defer
+	// to cometbft-load-test's CLI handling (after registering the client
+	// factory, which the "smoke" command also needs).
 	if len(os.Args) > 1 && os.Args[1] == "seed" {
-		seed.Run(os.Args[2:])
+		if err := seed.Run(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "addresses" {
+		accounts.RunAddresses(os.Args[2:])
 		return
 	}
 
@@ -21,11 +32,21 @@ func main() {
 	if err := loadtest.RegisterClientFactory("perpx-bank", client.NewPerpxBankClientFactory()); err != nil {
 		panic(fmt.Sprintf("failed to register client factory: %v", err))
 	}
+	if len(os.Args) > 1 && os.Args[1] == "smoke" {
+		smoke.Run(os.Args[2:], "perpx-bank")
+		return
+	}
 
 	loadtest.Run(&loadtest.CLIConfig{
 		AppName:              "perpx-load-test",
 		AppShortDesc:         "Load testing tool for PerpX Protocol",
 		AppLongDesc:          "Load testing tool for PerpX Protocol localnet using cometbft-load-test.",
 		DefaultClientFactory: "perpx-bank",
+		// Transactions are encoded and signed using these modules' types,
+		// so mismatches with the chain's versions can cause rejections
+		VersionModules: []string{
+			"github.com/1119-Labs/perpx-chain/protocol",
+			"github.com/cosmos/cosmos-sdk",
+		},
 	})
 }
@@ -0,0 +1,30 @@
+// Package golden compares test output against golden files kept in the
+// testdata directory of the package under test.
+package golden
+
+import (
+	"encoding/hex"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update-golden", false, "Rewrite the golden files in testdata with the current output")
+
+// RequireTx compares the hex encoding of the signed transaction got against
+// the named golden file in testdata, or rewrites the file when run with
+// -update-golden.
+func RequireTx(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		require.NoError(t, os.WriteFile(path, []byte(hex.EncodeToString(got)+"\n"), 0o644))
+	}
+	want, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, strings.TrimSpace(string(want)), hex.EncodeToString(got), "signed transaction bytes changed; if this is expected (e.g. after upgrading the chain's modules), rerun with -update-golden")
+}
@@ -0,0 +1,57 @@
+package httpclient
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNew(t *testing.T) {
+	c := New(5*time.Second, 42, false)
+	if c.Timeout != 5*time.Second {
+		t.Errorf("Expected timeout %v, but got %v", 5*time.Second, c.Timeout)
+	}
+	transport, ok := c.Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("Expected *http.Transport, but got %T", c.Transport)
+	}
+	if transport.MaxIdleConnsPerHost != 42 {
+		t.Errorf("Expected MaxIdleConnsPerHost 42, but got %d", transport.MaxIdleConnsPerHost)
+	}
+	// we must not have modified the default transport
+	if http.DefaultTransport.(*http.Transport).MaxIdleConnsPerHost == 42 {
+		t.Error("Expected the default transport to be left unmodified")
+	}
+}
+
+func TestNewCompression(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept-Encoding") != "gzip" {
+			_, _ = w.Write([]byte("plain"))
+			return
+		}
+		w.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(w)
+		_, _ = gz.Write([]byte("compressed"))
+		_ = gz.Close()
+	}))
+	defer server.Close()
+
+	for compression, expected := range map[bool]string{true: "compressed", false: "plain"} {
+		resp, err := New(5*time.Second, 1, compression).Get(server.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(body) != expected {
+			t.Errorf("Expected %q with compression %v, but got %q", expected, compression, body)
+		}
+	}
+}
@@ -1,11 +1,19 @@
 package logging
 
 import (
+	"fmt"
+	"os"
 	"sync"
 
 	"github.com/sirupsen/logrus"
 )
 
+// Supported log output formats.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
 // Logger is the interface to our internal logger.
 type Logger interface {
 	Debug(msg string, kvpairs ...interface{})
@@ -34,6 +42,39 @@ var (
 	_ Logger = (*NoopLogger)(nil)
 )
 
+// Configure sets the minimum level (e.g. "debug", "info", "error") and output
+// format (FormatText or FormatJSON) of the global logrus logger, which backs
+// all loggers created via NewLogrusLogger with a non-empty context.
+func Configure(level, format string) error {
+	lvl, err := logrus.ParseLevel(level)
+	if err != nil {
+		return fmt.Errorf("invalid log level: %w", err)
+	}
+	switch format {
+	case FormatText:
+		logrus.SetFormatter(&logrus.TextFormatter{})
+	case FormatJSON:
+		logrus.SetFormatter(&logrus.JSONFormatter{})
+	default:
+		return fmt.Errorf("invalid log format %q: must be %q or %q", format, FormatText, FormatJSON)
+	}
+	logrus.SetLevel(lvl)
+	return nil
+}
+
+// SetOutputFile directs the output of the global logrus logger to the file at
+// the given path, appending to it if it already exists. The file is not
+// buffered and logrus writes each entry in a single call, so every line is
+// flushed as it is logged and a crash leaves a usable tail.
+func SetOutputFile(path string) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	logrus.SetOutput(f)
+	return nil
+}
+
 //
 // LogrusLogger
 //
@@ -3,6 +3,8 @@ package logging
 import (
 	"reflect"
 	"testing"
+
+	"github.com/sirupsen/logrus"
 )
 
 func TestKVPairSerialization(t *testing.T) {
@@ -34,3 +36,27 @@ func TestKVPairSerialization(t *testing.T) {
 		}
 	}
 }
+
+func TestConfigure(t *testing.T) {
+	defer func() {
+		logrus.SetLevel(logrus.InfoLevel)
+		logrus.SetFormatter(&logrus.TextFormatter{})
+	}()
+
+	if err := Configure("debug", FormatJSON); err != nil {
+		t.Fatalf("Expected no error, but got %v", err)
+	}
+	if logrus.GetLevel() != logrus.DebugLevel {
+		t.Errorf("Expected level %v, but got %v", logrus.DebugLevel, logrus.GetLevel())
+	}
+	if _, ok := logrus.StandardLogger().Formatter.(*logrus.JSONFormatter); !ok {
+		t.Errorf("Expected JSON formatter, but got %T", logrus.StandardLogger().Formatter)
+	}
+
+	if err := Configure("loud", FormatText); err == nil {
+		t.Error("Expected an error for an invalid log level")
+	}
+	if err := Configure("info", "xml"); err == nil {
+		t.Error("Expected an error for an invalid log format")
+	}
+}
@@ -0,0 +1,48 @@
+// Package accounts derives the deterministic worker accounts that the seeder
+// funds and the load test client sends transactions from. Both must use
+// exactly the same derivation, so it lives here rather than in either of
+// them.
+package accounts
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+)
+
+// WorkerPrivKey derives the private key of the worker account with the given
+// index (similar to regen_genesis_addresses.go), of the configured algorithm
+// (see SetKeyAlgo).
+func WorkerPrivKey(index int) cryptotypes.PrivKey {
+	seedStr := fmt.Sprintf("bench worker %d seed phrase for load testing account", index)
+	seed := sha256.Sum256([]byte(seedStr))
+	// Use worker index as path for additional determinism. The full index is
+	// appended (rather than just its lowest byte) so that the derivation is
+	// unambiguous for any number of workers.
+	indexBytes := make([]byte, binary.MaxVarintLen64)
+	n := binary.PutUvarint(indexBytes, uint64(index))
+	adjustedSeed := sha256.Sum256(append(seed[:], indexBytes[:n]...))
+	privKeyBytes, _ := btcec.PrivKeyFromBytes(adjustedSeed[:])
+	return NewPrivKey(privKeyBytes.Serialize())
+}
+
+// FeeGranterPrivKey derives the private key of the fee granter account with
+// the given index, which pays the fees of the workers assigned to it (see the
+// seed command's --fee-granters).
+func FeeGranterPrivKey(index int) cryptotypes.PrivKey {
+	seed := sha256.Sum256([]byte(fmt.Sprintf("bench fee granter %d seed phrase for load testing account", index)))
+	privKeyBytes, _ := btcec.PrivKeyFromBytes(seed[:])
+	return NewPrivKey(privKeyBytes.Serialize())
+}
+
+// FreshWorkerPrivKey derives the private key of the worker account with the
+// given index from the given salt instead, so that a new salt yields accounts
+// that have never been seen on any chain (see the load test's --cold-start).
+func FreshWorkerPrivKey(salt string, index int) cryptotypes.PrivKey {
+	seed := sha256.Sum256([]byte(fmt.Sprintf("fresh bench worker %s/%d seed phrase for load testing account", salt, index)))
+	privKeyBytes, _ := btcec.PrivKeyFromBytes(seed[:])
+	return NewPrivKey(privKeyBytes.Serialize())
+}
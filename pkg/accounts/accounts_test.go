@@ -0,0 +1,46 @@
+package accounts_test
+
+import (
+	"testing"
+
+	"github.com/1119-Labs/perpx-load-test/pkg/accounts"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkerPrivKeyUnique(t *testing.T) {
+	const workers = 100_000
+	seen := make(map[string]int, workers)
+	for i := 0; i < workers; i++ {
+		key := string(accounts.WorkerPrivKey(i).Bytes())
+		prev, ok := seen[key]
+		require.False(t, ok, "workers %d and %d derive the same key", prev, i)
+		seen[key] = i
+	}
+}
+
+func TestWorkerPrivKeyDeterministic(t *testing.T) {
+	for _, i := range []int{0, 1, 255, 256, 65536} {
+		require.Equal(t, accounts.WorkerPrivKey(i).Bytes(), accounts.WorkerPrivKey(i).Bytes())
+	}
+}
+
+func TestFeeGranterPrivKeyDistinctFromWorkers(t *testing.T) {
+	workers := make(map[string]bool)
+	for i := 0; i < 1000; i++ {
+		workers[string(accounts.WorkerPrivKey(i).Bytes())] = true
+	}
+	granters := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		key := string(accounts.FeeGranterPrivKey(i).Bytes())
+		require.False(t, workers[key], "fee granter %d derives a worker's key", i)
+		require.False(t, granters[key], "fee granter %d derives another granter's key", i)
+		granters[key] = true
+	}
+}
+
+func TestFreshWorkerPrivKeySalted(t *testing.T) {
+	require.Equal(t, accounts.FreshWorkerPrivKey("a", 1).Bytes(), accounts.FreshWorkerPrivKey("a", 1).Bytes())
+	require.NotEqual(t, accounts.FreshWorkerPrivKey("a", 1).Bytes(), accounts.FreshWorkerPrivKey("b", 1).Bytes())
+	require.NotEqual(t, accounts.FreshWorkerPrivKey("a", 1).Bytes(), accounts.FreshWorkerPrivKey("a", 2).Bytes())
+	require.NotEqual(t, accounts.FreshWorkerPrivKey("a", 1).Bytes(), accounts.WorkerPrivKey(1).Bytes())
+}
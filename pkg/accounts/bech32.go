@@ -0,0 +1,55 @@
+package accounts
+
+import (
+	"fmt"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/bech32"
+)
+
+// DefaultBech32Prefix is the Bech32 prefix of PerpX account addresses.
+const DefaultBech32Prefix = "perpx"
+
+// SetBech32Prefix configures the Cosmos SDK to use the given prefix for
+// account addresses (and the prefixes derived from it for public keys,
+// validator and consensus node addresses), as the chain does. It must be
+// called before any addresses are parsed or formatted, since the SDK's
+// configuration is global and not safe to change concurrently, and the SDK
+// caches the strings of addresses once they have been formatted.
+func SetBech32Prefix(prefix string) error {
+	if err := ValidateBech32Prefix(prefix); err != nil {
+		return err
+	}
+	config := sdk.GetConfig()
+	if config.GetBech32AccountAddrPrefix() == prefix {
+		return nil
+	}
+	config.SetBech32PrefixForAccount(prefix, prefix+sdk.PrefixPublic)
+	config.SetBech32PrefixForValidator(prefix+sdk.PrefixValidator+sdk.PrefixOperator, prefix+sdk.PrefixValidator+sdk.PrefixOperator+sdk.PrefixPublic)
+	config.SetBech32PrefixForConsensusNode(prefix+sdk.PrefixValidator+sdk.PrefixConsensus, prefix+sdk.PrefixValidator+sdk.PrefixConsensus+sdk.PrefixPublic)
+	return nil
+}
+
+// ValidateBech32Prefix checks that the given Bech32 prefix is non-empty and
+// only consists of lower-case letters and digits.
+func ValidateBech32Prefix(prefix string) error {
+	if len(prefix) == 0 {
+		return fmt.Errorf("bech32 prefix cannot be empty")
+	}
+	for _, c := range prefix {
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') {
+			return fmt.Errorf("invalid bech32 prefix %q: must only contain lower-case letters and digits", prefix)
+		}
+	}
+	return nil
+}
+
+// ConvertBech32Prefix re-encodes the given Bech32 address with another
+// prefix.
+func ConvertBech32Prefix(addr, prefix string) (string, error) {
+	_, bz, err := bech32.DecodeAndConvert(addr)
+	if err != nil {
+		return "", fmt.Errorf("invalid address %q: %w", addr, err)
+	}
+	return sdk.Bech32ifyAddressBytes(prefix, bz)
+}
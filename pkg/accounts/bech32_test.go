@@ -0,0 +1,34 @@
+package accounts_test
+
+import (
+	"testing"
+
+	"github.com/1119-Labs/perpx-load-test/pkg/accounts"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetBech32Prefix(t *testing.T) {
+	t.Cleanup(func() { require.NoError(t, accounts.SetBech32Prefix(accounts.DefaultBech32Prefix)) })
+
+	addr := sdk.AccAddress(accounts.WorkerPrivKey(0).PubKey().Address())
+	require.NoError(t, accounts.SetBech32Prefix("fork"))
+	require.Equal(t, "forkvaloper", sdk.GetConfig().GetBech32ValidatorAddrPrefix())
+	forkAddr := addr.String()
+	require.Regexp(t, "^fork1", forkAddr)
+	parsed, err := sdk.AccAddressFromBech32(forkAddr)
+	require.NoError(t, err)
+	require.Equal(t, addr, parsed)
+
+	converted, err := accounts.ConvertBech32Prefix(forkAddr, accounts.DefaultBech32Prefix)
+	require.NoError(t, err)
+	require.Regexp(t, "^perpx1", converted)
+	require.NoError(t, accounts.SetBech32Prefix(accounts.DefaultBech32Prefix))
+	parsed, err = sdk.AccAddressFromBech32(converted)
+	require.NoError(t, err)
+	require.Equal(t, addr, parsed)
+
+	for _, prefix := range []string{"", "Perpx", "perp-x"} {
+		require.Error(t, accounts.SetBech32Prefix(prefix), prefix)
+	}
+}
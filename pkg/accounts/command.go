@@ -0,0 +1,107 @@
+package accounts
+
+import (
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strconv"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// AddressesConfig holds the configuration of the addresses command.
+type AddressesConfig struct {
+	Workers int  // How many worker accounts to print.
+	Start   int  // The index of the first worker account to print.
+	PubKey  bool // Whether to also print each account's public key.
+
+	Bech32Prefix string // The Bech32 prefix with which to format addresses.
+	KeyAlgo      string // The algorithm with which to derive keys.
+}
+
+// RunAddresses executes the addresses command, which prints the worker
+// accounts that the seeder funds and the load test client sends from.
+func RunAddresses(args []string) {
+	cfg := parseAddressesArgs(args)
+	if cfg.Workers < 1 || cfg.Start < 0 {
+		fmt.Fprintln(os.Stderr, "Error: --workers must be at least 1 and --start at least 0")
+		os.Exit(1)
+	}
+	// Addresses are formatted exactly as for the seeder and the load test
+	// client
+	if err := SetBech32Prefix(cfg.Bech32Prefix); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if err := SetKeyAlgo(cfg.KeyAlgo); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	for i := cfg.Start; i < cfg.Start+cfg.Workers; i++ {
+		pubKey := WorkerPrivKey(i).PubKey()
+		addr := sdk.AccAddress(pubKey.Address())
+		if cfg.PubKey {
+			fmt.Printf("%d\t%s\t%s\n", i, addr.String(), hex.EncodeToString(pubKey.Bytes()))
+		} else {
+			fmt.Printf("%d\t%s\n", i, addr.String())
+		}
+	}
+}
+
+func parseAddressesArgs(args []string) AddressesConfig {
+	cfg := AddressesConfig{Workers: 10, Bech32Prefix: DefaultBech32Prefix, KeyAlgo: DefaultKeyAlgo}
+	if prefix := os.Getenv("LOADTEST_BECH32_PREFIX"); len(prefix) > 0 {
+		cfg.Bech32Prefix = prefix
+	}
+	if algo := os.Getenv("LOADTEST_KEY_ALGO"); len(algo) > 0 {
+		cfg.KeyAlgo = algo
+	}
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--workers", "-w":
+			if i+1 < len(args) {
+				cfg.Workers, _ = strconv.Atoi(args[i+1])
+				i++
+			}
+		case "--start":
+			if i+1 < len(args) {
+				cfg.Start, _ = strconv.Atoi(args[i+1])
+				i++
+			}
+		case "--bech32-prefix":
+			if i+1 < len(args) {
+				cfg.Bech32Prefix = args[i+1]
+				i++
+			}
+		case "--key-algo":
+			if i+1 < len(args) {
+				cfg.KeyAlgo = args[i+1]
+				i++
+			}
+		case "--pubkey":
+			cfg.PubKey = true
+		case "--help", "-h":
+			printAddressesHelp()
+			os.Exit(0)
+		}
+	}
+	return cfg
+}
+
+func printAddressesHelp() {
+	fmt.Println(`Usage: perpx-load-test addresses [OPTIONS]
+
+Prints the index and address of each worker account, exactly as derived by the
+seed command and the load test client, one per line (tab-separated).
+
+Options:
+  --workers, -w N          Number of worker accounts to print (default: 10)
+  --start N                Index of the first worker account to print (default: 0)
+  --pubkey                 Also print each account's hex-encoded compressed public key
+  --bech32-prefix PREFIX   Bech32 prefix of the chain's account addresses
+                           (default: LOADTEST_BECH32_PREFIX, or perpx)
+  --key-algo ALGO          Algorithm with which to derive keys: secp256k1 or eth_secp256k1
+                           (default: LOADTEST_KEY_ALGO, or secp256k1)
+  --help, -h               Show this help message`)
+}
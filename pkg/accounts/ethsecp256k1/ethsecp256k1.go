@@ -0,0 +1,190 @@
+// Package ethsecp256k1 implements the Ethereum-style secp256k1 keys
+// (eth_secp256k1) of EVM-enabled chains (see Ethermint), whose addresses and
+// signatures are derived with Keccak-256 rather than SHA-256 and RIPEMD-160.
+//
+// The load test doesn't depend on Ethermint, so the keys encode themselves
+// rather than being generated from their proto definitions. That suffices to
+// sign transactions in SIGN_MODE_DIRECT and pack the public key into them,
+// but transactions signed with these keys can't be decoded locally, since
+// their types aren't registered.
+package ethsecp256k1
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	"golang.org/x/crypto/sha3"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const (
+	// KeyType is the type of the keys, as reported by their Type methods.
+	KeyType = "eth_secp256k1"
+	// PubKeyName is the fully-qualified proto name of the public key, from
+	// which its type URL is derived when it is packed into a transaction.
+	PubKeyName = "ethermint.crypto.v1.ethsecp256k1.PubKey"
+	// PrivKeyName is the fully-qualified proto name of the private key.
+	PrivKeyName = "ethermint.crypto.v1.ethsecp256k1.PrivKey"
+
+	// PrivKeySize is the size of a private key, in bytes.
+	PrivKeySize = 32
+	// PubKeySize is the size of a (compressed) public key, in bytes.
+	PubKeySize = 33
+	// SignatureSize is the size of a signature, in bytes: R and S followed by
+	// the recovery ID, as Ethereum signs.
+	SignatureSize = 65
+)
+
+// Ensure that the keys implement the SDK's interfaces
+var (
+	_ cryptotypes.PrivKey = (*PrivKey)(nil)
+	_ cryptotypes.PubKey  = (*PubKey)(nil)
+)
+
+// PrivKey is an eth_secp256k1 private key.
+type PrivKey struct {
+	Key []byte
+}
+
+// Bytes returns the key's raw bytes.
+func (k *PrivKey) Bytes() []byte {
+	return k.Key
+}
+
+// PubKey returns the key's (compressed) public key.
+func (k *PrivKey) PubKey() cryptotypes.PubKey {
+	_, pub := btcec.PrivKeyFromBytes(k.Key)
+	return &PubKey{Key: pub.SerializeCompressed()}
+}
+
+// Equals returns whether the given key is the same eth_secp256k1 key.
+func (k *PrivKey) Equals(other cryptotypes.LedgerPrivKey) bool {
+	return k.Type() == other.Type() && subtle.ConstantTimeCompare(k.Bytes(), other.Bytes()) == 1
+}
+
+// Type returns KeyType.
+func (k *PrivKey) Type() string {
+	return KeyType
+}
+
+// Sign signs the Keccak-256 hash of the given message, returning a
+// SignatureSize-byte signature.
+func (k *PrivKey) Sign(msg []byte) ([]byte, error) {
+	if len(k.Key) != PrivKeySize {
+		return nil, fmt.Errorf("invalid private key length: expected %d bytes, got %d", PrivKeySize, len(k.Key))
+	}
+	priv, _ := btcec.PrivKeyFromBytes(k.Key)
+	sig, err := ecdsa.SignCompact(priv, keccak256(msg), false)
+	if err != nil {
+		return nil, err
+	}
+	// [27 + recovery ID || R || S] -> [R || S || recovery ID]
+	return append(sig[1:], sig[0]-27), nil
+}
+
+func (k *PrivKey) Reset() { *k = PrivKey{} }
+
+func (k *PrivKey) String() string { return "EthPrivKeySecp256k1{...}" }
+
+func (*PrivKey) ProtoMessage() {}
+
+// XXX_MessageName returns PrivKeyName.
+func (*PrivKey) XXX_MessageName() string {
+	return PrivKeyName
+}
+
+// Marshal encodes the key in the wire format of its proto definition.
+func (k *PrivKey) Marshal() ([]byte, error) {
+	return marshalKey(k.Key), nil
+}
+
+// PubKey is an eth_secp256k1 public key, in compressed form.
+type PubKey struct {
+	Key []byte
+}
+
+// Address returns the key's Ethereum address: the last 20 bytes of the
+// Keccak-256 hash of the uncompressed key (without its prefix).
+func (k *PubKey) Address() cryptotypes.Address {
+	pub, err := btcec.ParsePubKey(k.Key)
+	if err != nil {
+		panic(fmt.Sprintf("invalid eth_secp256k1 public key: %v", err))
+	}
+	return cryptotypes.Address(keccak256(pub.SerializeUncompressed()[1:])[12:])
+}
+
+// Bytes returns the key's raw (compressed) bytes.
+func (k *PubKey) Bytes() []byte {
+	return k.Key
+}
+
+// VerifySignature returns whether the given signature (with or without its
+// recovery ID) is a valid signature of the Keccak-256 hash of the given
+// message by this key. Like Ethereum, it rejects signatures whose S values
+// are in the upper half of the curve order.
+func (k *PubKey) VerifySignature(msg, sig []byte) bool {
+	if len(sig) == SignatureSize {
+		sig = sig[:SignatureSize-1]
+	}
+	if len(sig) != SignatureSize-1 {
+		return false
+	}
+	pub, err := btcec.ParsePubKey(k.Key)
+	if err != nil {
+		return false
+	}
+	var r, s btcec.ModNScalar
+	if overflow := r.SetByteSlice(sig[:32]); overflow {
+		return false
+	}
+	if overflow := s.SetByteSlice(sig[32:]); overflow || s.IsOverHalfOrder() {
+		return false
+	}
+	return ecdsa.NewSignature(&r, &s).Verify(keccak256(msg), pub)
+}
+
+// Equals returns whether the given key is the same eth_secp256k1 key.
+func (k *PubKey) Equals(other cryptotypes.PubKey) bool {
+	return k.Type() == other.Type() && bytes.Equal(k.Bytes(), other.Bytes())
+}
+
+// Type returns KeyType.
+func (k *PubKey) Type() string {
+	return KeyType
+}
+
+func (k *PubKey) Reset() { *k = PubKey{} }
+
+func (k *PubKey) String() string { return fmt.Sprintf("EthPubKeySecp256k1{%X}", k.Key) }
+
+func (*PubKey) ProtoMessage() {}
+
+// XXX_MessageName returns PubKeyName.
+func (*PubKey) XXX_MessageName() string {
+	return PubKeyName
+}
+
+// Marshal encodes the key in the wire format of its proto definition:
+//
+//	bytes key = 1;
+func (k *PubKey) Marshal() ([]byte, error) {
+	return marshalKey(k.Key), nil
+}
+
+func marshalKey(key []byte) []byte {
+	if len(key) == 0 {
+		return nil
+	}
+	b := protowire.AppendTag(nil, 1, protowire.BytesType)
+	return protowire.AppendBytes(b, key)
+}
+
+func keccak256(data []byte) []byte {
+	h := sha3.NewLegacyKeccak256()
+	h.Write(data)
+	return h.Sum(nil)
+}
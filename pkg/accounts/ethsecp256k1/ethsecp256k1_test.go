@@ -0,0 +1,50 @@
+package ethsecp256k1_test
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/1119-Labs/perpx-load-test/pkg/accounts/ethsecp256k1"
+	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/sha3"
+)
+
+func testPrivKey() *ethsecp256k1.PrivKey {
+	key := make([]byte, ethsecp256k1.PrivKeySize)
+	key[len(key)-1] = 1
+	return &ethsecp256k1.PrivKey{Key: key}
+}
+
+func TestAddress(t *testing.T) {
+	// the well-known Ethereum address of private key 1
+	require.Equal(t, "7e5f4552091a69125d5dfcb7b8c2659029395bdf", hex.EncodeToString(testPrivKey().PubKey().Address()))
+}
+
+func TestSignAndVerify(t *testing.T) {
+	priv := testPrivKey()
+	pub := priv.PubKey()
+	msg := []byte("sign doc")
+	sig, err := priv.Sign(msg)
+	require.NoError(t, err)
+	require.Len(t, sig, ethsecp256k1.SignatureSize)
+	require.True(t, pub.VerifySignature(msg, sig))
+	// with or without the recovery ID
+	require.True(t, pub.VerifySignature(msg, sig[:ethsecp256k1.SignatureSize-1]))
+	require.False(t, pub.VerifySignature([]byte("other doc"), sig))
+
+	// the recovery ID recovers the public key, as Ethereum expects
+	h := sha3.NewLegacyKeccak256()
+	h.Write(msg)
+	compact := append([]byte{27 + sig[ethsecp256k1.SignatureSize-1]}, sig[:ethsecp256k1.SignatureSize-1]...)
+	recovered, _, err := ecdsa.RecoverCompact(compact, h.Sum(nil))
+	require.NoError(t, err)
+	require.Equal(t, pub.Bytes(), recovered.SerializeCompressed())
+}
+
+func TestMarshal(t *testing.T) {
+	pub := testPrivKey().PubKey().(*ethsecp256k1.PubKey)
+	bz, err := pub.Marshal()
+	require.NoError(t, err)
+	require.Equal(t, append([]byte{0x0a, ethsecp256k1.PubKeySize}, pub.Key...), bz)
+}
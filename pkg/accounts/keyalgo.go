@@ -0,0 +1,134 @@
+package accounts
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	"github.com/1119-Labs/perpx-load-test/pkg/accounts/ethsecp256k1"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+)
+
+// The algorithms of the keys that accounts can be derived with.
+const (
+	KeyAlgoSecp256k1    = "secp256k1"     // Cosmos SDK keys (coin type 118), as PerpX uses.
+	KeyAlgoEthSecp256k1 = "eth_secp256k1" // Ethereum-style keys (coin type 60), as EVM-enabled forks use.
+)
+
+// DefaultKeyAlgo is the algorithm of PerpX account keys.
+const DefaultKeyAlgo = KeyAlgoSecp256k1
+
+// The type URLs of the public keys of each algorithm, as reported by the
+// chain for accounts that have signed a transaction.
+var pubKeyTypeURLs = map[string]string{
+	KeyAlgoSecp256k1:    "/cosmos.crypto.secp256k1.PubKey",
+	KeyAlgoEthSecp256k1: "/" + ethsecp256k1.PubKeyName,
+}
+
+var keyAlgo atomic.Value // string
+
+// ValidateKeyAlgo checks that the given key algorithm is supported.
+func ValidateKeyAlgo(algo string) error {
+	if _, ok := pubKeyTypeURLs[algo]; !ok {
+		return fmt.Errorf("invalid key algorithm %q (must be %q or %q)", algo, KeyAlgoSecp256k1, KeyAlgoEthSecp256k1)
+	}
+	return nil
+}
+
+// SetKeyAlgo configures the algorithm of all keys derived from here on
+// (including those of the worker accounts), as the chain uses. Like
+// SetBech32Prefix, it must be called before any keys are derived.
+func SetKeyAlgo(algo string) error {
+	if err := ValidateKeyAlgo(algo); err != nil {
+		return err
+	}
+	keyAlgo.Store(algo)
+	return nil
+}
+
+// KeyAlgo returns the configured key algorithm.
+func KeyAlgo() string {
+	if algo, ok := keyAlgo.Load().(string); ok {
+		return algo
+	}
+	return DefaultKeyAlgo
+}
+
+// CoinType returns the BIP-44 coin type with which keys of the configured
+// algorithm are derived from mnemonics.
+func CoinType() uint32 {
+	if KeyAlgo() == KeyAlgoEthSecp256k1 {
+		return 60
+	}
+	return 118
+}
+
+// NewPrivKey returns the private key of the configured algorithm with the
+// given raw bytes.
+func NewPrivKey(key []byte) cryptotypes.PrivKey {
+	if KeyAlgo() == KeyAlgoEthSecp256k1 {
+		return &ethsecp256k1.PrivKey{Key: key}
+	}
+	return &secp256k1.PrivKey{Key: key}
+}
+
+// CheckPubKeyType checks that the given type URL of a public key on the
+// chain (see ChainPubKeyType) is that of the configured key algorithm: a
+// mismatch would derive the wrong addresses and fail every signature. An
+// empty type URL, meaning that there is nothing to tell by, passes.
+func CheckPubKeyType(typeURL string) error {
+	if len(typeURL) == 0 || typeURL == pubKeyTypeURLs[KeyAlgo()] {
+		return nil
+	}
+	for algo, url := range pubKeyTypeURLs {
+		if typeURL == url {
+			return fmt.Errorf("key algorithm %s doesn't match the chain, whose accounts use %s keys - set the key algorithm to %s", KeyAlgo(), algo, algo)
+		}
+	}
+	// e.g. the eth_secp256k1 keys of another EVM module
+	return fmt.Errorf("key algorithm %s doesn't match the chain, whose accounts use %s keys, which aren't supported", KeyAlgo(), typeURL)
+}
+
+// ChainPubKeyType returns the type URL of the first secp256k1 public key (of
+// either algorithm) among the first page of the chain's accounts, queried via
+// the REST API at restURL, or an empty string if none of them has one. The
+// accounts of EVM-enabled chains wrap the base account's fields, so their
+// public keys are nested.
+func ChainPubKeyType(client *http.Client, restURL string) (string, error) {
+	accountsURL := fmt.Sprintf("%s/cosmos/auth/v1beta1/accounts?pagination.limit=100", restURL)
+	resp, err := client.Get(accountsURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to query accounts via REST API at %s: %w", accountsURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to query accounts: HTTP %d", resp.StatusCode)
+	}
+	type pubKey struct {
+		Type string `json:"@type"`
+	}
+	var accountsResp struct {
+		Accounts []struct {
+			PubKey      *pubKey `json:"pub_key"`
+			BaseAccount *struct {
+				PubKey *pubKey `json:"pub_key"`
+			} `json:"base_account"`
+		} `json:"accounts"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&accountsResp); err != nil {
+		return "", fmt.Errorf("failed to decode accounts response: %w", err)
+	}
+	for _, account := range accountsResp.Accounts {
+		key := account.PubKey
+		if key == nil && account.BaseAccount != nil {
+			key = account.BaseAccount.PubKey
+		}
+		if key != nil && (strings.HasSuffix(key.Type, ".secp256k1.PubKey") || strings.HasSuffix(key.Type, ".ethsecp256k1.PubKey")) {
+			return key.Type, nil
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,55 @@
+package accounts_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/1119-Labs/perpx-load-test/pkg/accounts"
+	"github.com/1119-Labs/perpx-load-test/pkg/accounts/ethsecp256k1"
+	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetKeyAlgo(t *testing.T) {
+	t.Cleanup(func() { require.NoError(t, accounts.SetKeyAlgo(accounts.DefaultKeyAlgo)) })
+
+	require.IsType(t, &secp256k1.PrivKey{}, accounts.WorkerPrivKey(0))
+	require.Equal(t, uint32(118), accounts.CoinType())
+
+	require.NoError(t, accounts.SetKeyAlgo(accounts.KeyAlgoEthSecp256k1))
+	require.IsType(t, &ethsecp256k1.PrivKey{}, accounts.WorkerPrivKey(0))
+	require.Equal(t, uint32(60), accounts.CoinType())
+
+	require.Error(t, accounts.SetKeyAlgo("ed25519"))
+	require.Equal(t, accounts.KeyAlgoEthSecp256k1, accounts.KeyAlgo())
+}
+
+func TestCheckChainKeyAlgo(t *testing.T) {
+	t.Cleanup(func() { require.NoError(t, accounts.SetKeyAlgo(accounts.DefaultKeyAlgo)) })
+
+	// an EVM-enabled chain, whose accounts wrap the base account, and
+	// whose module accounts have no public key
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/cosmos/auth/v1beta1/accounts", r.URL.Path)
+		fmt.Fprint(w, `{"accounts": [
+			{"@type": "/cosmos.auth.v1beta1.ModuleAccount", "base_account": {"pub_key": null}},
+			{"@type": "/ethermint.types.v1.EthAccount", "base_account": {"pub_key": {"@type": "/ethermint.crypto.v1.ethsecp256k1.PubKey", "key": "A1"}}}
+		]}`)
+	}))
+	defer server.Close()
+
+	typeURL, err := accounts.ChainPubKeyType(server.Client(), server.URL)
+	require.NoError(t, err)
+	require.Equal(t, "/ethermint.crypto.v1.ethsecp256k1.PubKey", typeURL)
+
+	err = accounts.CheckPubKeyType(typeURL)
+	require.ErrorContains(t, err, "set the key algorithm to eth_secp256k1")
+	require.NoError(t, accounts.SetKeyAlgo(accounts.KeyAlgoEthSecp256k1))
+	require.NoError(t, accounts.CheckPubKeyType(typeURL))
+
+	// a chain none of whose accounts has signed anything yet
+	require.NoError(t, accounts.CheckPubKeyType(""))
+	require.Error(t, accounts.CheckPubKeyType("/cosmos.evm.crypto.v1.ethsecp256k1.PubKey"))
+}
@@ -0,0 +1,120 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/1119-Labs/perpx-load-test/internal/logging"
+	"github.com/1119-Labs/perpx-load-test/pkg/loadtest"
+	"github.com/1119-Labs/perpx-load-test/pkg/seed"
+)
+
+// How long the account creator waits for more accounts to be requested
+// before funding a batch of them, when it isn't busy funding the previous
+// batch anyway.
+const accountCreationWindow = 100 * time.Millisecond
+
+// errAccountNotFound is returned (wrapped) by queryAccount if the client's
+// account doesn't exist on the chain, i.e. it was never funded.
+var errAccountNotFound = errors.New("account not found")
+
+// accountCreator creates the accounts of workers that were never seeded, by
+// funding them from the seed account as the seed command would. It is safe
+// for concurrent use: accounts requested concurrently (e.g. by clients being
+// prepared concurrently) are funded together, in batches of up to the seed
+// command's batch size, one batch per block at most.
+type accountCreator struct {
+	amount    sdk.Coins
+	batchSize int
+	logger    logging.Logger
+
+	mtx     sync.Mutex     // Guards pending.
+	pending *creationBatch // The batch to which newly requested accounts are added, if any.
+
+	funderMtx sync.Mutex // Serializes the use of funder.
+	funder    *seed.Funder
+}
+
+// creationBatch is a batch of accounts to be created by a single funding
+// transaction.
+type creationBatch struct {
+	addrs []sdk.AccAddress
+	done  chan struct{} // Closed once the batch has been funded (or failed to be).
+	err   error
+}
+
+// newAccountCreator creates an accountCreator that funds accounts with the
+// seed command's fund amounts (LOADTEST_FUND_AMOUNT and, if set,
+// LOADTEST_FEE_FUND_AMOUNT), from the seed account configured for the seeder,
+// through the given client's node.
+func newAccountCreator(cfg loadtest.Config, client *PerpxBankClient, logger logging.Logger) (*accountCreator, error) {
+	seedCfg := seedConfigFor(cfg, client)
+	amount, err := sdk.ParseCoinsNormalized(seedCfg.FundAmount)
+	if err != nil {
+		return nil, fmt.Errorf("invalid fund amount for auto-created accounts: %w", err)
+	}
+	if len(seedCfg.FeeFundAmount) > 0 {
+		feeAmount, err := sdk.ParseCoinsNormalized(seedCfg.FeeFundAmount)
+		if err != nil {
+			return nil, fmt.Errorf("invalid fee fund amount for auto-created accounts: %w", err)
+		}
+		amount = amount.Add(feeAmount...)
+	}
+	funder, err := seed.NewFunder(seedCfg, client.httpClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up account creation from the seed account: %w", err)
+	}
+	logger.Info("Auto-creating unseeded worker accounts", "funder", funder.Address().String(), "amount", amount.String())
+	return &accountCreator{amount: amount, batchSize: seedCfg.BatchSize, logger: logger, funder: funder}, nil
+}
+
+// Create funds the given account, waiting for the funding transaction to be
+// committed.
+func (a *accountCreator) Create(addr sdk.AccAddress) error {
+	a.mtx.Lock()
+	b := a.pending
+	if b == nil {
+		b = &creationBatch{done: make(chan struct{})}
+		a.pending = b
+		go a.fund(b)
+	}
+	b.addrs = append(b.addrs, addr)
+	if len(b.addrs) >= a.batchSize {
+		// the next account starts a new batch
+		a.pending = nil
+	}
+	a.mtx.Unlock()
+	<-b.done
+	return b.err
+}
+
+// fund funds the given batch once the previous batch has been funded and the
+// creation window has passed, gathering the accounts requested meanwhile.
+func (a *accountCreator) fund(b *creationBatch) {
+	defer close(b.done)
+	time.Sleep(accountCreationWindow)
+	a.funderMtx.Lock()
+	defer a.funderMtx.Unlock()
+	a.mtx.Lock()
+	if a.pending == b {
+		a.pending = nil
+	}
+	addrs := b.addrs
+	a.mtx.Unlock()
+
+	txHash, height, err := a.funder.Fund(addrs, a.amount)
+	if err != nil {
+		// the failed transaction may or may not have consumed a sequence
+		// number, so find out before the next batch is funded
+		if syncErr := a.funder.Sync(); syncErr != nil {
+			a.logger.Error("Failed to query seed account after failed account creation", "err", syncErr)
+		}
+		b.err = fmt.Errorf("failed to create %d accounts including %s: %w", len(addrs), addrs[0], err)
+		return
+	}
+	a.logger.Debug("Created worker accounts", "accounts", len(addrs), "amount", a.amount.String(), "txHash", txHash, "height", height)
+}
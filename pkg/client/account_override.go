@@ -0,0 +1,79 @@
+package client
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/1119-Labs/perpx-load-test/pkg/loadtest"
+)
+
+// accountOverrides holds the workers' account numbers and starting sequences
+// given in the configuration, which clients use instead of querying them, so
+// that they can build transactions without the REST or gRPC API.
+type accountOverrides struct {
+	accountNums []uint64 // Either one per worker, or only the first worker's, the others following consecutively.
+	sequences   []uint64 // Either one per worker, or one shared by all of them.
+}
+
+// parseAccountOverrides parses the configured account numbers and starting
+// sequences, returning nil if neither is configured. Both must be configured
+// together, either as a single value or as a comma-separated list with one
+// value for each of the cfg.Connections * len(cfg.Endpoints) workers.
+func parseAccountOverrides(cfg loadtest.Config) (*accountOverrides, error) {
+	if len(cfg.AccountNumber) == 0 && len(cfg.StartSequence) == 0 {
+		return nil, nil
+	}
+	if len(cfg.AccountNumber) == 0 || len(cfg.StartSequence) == 0 {
+		return nil, fmt.Errorf("account-number and start-sequence must be given together")
+	}
+	workers := cfg.Connections * len(cfg.Endpoints)
+	accountNums, err := parseUintList(cfg.AccountNumber, workers)
+	if err != nil {
+		return nil, fmt.Errorf("invalid account-number: %w", err)
+	}
+	sequences, err := parseUintList(cfg.StartSequence, workers)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start-sequence: %w", err)
+	}
+	return &accountOverrides{accountNums: accountNums, sequences: sequences}, nil
+}
+
+// parseUintList parses either a single number or a comma-separated list of
+// exactly n numbers.
+func parseUintList(s string, n int) ([]uint64, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) > 1 && len(parts) != n {
+		return nil, fmt.Errorf("expected a single value or one for each of the %d workers, but got %d", n, len(parts))
+	}
+	vals := make([]uint64, len(parts))
+	for i, part := range parts {
+		val, err := strconv.ParseUint(strings.TrimSpace(part), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a non-negative integer", part)
+		}
+		vals[i] = val
+	}
+	return vals, nil
+}
+
+// forWorker returns the account number and starting sequence of the worker
+// with the given ID.
+func (o *accountOverrides) forWorker(id int) (accountNum, sequence uint64, err error) {
+	if len(o.accountNums) > 1 && id >= len(o.accountNums) {
+		return 0, 0, fmt.Errorf("no account number was given for worker %d", id)
+	}
+	if len(o.accountNums) == 1 {
+		accountNum = o.accountNums[0] + uint64(id)
+	} else {
+		accountNum = o.accountNums[id]
+	}
+	if len(o.sequences) == 1 {
+		sequence = o.sequences[0]
+	} else if id < len(o.sequences) {
+		sequence = o.sequences[id]
+	} else {
+		return 0, 0, fmt.Errorf("no start sequence was given for worker %d", id)
+	}
+	return accountNum, sequence, nil
+}
@@ -0,0 +1,87 @@
+package client
+
+import (
+	"sync"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/authz"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+
+	"github.com/1119-Labs/perpx-load-test/pkg/strategies"
+)
+
+// The roles in which an accountSet has seen an account.
+const (
+	accountSender    uint8 = 1 << iota // The account sent funds.
+	accountRecipient                   // The account was sent funds.
+)
+
+// accountSet keeps track of the distinct accounts touched by the
+// transactions generated by all of a factory's clients: the accounts that
+// signed them or sent funds in them, and the accounts to which they sent
+// funds. Like feeMeter, it counts every transaction generated, whether or not
+// it ends up being committed, so its counts are upper bounds. It keeps every
+// address seen, so runs with fresh recipients grow it by one address per
+// message.
+type accountSet struct {
+	mtx        sync.Mutex
+	roles      map[string]uint8
+	senders    int
+	recipients int
+}
+
+func newAccountSet() *accountSet {
+	return &accountSet{roles: make(map[string]uint8)}
+}
+
+// add records the accounts touched by a transaction of the given messages
+// signed by the given account. A nil set keeps no track.
+func (s *accountSet) add(signer string, msgs []sdk.Msg) {
+	if s == nil {
+		return
+	}
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.addRole(signer, accountSender)
+	s.addMsgs(msgs)
+}
+
+func (s *accountSet) addMsgs(msgs []sdk.Msg) {
+	for _, msg := range msgs {
+		switch msg := msg.(type) {
+		case *banktypes.MsgSend:
+			s.addRole(msg.FromAddress, accountSender)
+			s.addRole(msg.ToAddress, accountRecipient)
+		case *strategies.MsgExecuteContract:
+			if !msg.Funds.IsZero() {
+				s.addRole(msg.Contract, accountRecipient)
+			}
+		case *authz.MsgExec:
+			// e.g. a hot account's send on behalf of the signer
+			if inner, err := msg.GetMessages(); err == nil {
+				s.addMsgs(inner)
+			}
+		}
+	}
+}
+
+func (s *accountSet) addRole(addr string, role uint8) {
+	roles := s.roles[addr]
+	if roles&role != 0 {
+		return
+	}
+	s.roles[addr] = roles | role
+	if role == accountSender {
+		s.senders++
+	} else {
+		s.recipients++
+	}
+}
+
+// Counts returns the number of distinct accounts that sent funds, that were
+// sent funds, and that did either.
+func (s *accountSet) Counts() (senders, recipients, total int) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.senders, s.recipients, len(s.roles)
+}
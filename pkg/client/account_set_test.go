@@ -0,0 +1,51 @@
+package client
+
+import (
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/authz"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/1119-Labs/perpx-load-test/pkg/accounts"
+	"github.com/1119-Labs/perpx-load-test/pkg/strategies"
+)
+
+func TestAccountSet(t *testing.T) {
+	require.NoError(t, accounts.SetBech32Prefix(accounts.DefaultBech32Prefix))
+	addr := func(i int) string {
+		return sdk.AccAddress(accounts.WorkerPrivKey(i).PubKey().Address()).String()
+	}
+	send := func(from, to string) *banktypes.MsgSend {
+		return &banktypes.MsgSend{FromAddress: from, ToAddress: to}
+	}
+	s := newAccountSet()
+
+	s.add(addr(0), []sdk.Msg{send(addr(0), addr(1)), send(addr(0), addr(2))})
+	// a self-send touches a single account
+	s.add(addr(3), []sdk.Msg{send(addr(3), addr(3))})
+	// an account sent to again isn't counted twice, but one that later sends
+	// is counted as a sender too
+	s.add(addr(1), []sdk.Msg{send(addr(1), addr(2))})
+	// a send executed via authz touches the granter it is sent from, as well
+	// as the signer
+	exec := authz.NewMsgExec(sdk.MustAccAddressFromBech32(addr(4)), []sdk.Msg{send(addr(5), addr(6))})
+	s.add(addr(4), []sdk.Msg{&exec})
+	// a contract execution only sends funds to the contract if it attaches
+	// any
+	funds := sdk.NewCoins(sdk.NewInt64Coin("aperpx", 1))
+	s.add(addr(0), []sdk.Msg{
+		&strategies.MsgExecuteContract{Sender: addr(0), Contract: addr(7), Funds: funds},
+		&strategies.MsgExecuteContract{Sender: addr(0), Contract: addr(8)},
+	})
+
+	senders, recipients, total := s.Counts()
+	require.Equal(t, 5, senders)    // 0, 1, 3, 4 and 5
+	require.Equal(t, 5, recipients) // 1, 2, 3, 6 and 7
+	require.Equal(t, 8, total)
+
+	// a nil set keeps no track
+	var none *accountSet
+	none.add(addr(0), []sdk.Msg{send(addr(0), addr(1))})
+}
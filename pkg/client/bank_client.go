@@ -1,36 +1,49 @@
 package client
 
 import (
-	"context"
-	"crypto/sha256"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
-	"github.com/btcsuite/btcd/btcec/v2"
-	"github.com/cosmos/cosmos-sdk/client/tx"
-	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
 	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/cosmos-sdk/types/tx/signing"
-	authsigning "github.com/cosmos/cosmos-sdk/x/auth/signing"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"google.golang.org/grpc"
 
 	"cosmossdk.io/math"
 	"github.com/1119-Labs/perpx-chain/protocol/app"
+	"github.com/1119-Labs/perpx-load-test/pkg/accounts"
 	"github.com/1119-Labs/perpx-load-test/pkg/loadtest"
+	"github.com/1119-Labs/perpx-load-test/pkg/seed"
 	"github.com/1119-Labs/perpx-load-test/pkg/strategies"
 )
 
+const (
+	// Minimum gas price: 25000000000aperpx per unit of gas (from cmd/perpxd/cmd/config.go)
+	defaultMinGasPrice = 25000000000
+	// How long the account's on-chain sequence must have stopped advancing
+	// before CheckSequence concludes that our local sequence has drifted
+	// ahead of it (rather than merely being ahead by uncommitted transactions).
+	sequenceStallTimeout = 10 * time.Second
+	// How far ahead of the account's sequence the transactions generated by
+	// GenerateInvalidTx are signed, so that they're rejected even if many of
+	// our transactions are in flight.
+	invalidSequenceOffset = 1_000_000
+)
+
 // PerpxBankClient implements loadtest.Client for PerpX bank send transactions
 type PerpxBankClient struct {
 	config   loadtest.Config
-	strategy *strategies.BankSendStrategy
+	strategy strategies.Strategy
 
 	// Account information
 	privKey    cryptotypes.PrivKey
@@ -38,37 +51,135 @@ type PerpxBankClient struct {
 	accountNum uint64
 	sequence   uint64 // Local sequence counter (atomic)
 
+	// Static signing data, computed once at construction so that GenerateTx
+	// only has to deal with the per-transaction sequence number. Deriving the
+	// public key in particular is an elliptic curve multiplication, which is
+	// far too expensive to repeat for every transaction.
+	pubKey     cryptotypes.PubKey
+	addrStr    string
+	chainID    string
+	feeCoins   sdk.Coins
+	feeGranter sdk.AccAddress // The account that pays the fees via a fee allowance, if any.
+	gasLimit   uint64
+	msgsPerTx  int  // How many strategy messages to pack into each transaction.
+	seqFree    bool // Whether the chain leaves the sequence unchanged for the strategy's transactions.
+
 	// Encoding config
 	encCfg app.EncodingConfig
 
 	// Lazy initialization: query account info on first use
-	accountQueried  bool
+	accountQueried  atomic.Bool
 	accountQueryMtx sync.Mutex
-	restURL         string // Cached REST API URL
+	rpcURL          string           // Cached CometBFT RPC URL
+	restURL         string           // Cached REST API URL
+	httpClient      *http.Client     // Shared, connection-pooled client for REST API queries
+	grpcAddr        string           // The node's gRPC API address
+	grpcConn        *grpc.ClientConn // Shared connection for gRPC account queries, if queries are made via gRPC
+	grpcFallback    *grpcFallback    // Switches queries to gRPC if the REST API is unavailable, unless they are made via gRPC anyway
+	accountCreator  *accountCreator  // Creates the account if it doesn't exist, if enabled
+	accountOverride bool             // Set if the account number and sequence were configured, rather than queried (see accountOverrides).
+	signPool        *signPool        // Bounds the number of transactions signed at once by all clients, if set
+	fees            *feeMeter        // Totals the fees of all clients' transactions, if set
+	accounts        *accountSet      // Keeps track of the accounts touched by all clients' transactions, if set
+
+	// Sequence drift detection (only accessed by CheckSequence)
+	lastChainSeq       uint64    // The on-chain sequence seen by the most recent check.
+	lastChainSeqChange time.Time // When the on-chain sequence was first seen at lastChainSeq.
 }
 
-// Ensure PerpxBankClient implements Client
-var _ loadtest.Client = (*PerpxBankClient)(nil)
+// Ensure PerpxBankClient implements Client and its optional interfaces
+var (
+	_ loadtest.Client                = (*PerpxBankClient)(nil)
+	_ loadtest.ClientPreparer        = (*PerpxBankClient)(nil)
+	_ loadtest.ClientResyncer        = (*PerpxBankClient)(nil)
+	_ loadtest.ClientSequenceChecker = (*PerpxBankClient)(nil)
+	_ loadtest.ClientValidator       = (*PerpxBankClient)(nil)
+	_ loadtest.ClientLifecycle       = (*PerpxBankClient)(nil)
+	_ loadtest.ClientFailureInjector = (*PerpxBankClient)(nil)
+)
 
 // NewPerpxBankClient creates a new PerpX bank client.
 // The id is a per-worker identifier used to derive a unique account key.
-func NewPerpxBankClient(cfg loadtest.Config, strategy *strategies.BankSendStrategy, seedKey string, id int) (*PerpxBankClient, error) {
+func NewPerpxBankClient(cfg loadtest.Config, strategy strategies.Strategy, seedKey string, id int, httpClient *http.Client) (*PerpxBankClient, error) {
 	encCfg := app.GetEncodingConfig()
 
 	// Use the provided worker id so each worker gets a distinct account.
 	workerID := id
 
-	// Generate deterministic key for this worker (similar to regen_genesis_addresses.go)
-	seedStr := fmt.Sprintf("bench worker %d seed phrase for load testing account", workerID)
-	seed := sha256.Sum256([]byte(seedStr))
-	// Use worker ID as path for additional determinism
-	adjustedSeed := sha256.Sum256(append(seed[:], byte(workerID)))
-	privKeyBytes, _ := btcec.PrivKeyFromBytes(adjustedSeed[:])
-	privKey := &secp256k1.PrivKey{Key: privKeyBytes.Serialize()}
+	// Generate deterministic key for this worker (exactly as the seeder does)
+	privKey := accounts.WorkerPrivKey(workerID)
 	addr := sdk.AccAddress(privKey.PubKey().Address())
 
-	// Connect to gRPC endpoint (use first endpoint, convert ws:// to http://)
-	rpcEndpoint := cfg.Endpoints[0]
+	rpcEndpoint, restURL := endpointURLs(cfg)
+
+	// Each transaction carries msgsPerTx of the strategy's messages, so scale
+	// the gas limit (and therefore the fees, which are based on the gas limit
+	// and the strategy's gas price) accordingly
+	msgsPerTx := cfg.MsgsPerTx
+	if msgsPerTx < 1 {
+		msgsPerTx = 1
+	}
+	gasOverrides, err := strategies.ParseGasOverrides(cfg.StrategyGas)
+	if err != nil {
+		return nil, fmt.Errorf("invalid strategy gas: %w", err)
+	}
+	gasLimit := gasOverrides.GasPerMsg(strategy) * uint64(msgsPerTx)
+	feeDenom := cfg.FeeDenom
+	if len(feeDenom) == 0 {
+		feeDenom = strategy.Denom()
+	}
+	gasPrices, err := getStrategyGasPrices(cfg, feeDenom)
+	if err != nil {
+		return nil, err
+	}
+	gasPrice := gasPrices.GasPrice(strategy, sdk.NewDecCoin(feeDenom, math.NewInt(defaultMinGasPrice)))
+	feeAmount := gasPrice.Amount.MulInt(math.NewIntFromUint64(gasLimit)).Ceil().TruncateInt()
+	feeCoins := sdk.NewCoins(sdk.NewCoin(feeDenom, feeAmount))
+
+	// Workers take turns with the fee granters (as the seeder assigns their
+	// allowances), so that each granter pays the fees of an equal share
+	var feeGranter sdk.AccAddress
+	if len(cfg.FeeGranters) > 0 {
+		if feeGranter, err = sdk.AccAddressFromBech32(cfg.FeeGranters[workerID%len(cfg.FeeGranters)]); err != nil {
+			return nil, fmt.Errorf("invalid fee granter: %w", err)
+		}
+	}
+
+	// Initialize client without querying account (lazy initialization)
+	// This avoids blocking during initialization, which happens before WebSocket connection
+	client := &PerpxBankClient{
+		config:     cfg,
+		strategy:   strategy,
+		privKey:    privKey,
+		addr:       addr,
+		accountNum: 0, // Will be queried lazily
+		sequence:   0, // Will be queried lazily
+		pubKey:     privKey.PubKey(),
+		addrStr:    addr.String(),
+		chainID:    strategy.ChainID(),
+		feeCoins:   feeCoins,
+		feeGranter: feeGranter,
+		gasLimit:   gasLimit,
+		msgsPerTx:  msgsPerTx,
+		encCfg:     encCfg,
+		rpcURL:     rpcEndpoint,
+		restURL:    restURL,
+		httpClient: httpClient,
+		grpcAddr:   grpcAddrFor(rpcEndpoint),
+	}
+
+	if sf, ok := strategy.(strategies.SequenceFreeStrategy); ok {
+		client.seqFree = sf.SequenceFree()
+	}
+
+	return client, nil
+}
+
+// endpointURLs derives the CometBFT RPC and REST API URLs of the node behind
+// the first configured endpoint.
+func endpointURLs(cfg loadtest.Config) (rpcEndpoint, restURL string) {
+	// Use the first endpoint, converting ws:// to http://
+	rpcEndpoint = cfg.Endpoints[0]
 	if len(rpcEndpoint) > 0 {
 		// Convert ws://localhost:36657/websocket to http://localhost:36657
 		rpcEndpoint = convertWebSocketToHTTP(rpcEndpoint)
@@ -80,6 +191,22 @@ func NewPerpxBankClient(cfg loadtest.Config, strategy *strategies.BankSendStrate
 		rpcEndpoint = "http://localhost:36657"
 	}
 
+	// Use REST API for account queries (more reliable than gRPC, avoids frame size issues)
+	// Convert RPC URL to REST API URL (same logic as seed.go)
+	restURL = strings.Replace(rpcEndpoint, ":36657", ":31317", 1)
+	if !strings.Contains(restURL, ":31317") {
+		// If port wasn't 36657, try to infer REST port or use default
+		restURL = strings.Replace(rpcEndpoint, ":26657", ":1317", 1)
+		if !strings.Contains(restURL, ":1317") {
+			// Default to localhost:31317 if we can't determine
+			restURL = "http://localhost:31317"
+		}
+	}
+	return rpcEndpoint, restURL
+}
+
+// grpcAddrFor converts an RPC URL to the address of the node's gRPC API.
+func grpcAddrFor(rpcEndpoint string) string {
 	// Convert RPC port to gRPC port (36657 -> 39090, 26657 -> 9090)
 	grpcAddr := rpcEndpoint
 	if len(grpcAddr) > 7 && grpcAddr[:7] == "http://" {
@@ -94,45 +221,120 @@ func NewPerpxBankClient(cfg loadtest.Config, strategy *strategies.BankSendStrate
 		// Default to gRPC port if no port specified
 		grpcAddr = "localhost:39090"
 	}
+	return grpcAddr
+}
 
-	// Use REST API for account queries (more reliable than gRPC, avoids frame size issues)
-	// Convert RPC URL to REST API URL (same logic as seed.go)
-	restURL := strings.Replace(rpcEndpoint, ":36657", ":31317", 1)
-	if !strings.Contains(restURL, ":31317") {
-		// If port wasn't 36657, try to infer REST port or use default
-		restURL = strings.Replace(rpcEndpoint, ":26657", ":1317", 1)
-		if !strings.Contains(restURL, ":1317") {
-			// Default to localhost:31317 if we can't determine
-			restURL = "http://localhost:31317"
-		}
-	}
+// Prepare queries the client's account number and sequence ahead of the load
+// test, so that the queries for all clients don't happen at once.
+func (c *PerpxBankClient) Prepare() error {
+	return c.ensureAccountQueried()
+}
 
-	// Initialize client without querying account (lazy initialization)
-	// This avoids blocking during initialization, which happens before WebSocket connection
-	client := &PerpxBankClient{
-		config:         cfg,
-		strategy:       strategy,
-		privKey:        privKey,
-		addr:           addr,
-		accountNum:     0, // Will be queried lazily
-		sequence:       0, // Will be queried lazily
-		encCfg:         encCfg,
-		accountQueried: false,
-		restURL:        restURL,
+// Resync re-queries the client's account sequence, which may have fallen out
+// of step with the chain if transactions were lost along with a dropped
+// connection. It does nothing if the account number and sequence were
+// configured instead.
+func (c *PerpxBankClient) Resync() error {
+	if c.accountOverride {
+		// there's nothing to query
+		return nil
 	}
+	c.accountQueryMtx.Lock()
+	c.accountQueried.Store(false)
+	c.accountQueryMtx.Unlock()
+	return c.ensureAccountQueried()
+}
 
-	return client, nil
+// useKey makes the client send from the account of the given private key
+// instead of its worker account. It must be called before the account is
+// queried.
+func (c *PerpxBankClient) useKey(privKey cryptotypes.PrivKey) {
+	c.privKey = privKey
+	c.pubKey = privKey.PubKey()
+	c.addr = sdk.AccAddress(c.pubKey.Address())
+	c.addrStr = c.addr.String()
+}
+
+// overrideAccount sets the client's account number and starting sequence to
+// the given configured values, so that they are never queried.
+func (c *PerpxBankClient) overrideAccount(accountNum, sequence uint64) {
+	c.accountNum = accountNum
+	atomic.StoreUint64(&c.sequence, sequence)
+	c.accountOverride = true
+	c.accountQueried.Store(true)
 }
 
 // ensureAccountQueried queries account info if not already queried (lazy initialization)
 func (c *PerpxBankClient) ensureAccountQueried() error {
+	// Fast path: avoid taking the lock on every transaction once initialized.
+	if c.accountQueried.Load() {
+		return nil
+	}
+
 	c.accountQueryMtx.Lock()
 	defer c.accountQueryMtx.Unlock()
 
-	if c.accountQueried {
+	if c.accountQueried.Load() {
 		return nil
 	}
 
+	accountNum, sequence, err := c.queryAccountWithRetries()
+	if errors.Is(err, errAccountNotFound) && c.accountCreator != nil {
+		if err := c.accountCreator.Create(c.addr); err != nil {
+			return err
+		}
+		accountNum, sequence, err = c.queryAccountWithRetries()
+	}
+	if err != nil {
+		return err
+	}
+	c.accountNum = accountNum
+	atomic.StoreUint64(&c.sequence, sequence)
+	c.accountQueried.Store(true)
+
+	return nil
+}
+
+// CheckSequence compares the local sequence counter with the account's
+// on-chain sequence and corrects it if it has drifted. The on-chain sequence
+// normally lags behind ours by the transactions that are yet to be committed,
+// so we only move our counter back once the chain's sequence hasn't advanced
+// for sequenceStallTimeout, which means that our transactions are being
+// rejected (e.g. because an earlier one was rejected, leaving a gap). It does
+// nothing if the account number and sequence were configured instead.
+func (c *PerpxBankClient) CheckSequence() (bool, error) {
+	if c.accountOverride {
+		return false, nil
+	}
+	if err := c.ensureAccountQueried(); err != nil {
+		return false, err
+	}
+	_, chainSeq, err := c.queryAccount()
+	if err != nil {
+		return false, err
+	}
+	now := time.Now()
+	if c.lastChainSeqChange.IsZero() || chainSeq != c.lastChainSeq {
+		c.lastChainSeq = chainSeq
+		c.lastChainSeqChange = now
+	}
+
+	local := atomic.LoadUint64(&c.sequence)
+	stalled := now.Sub(c.lastChainSeqChange) >= sequenceStallTimeout
+	if chainSeq > local || (chainSeq < local && stalled) {
+		atomic.StoreUint64(&c.sequence, chainSeq)
+		return true, nil
+	}
+	return false, nil
+}
+
+// queryAccount queries the client's account number and sequence from the
+// chain.
+func (c *PerpxBankClient) queryAccount() (accountNum, sequence uint64, err error) {
+	if conn := c.queryConn(); conn != nil {
+		return c.queryAccountGRPC(conn)
+	}
+
 	// Query account info via REST API (same approach as seed.go)
 	accountURL := fmt.Sprintf("%s/cosmos/auth/v1beta1/accounts/%s", c.restURL, c.addr.String())
 
@@ -146,41 +348,95 @@ func (c *PerpxBankClient) ensureAccountQueried() error {
 			} `json:"pub_key"`
 			AccountNumber string `json:"account_number"`
 			Sequence      string `json:"sequence"`
+			// The accounts of EVM-enabled chains wrap the base account
+			BaseAccount *struct {
+				AccountNumber string `json:"account_number"`
+				Sequence      string `json:"sequence"`
+			} `json:"base_account"`
 		} `json:"account"`
 	}
 
-	// Use a simple HTTP client with timeout (same approach as seed.go)
-	httpClient := &http.Client{Timeout: 10 * time.Second}
-	resp, err := httpClient.Get(accountURL)
+	resp, err := c.httpClient.Get(accountURL)
 	if err != nil {
-		return fmt.Errorf("failed to query account via REST API at %s (account %s may not exist - run 'seed' command first): %w", accountURL, c.addr.String(), err)
+		if conn := c.grpcFallback.Activate(err); conn != nil {
+			return c.queryAccountGRPC(conn)
+		}
+		return 0, 0, &transientQueryError{fmt.Errorf("failed to query account via REST API at %s (account %s may not exist - run 'seed' command first): %w", accountURL, c.addr.String(), err)}
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotFound {
+		body, _ := io.ReadAll(resp.Body)
+		return 0, 0, fmt.Errorf("%w: %s (account %s was never funded - run 'seed' command first, or pass --auto-create-accounts)", errAccountNotFound, string(body), c.addr.String())
+	}
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("failed to query account: HTTP %d: %s (account %s may not exist - run 'seed' command first)", resp.StatusCode, string(body), c.addr.String())
+		err := fmt.Errorf("failed to query account: HTTP %d: %s (account %s may not exist - run 'seed' command first)", resp.StatusCode, string(body), c.addr.String())
+		if transientHTTPStatus(resp.StatusCode) {
+			return 0, 0, &transientQueryError{err}
+		}
+		return 0, 0, err
 	}
 
 	if err := json.NewDecoder(resp.Body).Decode(&accountResp); err != nil {
-		return fmt.Errorf("failed to decode account response: %w", err)
+		return 0, 0, fmt.Errorf("failed to decode account response: %w", err)
+	}
+	if base := accountResp.Account.BaseAccount; base != nil {
+		accountResp.Account.AccountNumber = base.AccountNumber
+		accountResp.Account.Sequence = base.Sequence
 	}
 
 	// Parse account number and sequence
-	accountNum, err := strconv.ParseUint(accountResp.Account.AccountNumber, 10, 64)
+	accountNum, err = strconv.ParseUint(accountResp.Account.AccountNumber, 10, 64)
 	if err != nil {
-		return fmt.Errorf("failed to parse account number: %w", err)
+		return 0, 0, fmt.Errorf("failed to parse account number: %w", err)
 	}
-	sequence, err := strconv.ParseUint(accountResp.Account.Sequence, 10, 64)
+	sequence, err = strconv.ParseUint(accountResp.Account.Sequence, 10, 64)
 	if err != nil {
-		return fmt.Errorf("failed to parse sequence: %w", err)
+		return 0, 0, fmt.Errorf("failed to parse sequence: %w", err)
 	}
 
-	c.accountNum = accountNum
-	c.sequence = sequence
-	c.accountQueried = true
+	return accountNum, sequence, nil
+}
 
-	return nil
+// checkDenomKnown queries the chain's bank module to determine whether the
+// strategy's denom is known, i.e. whether it either has registered denom
+// metadata or a non-zero total supply. Base denoms frequently have no
+// metadata, so the supply is checked as a fallback.
+func (c *PerpxBankClient) checkDenomKnown() (bool, error) {
+	denom := c.strategy.Denom()
+	metadataURL := fmt.Sprintf("%s/cosmos/bank/v1beta1/denoms_metadata/%s", c.restURL, url.PathEscape(denom))
+	resp, err := c.httpClient.Get(metadataURL)
+	if err != nil {
+		return false, fmt.Errorf("failed to query denom metadata via REST API at %s: %w", metadataURL, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return true, nil
+	}
+
+	supplyURL := fmt.Sprintf("%s/cosmos/bank/v1beta1/supply/by_denom?denom=%s", c.restURL, url.QueryEscape(denom))
+	resp, err = c.httpClient.Get(supplyURL)
+	if err != nil {
+		return false, fmt.Errorf("failed to query denom supply via REST API at %s: %w", supplyURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return false, fmt.Errorf("failed to query denom supply: HTTP %d: %s", resp.StatusCode, string(body))
+	}
+
+	var supplyResp struct {
+		Amount struct {
+			Denom  string `json:"denom"`
+			Amount string `json:"amount"`
+		} `json:"amount"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&supplyResp); err != nil {
+		return false, fmt.Errorf("failed to decode denom supply response: %w", err)
+	}
+	supply, ok := math.NewIntFromString(supplyResp.Amount.Amount)
+	return ok && supply.IsPositive(), nil
 }
 
 // GenerateTx generates a bank send transaction
@@ -190,77 +446,91 @@ func (c *PerpxBankClient) GenerateTx() ([]byte, error) {
 		return nil, err
 	}
 
-	// Get current sequence and increment atomically
-	seq := atomic.AddUint64(&c.sequence, 1) - 1
-
-	// Build transaction using strategy
-	txBuilder := c.encCfg.TxConfig.NewTxBuilder()
-
-	// Create bank send message
-	msg, err := c.strategy.CreateMsg(c.addr.String())
+	// Build the messages first, so that a failure to do so neither burns a
+	// sequence number nor charges the fee budget
+	msgs, err := c.createMsgs()
 	if err != nil {
-		return nil, fmt.Errorf("failed to create message: %w", err)
+		return nil, err
 	}
 
-	if err := txBuilder.SetMsgs(msg); err != nil {
-		return nil, fmt.Errorf("failed to set message: %w", err)
+	if !c.fees.spend(c.feeCoins) {
+		return nil, loadtest.ErrClientExhausted
 	}
 
-	// Set fees based on gas limit and minimum gas price
-	// Minimum gas price: 25000000000aperpx per unit of gas (from cmd/perpxd/cmd/config.go)
-	gasLimit := uint64(200000)
-	minGasPrice := math.NewInt(25000000000) // 25 billion aperpx per unit of gas
-	feeAmount := minGasPrice.Mul(math.NewInt(int64(gasLimit)))
-	feeCoins := sdk.NewCoins(sdk.NewCoin(c.strategy.Denom(), feeAmount))
-	txBuilder.SetFeeAmount(feeCoins)
-	txBuilder.SetGasLimit(gasLimit)
-
-	// First round: set empty signatures to gather signer infos (required for SIGN_MODE_DIRECT)
-	sigV2Empty := signing.SignatureV2{
-		PubKey: c.privKey.PubKey(),
-		Data: &signing.SingleSignatureData{
-			SignMode:  signing.SignMode_SIGN_MODE_DIRECT,
-			Signature: nil,
-		},
-		Sequence: seq,
-	}
-	if err := txBuilder.SetSignatures(sigV2Empty); err != nil {
-		return nil, fmt.Errorf("failed to set empty signature: %w", err)
-	}
-
-	// Second round: actually sign the transaction
-	signerData := authsigning.SignerData{
-		Address:       c.addr.String(),
-		ChainID:       c.strategy.ChainID(),
-		AccountNumber: c.accountNum,
-		Sequence:      seq,
-		PubKey:        c.privKey.PubKey(),
-	}
-
-	sigV2, err := tx.SignWithPrivKey(
-		context.Background(),
-		signing.SignMode_SIGN_MODE_DIRECT,
-		signerData,
-		txBuilder,
-		c.privKey,
-		c.encCfg.TxConfig,
-		seq,
-	)
-	if err != nil {
-		return nil, fmt.Errorf("failed to sign: %w", err)
+	// Get current sequence and increment atomically (once per transaction,
+	// regardless of how many messages it carries), unless the chain won't
+	// increment it either
+	var seq uint64
+	if c.seqFree {
+		seq = atomic.LoadUint64(&c.sequence)
+	} else {
+		seq = atomic.AddUint64(&c.sequence, 1) - 1
 	}
 
-	if err := txBuilder.SetSignatures(sigV2); err != nil {
-		return nil, fmt.Errorf("failed to set signature: %w", err)
+	c.accounts.add(c.addrStr, msgs)
+	return c.signTx(seq, msgs)
+}
+
+// GenerateInvalidTx generates a transaction that the chain rejects for
+// carrying a sequence number far beyond the account's. It sends a single base
+// unit to the sender itself, so that it doesn't affect the strategy's state,
+// and leaves the sequence used by GenerateTx unchanged.
+func (c *PerpxBankClient) GenerateInvalidTx() ([]byte, error) {
+	if err := c.ensureAccountQueried(); err != nil {
+		return nil, err
+	}
+	msg := &banktypes.MsgSend{
+		FromAddress: c.addrStr,
+		ToAddress:   c.addrStr,
+		Amount:      sdk.NewCoins(sdk.NewCoin(c.strategy.Denom(), math.OneInt())),
 	}
+	return c.signTx(atomic.LoadUint64(&c.sequence)+invalidSequenceOffset, []sdk.Msg{msg})
+}
 
-	// Encode transaction
-	txBytes, err := c.encCfg.TxConfig.TxEncoder()(txBuilder.GetTx())
+// buildTx builds, signs and encodes a transaction with the given sequence
+// number.
+func (c *PerpxBankClient) buildTx(seq uint64) ([]byte, error) {
+	msgs, err := c.createMsgs()
 	if err != nil {
-		return nil, fmt.Errorf("failed to encode transaction: %w", err)
+		return nil, err
 	}
+	return c.signTx(seq, msgs)
+}
+
+// createMsgs creates the messages of a transaction, calling the strategy once
+// per message so that each one is built independently.
+func (c *PerpxBankClient) createMsgs() ([]sdk.Msg, error) {
+	msgs := make([]sdk.Msg, c.msgsPerTx)
+	for i := range msgs {
+		msg, err := c.strategy.CreateMsg(c.addrStr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create message: %w", err)
+		}
+		msgs[i] = msg
+	}
+	return msgs, nil
+}
 
-	return txBytes, nil
+// signTx builds, signs and encodes a transaction of the given messages with
+// the given sequence number.
+func (c *PerpxBankClient) signTx(seq uint64, msgs []sdk.Msg) ([]byte, error) {
+	p := seed.TxParams{
+		PubKey:        c.pubKey,
+		Address:       c.addrStr,
+		ChainID:       c.chainID,
+		AccountNumber: c.accountNum,
+		Sequence:      seq,
+		FeeCoins:      c.feeCoins,
+		FeeGranter:    c.feeGranter,
+		GasLimit:      c.gasLimit,
+		SignMode:      signing.SignMode_SIGN_MODE_DIRECT,
+	}
+	var txBytes []byte
+	err := c.signPool.Do(func() (err error) {
+		txBytes, err = seed.BuildTx(c.encCfg.TxConfig, p, msgs, seed.PrivKeySigner(c.encCfg.TxConfig, c.privKey))
+		return err
+	})
+	return txBytes, err
 }
 
 // convertWebSocketToHTTP converts ws://host:port/path to http://host:port
@@ -0,0 +1,65 @@
+package client
+
+import (
+	"errors"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+
+	"cosmossdk.io/math"
+	"github.com/1119-Labs/perpx-chain/protocol/app"
+	"github.com/1119-Labs/perpx-load-test/internal/logging"
+	"github.com/1119-Labs/perpx-load-test/pkg/accounts"
+	"github.com/1119-Labs/perpx-load-test/pkg/strategies"
+)
+
+// failingStrategy fails to create messages while err is set.
+type failingStrategy struct {
+	strategies.Strategy
+	err error
+}
+
+func (s *failingStrategy) CreateMsg(fromAddr string) (sdk.Msg, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.Strategy.CreateMsg(fromAddr)
+}
+
+func TestGenerateTxMsgFailure(t *testing.T) {
+	require.NoError(t, accounts.SetBech32Prefix(accounts.DefaultBech32Prefix))
+	privKey := accounts.WorkerPrivKey(0)
+	sink := sdk.AccAddress(accounts.WorkerPrivKey(1).PubKey().Address()).String()
+	bankSend, err := strategies.NewBankSendStrategy("localperpxprotocol", "aperpx", sink)
+	require.NoError(t, err)
+	strategy := &failingStrategy{Strategy: bankSend, err: errors.New("no message")}
+	fee := sdk.NewCoins(sdk.NewCoin("aperpx", math.NewInt(100)))
+	c := &PerpxBankClient{
+		strategy:  strategy,
+		privKey:   privKey,
+		pubKey:    privKey.PubKey(),
+		addrStr:   sdk.AccAddress(privKey.PubKey().Address()).String(),
+		chainID:   "localperpxprotocol",
+		sequence:  3,
+		feeCoins:  fee,
+		gasLimit:  200_000,
+		msgsPerTx: 1,
+		encCfg:    app.GetEncodingConfig(),
+		fees:      &feeMeter{logger: logging.NewNoopLogger()},
+	}
+	c.accountQueried.Store(true)
+
+	// a transaction whose messages can't be created consumes neither a
+	// sequence number nor any of the fee budget
+	_, err = c.GenerateTx()
+	require.ErrorContains(t, err, "no message")
+	require.Equal(t, uint64(3), c.sequence)
+	require.True(t, c.fees.Spent().Empty())
+
+	strategy.err = nil
+	_, err = c.GenerateTx()
+	require.NoError(t, err)
+	require.Equal(t, uint64(4), c.sequence)
+	require.Equal(t, fee, c.fees.Spent())
+}
@@ -2,26 +2,164 @@ package client
 
 import (
 	"fmt"
+	"net/http"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
 	"sync/atomic"
+	"time"
 
+	"github.com/1119-Labs/perpx-load-test/internal/httpclient"
+	"github.com/1119-Labs/perpx-load-test/internal/logging"
+	"github.com/1119-Labs/perpx-load-test/pkg/accounts"
 	"github.com/1119-Labs/perpx-load-test/pkg/loadtest"
 	"github.com/1119-Labs/perpx-load-test/pkg/strategies"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"google.golang.org/grpc"
 )
 
+// The address to which bank sends go by default (the faucet's).
+const defaultSinkAddress = "perpx1kyfmupa8z5jtxgf5f4gt285sepeg6eqnzvs25m"
+
 // PerpxBankClientFactory implements loadtest.ClientFactory for PerpX bank send transactions
 type PerpxBankClientFactory struct {
 	// workerCounter assigns a unique, monotonically increasing ID to each
 	// client instance so that each worker derives a distinct key.
 	workerCounter int64
+
+	// denomCheck ensures that we only check whether the denom is known to
+	// the chain once, rather than for every client.
+	denomCheck sync.Once
+
+	// encodingCheck ensures that we only check whether the node can decode
+	// and verify our transactions once, and encodingErr is its result.
+	encodingCheck sync.Once
+	encodingErr   error
+
+	// signatureCheck ensures that we only verify a generated transaction's
+	// signature once, and signatureErr is its result.
+	signatureCheck sync.Once
+	signatureErr   error
+
+	// wasmCheck ensures that we only check whether the chain supports wasm
+	// (and has the executed contract) once, and wasmErr is its result.
+	wasmCheck sync.Once
+	wasmErr   error
+
+	// spendEstimate ensures that we only log the estimated spend per worker
+	// once, and lowBalances counts the workers whose balances are unlikely to
+	// last for the whole run.
+	spendEstimate sync.Once
+	lowBalances   atomic.Int64
+
+	// bech32Once ensures that the SDK's address prefixes are configured
+	// before the first client is created, even if the configuration wasn't
+	// validated.
+	bech32Once sync.Once
+	bech32Err  error
+
+	// keyAlgoOnce ensures that the key algorithm is configured before the
+	// first worker's key is derived, even if the configuration wasn't
+	// validated, and keyAlgoCheck that we only check it against the chain
+	// once, with keyAlgoErr the result of either.
+	keyAlgoOnce  sync.Once
+	keyAlgoCheck sync.Once
+	keyAlgoErr   error
+
+	// The salt from which fresh recipient addresses are derived, if not
+	// configured explicitly.
+	recipientSaltOnce sync.Once
+	recipientSalt     string
+
+	// The distribution from which amounts are drawn.
+	amountsOnce sync.Once
+	amounts     strategies.AmountDistribution
+	amountsErr  error
+
+	// The recipients read from the recipients file, if configured.
+	recipientsOnce sync.Once
+	recipients     []string
+	recipientsErr  error
+
+	// The accounts with the largest balances, if sending to them (see
+	// getTopAccounts).
+	topAccountsOnce sync.Once
+	topAccounts     []string
+
+	// Tops up worker accounts during the run, if auto-refund is enabled.
+	refuelerOnce sync.Once
+	refueler     *refueler
+	refuelerErr  error
+
+	// The latest block height, shared by all clients that place short-term
+	// orders.
+	heightsOnce sync.Once
+	heights     *heightTracker
+
+	// Creates the accounts of unseeded workers, if enabled.
+	creatorOnce sync.Once
+	creator     *accountCreator
+	creatorErr  error
+
+	// The salt from which the workers' fresh accounts are derived in cold
+	// start mode, generated once per run.
+	coldStartSaltOnce sync.Once
+	coldStartSalt     string
+
+	// The gRPC connection shared by all clients, if account state is queried
+	// via gRPC.
+	grpcConnOnce sync.Once
+	grpcConn     *grpc.ClientConn
+	grpcConnErr  error
+
+	// Switches all clients' queries to gRPC if the REST API turns out to be
+	// unavailable, unless they query via gRPC anyway.
+	grpcFallbackOnce sync.Once
+	grpcFallback     *grpcFallback
+
+	// The configured account numbers and starting sequences, if any.
+	overridesOnce sync.Once
+	overrides     *accountOverrides
+	overridesErr  error
+
+	// Totals the fees of all clients' transactions, within the fee budget if
+	// one is configured.
+	feesOnce sync.Once
+	fees     *feeMeter
+	feesErr  error
+
+	// Keeps track of the accounts touched by all clients' transactions.
+	accountsOnce sync.Once
+	accounts     *accountSet
+
+	// Bounds the number of transactions signed at once by all clients, if
+	// sign-concurrency is set.
+	signPoolOnce sync.Once
+	signPool     *signPool
+
+	// The HTTP client shared by all clients, so that they can reuse each
+	// other's connections to the REST API.
+	httpClientOnce sync.Once
+	httpClient     *http.Client
+
+	logger logging.Logger
 }
 
-// Ensure PerpxBankClientFactory implements ClientFactory
-var _ loadtest.ClientFactory = (*PerpxBankClientFactory)(nil)
+// Ensure PerpxBankClientFactory implements ClientFactory and FeeTracker
+var (
+	_ loadtest.ClientFactory  = (*PerpxBankClientFactory)(nil)
+	_ loadtest.FeeTracker     = (*PerpxBankClientFactory)(nil)
+	_ loadtest.AccountTracker = (*PerpxBankClientFactory)(nil)
+	_ loadtest.FactoryStopper = (*PerpxBankClientFactory)(nil)
+)
 
 // NewPerpxBankClientFactory creates a new factory instance
 func NewPerpxBankClientFactory() *PerpxBankClientFactory {
-	return &PerpxBankClientFactory{}
+	return &PerpxBankClientFactory{
+		logger: logging.NewLogrusLogger("perpx-bank"),
+	}
 }
 
 // ValidateConfig validates the configuration for PerpX bank client
@@ -35,6 +173,102 @@ func (f *PerpxBankClientFactory) ValidateConfig(cfg loadtest.Config) error {
 	if len(cfg.Endpoints) == 0 {
 		return fmt.Errorf("at least one endpoint must be specified")
 	}
+	// Addresses are parsed from here on, so they must be parsed with the
+	// chain's prefix
+	if err := accounts.SetBech32Prefix(getBech32Prefix(cfg)); err != nil {
+		return err
+	}
+	if err := accounts.SetKeyAlgo(getKeyAlgo(cfg)); err != nil {
+		return err
+	}
+	if accounts.KeyAlgo() == accounts.KeyAlgoEthSecp256k1 {
+		// the SDK's encoding config can't decode Ethereum-style public keys,
+		// be it in the generated transactions or in the queried accounts
+		if cfg.ValidateOnly || cfg.VerifySignatures {
+			return fmt.Errorf("key-algo %s cannot be combined with validate-only or verify-signatures", accounts.KeyAlgoEthSecp256k1)
+		}
+		if cfg.QueryTransport == queryTransportGRPC {
+			return fmt.Errorf("key-algo %s requires query-transport %s", accounts.KeyAlgoEthSecp256k1, queryTransportREST)
+		}
+	}
+	if err := sdk.ValidateDenom(getEnv("LOADTEST_DENOM", "aperpx")); err != nil {
+		return fmt.Errorf("invalid denom: %w", err)
+	}
+	if _, err := strategies.ParseAmountDistribution(cfg.AmountDistribution); err != nil {
+		return fmt.Errorf("invalid amount-distribution: %w", err)
+	}
+	if len(cfg.HotAccount) > 0 {
+		if _, err := sdk.AccAddressFromBech32(cfg.HotAccount); err != nil {
+			return fmt.Errorf("invalid hot-account: %w", err)
+		}
+	}
+	if cfg.ColdStart && (len(cfg.HotAccount) > 0 || len(cfg.FeeGranters) > 0) {
+		// the seeder only makes grants to the seeded worker accounts
+		return fmt.Errorf("cold-start cannot be combined with hot-account or fee-granters")
+	}
+	for _, granter := range cfg.FeeGranters {
+		if _, err := sdk.AccAddressFromBech32(granter); err != nil {
+			return fmt.Errorf("invalid fee-granters: %w", err)
+		}
+	}
+	if len(cfg.PerpRoundTrip) > 0 {
+		if _, err := strategies.ParsePerpRoundTripParams(cfg.PerpRoundTrip); err != nil {
+			return fmt.Errorf("invalid perp-round-trip: %w", err)
+		}
+	}
+	if len(cfg.WasmContract) > 0 {
+		if _, err := newWasmExecuteStrategy(cfg, getEnv("LOADTEST_CHAIN_ID", "localperpxprotocol"), getEnv("LOADTEST_DENOM", "aperpx")); err != nil {
+			return fmt.Errorf("invalid wasm-contract: %w", err)
+		}
+		if cfg.ValidateOnly || cfg.VerifySignatures {
+			// both decode the generated transaction, which requires its
+			// message types to be registered
+			return fmt.Errorf("wasm-contract cannot be combined with validate-only or verify-signatures")
+		}
+	}
+	if len(cfg.Recipients) > 0 {
+		if _, err := parseTopAccounts(cfg.Recipients); err != nil {
+			return fmt.Errorf("invalid recipients: %w", err)
+		}
+	}
+	if len(cfg.StrategySequence) > 0 {
+		names, err := strategies.ParseStrategySequence(cfg.StrategySequence)
+		if err != nil {
+			return fmt.Errorf("invalid strategy-sequence: %w", err)
+		}
+		if hot := slices.Contains(names, strategies.HotAccountStrategyName); hot != (len(cfg.HotAccount) > 0) {
+			return fmt.Errorf("strategy-sequence must include %s if and only if hot-account is set", strategies.HotAccountStrategyName)
+		}
+	}
+	if _, err := strategies.ParseGasOverrides(cfg.StrategyGas); err != nil {
+		return fmt.Errorf("invalid strategy-gas: %w", err)
+	}
+	if _, err := getStrategyGasPrices(cfg, feeDenomOrDefault(cfg)); err != nil {
+		return err
+	}
+	switch cfg.QueryTransport {
+	case "", queryTransportREST, queryTransportGRPC:
+	default:
+		return fmt.Errorf("invalid query-transport %q (must be %q or %q)", cfg.QueryTransport, queryTransportREST, queryTransportGRPC)
+	}
+	if feeDenom := getFeeDenom(cfg); len(feeDenom) > 0 {
+		if err := sdk.ValidateDenom(feeDenom); err != nil {
+			return fmt.Errorf("invalid fee denom: %w", err)
+		}
+	}
+	if len(cfg.AutoRefund) > 0 {
+		if _, _, err := parseRefund(cfg); err != nil {
+			return err
+		}
+	}
+	if _, err := parseFeeBudget(cfg.FeeBudget, feeDenomOrDefault(cfg)); err != nil {
+		return err
+	}
+	if overrides, err := parseAccountOverrides(cfg); err != nil {
+		return err
+	} else if overrides != nil && (cfg.AutoCreateAccounts || cfg.ColdStart) {
+		return fmt.Errorf("account-number cannot be combined with auto-create-accounts or cold-start")
+	}
 	return nil
 }
 
@@ -43,27 +277,500 @@ func (f *PerpxBankClientFactory) NewClient(cfg loadtest.Config) (loadtest.Client
 	// Get chain configuration from environment or use defaults
 	chainID := getEnv("LOADTEST_CHAIN_ID", "localperpxprotocol")
 	denom := getEnv("LOADTEST_DENOM", "aperpx")
-	sinkAddr := getEnv("LOADTEST_SINK_ADDRESS", "perpx1kyfmupa8z5jtxgf5f4gt285sepeg6eqnzvs25m") // Faucet address
+	bech32Prefix := getBech32Prefix(cfg)
+	f.bech32Once.Do(func() {
+		f.bech32Err = accounts.SetBech32Prefix(bech32Prefix)
+	})
+	if f.bech32Err != nil {
+		return nil, f.bech32Err
+	}
+	f.keyAlgoOnce.Do(func() {
+		f.keyAlgoErr = accounts.SetKeyAlgo(getKeyAlgo(cfg))
+	})
+	if f.keyAlgoErr != nil {
+		return nil, f.keyAlgoErr
+	}
+	sinkAddr := getEnv("LOADTEST_SINK_ADDRESS", "")
+	if len(sinkAddr) == 0 {
+		// the faucet address, with the chain's prefix
+		var err error
+		if sinkAddr, err = accounts.ConvertBech32Prefix(defaultSinkAddress, bech32Prefix); err != nil {
+			return nil, err
+		}
+	}
 	seedKey := getEnv("LOADTEST_SEED_KEY", "")
+	// The client pays fees in cfg.FeeDenom, or the transfer denom if unset
+	cfg.FeeDenom = getFeeDenom(cfg)
+
+	// Assign a unique worker ID for this client so each worker uses a distinct account.
+	workerID := atomic.AddInt64(&f.workerCounter, 1) - 1
 
 	// Create bank send strategy
-	strategy, err := strategies.NewBankSendStrategy(chainID, denom, sinkAddr)
+	var strategy strategies.Strategy
+	var err error
+	switch {
+	case len(cfg.StrategySequence) > 0:
+		strategy, err = f.newSequenceStrategy(cfg, chainID, denom, sinkAddr, workerID)
+	case len(cfg.HotAccount) > 0:
+		strategy, err = strategies.NewHotAccountStrategy(chainID, denom, cfg.HotAccount)
+	case len(cfg.PerpRoundTrip) > 0:
+		var params strategies.PerpRoundTripParams
+		if params, err = strategies.ParsePerpRoundTripParams(cfg.PerpRoundTrip); err == nil {
+			strategy, err = strategies.NewPerpRoundTripStrategy(chainID, denom, params, f.getHeightTracker(cfg).Height)
+		}
+	case len(cfg.WasmContract) > 0:
+		strategy, err = newWasmExecuteStrategy(cfg, chainID, denom)
+	default:
+		strategy, err = f.newBankSendStrategy(cfg, chainID, denom, sinkAddr, workerID)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to create bank send strategy: %w", err)
+		return nil, fmt.Errorf("failed to create strategy: %w", err)
 	}
-
-	// Assign a unique worker ID for this client so each worker uses a distinct account.
-	workerID := atomic.AddInt64(&f.workerCounter, 1) - 1
+	amounts, err := f.getAmountDistribution(cfg)
+	if err != nil {
+		return nil, err
+	}
+	// Each worker's amounts are reproducible from run to run with the same
+	// random seed
+	strategy.SetAmountDistribution(amounts, loadtest.DeriveSeed(cfg.RandomSeed, "strategy", int(workerID)))
 
 	// Create client with strategy and worker ID
-	client, err := NewPerpxBankClient(cfg, strategy, seedKey, int(workerID))
+	client, err := NewPerpxBankClient(cfg, strategy, seedKey, int(workerID), f.getHTTPClient(cfg))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create PerpX bank client: %w", err)
 	}
+	if cfg.ColdStart {
+		client.useKey(accounts.FreshWorkerPrivKey(f.getColdStartSalt(cfg), int(workerID)))
+	}
+	if cfg.QueryTransport == queryTransportGRPC {
+		if client.grpcConn, err = f.getGRPCConn(client.grpcAddr, cfg.Compression); err != nil {
+			return nil, err
+		}
+	} else {
+		grpcAddr := client.grpcAddr
+		f.grpcFallbackOnce.Do(func() {
+			f.grpcFallback = &grpcFallback{
+				dial:   func() (*grpc.ClientConn, error) { return f.getGRPCConn(grpcAddr, cfg.Compression) },
+				logger: f.logger,
+			}
+		})
+		client.grpcFallback = f.grpcFallback
+	}
+
+	f.overridesOnce.Do(func() {
+		f.overrides, f.overridesErr = parseAccountOverrides(cfg)
+		if f.overrides != nil {
+			f.logger.Error("WARNING: using the configured account numbers and sequences without querying them - " +
+				"sequence drift (e.g. after a rejected transaction) won't be corrected")
+		}
+	})
+	if f.overridesErr != nil {
+		return nil, f.overridesErr
+	}
+	if f.overrides != nil {
+		accountNum, sequence, err := f.overrides.forWorker(int(workerID))
+		if err != nil {
+			return nil, err
+		}
+		client.overrideAccount(accountNum, sequence)
+	}
+
+	f.feesOnce.Do(func() {
+		var budget sdk.Coins
+		if budget, f.feesErr = parseFeeBudget(cfg.FeeBudget, feeDenomOrDefault(cfg)); f.feesErr == nil {
+			f.fees = &feeMeter{budget: budget, logger: f.logger}
+		}
+	})
+	if f.feesErr != nil {
+		return nil, f.feesErr
+	}
+	client.fees = f.fees
+
+	f.accountsOnce.Do(func() {
+		f.accounts = newAccountSet()
+	})
+	client.accounts = f.accounts
+
+	f.signPoolOnce.Do(func() {
+		f.signPool = newSignPool(cfg.SignConcurrency)
+	})
+	client.signPool = f.signPool
+
+	if cfg.AutoCreateAccounts || cfg.ColdStart {
+		f.creatorOnce.Do(func() {
+			f.creator, f.creatorErr = newAccountCreator(cfg, client, f.logger)
+		})
+		if f.creatorErr != nil {
+			return nil, f.creatorErr
+		}
+		client.accountCreator = f.creator
+	}
+
+	// A denom that passes format validation may still be a typo, which would
+	// otherwise only surface as mass transaction failures
+	f.denomCheck.Do(func() {
+		known, err := client.checkDenomKnown()
+		if err != nil {
+			f.logger.Debug("Unable to check whether denom is known to the chain", "denom", denom, "err", err)
+			return
+		}
+		if !known {
+			f.logger.Error("WARNING: denom has no bank metadata and no supply on the chain - transactions will likely fail", "denom", denom)
+		}
+	})
+
+	// Keys of the wrong algorithm sign from accounts that were never funded
+	// (or, with the right addresses, with signatures that the chain can't
+	// verify). The chain only tells by its accounts' public keys, so the
+	// check is skipped if they can't be queried.
+	f.keyAlgoCheck.Do(func() {
+		typeURL, err := accounts.ChainPubKeyType(client.httpClient, client.restURL)
+		if err != nil {
+			f.logger.Debug("Unable to check the key algorithm against the chain", "err", err)
+			return
+		}
+		f.keyAlgoErr = accounts.CheckPubKeyType(typeURL)
+	})
+	if f.keyAlgoErr != nil {
+		return nil, f.keyAlgoErr
+	}
+
+	// Transactions executing a contract can't even be decoded by a chain
+	// without wasm, which the encoding check below would misdiagnose
+	if wasm, ok := strategy.(*strategies.WasmExecuteStrategy); ok {
+		f.wasmCheck.Do(func() {
+			f.wasmErr = f.checkWasmContract(client, wasm.Contract())
+		})
+		if f.wasmErr != nil {
+			return nil, f.wasmErr
+		}
+	}
+
+	if cfg.VerifySignatures {
+		f.signatureCheck.Do(func() {
+			f.signatureErr = f.checkSignature(client)
+		})
+		if f.signatureErr != nil {
+			return nil, f.signatureErr
+		}
+	}
+
+	// Transactions the node can't decode or verify would all be rejected,
+	// so fail fast with a diagnosis instead
+	f.encodingCheck.Do(func() {
+		f.encodingErr = f.checkEncoding(client)
+	})
+	if f.encodingErr != nil {
+		return nil, f.encodingErr
+	}
+
+	if len(cfg.AutoRefund) > 0 {
+		f.refuelerOnce.Do(func() {
+			f.refueler, f.refuelerErr = newRefueler(cfg, client, f.logger)
+			if f.refuelerErr == nil {
+				go f.refueler.Run()
+			}
+		})
+		if f.refuelerErr != nil {
+			return nil, f.refuelerErr
+		}
+		f.refueler.Add(client.addr)
+	} else {
+		// Warn up front about accounts that are likely to run dry, rather
+		// than have them start failing part way through the run
+		f.checkBalance(cfg, client, int(workerID))
+	}
 
 	return client, nil
 }
 
+// getBech32Prefix returns the configured Bech32 prefix, falling back to the
+// LOADTEST_BECH32_PREFIX environment variable and then the PerpX prefix.
+func getBech32Prefix(cfg loadtest.Config) string {
+	if len(cfg.Bech32Prefix) > 0 {
+		return cfg.Bech32Prefix
+	}
+	return getEnv("LOADTEST_BECH32_PREFIX", accounts.DefaultBech32Prefix)
+}
+
+// getKeyAlgo returns the configured key algorithm, falling back to the
+// LOADTEST_KEY_ALGO environment variable and then that of PerpX keys.
+func getKeyAlgo(cfg loadtest.Config) string {
+	if len(cfg.KeyAlgo) > 0 {
+		return cfg.KeyAlgo
+	}
+	return getEnv("LOADTEST_KEY_ALGO", accounts.DefaultKeyAlgo)
+}
+
+// getFeeDenom returns the configured fee denom, falling back to the
+// LOADTEST_FEE_DENOM environment variable. An empty result means that fees are
+// paid in the transfer denom.
+func getFeeDenom(cfg loadtest.Config) string {
+	if len(cfg.FeeDenom) > 0 {
+		return cfg.FeeDenom
+	}
+	return getEnv("LOADTEST_FEE_DENOM", "")
+}
+
+// getStrategyGasPrices returns the configured per-strategy gas prices: those
+// of strategy-gas-price and, for the strategies that it doesn't list, those of
+// the LOADTEST_GAS_PRICE_<strategy> environment variables, with the strategy
+// named either as is (e.g. LOADTEST_GAS_PRICE_perp-round-trip) or in upper
+// case with underscores (e.g. LOADTEST_GAS_PRICE_PERP_ROUND_TRIP). Fees are
+// paid in a single denom, so every price must be in the given fee denom.
+func getStrategyGasPrices(cfg loadtest.Config, feeDenom string) (strategies.GasPrices, error) {
+	prices, err := strategies.ParseGasPrices(cfg.StrategyGasPrice)
+	if err != nil {
+		return nil, fmt.Errorf("invalid strategy-gas-price: %w", err)
+	}
+	for _, name := range strategies.StrategyNames {
+		if _, ok := prices[name]; ok {
+			continue
+		}
+		val := getEnv("LOADTEST_GAS_PRICE_"+name, getEnv("LOADTEST_GAS_PRICE_"+strings.ToUpper(strings.ReplaceAll(name, "-", "_")), ""))
+		if len(val) == 0 {
+			continue
+		}
+		envPrices, err := strategies.ParseGasPrices(name + "=" + val)
+		if err != nil {
+			return nil, fmt.Errorf("invalid LOADTEST_GAS_PRICE_%s: %w", name, err)
+		}
+		prices[name] = envPrices[name]
+	}
+	for name, price := range prices {
+		if price.Denom != feeDenom {
+			return nil, fmt.Errorf("gas price %s of strategy %s must be in the fee denom %s", price, name, feeDenom)
+		}
+	}
+	return prices, nil
+}
+
+// feeDenomOrDefault returns the denom in which fees are paid: the fee denom,
+// if configured, and the transfer denom otherwise.
+func feeDenomOrDefault(cfg loadtest.Config) string {
+	if feeDenom := getFeeDenom(cfg); len(feeDenom) > 0 {
+		return feeDenom
+	}
+	return getEnv("LOADTEST_DENOM", "aperpx")
+}
+
+// FeesSpent returns the total fees of the transactions generated by the
+// factory's clients so far.
+func (f *PerpxBankClientFactory) FeesSpent() string {
+	if f.fees == nil {
+		return ""
+	}
+	return f.fees.Spent().String()
+}
+
+// AccountsTouched returns the number of distinct accounts that sent funds in,
+// and that were sent funds by, the transactions generated by the factory's
+// clients so far, and the number that did either.
+func (f *PerpxBankClientFactory) AccountsTouched() (senders, recipients, total int) {
+	if f.accounts == nil {
+		return 0, 0, 0
+	}
+	return f.accounts.Counts()
+}
+
+// FeeBudgetExhausted returns whether the factory's clients stopped generating
+// transactions because of the fee budget.
+func (f *PerpxBankClientFactory) FeeBudgetExhausted() bool {
+	return f.fees != nil && f.fees.Exhausted()
+}
+
+// Stop stops the background tasks run on behalf of the factory's clients,
+// i.e. the auto-refund, once the load test is over.
+func (f *PerpxBankClientFactory) Stop() {
+	if f.refueler != nil {
+		f.refueler.Stop()
+	}
+}
+
+// getAmountDistribution parses the configured amount distribution once (which
+// may involve reading a histogram file).
+func (f *PerpxBankClientFactory) getAmountDistribution(cfg loadtest.Config) (strategies.AmountDistribution, error) {
+	f.amountsOnce.Do(func() {
+		f.amounts, f.amountsErr = strategies.ParseAmountDistribution(cfg.AmountDistribution)
+		if f.amountsErr == nil && len(cfg.AmountDistribution) > 0 {
+			f.logger.Info("Drawing amounts from distribution", "distribution", cfg.AmountDistribution, "mean", f.amounts.Mean())
+		}
+	})
+	return f.amounts, f.amountsErr
+}
+
+// getRecipients reads the recipients file once, and returns the valid
+// recipients in it. Invalid lines are reported and skipped, unless the
+// recipients file is meant to be strictly valid.
+func (f *PerpxBankClientFactory) getRecipients(cfg loadtest.Config) ([]string, error) {
+	f.recipientsOnce.Do(func() {
+		recipients, invalid, err := strategies.ReadRecipientsFile(cfg.RecipientsFile)
+		if err != nil {
+			f.recipientsErr = err
+			return
+		}
+		for _, lineErr := range invalid {
+			if cfg.RecipientsFileStrict {
+				f.logger.Error("Invalid line in recipients file", "file", cfg.RecipientsFile, "line", lineErr.Line, "err", lineErr.Err)
+			} else {
+				f.logger.Error("WARNING: skipping invalid line in recipients file", "file", cfg.RecipientsFile, "line", lineErr.Line, "err", lineErr.Err)
+			}
+		}
+		if len(invalid) > 0 && cfg.RecipientsFileStrict {
+			f.recipientsErr = fmt.Errorf("recipients file %s contains %d invalid line(s)", cfg.RecipientsFile, len(invalid))
+			return
+		}
+		if len(recipients) == 0 {
+			f.recipientsErr = fmt.Errorf("recipients file %s contains no valid recipients", cfg.RecipientsFile)
+			return
+		}
+		f.logger.Info("Loaded recipients", "file", cfg.RecipientsFile, "recipients", len(recipients), "skipped", len(invalid))
+		f.recipients = recipients
+	})
+	return f.recipients, f.recipientsErr
+}
+
+// getTopAccounts queries the accounts with the largest balances of the given
+// denom for --recipients top-accounts:N, caching them for all clients. It
+// returns nil if they can't be queried (e.g. because the node doesn't support
+// the query), in which case clients send to the sink instead.
+func (f *PerpxBankClientFactory) getTopAccounts(cfg loadtest.Config, denom string) []string {
+	f.topAccountsOnce.Do(func() {
+		// the option was validated by ValidateConfig
+		n, _ := parseTopAccounts(cfg.Recipients)
+		_, restURL := endpointURLs(cfg)
+		recipients, err := queryTopAccounts(f.getHTTPClient(cfg), restURL, denom, n)
+		if err != nil {
+			f.logger.Error("WARNING: failed to query the top accounts by balance - sending to the sink instead", "err", err)
+			return
+		}
+		if len(recipients) < n {
+			f.logger.Info("Fewer accounts hold the denom than requested", "denom", denom, "requested", n, "found", len(recipients))
+		}
+		f.logger.Info("Sending to the top accounts by balance", "denom", denom, "recipients", len(recipients))
+		f.topAccounts = recipients
+	})
+	return f.topAccounts
+}
+
+// newBankSendStrategy creates the given worker's bank send strategy, which
+// sends to the recipients selected by the configuration (the sink by
+// default).
+func (f *PerpxBankClientFactory) newBankSendStrategy(cfg loadtest.Config, chainID, denom, sinkAddr string, workerID int64) (strategies.Strategy, error) {
+	switch {
+	case cfg.SelfSend:
+		return strategies.NewBankSelfSendStrategy(chainID, denom)
+	case cfg.FreshRecipients > 0:
+		return strategies.NewBankFreshRecipientStrategy(chainID, denom, f.getRecipientSalt(cfg), int(workerID), cfg.FreshRecipients)
+	case len(cfg.RecipientsFile) > 0:
+		recipients, err := f.getRecipients(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return strategies.NewBankFileRecipientStrategy(chainID, denom, recipients, int(workerID))
+	case cfg.SinksPerWorker > 0:
+		return strategies.NewBankWorkerSinksStrategy(chainID, denom, int(workerID), cfg.SinksPerWorker)
+	case len(cfg.Recipients) > 0:
+		if recipients := f.getTopAccounts(cfg, denom); len(recipients) > 0 {
+			return strategies.NewBankFileRecipientStrategy(chainID, denom, recipients, int(workerID))
+		}
+	}
+	return strategies.NewBankSendStrategy(chainID, denom, sinkAddr)
+}
+
+// newWasmExecuteStrategy creates the strategy that executes the configured
+// wasm contract.
+func newWasmExecuteStrategy(cfg loadtest.Config, chainID, denom string) (*strategies.WasmExecuteStrategy, error) {
+	var funds sdk.Coins
+	if len(cfg.WasmFunds) > 0 {
+		var err error
+		if funds, err = sdk.ParseCoinsNormalized(cfg.WasmFunds); err != nil {
+			return nil, fmt.Errorf("invalid wasm-funds: %w", err)
+		}
+	}
+	return strategies.NewWasmExecuteStrategy(chainID, denom, cfg.WasmContract, cfg.WasmMsg, funds)
+}
+
+// newSequenceStrategy creates the given worker's strategy sequence, in which
+// every occurrence of a strategy is the same instance (so that, e.g., a
+// hot-account strategy still alternates between funding and defunding).
+func (f *PerpxBankClientFactory) newSequenceStrategy(cfg loadtest.Config, chainID, denom, sinkAddr string, workerID int64) (strategies.Strategy, error) {
+	names, err := strategies.ParseStrategySequence(cfg.StrategySequence)
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]strategies.Strategy)
+	sequence := make([]strategies.Strategy, 0, len(names))
+	for _, name := range names {
+		strategy, ok := byName[name]
+		if !ok {
+			switch name {
+			case strategies.HotAccountStrategyName:
+				strategy, err = strategies.NewHotAccountStrategy(chainID, denom, cfg.HotAccount)
+			default:
+				strategy, err = f.newBankSendStrategy(cfg, chainID, denom, sinkAddr, workerID)
+			}
+			if err != nil {
+				return nil, err
+			}
+			byName[name] = strategy
+		}
+		sequence = append(sequence, strategy)
+	}
+	return strategies.NewSequenceStrategy(sequence...)
+}
+
+func (f *PerpxBankClientFactory) getHeightTracker(cfg loadtest.Config) *heightTracker {
+	f.heightsOnce.Do(func() {
+		_, restURL := endpointURLs(cfg)
+		f.heights = newHeightTracker(restURL, f.getHTTPClient(cfg))
+	})
+	return f.heights
+}
+
+func (f *PerpxBankClientFactory) getGRPCConn(grpcAddr string, compression bool) (*grpc.ClientConn, error) {
+	f.grpcConnOnce.Do(func() {
+		f.grpcConn, f.grpcConnErr = dialGRPC(grpcAddr, compression)
+		if f.grpcConnErr == nil {
+			f.logger.Info("Querying account state via gRPC", "addr", grpcAddr)
+		}
+	})
+	return f.grpcConn, f.grpcConnErr
+}
+
+func (f *PerpxBankClientFactory) getHTTPClient(cfg loadtest.Config) *http.Client {
+	f.httpClientOnce.Do(func() {
+		f.httpClient = httpclient.New(time.Duration(cfg.HTTPTimeout)*time.Second, cfg.HTTPMaxIdleConns, cfg.Compression)
+	})
+	return f.httpClient
+}
+
+// getRecipientSalt returns the configured recipient salt or, if none was
+// configured, a salt derived from the run's random seed (which is new for
+// every run unless configured, so that the recipients are actually fresh).
+func (f *PerpxBankClientFactory) getRecipientSalt(cfg loadtest.Config) string {
+	if len(cfg.RecipientSalt) > 0 {
+		return cfg.RecipientSalt
+	}
+	f.recipientSaltOnce.Do(func() {
+		f.recipientSalt = strconv.FormatInt(loadtest.DeriveSeed(cfg.RandomSeed, "recipients", 0), 10)
+		f.logger.Info("Generated fresh recipient salt (use --recipient-salt or --random-seed to reproduce the same recipients)", "salt", f.recipientSalt)
+	})
+	return f.recipientSalt
+}
+
+// getColdStartSalt returns the salt from which the workers' fresh accounts
+// are derived in cold start mode. It is derived from the run's random seed,
+// which is new for every run unless configured, so that the accounts have
+// never been seen before.
+func (f *PerpxBankClientFactory) getColdStartSalt(cfg loadtest.Config) string {
+	f.coldStartSaltOnce.Do(func() {
+		f.coldStartSalt = strconv.FormatInt(loadtest.DeriveSeed(cfg.RandomSeed, "cold-start", 0), 10)
+		f.logger.Info("Sending from fresh worker accounts", "salt", f.coldStartSalt)
+	})
+	return f.coldStartSalt
+}
+
 func getEnv(key, defaultValue string) string {
 	if val := os.Getenv(key); val != "" {
 		return val
@@ -0,0 +1,42 @@
+package client
+
+import (
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+
+	"cosmossdk.io/math"
+	"github.com/1119-Labs/perpx-load-test/pkg/loadtest"
+	"github.com/1119-Labs/perpx-load-test/pkg/strategies"
+)
+
+func TestGetStrategyGasPrices(t *testing.T) {
+	t.Setenv("LOADTEST_GAS_PRICE_perp-round-trip", "40000000000aperpx")
+	t.Setenv("LOADTEST_GAS_PRICE_HOT_ACCOUNT", "30000000000.5aperpx")
+	cfg := loadtest.Config{StrategyGasPrice: "perp-round-trip=50000000000aperpx"}
+
+	prices, err := getStrategyGasPrices(cfg, "aperpx")
+	require.NoError(t, err)
+	// the flag takes precedence over the environment
+	require.Equal(t, sdk.NewDecCoin("aperpx", math.NewInt(50000000000)), prices[strategies.PerpRoundTripStrategyName])
+	require.Equal(t, "30000000000.500000000000000000aperpx", prices[strategies.HotAccountStrategyName].String())
+	_, ok := prices[strategies.BankSendStrategyName]
+	require.False(t, ok)
+
+	// a sequence pays the highest of its strategies' prices
+	bankSend, err := strategies.NewBankSendStrategy("localperpxprotocol", "aperpx", "perpx1kyfmupa8z5jtxgf5f4gt285sepeg6eqnzvs25m")
+	require.NoError(t, err)
+	hotAccount, err := strategies.NewHotAccountStrategy("localperpxprotocol", "aperpx", "perpx1kyfmupa8z5jtxgf5f4gt285sepeg6eqnzvs25m")
+	require.NoError(t, err)
+	seq, err := strategies.NewSequenceStrategy(bankSend, hotAccount)
+	require.NoError(t, err)
+	defaultPrice := sdk.NewDecCoin("aperpx", math.NewInt(defaultMinGasPrice))
+	require.Equal(t, defaultPrice, prices.GasPrice(bankSend, defaultPrice))
+	require.Equal(t, prices[strategies.HotAccountStrategyName], prices.GasPrice(seq, defaultPrice))
+
+	_, err = getStrategyGasPrices(cfg, "uusdc")
+	require.ErrorContains(t, err, "must be in the fee denom uusdc")
+	_, err = getStrategyGasPrices(loadtest.Config{StrategyGasPrice: "bank-send=0aperpx"}, "aperpx")
+	require.Error(t, err)
+}
@@ -0,0 +1,80 @@
+package client
+
+import (
+	"fmt"
+	"sync"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/1119-Labs/perpx-load-test/internal/logging"
+)
+
+// feeMeter totals the fees of the transactions generated by all of a
+// factory's clients and, if given a budget, refuses to spend more than it. It
+// counts the fees of every transaction generated, whether or not it ends up
+// being committed (and charged), so the total is an upper bound on the fees
+// actually paid.
+type feeMeter struct {
+	mtx       sync.Mutex
+	spent     sdk.Coins
+	budget    sdk.Coins // No limit if empty.
+	exhausted bool      // Set once a transaction was refused for exceeding the budget.
+	logger    logging.Logger
+}
+
+// parseFeeBudget parses the configured fee budget, which must be given in the
+// fee denom.
+func parseFeeBudget(budget, feeDenom string) (sdk.Coins, error) {
+	if len(budget) == 0 {
+		return nil, nil
+	}
+	coins, err := sdk.ParseCoinsNormalized(budget)
+	if err != nil {
+		return nil, fmt.Errorf("invalid fee-budget: %w", err)
+	}
+	if coins.Empty() {
+		return nil, fmt.Errorf("fee-budget must be positive")
+	}
+	if coins.AmountOf(feeDenom).IsZero() {
+		return nil, fmt.Errorf("fee-budget %s must include the fee denom %s", coins, feeDenom)
+	}
+	return coins, nil
+}
+
+// spend adds the given fee to the total, unless that would take the total of
+// any of the budget's denoms beyond the budget, in which case it returns
+// false. A nil meter spends without keeping count.
+func (m *feeMeter) spend(fee sdk.Coins) bool {
+	if m == nil {
+		return true
+	}
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	spent := m.spent.Add(fee...)
+	for _, limit := range m.budget {
+		if spent.AmountOf(limit.Denom).GT(limit.Amount) {
+			if !m.exhausted {
+				m.logger.Info("Fee budget reached, stopping", "spent", m.spent.String(), "budget", m.budget.String())
+				m.exhausted = true
+			}
+			return false
+		}
+	}
+	m.spent = spent
+	return true
+}
+
+// Spent returns the total fees spent so far.
+func (m *feeMeter) Spent() sdk.Coins {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	return m.spent
+}
+
+// Exhausted returns whether a transaction was refused for exceeding the
+// budget.
+func (m *feeMeter) Exhausted() bool {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	return m.exhausted
+}
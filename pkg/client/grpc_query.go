@@ -0,0 +1,146 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/status"
+
+	"github.com/1119-Labs/perpx-load-test/internal/logging"
+)
+
+const (
+	queryTransportREST = "rest"
+	queryTransportGRPC = "grpc"
+
+	// The largest gRPC response we accept. gRPC's default limit of 4 MiB is
+	// easily exceeded by nodes that return large responses, which fails the
+	// query with a "frame too large"/"received message larger than max"
+	// error, so we allow for far larger ones.
+	grpcMaxRecvMsgSize = 64 * 1024 * 1024
+)
+
+// dialGRPC sets up a connection to the given gRPC address, which accepts
+// responses of up to grpcMaxRecvMsgSize. With compression, requests are gzip
+// compressed, and so are the responses of nodes that support it.
+func dialGRPC(grpcAddr string, compression bool) (*grpc.ClientConn, error) {
+	callOpts := []grpc.CallOption{grpc.MaxCallRecvMsgSize(grpcMaxRecvMsgSize)}
+	if compression {
+		callOpts = append(callOpts, grpc.UseCompressor(gzip.Name))
+	}
+	conn, err := grpc.Dial(
+		grpcAddr,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(callOpts...),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up gRPC connection to %s: %w", grpcAddr, err)
+	}
+	return conn, nil
+}
+
+// grpcFallback switches a factory's clients from querying account state via
+// the REST API to querying it via gRPC once the REST API turns out to be
+// unavailable, as it is on many hardened nodes that disable the REST gateway.
+// It is shared by all of the factory's clients, so that the switch is only
+// made (and logged) once.
+type grpcFallback struct {
+	dial   func() (*grpc.ClientConn, error)
+	logger logging.Logger
+
+	mtx  sync.Mutex
+	conn atomic.Pointer[grpc.ClientConn] // Set once queries have switched to gRPC.
+}
+
+// Conn returns the gRPC connection through which queries are made, or nil if
+// they are still made via the REST API.
+func (g *grpcFallback) Conn() *grpc.ClientConn {
+	if g == nil {
+		return nil
+	}
+	return g.conn.Load()
+}
+
+// Activate switches queries to gRPC if the given error from a REST API query
+// shows that the REST API is unreachable (i.e. the connection to it was
+// refused). It returns the gRPC connection through which to retry the query,
+// or nil if the error isn't one that gRPC could help with.
+func (g *grpcFallback) Activate(restErr error) *grpc.ClientConn {
+	if g == nil || !errors.Is(restErr, syscall.ECONNREFUSED) {
+		return nil
+	}
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+	if conn := g.conn.Load(); conn != nil {
+		return conn
+	}
+	conn, err := g.dial()
+	if err != nil {
+		g.logger.Error("REST API is unavailable, and failed to fall back to gRPC", "restErr", restErr, "err", err)
+		return nil
+	}
+	g.logger.Info("REST API is unavailable, falling back to gRPC for account and balance queries", "err", restErr)
+	g.conn.Store(conn)
+	return conn
+}
+
+// queryConn returns the gRPC connection through which the client makes its
+// account and balance queries, or nil if it makes them via the REST API.
+func (c *PerpxBankClient) queryConn() *grpc.ClientConn {
+	if c.grpcConn != nil {
+		return c.grpcConn
+	}
+	return c.grpcFallback.Conn()
+}
+
+// queryAccountGRPC queries the client's account number and sequence via the
+// node's gRPC API, through the given connection.
+func (c *PerpxBankClient) queryAccountGRPC(conn *grpc.ClientConn) (accountNum, sequence uint64, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(c.config.HTTPTimeout)*time.Second)
+	defer cancel()
+	resp, err := authtypes.NewQueryClient(conn).Account(ctx, &authtypes.QueryAccountRequest{Address: c.addrStr})
+	if status.Code(err) == codes.NotFound {
+		return 0, 0, fmt.Errorf("%w: %s (account %s was never funded - run 'seed' command first, or pass --auto-create-accounts)", errAccountNotFound, status.Convert(err).Message(), c.addrStr)
+	}
+	if err != nil {
+		transient := transientGRPCCode(status.Code(err))
+		err = fmt.Errorf("failed to query account via gRPC at %s (account %s may not exist - run 'seed' command first): %w", c.grpcAddr, c.addrStr, err)
+		if transient {
+			return 0, 0, &transientQueryError{err}
+		}
+		return 0, 0, err
+	}
+	var account sdk.AccountI
+	if err := c.encCfg.InterfaceRegistry.UnpackAny(resp.Account, &account); err != nil {
+		return 0, 0, fmt.Errorf("failed to decode account response: %w", err)
+	}
+	return account.GetAccountNumber(), account.GetSequence(), nil
+}
+
+// queryBalanceGRPC queries the client account's balance of the strategy's
+// denom via the node's gRPC API, through the given connection.
+func (c *PerpxBankClient) queryBalanceGRPC(conn *grpc.ClientConn) (math.Int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(c.config.HTTPTimeout)*time.Second)
+	defer cancel()
+	resp, err := banktypes.NewQueryClient(conn).Balance(ctx, &banktypes.QueryBalanceRequest{Address: c.addrStr, Denom: c.strategy.Denom()})
+	if err != nil {
+		return math.Int{}, fmt.Errorf("failed to query balance via gRPC at %s: %w", c.grpcAddr, err)
+	}
+	if resp.Balance == nil {
+		return math.ZeroInt(), nil
+	}
+	return resp.Balance.Amount, nil
+}
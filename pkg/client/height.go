@@ -0,0 +1,78 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// How long a queried block height is reused before it is queried again. A
+// height that is slightly out of date is good enough for good-til-block
+// orders, and all workers sharing one cached height keeps the query load
+// independent of the send rate.
+const heightRefreshInterval = 500 * time.Millisecond
+
+// heightTracker caches the chain's latest block height, as reported by the
+// REST API. It is safe for concurrent use.
+type heightTracker struct {
+	restURL    string
+	httpClient *http.Client
+
+	mtx       sync.Mutex
+	height    uint32
+	queriedAt time.Time
+}
+
+func newHeightTracker(restURL string, httpClient *http.Client) *heightTracker {
+	return &heightTracker{restURL: restURL, httpClient: httpClient}
+}
+
+// Height returns the latest block height, querying it if the cached height is
+// older than heightRefreshInterval.
+func (t *heightTracker) Height() (uint32, error) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	if !t.queriedAt.IsZero() && time.Since(t.queriedAt) < heightRefreshInterval {
+		return t.height, nil
+	}
+	height, err := t.queryHeight()
+	if err != nil {
+		return 0, err
+	}
+	t.height = height
+	t.queriedAt = time.Now()
+	return height, nil
+}
+
+func (t *heightTracker) queryHeight() (uint32, error) {
+	latestURL := fmt.Sprintf("%s/cosmos/base/tendermint/v1beta1/blocks/latest", t.restURL)
+	resp, err := t.httpClient.Get(latestURL)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query latest block via REST API at %s: %w", latestURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return 0, fmt.Errorf("failed to query latest block: HTTP %d: %s", resp.StatusCode, string(body))
+	}
+
+	var latestResp struct {
+		Block struct {
+			Header struct {
+				Height string `json:"height"`
+			} `json:"header"`
+		} `json:"block"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&latestResp); err != nil {
+		return 0, fmt.Errorf("failed to decode latest block response: %w", err)
+	}
+	height, err := strconv.ParseUint(latestResp.Block.Header.Height, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse latest block height: %w", err)
+	}
+	return uint32(height), nil
+}
@@ -0,0 +1,65 @@
+package client
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/1119-Labs/perpx-load-test/pkg/strategies"
+)
+
+// Address returns the address of the client's account.
+func (c *PerpxBankClient) Address() string {
+	return c.addrStr
+}
+
+// BroadcastMsgs signs the given messages into a transaction from the client's
+// account, with the same gas limit and fees as the load test's transactions,
+// and broadcasts it via the node's broadcast_tx_sync RPC method. Like
+// GenerateTx, it consumes a sequence number unless the strategy's messages
+// are sequence-free.
+func (c *PerpxBankClient) BroadcastMsgs(msgs ...sdk.Msg) error {
+	if err := c.ensureAccountQueried(); err != nil {
+		return err
+	}
+	if !c.fees.spend(c.feeCoins) {
+		return fmt.Errorf("fee budget exhausted")
+	}
+	seq := atomic.LoadUint64(&c.sequence)
+	txBytes, err := c.signTx(seq, msgs)
+	if err != nil {
+		return err
+	}
+	result, err := c.callTxMethod("broadcast_tx_sync", txBytes)
+	if err != nil {
+		return err
+	}
+	if result.Code != 0 {
+		return fmt.Errorf("transaction was rejected (%s code %d): %s", result.Codespace, result.Code, result.Log)
+	}
+	if !c.seqFree {
+		atomic.AddUint64(&c.sequence, 1)
+	}
+	return nil
+}
+
+// Warmup runs the strategy's warmup, if it has one.
+func (c *PerpxBankClient) Warmup() error {
+	if ls, ok := c.strategy.(strategies.LifecycleStrategy); ok {
+		return ls.Warmup(c)
+	}
+	return nil
+}
+
+// Teardown runs the strategy's teardown, if it has one.
+func (c *PerpxBankClient) Teardown() error {
+	if ls, ok := c.strategy.(strategies.LifecycleStrategy); ok {
+		return ls.Teardown(c)
+	}
+	return nil
+}
+
+// Ensure PerpxBankClient can broadcast strategies' warmup and teardown
+// transactions
+var _ strategies.MsgBroadcaster = (*PerpxBankClient)(nil)
@@ -0,0 +1,141 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	stdmath "math"
+	"net/http"
+	"net/url"
+
+	"cosmossdk.io/math"
+	"github.com/1119-Labs/perpx-load-test/pkg/loadtest"
+)
+
+// How many accounts likely to run out of funds we warn about individually,
+// before only counting them.
+const maxBalanceWarnings = 10
+
+// estimatedTxsPerWorker estimates the maximum number of transactions that a
+// single worker (i.e. a single connection) will send over the course of the
+// load test. Since this is used to warn about insufficient funds, it errs on
+// the high side: the rate of the most heavily weighted endpoint is assumed.
+func estimatedTxsPerWorker(cfg loadtest.Config) uint64 {
+	rate := cfg.Rate
+	if weights, err := loadtest.ParseEndpointWeights(cfg.EndpointWeights); err == nil {
+		for _, r := range weights.Rates(cfg.Rate, cfg.Endpoints) {
+			if r > rate {
+				rate = r
+			}
+		}
+	}
+	var txs uint64
+	if cfg.Time > 0 && cfg.SendPeriod > 0 {
+		txs = uint64(rate) * uint64(cfg.Time) / uint64(cfg.SendPeriod)
+		// spikes increase the average rate
+		if spike, err := loadtest.ParseSpikeSchedule(cfg.Spike); err == nil && spike != nil {
+			txs = uint64(stdmath.Ceil(float64(txs) * spike.MeanFactor()))
+		}
+	}
+	// the transaction count is shared between all workers, so a single
+	// worker could (in theory) send all of them
+	if cfg.Count > 0 && (txs == 0 || uint64(cfg.Count) < txs) {
+		txs = uint64(cfg.Count)
+	}
+	return txs
+}
+
+// spendPerTx returns the amount by which each transaction reduces the
+// sender's balance of the strategy's denom: its fee (unless it is paid in a
+// separate fee denom or by a fee granter), plus the amount sent by each of its messages (unless
+// the messages send funds back to the sender).
+func (c *PerpxBankClient) spendPerTx() math.Int {
+	spend := math.ZeroInt()
+	if c.feeGranter == nil {
+		spend = c.feeCoins.AmountOf(c.strategy.Denom())
+	}
+	if !c.strategy.SelfSend() {
+		spend = spend.Add(c.strategy.AmountPerMsg().MulRaw(int64(c.msgsPerTx)))
+	}
+	return spend
+}
+
+// queryBalance queries the client account's balance of the strategy's denom.
+func (c *PerpxBankClient) queryBalance() (math.Int, error) {
+	if conn := c.queryConn(); conn != nil {
+		return c.queryBalanceGRPC(conn)
+	}
+	balanceURL := fmt.Sprintf("%s/cosmos/bank/v1beta1/balances/%s/by_denom?denom=%s", c.restURL, c.addrStr, url.QueryEscape(c.strategy.Denom()))
+	resp, err := c.httpClient.Get(balanceURL)
+	if err != nil {
+		if conn := c.grpcFallback.Activate(err); conn != nil {
+			return c.queryBalanceGRPC(conn)
+		}
+		return math.Int{}, fmt.Errorf("failed to query balance via REST API at %s: %w", balanceURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return math.Int{}, fmt.Errorf("failed to query balance: HTTP %d: %s", resp.StatusCode, string(body))
+	}
+
+	var balanceResp struct {
+		Balance struct {
+			Denom  string `json:"denom"`
+			Amount string `json:"amount"`
+		} `json:"balance"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&balanceResp); err != nil {
+		return math.Int{}, fmt.Errorf("failed to decode balance response: %w", err)
+	}
+	balance, ok := math.NewIntFromString(balanceResp.Balance.Amount)
+	if !ok {
+		return math.Int{}, fmt.Errorf("invalid balance amount: %q", balanceResp.Balance.Amount)
+	}
+	return balance, nil
+}
+
+// checkBalance estimates whether the given client's balance will last for the
+// whole load test, and warns if it is likely to run dry.
+func (f *PerpxBankClientFactory) checkBalance(cfg loadtest.Config, client *PerpxBankClient, workerID int) {
+	txs := estimatedTxsPerWorker(cfg)
+	spendPerTx := client.spendPerTx()
+	spend := spendPerTx.Mul(math.NewIntFromUint64(txs))
+	f.spendEstimate.Do(func() {
+		f.logger.Info("Estimated maximum spend per worker",
+			"txs", txs,
+			"spendPerTx", spendPerTx.String()+client.strategy.Denom(),
+			"spend", spend.String()+client.strategy.Denom(),
+		)
+	})
+
+	balance, err := client.queryBalance()
+	if err != nil {
+		f.logger.Debug("Unable to check worker balance", "worker", workerID, "address", client.addrStr, "err", err)
+		return
+	}
+	if balance.GTE(spend) {
+		return
+	}
+	if balance.IsZero() && (cfg.AutoCreateAccounts || cfg.ColdStart) {
+		// the account may not exist yet, in which case it will be funded
+		// when it is created
+		return
+	}
+	switch n := f.lowBalances.Add(1); {
+	case n <= maxBalanceWarnings:
+		lastTxs := "0"
+		if spendPerTx.IsPositive() {
+			lastTxs = balance.Quo(spendPerTx).String()
+		}
+		f.logger.Error("WARNING: worker account is likely to run out of funds before the end of the run",
+			"worker", workerID,
+			"address", client.addrStr,
+			"balance", balance.String()+client.strategy.Denom(),
+			"spend", spend.String()+client.strategy.Denom(),
+			"affordableTxs", lastTxs,
+		)
+	case n == maxBalanceWarnings+1:
+		f.logger.Error("WARNING: more worker accounts are likely to run out of funds - suppressing further warnings")
+	}
+}
@@ -0,0 +1,68 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"time"
+
+	"google.golang.org/grpc/codes"
+)
+
+// How long to wait before retrying a failed account query the first time,
+// which doubles with every further retry up to maxQueryRetryBackoff.
+const (
+	queryRetryBackoff    = 500 * time.Millisecond
+	maxQueryRetryBackoff = 8 * time.Second
+)
+
+// transientQueryError wraps the error of a query that may well succeed if
+// retried, e.g. because the node was momentarily too busy to answer it.
+type transientQueryError struct {
+	err error
+}
+
+func (e *transientQueryError) Error() string { return e.err.Error() }
+
+func (e *transientQueryError) Unwrap() error { return e.err }
+
+// transientHTTPStatus returns whether a query that failed with the given HTTP
+// status is worth retrying: the node (or a gateway in front of it) was
+// overloaded or momentarily unavailable.
+func transientHTTPStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
+}
+
+// transientGRPCCode is like transientHTTPStatus, for gRPC queries.
+func transientGRPCCode(code codes.Code) bool {
+	switch code {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Aborted:
+		return true
+	}
+	return false
+}
+
+// queryAccountWithRetries queries the client's account number and sequence
+// like queryAccount, retrying transient failures up to QueryRetries times
+// with exponential backoff. The delays are jittered, so that workers whose
+// queries failed together (e.g. because they all started at once) don't all
+// retry together too. A missing account isn't retried.
+func (c *PerpxBankClient) queryAccountWithRetries() (accountNum, sequence uint64, err error) {
+	backoff := queryRetryBackoff
+	for retries := 0; ; retries++ {
+		accountNum, sequence, err = c.queryAccount()
+		var transient *transientQueryError
+		if !errors.As(err, &transient) {
+			return accountNum, sequence, err
+		}
+		if retries >= c.config.QueryRetries {
+			if retries > 0 {
+				err = fmt.Errorf("%w (gave up after %d retries)", err, retries)
+			}
+			return 0, 0, err
+		}
+		time.Sleep(backoff/2 + rand.N(backoff/2))
+		backoff = min(2*backoff, maxQueryRetryBackoff)
+	}
+}
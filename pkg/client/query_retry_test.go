@@ -0,0 +1,60 @@
+package client
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/1119-Labs/perpx-load-test/pkg/accounts"
+	"github.com/1119-Labs/perpx-load-test/pkg/loadtest"
+)
+
+// accountServer serves the given account query responses in turn, the last
+// of them repeatedly, counting the queries.
+func accountServer(t *testing.T, queries *atomic.Int32, statuses ...int) *PerpxBankClient {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(queries.Add(1))
+		status := statuses[min(n, len(statuses))-1]
+		w.WriteHeader(status)
+		if status == http.StatusOK {
+			_, _ = w.Write([]byte(`{"account":{"account_number":"7","sequence":"3"}}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+	require.NoError(t, accounts.SetBech32Prefix(accounts.DefaultBech32Prefix))
+	return &PerpxBankClient{
+		config:     loadtest.Config{QueryRetries: 2},
+		restURL:    server.URL,
+		httpClient: server.Client(),
+		addr:       sdk.AccAddress(accounts.WorkerPrivKey(0).PubKey().Address()),
+	}
+}
+
+func TestQueryAccountRetries(t *testing.T) {
+	// a busy node is retried until it answers
+	var queries atomic.Int32
+	c := accountServer(t, &queries, http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusOK)
+	accountNum, sequence, err := c.queryAccountWithRetries()
+	require.NoError(t, err)
+	require.Equal(t, uint64(7), accountNum)
+	require.Equal(t, uint64(3), sequence)
+	require.Equal(t, int32(3), queries.Load())
+
+	// but only so many times
+	queries.Store(0)
+	c = accountServer(t, &queries, http.StatusServiceUnavailable)
+	_, _, err = c.queryAccountWithRetries()
+	require.ErrorContains(t, err, "gave up after 2 retries")
+	require.Equal(t, int32(3), queries.Load())
+
+	// while a missing account isn't retried at all
+	queries.Store(0)
+	c = accountServer(t, &queries, http.StatusNotFound)
+	_, _, err = c.queryAccountWithRetries()
+	require.ErrorIs(t, err, errAccountNotFound)
+	require.Equal(t, int32(1), queries.Load())
+}
@@ -0,0 +1,202 @@
+package client
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"cosmossdk.io/math"
+	"github.com/1119-Labs/perpx-load-test/internal/logging"
+	"github.com/1119-Labs/perpx-load-test/pkg/loadtest"
+	"github.com/1119-Labs/perpx-load-test/pkg/seed"
+)
+
+// How often the refueler checks the workers' balances.
+const refuelInterval = 30 * time.Second
+
+// refueler periodically tops up the balances of worker accounts that have
+// dropped below a threshold, by sending them funds from the seed account.
+// This allows for long-running load tests without having to seed accounts
+// with enough funds for the whole run up front.
+type refueler struct {
+	funder    accountFunder
+	amount    sdk.Coins // How much to send to each worker that needs topping up.
+	threshold sdk.Coins // Workers are topped up once their balance of any of these denoms drops below the given amount.
+	batchSize int       // How many workers to top up per transaction.
+	logger    logging.Logger
+
+	mtx     sync.Mutex
+	workers []sdk.AccAddress
+
+	stop     chan struct{} // Close this to stop the refueler.
+	stopped  chan struct{} // Closed when Run has returned.
+	stopOnce sync.Once
+}
+
+// accountFunder is the part of seed.Funder with which the refueler checks
+// and tops up the workers' balances.
+type accountFunder interface {
+	Address() sdk.AccAddress
+	BalanceIn(addr sdk.AccAddress, coins sdk.Coins) (sdk.Coins, error)
+	Fund(recipients []sdk.AccAddress, amount sdk.Coins) (txHash, height string, err error)
+	Sync() error
+}
+
+// parseRefund parses the auto-refund amount and threshold. If no threshold is
+// given, workers are topped up once they have spent half of the amount.
+func parseRefund(cfg loadtest.Config) (amount, threshold sdk.Coins, err error) {
+	amount, err = sdk.ParseCoinsNormalized(cfg.AutoRefund)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid auto-refund amount: %w", err)
+	}
+	if amount.Empty() {
+		return nil, nil, fmt.Errorf("invalid auto-refund amount: must be positive")
+	}
+	if len(cfg.RefundThreshold) == 0 {
+		threshold = amount.QuoInt(math.NewInt(2))
+		return amount, threshold, nil
+	}
+	threshold, err = sdk.ParseCoinsNormalized(cfg.RefundThreshold)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid refund-threshold: %w", err)
+	}
+	// topping up wouldn't help workers that are low on a denom that isn't
+	// part of the top-up
+	for _, t := range threshold {
+		if !amount.AmountOf(t.Denom).IsPositive() {
+			return nil, nil, fmt.Errorf("refund-threshold denom %s is not part of the auto-refund amount (%s)", t.Denom, amount)
+		}
+	}
+	return amount, threshold, nil
+}
+
+// newRefueler creates a refueler that tops up workers from the seed account
+// configured for the seeder (via LOADTEST_SEED_KEY or
+// LOADTEST_SEED_PRIVATE_KEY), querying and broadcasting through the given
+// client's node.
+func newRefueler(cfg loadtest.Config, client *PerpxBankClient, logger logging.Logger) (*refueler, error) {
+	amount, threshold, err := parseRefund(cfg)
+	if err != nil {
+		return nil, err
+	}
+	seedCfg := seedConfigFor(cfg, client)
+	funder, err := seed.NewFunder(seedCfg, client.httpClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up auto-refund from the seed account: %w", err)
+	}
+	return &refueler{
+		funder:    funder,
+		amount:    amount,
+		threshold: threshold,
+		batchSize: seedCfg.BatchSize,
+		logger:    logger,
+		stop:      make(chan struct{}),
+		stopped:   make(chan struct{}),
+	}, nil
+}
+
+// seedConfigFor returns the seeder's configuration (taken from the
+// environment, as for the seed command), adjusted to fund accounts through the
+// given client's node in its denoms.
+func seedConfigFor(cfg loadtest.Config, client *PerpxBankClient) seed.Config {
+	seedCfg := seed.DefaultConfig()
+	seedCfg.RPC = client.rpcURL
+	seedCfg.ChainID = client.chainID
+	seedCfg.KeyAlgo = getKeyAlgo(cfg)
+	seedCfg.Denom = client.strategy.Denom()
+	seedCfg.FeeDenom = cfg.FeeDenom
+	seedCfg.Compression = cfg.Compression
+	if len(seedCfg.FeeDenom) == 0 {
+		seedCfg.FeeDenom = seedCfg.Denom
+	}
+	return seedCfg
+}
+
+// Add registers a worker account to be kept topped up.
+func (r *refueler) Add(addr sdk.AccAddress) {
+	r.mtx.Lock()
+	r.workers = append(r.workers, addr)
+	r.mtx.Unlock()
+}
+
+// Run checks and tops up the workers' balances every refuelInterval, until
+// the refueler is stopped.
+func (r *refueler) Run() {
+	defer close(r.stopped)
+	r.logger.Info("Auto-refund enabled",
+		"funder", r.funder.Address().String(),
+		"amount", r.amount.String(),
+		"threshold", r.threshold.String(),
+		"interval", refuelInterval.String(),
+	)
+	ticker := time.NewTicker(refuelInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			r.refuel()
+		case <-r.stop:
+			return
+		}
+	}
+}
+
+// Stop stops the refueler, waiting for any top-up in progress to finish. Run
+// must have been started.
+func (r *refueler) Stop() {
+	r.stopOnce.Do(func() { close(r.stop) })
+	<-r.stopped
+}
+
+// needsRefuel returns whether the given balance has dropped below the
+// threshold in any of the threshold's denoms.
+func (r *refueler) needsRefuel(balance sdk.Coins) bool {
+	for _, t := range r.threshold {
+		if balance.AmountOf(t.Denom).LT(t.Amount) {
+			return true
+		}
+	}
+	return false
+}
+
+// refuel tops up all workers whose balances have dropped below the threshold.
+func (r *refueler) refuel() {
+	r.mtx.Lock()
+	workers := append([]sdk.AccAddress(nil), r.workers...)
+	r.mtx.Unlock()
+
+	var low []sdk.AccAddress
+	for _, addr := range workers {
+		balance, err := r.funder.BalanceIn(addr, r.threshold)
+		if err != nil {
+			r.logger.Debug("Unable to check worker balance for auto-refund", "address", addr.String(), "err", err)
+			continue
+		}
+		if r.needsRefuel(balance) {
+			low = append(low, addr)
+		}
+	}
+	if len(low) == 0 {
+		return
+	}
+
+	for i := 0; i < len(low); i += r.batchSize {
+		end := i + r.batchSize
+		if end > len(low) {
+			end = len(low)
+		}
+		txHash, height, err := r.funder.Fund(low[i:end], r.amount)
+		if err != nil {
+			r.logger.Error("Failed to top up worker accounts", "accounts", end-i, "err", err)
+			// the failed transaction may or may not have consumed a sequence
+			// number, so find out before trying again on the next check
+			if err := r.funder.Sync(); err != nil {
+				r.logger.Error("Failed to query seed account after failed top-up", "err", err)
+			}
+			return
+		}
+		r.logger.Info("Topped up worker accounts", "accounts", end-i, "amount", r.amount.String(), "txHash", txHash, "height", height)
+	}
+}
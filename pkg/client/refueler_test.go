@@ -0,0 +1,176 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/1119-Labs/perpx-load-test/internal/logging"
+	"github.com/1119-Labs/perpx-load-test/pkg/accounts"
+	"github.com/1119-Labs/perpx-load-test/pkg/loadtest"
+	"github.com/1119-Labs/perpx-load-test/pkg/seed"
+)
+
+func TestParseRefund(t *testing.T) {
+	testCases := []struct {
+		autoRefund, threshold string
+		amount, wantThreshold string
+		err                   string
+	}{
+		// the threshold defaults to half of the amount
+		{autoRefund: "1000aperpx", amount: "1000aperpx", wantThreshold: "500aperpx"},
+		{autoRefund: "1000aperpx,500ugas", amount: "1000aperpx,500ugas", wantThreshold: "500aperpx,250ugas"},
+		{autoRefund: "1000aperpx,500ugas", threshold: "100ugas", amount: "1000aperpx,500ugas", wantThreshold: "100ugas"},
+		{autoRefund: "", err: "must be positive"},
+		{autoRefund: "lots", err: "invalid auto-refund amount"},
+		{autoRefund: "1000aperpx", threshold: "some", err: "invalid refund-threshold"},
+		{autoRefund: "1000aperpx", threshold: "100ugas", err: "refund-threshold denom ugas is not part of the auto-refund amount"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.autoRefund+"/"+tc.threshold, func(t *testing.T) {
+			amount, threshold, err := parseRefund(loadtest.Config{AutoRefund: tc.autoRefund, RefundThreshold: tc.threshold})
+			if len(tc.err) > 0 {
+				require.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.amount, amount.String())
+			require.Equal(t, tc.wantThreshold, threshold.String())
+		})
+	}
+}
+
+func TestNeedsRefuel(t *testing.T) {
+	r := &refueler{threshold: sdk.NewCoins(sdk.NewInt64Coin("aperpx", 500), sdk.NewInt64Coin("ugas", 100))}
+	require.False(t, r.needsRefuel(sdk.NewCoins(sdk.NewInt64Coin("aperpx", 600), sdk.NewInt64Coin("ugas", 100))))
+	// running low on any of the denoms is enough
+	require.True(t, r.needsRefuel(sdk.NewCoins(sdk.NewInt64Coin("aperpx", 499), sdk.NewInt64Coin("ugas", 200))))
+	require.True(t, r.needsRefuel(sdk.NewCoins(sdk.NewInt64Coin("aperpx", 600))))
+	// denoms that aren't part of the threshold don't matter
+	r.threshold = sdk.NewCoins(sdk.NewInt64Coin("aperpx", 500))
+	require.False(t, r.needsRefuel(sdk.NewCoins(sdk.NewInt64Coin("aperpx", 500))))
+}
+
+// redirectTransport sends all requests to the given server, whatever their
+// host, so that a seed.Funder can query a fake REST API.
+type redirectTransport struct {
+	target *url.URL
+}
+
+func (t redirectTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	r = r.Clone(r.Context())
+	r.URL.Scheme, r.URL.Host = t.target.Scheme, t.target.Host
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+// recordingFunder queries balances through a real seed.Funder, but records
+// the top-ups instead of broadcasting them.
+type recordingFunder struct {
+	*seed.Funder
+	fundErr  error
+	attempts int
+	funded   [][]sdk.AccAddress
+}
+
+func (f *recordingFunder) Fund(recipients []sdk.AccAddress, amount sdk.Coins) (string, string, error) {
+	f.attempts++
+	if f.fundErr != nil {
+		return "", "", f.fundErr
+	}
+	f.funded = append(f.funded, append([]sdk.AccAddress(nil), recipients...))
+	return fmt.Sprintf("TX%d", len(f.funded)), "1", nil
+}
+
+// refuelServer is a fake REST API serving the seed account and the given
+// balances of the workers (which fail to be queried if missing), counting
+// the seed account queries.
+func refuelServer(t *testing.T, balances map[string]int64, accountQueries *atomic.Int32) *http.Client {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/cosmos/auth/v1beta1/accounts/"):
+			accountQueries.Add(1)
+			_, _ = w.Write([]byte(`{"account":{"account_number":"1","sequence":"42"}}`))
+		case strings.HasPrefix(r.URL.Path, "/cosmos/bank/v1beta1/balances/"):
+			addr := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/cosmos/bank/v1beta1/balances/"), "/by_denom")
+			balance, ok := balances[addr]
+			if !ok {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			_, _ = fmt.Fprintf(w, `{"balance":{"denom":%q,"amount":"%d"}}`, r.URL.Query().Get("denom"), balance)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	target, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	return &http.Client{Transport: redirectTransport{target: target}}
+}
+
+func TestRefuel(t *testing.T) {
+	require.NoError(t, accounts.SetBech32Prefix(accounts.DefaultBech32Prefix))
+	workers := make([]sdk.AccAddress, 5)
+	for i := range workers {
+		workers[i] = sdk.AccAddress(accounts.WorkerPrivKey(i).PubKey().Address())
+	}
+	// the fifth worker's balance can't be queried, so it is skipped
+	balances := map[string]int64{
+		workers[0].String(): 100,
+		workers[1].String(): 1000,
+		workers[2].String(): 0,
+		workers[3].String(): 499,
+	}
+	var accountQueries atomic.Int32
+	httpClient := refuelServer(t, balances, &accountQueries)
+	seedCfg := seed.DefaultConfig()
+	seedCfg.RPC = "http://localhost:26657"
+	seedCfg.SeedKey = "alice"
+	seedCfg.SeedPrivateKey = ""
+	seedCfg.Denom = "aperpx"
+	funder, err := seed.NewFunder(seedCfg, httpClient)
+	require.NoError(t, err)
+	require.Equal(t, int32(1), accountQueries.Load())
+
+	newRefueler := func(f accountFunder) *refueler {
+		r := &refueler{
+			funder:    f,
+			amount:    sdk.NewCoins(sdk.NewInt64Coin("aperpx", 1000)),
+			threshold: sdk.NewCoins(sdk.NewInt64Coin("aperpx", 500)),
+			batchSize: 2,
+			logger:    logging.NewNoopLogger(),
+			stop:      make(chan struct{}),
+			stopped:   make(chan struct{}),
+		}
+		for _, addr := range workers {
+			r.Add(addr)
+		}
+		return r
+	}
+
+	// the workers that run low are topped up, in batches
+	recorder := &recordingFunder{Funder: funder}
+	newRefueler(recorder).refuel()
+	require.Equal(t, [][]sdk.AccAddress{{workers[0], workers[2]}, {workers[3]}}, recorder.funded)
+
+	// a failed top-up resyncs the seed account and leaves the remaining
+	// batches to the next check
+	failing := &recordingFunder{Funder: funder, fundErr: errors.New("broadcast failed")}
+	newRefueler(failing).refuel()
+	require.Equal(t, 1, failing.attempts)
+	require.Equal(t, int32(2), accountQueries.Load())
+
+	// a running refueler stops when asked to
+	r := newRefueler(recorder)
+	go r.Run()
+	r.Stop()
+	r.Stop()
+}
@@ -0,0 +1,311 @@
+package client
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/cosmos/cosmos-sdk/types/tx/signing"
+)
+
+// checkTxResult is the relevant part of the result of CometBFT's check_tx RPC
+// method.
+type checkTxResult struct {
+	Code      uint32 `json:"code"`
+	Codespace string `json:"codespace"`
+	Log       string `json:"log"`
+}
+
+// encodingMismatch returns whether the result indicates that the node was
+// unable to decode the transaction or verify its signature, rather than
+// rejecting it for some other (e.g. balance or sequence related) reason.
+func (r *checkTxResult) encodingMismatch() bool {
+	if r.Codespace != sdkerrors.ErrTxDecode.Codespace() {
+		return false
+	}
+	switch r.Code {
+	case sdkerrors.ErrTxDecode.ABCICode(), sdkerrors.ErrUnauthorized.ABCICode(), sdkerrors.ErrInvalidChainID.ABCICode():
+		return true
+	}
+	return false
+}
+
+// checkTx builds a transaction exactly like GenerateTx does (but without
+// consuming a sequence number), and has the node run CheckTx on it. Unlike
+// simulation, CheckTx verifies the transaction's signature, but the
+// transaction is not added to the mempool.
+func (c *PerpxBankClient) checkTx() (*checkTxResult, error) {
+	if err := c.ensureAccountQueried(); err != nil {
+		return nil, err
+	}
+	txBytes, err := c.buildTx(atomic.LoadUint64(&c.sequence))
+	if err != nil {
+		return nil, err
+	}
+	return c.checkTxBytes(txBytes)
+}
+
+// checkTxBytes has the node run CheckTx on the given transaction.
+func (c *PerpxBankClient) checkTxBytes(txBytes []byte) (*checkTxResult, error) {
+	return c.callTxMethod("check_tx", txBytes)
+}
+
+// callTxMethod calls the given CometBFT RPC method (check_tx or one of the
+// broadcast_tx methods) with the given transaction, returning its CheckTx
+// result.
+func (c *PerpxBankClient) callTxMethod(method string, txBytes []byte) (*checkTxResult, error) {
+	reqBody, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  method,
+		"params":  map[string]string{"tx": base64.StdEncoding.EncodeToString(txBytes)},
+	})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.httpClient.Post(c.rpcURL, "application/json", bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to call %s at %s: %w", method, c.rpcURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to call %s: HTTP %d: %s", method, resp.StatusCode, string(body))
+	}
+
+	var rpcResp struct {
+		Result *checkTxResult `json:"result"`
+		Error  *struct {
+			Message string `json:"message"`
+			Data    string `json:"data"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&rpcResp); err != nil {
+		return nil, fmt.Errorf("failed to decode %s response: %w", method, err)
+	}
+	if rpcResp.Error != nil {
+		return nil, fmt.Errorf("%s failed: %s %s", method, rpcResp.Error.Message, rpcResp.Error.Data)
+	}
+	if rpcResp.Result == nil {
+		return nil, fmt.Errorf("%s returned no result", method)
+	}
+	return rpcResp.Result, nil
+}
+
+// checkWasmContract checks that the chain has the wasm module, and that the
+// given contract exists on it, so that a wasm-execute load test against a
+// chain without CosmWasm fails up front rather than with every transaction
+// (which the node would reject as undecodable). If the check itself can't be
+// carried out, it is skipped.
+func (f *PerpxBankClientFactory) checkWasmContract(client *PerpxBankClient, contract string) error {
+	contractURL := fmt.Sprintf("%s/cosmwasm/wasm/v1/contract/%s", client.restURL, url.PathEscape(contract))
+	resp, err := client.httpClient.Get(contractURL)
+	if err != nil {
+		f.logger.Debug("Unable to check whether the chain supports wasm", "err", err)
+		return nil
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusNotImplemented:
+		// the REST API answers unknown routes (i.e. those of modules the
+		// chain doesn't have) with gRPC's Unimplemented
+		return fmt.Errorf("wasm-contract is only available on chains with the CosmWasm (wasm) module, which the chain doesn't have")
+	default:
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to query wasm contract %s: HTTP %d: %s", contract, resp.StatusCode, string(body))
+	}
+}
+
+// checkEncoding runs a self-check against the node to make sure that it can
+// decode and verify the transactions we generate. Transactions that can't be
+// decoded or verified are otherwise only reported as a flood of opaque
+// rejections, so this returns an error suggesting the most likely causes. If
+// the check itself can't be carried out, it is skipped.
+func (f *PerpxBankClientFactory) checkEncoding(client *PerpxBankClient) error {
+	result, err := client.checkTx()
+	if err != nil {
+		f.logger.Debug("Unable to check transaction encoding", "err", err)
+		return nil
+	}
+	if result.encodingMismatch() {
+		return fmt.Errorf("the node cannot decode or verify transactions generated by this tool (%s code %d: %s) - "+
+			"this usually means that the tool was built against a different perpx-chain version than the node is running "+
+			"(see \"perpx-load-test version\"), or that LOADTEST_CHAIN_ID (%s) doesn't match the chain ID",
+			result.Codespace, result.Code, result.Log, client.chainID)
+	}
+	if result.Code != 0 {
+		f.logger.Debug("Self-check transaction was rejected, but not due to an encoding mismatch",
+			"codespace", result.Codespace, "code", result.Code, "log", result.Log)
+	}
+	return nil
+}
+
+// nodeChainID returns the ID of the chain the node is running, as reported by
+// CometBFT's status RPC method.
+func (c *PerpxBankClient) nodeChainID() (string, error) {
+	reqBody, err := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": 1, "method": "status"})
+	if err != nil {
+		return "", err
+	}
+	resp, err := c.httpClient.Post(c.rpcURL, "application/json", bytes.NewReader(reqBody))
+	if err != nil {
+		return "", fmt.Errorf("failed to query status at %s: %w", c.rpcURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("failed to query status: HTTP %d: %s", resp.StatusCode, string(body))
+	}
+	var rpcResp struct {
+		Result struct {
+			NodeInfo struct {
+				Network string `json:"network"`
+			} `json:"node_info"`
+		} `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&rpcResp); err != nil {
+		return "", fmt.Errorf("failed to decode status response: %w", err)
+	}
+	if len(rpcResp.Result.NodeInfo.Network) == 0 {
+		return "", fmt.Errorf("status response has no chain ID")
+	}
+	return rpcResp.Result.NodeInfo.Network, nil
+}
+
+// checkSignature builds a transaction exactly like GenerateTx does (but
+// without consuming a sequence number), and verifies its signature locally,
+// as the node would, before anything is broadcast. The signature is verified
+// against the node's chain ID where it can be queried, so that both a broken
+// sign mode and a misconfigured chain ID are caught with a clear diagnosis,
+// rather than as a flood of "signature verification failed" rejections.
+func (f *PerpxBankClientFactory) checkSignature(client *PerpxBankClient) error {
+	if err := client.ensureAccountQueried(); err != nil {
+		return fmt.Errorf("failed to prepare signature self-test: %w", err)
+	}
+	txBytes, err := client.buildTx(atomic.LoadUint64(&client.sequence))
+	if err != nil {
+		return fmt.Errorf("failed to build signature self-test transaction: %w", err)
+	}
+	txConfig := client.encCfg.TxConfig
+	if err := verifySignedTx(txConfig, txBytes, client.addrStr, client.chainID, client.accountNum); err != nil {
+		return fmt.Errorf("signature self-test failed: the generated transaction's signature doesn't verify against its own public key (%v) - "+
+			"the tool is signing in a sign mode, or with sign bytes, that the chain doesn't use", err)
+	}
+	chainID, err := client.nodeChainID()
+	if err != nil {
+		f.logger.Error("Signature self-test couldn't query the node's chain ID, so only verified the signature against LOADTEST_CHAIN_ID",
+			"chain_id", client.chainID, "err", err)
+		return nil
+	}
+	if err := verifySignedTx(txConfig, txBytes, client.addrStr, chainID, client.accountNum); err != nil {
+		return fmt.Errorf("signature self-test failed: the generated transaction's signature is invalid on the node's chain %q (%v) - "+
+			"LOADTEST_CHAIN_ID (%s) doesn't match the chain ID", chainID, err, client.chainID)
+	}
+	f.logger.Info("Signature self-test passed", "chain_id", chainID, "sign_mode", signing.SignMode_SIGN_MODE_DIRECT)
+	return nil
+}
+
+// simulateTx has the node simulate the given transaction via the REST API,
+// returning the amount of gas it used. Unlike CheckTx, simulation doesn't
+// verify signatures, but it does execute the transaction's messages.
+func (c *PerpxBankClient) simulateTx(txBytes []byte) (uint64, error) {
+	reqBody, err := json.Marshal(map[string]string{"tx_bytes": base64.StdEncoding.EncodeToString(txBytes)})
+	if err != nil {
+		return 0, err
+	}
+	simulateURL := c.restURL + "/cosmos/tx/v1beta1/simulate"
+	resp, err := c.httpClient.Post(simulateURL, "application/json", bytes.NewReader(reqBody))
+	if err != nil {
+		return 0, fmt.Errorf("failed to simulate transaction via REST API at %s: %w", simulateURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		var errResp struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(body, &errResp) == nil && len(errResp.Message) > 0 {
+			return 0, fmt.Errorf("HTTP %d: %s", resp.StatusCode, errResp.Message)
+		}
+		return 0, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body))
+	}
+
+	var simResp struct {
+		GasInfo struct {
+			GasWanted string `json:"gas_wanted"`
+			GasUsed   string `json:"gas_used"`
+		} `json:"gas_info"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&simResp); err != nil {
+		return 0, fmt.Errorf("failed to decode simulation response: %w", err)
+	}
+	gasUsed, err := strconv.ParseUint(simResp.GasInfo.GasUsed, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid gas used in simulation response: %q", simResp.GasInfo.GasUsed)
+	}
+	return gasUsed, nil
+}
+
+// ValidateTx builds a transaction exactly like GenerateTx does (but without
+// consuming a sequence number), and has the node both simulate it and run
+// CheckTx on it, so that misconfigured fees, denoms, gas limits or signing
+// are caught without broadcasting anything.
+func (c *PerpxBankClient) ValidateTx() (string, error) {
+	if err := c.ensureAccountQueried(); err != nil {
+		return "", err
+	}
+	seq := atomic.LoadUint64(&c.sequence)
+	txBytes, err := c.buildTx(seq)
+	if err != nil {
+		return "", err
+	}
+	decoded, err := c.encCfg.TxConfig.TxDecoder()(txBytes)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode generated transaction: %w", err)
+	}
+	txJSON, err := c.encCfg.TxConfig.TxJSONEncoder()(decoded)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode generated transaction as JSON: %w", err)
+	}
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, txJSON, "", "  "); err != nil {
+		indented.Reset()
+		indented.Write(txJSON)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Transaction (%d bytes, signer %s, account number %d, sequence %d):\n%s\n",
+		len(txBytes), c.addrStr, c.accountNum, seq, indented.String())
+
+	gasUsed, err := c.simulateTx(txBytes)
+	if err != nil {
+		fmt.Fprintf(&sb, "Simulation: FAILED (%v)", err)
+		return sb.String(), fmt.Errorf("transaction simulation failed: %w", err)
+	}
+	fmt.Fprintf(&sb, "Simulation: OK (gas used: %d, gas limit: %d)\n", gasUsed, c.gasLimit)
+	if gasUsed > c.gasLimit {
+		return sb.String(), fmt.Errorf("transactions would run out of gas: simulation used %d, but the gas limit is %d", gasUsed, c.gasLimit)
+	}
+
+	result, err := c.checkTxBytes(txBytes)
+	if err != nil {
+		fmt.Fprintf(&sb, "CheckTx: FAILED (%v)", err)
+		return sb.String(), err
+	}
+	if result.Code != 0 {
+		fmt.Fprintf(&sb, "CheckTx: REJECTED (%s code %d: %s)", result.Codespace, result.Code, result.Log)
+		return sb.String(), fmt.Errorf("transaction rejected by CheckTx (%s code %d): %s", result.Codespace, result.Code, result.Log)
+	}
+	sb.WriteString("CheckTx: OK")
+	return sb.String(), nil
+}
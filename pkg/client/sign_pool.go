@@ -0,0 +1,32 @@
+package client
+
+// signPool bounds the number of transactions that a factory's clients sign
+// at once. Signing is CPU-bound, so when workers vastly outnumber CPUs,
+// signing on every worker's goroutine at once only has them contend for the
+// CPUs, adding scheduling overhead and latency without increasing
+// throughput. With a pool, workers beyond its size wait (without using any
+// CPU) for a slot instead.
+type signPool struct {
+	slots chan struct{}
+}
+
+// newSignPool creates a pool that signs at most size transactions at once,
+// or returns nil (i.e. no pool, so that every worker signs inline) if size is
+// 0.
+func newSignPool(size int) *signPool {
+	if size < 1 {
+		return nil
+	}
+	return &signPool{slots: make(chan struct{}, size)}
+}
+
+// Do calls sign once a slot in the pool is free, or immediately if the pool
+// is nil.
+func (p *signPool) Do(sign func() error) error {
+	if p == nil {
+		return sign()
+	}
+	p.slots <- struct{}{}
+	defer func() { <-p.slots }()
+	return sign()
+}
@@ -0,0 +1,111 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"cosmossdk.io/math"
+)
+
+const (
+	// The --recipients source that sends to the accounts with the largest
+	// balances, given as "top-accounts:N".
+	recipientsTopAccounts = "top-accounts"
+
+	// How many holders of a denom to request per page when ranking accounts
+	// by balance.
+	denomOwnersPageSize = 1000
+	// The maximum number of holders of a denom to scan when ranking accounts
+	// by balance, so that chains with huge numbers of accounts don't take
+	// forever to scan. Beyond this, only the holders scanned are ranked.
+	maxDenomOwnersScanned = 100000
+)
+
+// parseTopAccounts parses the --recipients option, which must be of the form
+// "top-accounts:N", returning N.
+func parseTopAccounts(spec string) (int, error) {
+	source, count, ok := strings.Cut(spec, ":")
+	if !ok || source != recipientsTopAccounts {
+		return 0, fmt.Errorf("expected %q, but got %q", recipientsTopAccounts+":N", spec)
+	}
+	n, err := strconv.Atoi(count)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("expected the number of top accounts to be a positive integer, but got %q", count)
+	}
+	return n, nil
+}
+
+// queryTopAccounts queries the REST API for the n accounts with the largest
+// balances of the given denom, in descending order of balance. It pages
+// through the bank module's holders of the denom, of which it scans at most
+// maxDenomOwnersScanned.
+func queryTopAccounts(httpClient *http.Client, restURL, denom string, n int) ([]string, error) {
+	type holder struct {
+		address string
+		balance math.Int
+	}
+	var holders []holder
+	nextKey := ""
+	for len(holders) < maxDenomOwnersScanned {
+		params := url.Values{}
+		params.Set("pagination.limit", strconv.Itoa(denomOwnersPageSize))
+		if len(nextKey) > 0 {
+			params.Set("pagination.key", nextKey)
+		}
+		ownersURL := fmt.Sprintf("%s/cosmos/bank/v1beta1/denom_owners/%s?%s", restURL, url.PathEscape(denom), params.Encode())
+		resp, err := httpClient.Get(ownersURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query holders of %s via REST API at %s: %w", denom, restURL, err)
+		}
+		var page struct {
+			DenomOwners []struct {
+				Address string `json:"address"`
+				Balance struct {
+					Amount string `json:"amount"`
+				} `json:"balance"`
+			} `json:"denom_owners"`
+			Pagination struct {
+				NextKey string `json:"next_key"`
+			} `json:"pagination"`
+		}
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			return nil, fmt.Errorf("failed to query holders of %s: HTTP %d: %s", denom, resp.StatusCode, string(body))
+		}
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode denom owners response: %w", err)
+		}
+		for _, owner := range page.DenomOwners {
+			balance, ok := math.NewIntFromString(owner.Balance.Amount)
+			if !ok {
+				return nil, fmt.Errorf("invalid balance amount for %s: %q", owner.Address, owner.Balance.Amount)
+			}
+			holders = append(holders, holder{address: owner.Address, balance: balance})
+		}
+		if len(page.Pagination.NextKey) == 0 {
+			break
+		}
+		nextKey = page.Pagination.NextKey
+	}
+	if len(holders) == 0 {
+		return nil, fmt.Errorf("no accounts hold any %s", denom)
+	}
+
+	sort.SliceStable(holders, func(i, j int) bool {
+		return holders[i].balance.GT(holders[j].balance)
+	})
+	top := make([]string, 0, min(n, len(holders)))
+	for _, h := range holders[:min(n, len(holders))] {
+		top = append(top, h.address)
+	}
+	return top, nil
+}
@@ -0,0 +1,56 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	sdkclient "github.com/cosmos/cosmos-sdk/client"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/tx/signing"
+	authsigning "github.com/cosmos/cosmos-sdk/x/auth/signing"
+)
+
+// verifySignedTx decodes the given transaction and verifies its signature
+// exactly as the chain would for a sender with the given address and account
+// number on the chain with the given ID: the signing key must be the sender's,
+// and the signature must be valid for the transaction's sign bytes.
+func verifySignedTx(txConfig sdkclient.TxConfig, txBytes []byte, address, chainID string, accountNum uint64) error {
+	decoded, err := txConfig.TxDecoder()(txBytes)
+	if err != nil {
+		return fmt.Errorf("failed to decode transaction: %w", err)
+	}
+	sigTx, ok := decoded.(authsigning.SigVerifiableTx)
+	if !ok {
+		return fmt.Errorf("transaction of type %T has no signatures", decoded)
+	}
+	sigs, err := sigTx.GetSignaturesV2()
+	if err != nil {
+		return fmt.Errorf("failed to get signatures: %w", err)
+	}
+	if len(sigs) != 1 {
+		return fmt.Errorf("expected 1 signature, but got %d", len(sigs))
+	}
+	sig := sigs[0]
+	if signer := sdk.AccAddress(sig.PubKey.Address()).String(); signer != address {
+		return fmt.Errorf("signed with the key of %s rather than the sender %s", signer, address)
+	}
+	data, ok := sig.Data.(*signing.SingleSignatureData)
+	if !ok {
+		return fmt.Errorf("unexpected signature data of type %T", sig.Data)
+	}
+	signerData := authsigning.SignerData{
+		Address:       address,
+		ChainID:       chainID,
+		AccountNumber: accountNum,
+		Sequence:      sig.Sequence,
+		PubKey:        sig.PubKey,
+	}
+	signBytes, err := authsigning.GetSignBytesAdapter(context.Background(), txConfig.SignModeHandler(), data.SignMode, signerData, decoded)
+	if err != nil {
+		return fmt.Errorf("failed to get %s sign bytes: %w", data.SignMode, err)
+	}
+	if !sig.PubKey.VerifySignature(signBytes, data.Signature) {
+		return fmt.Errorf("%s signature is invalid for chain ID %q and account number %d", data.SignMode, chainID, accountNum)
+	}
+	return nil
+}
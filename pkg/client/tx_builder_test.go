@@ -0,0 +1,168 @@
+package client
+
+import (
+	"testing"
+
+	sdkclient "github.com/cosmos/cosmos-sdk/client"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	"github.com/cosmos/cosmos-sdk/types/tx/signing"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/stretchr/testify/require"
+
+	"cosmossdk.io/math"
+	"github.com/1119-Labs/perpx-chain/protocol/app"
+	"github.com/1119-Labs/perpx-load-test/internal/golden"
+	"github.com/1119-Labs/perpx-load-test/pkg/accounts"
+	"github.com/1119-Labs/perpx-load-test/pkg/seed"
+	"github.com/1119-Labs/perpx-load-test/pkg/strategies"
+)
+
+func testTxParams(t *testing.T, seq uint64) (seed.TxParams, []sdk.Msg) {
+	require.NoError(t, accounts.SetBech32Prefix(accounts.DefaultBech32Prefix))
+	privKey := accounts.WorkerPrivKey(0)
+	from := sdk.AccAddress(privKey.PubKey().Address())
+	to := sdk.AccAddress(accounts.WorkerPrivKey(1).PubKey().Address())
+	p := seed.TxParams{
+		PubKey:        privKey.PubKey(),
+		Address:       from.String(),
+		ChainID:       "localperpxprotocol",
+		AccountNumber: 7,
+		Sequence:      seq,
+		FeeCoins:      sdk.NewCoins(sdk.NewCoin("aperpx", math.NewInt(defaultMinGasPrice).MulRaw(200_000))),
+		GasLimit:      200_000,
+		SignMode:      signing.SignMode_SIGN_MODE_DIRECT,
+	}
+	msgs := []sdk.Msg{&banktypes.MsgSend{
+		FromAddress: from.String(),
+		ToAddress:   to.String(),
+		Amount:      sdk.NewCoins(sdk.NewCoin("aperpx", math.NewInt(1))),
+	}}
+	return p, msgs
+}
+
+// buildSignedTx builds the transaction of the given messages and parameters
+// from testTxParams, signed with worker 0's key.
+func buildSignedTx(txConfig sdkclient.TxConfig, p seed.TxParams, msgs []sdk.Msg) ([]byte, error) {
+	return seed.BuildTx(txConfig, p, msgs, seed.PrivKeySigner(txConfig, accounts.WorkerPrivKey(0)))
+}
+
+func TestBuildSignedTxGolden(t *testing.T) {
+	p, msgs := testTxParams(t, 3)
+	txBytes, err := buildSignedTx(app.GetEncodingConfig().TxConfig, p, msgs)
+	require.NoError(t, err)
+	golden.RequireTx(t, "bank_send_tx.golden", txBytes)
+}
+
+func TestBuildSignedTxDeterministic(t *testing.T) {
+	txConfig := app.GetEncodingConfig().TxConfig
+	p, msgs := testTxParams(t, 3)
+	first, err := buildSignedTx(txConfig, p, msgs)
+	require.NoError(t, err)
+	second, err := buildSignedTx(txConfig, p, msgs)
+	require.NoError(t, err)
+	require.Equal(t, first, second)
+
+	// The sequence is part of the signed bytes
+	p.Sequence++
+	next, err := buildSignedTx(txConfig, p, msgs)
+	require.NoError(t, err)
+	require.NotEqual(t, first, next)
+
+	decoded, err := txConfig.TxDecoder()(next)
+	require.NoError(t, err)
+	require.Len(t, decoded.GetMsgs(), 1)
+}
+
+func TestBuildSignedTxFeeGranter(t *testing.T) {
+	txConfig := app.GetEncodingConfig().TxConfig
+	p, msgs := testTxParams(t, 3)
+	p.FeeGranter = sdk.AccAddress(accounts.FeeGranterPrivKey(0).PubKey().Address())
+	txBytes, err := buildSignedTx(txConfig, p, msgs)
+	require.NoError(t, err)
+
+	decoded, err := txConfig.TxDecoder()(txBytes)
+	require.NoError(t, err)
+	feeTx, ok := decoded.(sdk.FeeTx)
+	require.True(t, ok)
+	require.Equal(t, []byte(p.FeeGranter), feeTx.FeeGranter())
+	require.Equal(t, p.FeeCoins, feeTx.GetFee())
+}
+
+func TestVerifySignedTx(t *testing.T) {
+	txConfig := app.GetEncodingConfig().TxConfig
+	p, msgs := testTxParams(t, 3)
+	txBytes, err := buildSignedTx(txConfig, p, msgs)
+	require.NoError(t, err)
+	require.NoError(t, verifySignedTx(txConfig, txBytes, p.Address, p.ChainID, p.AccountNumber))
+
+	// a signature is only valid for the chain ID and account number it was
+	// made for, and only for the sender's own key
+	require.ErrorContains(t, verifySignedTx(txConfig, txBytes, p.Address, "otherchain", p.AccountNumber), "invalid")
+	require.ErrorContains(t, verifySignedTx(txConfig, txBytes, p.Address, p.ChainID, p.AccountNumber+1), "invalid")
+	other := sdk.AccAddress(accounts.WorkerPrivKey(1).PubKey().Address()).String()
+	require.ErrorContains(t, verifySignedTx(txConfig, txBytes, other, p.ChainID, p.AccountNumber), "rather than the sender")
+}
+
+func TestBuildSignedTxWasmExecute(t *testing.T) {
+	p, _ := testTxParams(t, 3)
+	msg := &strategies.MsgExecuteContract{
+		Sender:   p.Address,
+		Contract: sdk.AccAddress(accounts.WorkerPrivKey(1).PubKey().Address()).String(),
+		Msg:      []byte(`{"increment":{}}`),
+		Funds:    sdk.NewCoins(sdk.NewCoin("aperpx", math.NewInt(5))),
+	}
+	txBytes, err := buildSignedTx(app.GetEncodingConfig().TxConfig, p, []sdk.Msg{msg})
+	require.NoError(t, err)
+
+	// the chain can't be asked to decode it here, so check the raw encoding
+	var raw txtypes.TxRaw
+	require.NoError(t, raw.Unmarshal(txBytes))
+	var body txtypes.TxBody
+	require.NoError(t, body.Unmarshal(raw.BodyBytes))
+	require.Len(t, body.Messages, 1)
+	require.Equal(t, "/cosmwasm.wasm.v1.MsgExecuteContract", body.Messages[0].TypeUrl)
+	want, err := msg.Marshal()
+	require.NoError(t, err)
+	require.Equal(t, want, body.Messages[0].Value)
+
+	// and that the signature covers it
+	signDoc := txtypes.SignDoc{
+		BodyBytes:     raw.BodyBytes,
+		AuthInfoBytes: raw.AuthInfoBytes,
+		ChainId:       p.ChainID,
+		AccountNumber: p.AccountNumber,
+	}
+	signBytes, err := signDoc.Marshal()
+	require.NoError(t, err)
+	require.Len(t, raw.Signatures, 1)
+	require.True(t, p.PubKey.VerifySignature(signBytes, raw.Signatures[0]))
+}
+
+func TestBuildSignedTxEthSecp256k1(t *testing.T) {
+	require.NoError(t, accounts.SetKeyAlgo(accounts.KeyAlgoEthSecp256k1))
+	t.Cleanup(func() { require.NoError(t, accounts.SetKeyAlgo(accounts.DefaultKeyAlgo)) })
+	p, msgs := testTxParams(t, 3)
+	txBytes, err := buildSignedTx(app.GetEncodingConfig().TxConfig, p, msgs)
+	require.NoError(t, err)
+
+	// the SDK can't decode the public key, so check the raw encoding
+	var raw txtypes.TxRaw
+	require.NoError(t, raw.Unmarshal(txBytes))
+	var authInfo txtypes.AuthInfo
+	require.NoError(t, authInfo.Unmarshal(raw.AuthInfoBytes))
+	require.Len(t, authInfo.SignerInfos, 1)
+	require.Equal(t, "/ethermint.crypto.v1.ethsecp256k1.PubKey", authInfo.SignerInfos[0].PublicKey.TypeUrl)
+
+	signDoc := txtypes.SignDoc{
+		BodyBytes:     raw.BodyBytes,
+		AuthInfoBytes: raw.AuthInfoBytes,
+		ChainId:       p.ChainID,
+		AccountNumber: p.AccountNumber,
+	}
+	signBytes, err := signDoc.Marshal()
+	require.NoError(t, err)
+	require.Len(t, raw.Signatures, 1)
+	require.Len(t, raw.Signatures[0], 65)
+	require.True(t, p.PubKey.VerifySignature(signBytes, raw.Signatures[0]))
+}
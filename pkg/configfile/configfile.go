@@ -0,0 +1,239 @@
+// Package configfile loads load test scenario files, which allow the seeder
+// and load test configuration to be captured in a single YAML or TOML file
+// instead of a collection of environment variables and flags.
+//
+// Options are named exactly like their command line flags (without the
+// leading "--"), and are applied as defaults: flags (and, where applicable,
+// LOADTEST_* environment variables) always override file values.
+package configfile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// SharedEnvVars maps the options in the "shared" section of a config file to
+// the environment variables through which they are passed to both the seeder
+// and the load test client.
+var SharedEnvVars = map[string]string{
+	"bech32-prefix":    "LOADTEST_BECH32_PREFIX",
+	"chain-id":         "LOADTEST_CHAIN_ID",
+	"denom":            "LOADTEST_DENOM",
+	"fee-denom":        "LOADTEST_FEE_DENOM",
+	"key-algo":         "LOADTEST_KEY_ALGO",
+	"seed-key":         "LOADTEST_SEED_KEY",
+	"seed-private-key": "LOADTEST_SEED_PRIVATE_KEY",
+	"sink-address":     "LOADTEST_SINK_ADDRESS",
+}
+
+// Redacted stands in for secrets (such as private keys) in printed
+// configurations.
+const Redacted = "[REDACTED]"
+
+// RedactSeedKey returns the given seed key as it may be printed: key names
+// are kept, but mnemonics (which are made up of several words) are redacted.
+func RedactSeedKey(key string) string {
+	if strings.ContainsAny(strings.TrimSpace(key), " \t\n") {
+		return Redacted
+	}
+	return key
+}
+
+// SharedEnv returns the values of the set environment variables of the shared
+// settings (see SharedEnvVars), keyed by variable name, with the seed key
+// redacted as for RedactSeedKey and the seed private key redacted entirely,
+// so that they can be printed along with the rest of a configuration.
+func SharedEnv() map[string]string {
+	env := make(map[string]string)
+	for _, name := range SharedEnvVars {
+		val, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+		switch name {
+		case "LOADTEST_SEED_KEY":
+			val = RedactSeedKey(val)
+		case "LOADTEST_SEED_PRIVATE_KEY":
+			val = Redacted
+		}
+		env[name] = val
+	}
+	return env
+}
+
+// File is the content of a scenario config file.
+type File struct {
+	// Settings used by both the seeder and the load test client (see
+	// SharedEnvVars).
+	Shared map[string]interface{} `yaml:"shared" toml:"shared"`
+	// Options for the "seed" command.
+	Seed map[string]interface{} `yaml:"seed" toml:"seed"`
+	// Options for the load test itself (standalone, coordinator or worker).
+	LoadTest map[string]interface{} `yaml:"loadtest" toml:"loadtest"`
+}
+
+// Load reads the config file at the given path. Files with a ".toml"
+// extension are parsed as TOML, and all others as YAML.
+func Load(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	f := &File{}
+	if strings.EqualFold(filepath.Ext(path), ".toml") {
+		err = toml.Unmarshal(data, f)
+	} else {
+		err = yaml.Unmarshal(data, f)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	return f, nil
+}
+
+// ApplySharedEnv exports the file's shared settings through their
+// corresponding environment variables, unless those variables are already
+// set (in which case the environment takes precedence).
+func (f *File) ApplySharedEnv() error {
+	for _, key := range sortedKeys(f.Shared) {
+		envVar, ok := SharedEnvVars[key]
+		if !ok {
+			return fmt.Errorf("unknown shared option in config file: %s", key)
+		}
+		if len(os.Getenv(envVar)) > 0 {
+			continue
+		}
+		val, err := FlagValue(f.Shared[key])
+		if err != nil {
+			return fmt.Errorf("invalid value for shared option %s: %w", key, err)
+		}
+		if val, err = ExpandEnv(val); err != nil {
+			return fmt.Errorf("invalid value for shared option %s: %w", key, err)
+		}
+		if err := os.Setenv(envVar, val); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Args converts the given config file section into command line arguments
+// (e.g. "--workers", "10"), in a deterministic order. Options for which skip
+// returns true are omitted.
+func Args(section map[string]interface{}, skip func(key string) bool) ([]string, error) {
+	args := make([]string, 0, 2*len(section))
+	for _, key := range sortedKeys(section) {
+		if skip != nil && skip(key) {
+			continue
+		}
+		val, err := FlagValue(section[key])
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for option %s: %w", key, err)
+		}
+		args = append(args, "--"+key, val)
+	}
+	return args, nil
+}
+
+// FlagValue renders a config file value in the form expected by the
+// corresponding command line flag. Lists are rendered as comma-separated
+// values.
+func FlagValue(v interface{}) (string, error) {
+	switch val := v.(type) {
+	case nil:
+		return "", nil
+	case []interface{}:
+		items := make([]string, len(val))
+		for i, item := range val {
+			s, err := FlagValue(item)
+			if err != nil {
+				return "", err
+			}
+			items[i] = s
+		}
+		return strings.Join(items, ","), nil
+	case map[string]interface{}:
+		return "", fmt.Errorf("nested tables are not supported")
+	default:
+		return fmt.Sprint(val), nil
+	}
+}
+
+// ExpandEnv replaces each "${VAR}" reference in s with the value of the
+// environment variable VAR, so that endpoints and addresses can be templated
+// in scripts without resorting to shell quoting. Strings without such
+// references, including ones containing a bare "$", are returned unchanged.
+// Referencing an unset variable is an error, since it would otherwise silently
+// produce a malformed endpoint or address.
+func ExpandEnv(s string) (string, error) {
+	if !strings.Contains(s, "${") {
+		return s, nil
+	}
+	var b strings.Builder
+	for {
+		start := strings.Index(s, "${")
+		if start < 0 {
+			b.WriteString(s)
+			return b.String(), nil
+		}
+		end := strings.IndexByte(s[start:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated variable reference in %q", s)
+		}
+		name := s[start+2 : start+end]
+		if !isEnvVarName(name) {
+			return "", fmt.Errorf("invalid variable reference ${%s}", name)
+		}
+		val, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", name)
+		}
+		b.WriteString(s[:start])
+		b.WriteString(val)
+		s = s[start+end+1:]
+	}
+}
+
+func isEnvVarName(name string) bool {
+	if len(name) == 0 {
+		return false
+	}
+	for i, c := range name {
+		switch {
+		case c == '_', c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z':
+		case c >= '0' && c <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// FindPath returns the value of the "--config" option in the given raw
+// command line arguments, if present.
+func FindPath(args []string) string {
+	for i, arg := range args {
+		if arg == "--config" && i+1 < len(args) {
+			return args[i+1]
+		}
+		if strings.HasPrefix(arg, "--config=") {
+			return strings.TrimPrefix(arg, "--config=")
+		}
+	}
+	return ""
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,143 @@
+package configfile_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/1119-Labs/perpx-load-test/pkg/configfile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testYAML = `
+shared:
+  chain-id: testchain
+  denom: utest
+seed:
+  workers: 100
+  fund-amount: 5000utest
+loadtest:
+  endpoints:
+    - ws://node0:36657/websocket
+    - ws://node1:36657/websocket
+  rate: 500
+  jitter: 0.25
+  confirm: true
+`
+
+const testTOML = `
+[shared]
+chain-id = "testchain"
+denom = "utest"
+
+[seed]
+workers = 100
+fund-amount = "5000utest"
+
+[loadtest]
+endpoints = ["ws://node0:36657/websocket", "ws://node1:36657/websocket"]
+rate = 500
+jitter = 0.25
+confirm = true
+`
+
+func TestLoad(t *testing.T) {
+	testCases := []struct {
+		name    string
+		content string
+	}{
+		{"scenario.yaml", testYAML},
+		{"scenario.toml", testTOML},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tc.name)
+			require.NoError(t, os.WriteFile(path, []byte(tc.content), 0o600))
+
+			f, err := configfile.Load(path)
+			require.NoError(t, err)
+
+			seedArgs, err := configfile.Args(f.Seed, nil)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"--fund-amount", "5000utest", "--workers", "100"}, seedArgs)
+
+			loadTestArgs, err := configfile.Args(f.LoadTest, func(key string) bool { return key == "confirm" })
+			require.NoError(t, err)
+			assert.Equal(t, []string{
+				"--endpoints", "ws://node0:36657/websocket,ws://node1:36657/websocket",
+				"--jitter", "0.25",
+				"--rate", "500",
+			}, loadTestArgs)
+		})
+	}
+}
+
+func TestApplySharedEnv(t *testing.T) {
+	t.Setenv("LOADTEST_CHAIN_ID", "")
+	t.Setenv("LOADTEST_DENOM", "fromenv")
+
+	f := &configfile.File{Shared: map[string]interface{}{"chain-id": "fromfile", "denom": "fromfile"}}
+	require.NoError(t, f.ApplySharedEnv())
+	assert.Equal(t, "fromfile", os.Getenv("LOADTEST_CHAIN_ID"))
+	assert.Equal(t, "fromenv", os.Getenv("LOADTEST_DENOM"))
+
+	f = &configfile.File{Shared: map[string]interface{}{"no-such-option": "x"}}
+	assert.Error(t, f.ApplySharedEnv())
+}
+
+func TestSharedEnv(t *testing.T) {
+	for _, name := range configfile.SharedEnvVars {
+		// restored once the test is done
+		t.Setenv(name, "")
+		require.NoError(t, os.Unsetenv(name))
+	}
+	t.Setenv("LOADTEST_CHAIN_ID", "localperpxprotocol")
+	t.Setenv("LOADTEST_SEED_KEY", "abandon abandon abandon art")
+	t.Setenv("LOADTEST_SEED_PRIVATE_KEY", "0123abcd")
+
+	assert.Equal(t, map[string]string{
+		"LOADTEST_CHAIN_ID":         "localperpxprotocol",
+		"LOADTEST_SEED_KEY":         configfile.Redacted,
+		"LOADTEST_SEED_PRIVATE_KEY": configfile.Redacted,
+	}, configfile.SharedEnv())
+
+	// key names aren't secret
+	t.Setenv("LOADTEST_SEED_KEY", "alice")
+	assert.Equal(t, "alice", configfile.SharedEnv()["LOADTEST_SEED_KEY"])
+}
+
+func TestFindPath(t *testing.T) {
+	assert.Equal(t, "a.yaml", configfile.FindPath([]string{"--workers", "10", "--config", "a.yaml"}))
+	assert.Equal(t, "b.toml", configfile.FindPath([]string{"--config=b.toml"}))
+	assert.Equal(t, "", configfile.FindPath([]string{"--workers", "10"}))
+}
+
+func TestExpandEnv(t *testing.T) {
+	t.Setenv("NODE_HOST", "node1")
+	t.Setenv("RPC_PORT", "36657")
+	t.Setenv("EMPTY", "")
+
+	tests := []struct {
+		in, want string
+	}{
+		{"ws://localhost:36657/websocket", "ws://localhost:36657/websocket"},
+		{"ws://${NODE_HOST}:${RPC_PORT}/websocket", "ws://node1:36657/websocket"},
+		{"${NODE_HOST}${EMPTY}", "node1"},
+		// only ${VAR} references are expanded
+		{"pa$$word$NODE_HOST", "pa$$word$NODE_HOST"},
+		{"$${NODE_HOST}", "$node1"},
+	}
+	for _, tc := range tests {
+		got, err := configfile.ExpandEnv(tc.in)
+		require.NoError(t, err, tc.in)
+		assert.Equal(t, tc.want, got, tc.in)
+	}
+
+	_, err := configfile.ExpandEnv("ws://${NO_SUCH_VAR_FOR_TESTING}:36657")
+	assert.EqualError(t, err, "environment variable NO_SUCH_VAR_FOR_TESTING is not set")
+	_, err = configfile.ExpandEnv("ws://${NODE_HOST:36657")
+	assert.Error(t, err)
+	_, err = configfile.ExpandEnv("${1BAD}")
+	assert.Error(t, err)
+}
@@ -0,0 +1,66 @@
+package loadtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+// queryAccountCount returns the number of accounts on the chain, via the
+// Cosmos SDK REST API at the given URL. The node counts all of its accounts
+// to answer, which may take a while on chains with very many of them.
+func queryAccountCount(client *http.Client, restURL string) (int, error) {
+	resp, err := client.Get(restURL + "/cosmos/auth/v1beta1/accounts?pagination.limit=1&pagination.count_total=true")
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	var data struct {
+		Pagination struct {
+			Total string `json:"total"`
+		} `json:"pagination"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return 0, err
+	}
+	total, err := strconv.Atoi(data.Pagination.Total)
+	if err != nil {
+		return 0, fmt.Errorf("invalid account count %q", data.Pagination.Total)
+	}
+	return total, nil
+}
+
+// countAccounts counts the chain's accounts via the first transactor's REST
+// API, logging (rather than returning) any error.
+func (g *TransactorGroup) countAccounts() (int, bool) {
+	if g.confirmer == nil || len(g.transactors) == 0 {
+		return 0, false
+	}
+	count, err := queryAccountCount(g.confirmer.client, g.transactors[0].restURL)
+	if err != nil {
+		g.logger.Info("Unable to count the chain's accounts", "err", err)
+		return 0, false
+	}
+	return count, true
+}
+
+// Accounts summarizes the accounts touched by the group's transactions
+// (including any sent during the warmup period), or returns nil if neither
+// the client factory nor confirmation keeps track of them.
+func (g *TransactorGroup) Accounts() *AccountsReport {
+	g.accountsMtx.Lock()
+	created := g.accountsCreated
+	g.accountsMtx.Unlock()
+	if g.accounts == nil && created == nil {
+		return nil
+	}
+	r := &AccountsReport{Created: created}
+	if g.accounts != nil {
+		r.Senders, r.Recipients, r.Touched = g.accounts.AccountsTouched()
+	}
+	return r
+}
@@ -0,0 +1,105 @@
+package loadtest
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// The bounds of the weights by which the adaptive router multiplies each
+	// endpoint's rate.
+	routingMinWeight = 0.2
+	routingMaxWeight = 5.0
+
+	// The fraction of the way towards its latency-derived target by which an
+	// endpoint's weight moves with each update, which keeps a single slow
+	// period from swinging the load around.
+	routingSmoothing = 0.5
+)
+
+// AdaptiveRouter steers load towards the endpoints that respond fastest to
+// broadcast requests, by weighting each endpoint's rate in inverse proportion
+// to its broadcast latency. The weights are normalized so that the total rate
+// sent to the endpoints is the same as without weighting.
+type AdaptiveRouter struct {
+	baseRates map[string]float64 // The total unweighted rate of each endpoint's transactors.
+
+	mtx     sync.RWMutex
+	weights map[string]float64
+}
+
+// NewAdaptiveRouter creates a router for endpoints with the given total
+// unweighted rates, starting with a weight of 1 for every endpoint.
+func NewAdaptiveRouter(baseRates map[string]float64) *AdaptiveRouter {
+	weights := make(map[string]float64, len(baseRates))
+	for endpoint := range baseRates {
+		weights[endpoint] = 1
+	}
+	return &AdaptiveRouter{baseRates: baseRates, weights: weights}
+}
+
+// Update reweights the endpoints given their average broadcast latencies since
+// the previous update, and returns the new weights. Endpoints without a
+// latency sample (e.g. because they're disconnected) keep their weights, and
+// the rate that the sampled endpoints are given between them stays the same.
+func (r *AdaptiveRouter) Update(latencies map[string]time.Duration) map[string]float64 {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	var sampled []string
+	for endpoint, latency := range latencies {
+		if _, ok := r.baseRates[endpoint]; ok && latency > 0 {
+			sampled = append(sampled, endpoint)
+		}
+	}
+	if len(sampled) < 2 {
+		return r.copyWeights()
+	}
+
+	// the rate currently allotted to the sampled endpoints, which is shared
+	// out anew
+	allotted, inverse := 0.0, 0.0
+	for _, endpoint := range sampled {
+		allotted += r.baseRates[endpoint] * r.weights[endpoint]
+		inverse += r.baseRates[endpoint] / latencies[endpoint].Seconds()
+	}
+	if allotted <= 0 || inverse <= 0 {
+		return r.copyWeights()
+	}
+	next := make(map[string]float64, len(sampled))
+	weighted := 0.0
+	for _, endpoint := range sampled {
+		target := allotted / inverse / latencies[endpoint].Seconds()
+		w := r.weights[endpoint] + routingSmoothing*(target-r.weights[endpoint])
+		w = min(max(w, routingMinWeight), routingMaxWeight)
+		next[endpoint] = w
+		weighted += r.baseRates[endpoint] * w
+	}
+	// clamping may have changed the total
+	for _, endpoint := range sampled {
+		r.weights[endpoint] = next[endpoint] * allotted / weighted
+	}
+	return r.copyWeights()
+}
+
+// Weight returns the weight by which the given endpoint's rate is multiplied,
+// which is 1 if the router is nil.
+func (r *AdaptiveRouter) Weight(endpoint string) float64 {
+	if r == nil {
+		return 1
+	}
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	if w, ok := r.weights[endpoint]; ok {
+		return w
+	}
+	return 1
+}
+
+func (r *AdaptiveRouter) copyWeights() map[string]float64 {
+	weights := make(map[string]float64, len(r.weights))
+	for endpoint, w := range r.weights {
+		weights[endpoint] = w
+	}
+	return weights
+}
@@ -0,0 +1,42 @@
+package loadtest_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/1119-Labs/perpx-load-test/pkg/loadtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdaptiveRouter(t *testing.T) {
+	router := loadtest.NewAdaptiveRouter(map[string]float64{"fast": 100, "slow": 100, "idle": 50})
+	assert.Equal(t, 1.0, router.Weight("fast"))
+	assert.Equal(t, 1.0, router.Weight("unknown"))
+	var nilRouter *loadtest.AdaptiveRouter
+	assert.Equal(t, 1.0, nilRouter.Weight("fast"))
+
+	// a single sampled endpoint has nothing to be compared with
+	weights := router.Update(map[string]time.Duration{"fast": 10 * time.Millisecond})
+	assert.Equal(t, 1.0, weights["fast"])
+
+	weights = router.Update(map[string]time.Duration{"fast": 10 * time.Millisecond, "slow": 30 * time.Millisecond})
+	assert.Greater(t, weights["fast"], 1.0)
+	assert.Less(t, weights["slow"], 1.0)
+	// the total rate is unchanged, and unsampled endpoints keep their weights
+	assert.InDelta(t, 200.0, 100*weights["fast"]+100*weights["slow"], 1e-9)
+	assert.Equal(t, 1.0, weights["idle"])
+
+	// the weights converge on the inverse latencies, within their bounds
+	for i := 0; i < 20; i++ {
+		weights = router.Update(map[string]time.Duration{"fast": 10 * time.Millisecond, "slow": 30 * time.Millisecond})
+	}
+	assert.InDelta(t, 1.5, weights["fast"], 1e-3)
+	assert.InDelta(t, 0.5, weights["slow"], 1e-3)
+	assert.Equal(t, weights["fast"], router.Weight("fast"))
+
+	for i := 0; i < 20; i++ {
+		weights = router.Update(map[string]time.Duration{"fast": time.Millisecond, "slow": time.Second})
+	}
+	assert.InDelta(t, 200.0, 100*weights["fast"]+100*weights["slow"], 1e-9)
+	assert.GreaterOrEqual(t, weights["slow"], 0.1)
+}
@@ -0,0 +1,163 @@
+package loadtest
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+)
+
+const (
+	// The number of buckets in the histogram of transactions per block.
+	blockHistogramBuckets = 10
+	// The width (in characters) of the longest bar in the histogram.
+	blockHistogramWidth = 40
+	// The maximum time to wait for each RPC request made by the analysis.
+	blockAnalysisRequestTimeout = 10 * time.Second
+)
+
+// BlockAnalysis describes how the transactions committed during a load test
+// were distributed across blocks. Every transaction in the blocks is counted,
+// including any not sent by the load test.
+type BlockAnalysis struct {
+	FromHeight int64 // The first block analyzed.
+	ToHeight   int64 // The last block analyzed.
+	TxCounts   []int // The number of transactions in each block, from FromHeight to ToHeight.
+}
+
+// Blocks returns the number of blocks analyzed.
+func (a *BlockAnalysis) Blocks() int {
+	return len(a.TxCounts)
+}
+
+// Total returns the total number of transactions in the analyzed blocks.
+func (a *BlockAnalysis) Total() int {
+	total := 0
+	for _, n := range a.TxCounts {
+		total += n
+	}
+	return total
+}
+
+// Mean returns the average number of transactions per block.
+func (a *BlockAnalysis) Mean() float64 {
+	if len(a.TxCounts) == 0 {
+		return 0
+	}
+	return float64(a.Total()) / float64(len(a.TxCounts))
+}
+
+// StdDev returns the (population) standard deviation of the number of
+// transactions per block.
+func (a *BlockAnalysis) StdDev() float64 {
+	if len(a.TxCounts) == 0 {
+		return 0
+	}
+	mean := a.Mean()
+	sumSq := 0.0
+	for _, n := range a.TxCounts {
+		d := float64(n) - mean
+		sumSq += d * d
+	}
+	return math.Sqrt(sumSq / float64(len(a.TxCounts)))
+}
+
+// CoefficientOfVariation returns the standard deviation of the number of
+// transactions per block relative to its mean. 0 means that every block held
+// the same number of transactions, while values approaching (or exceeding) 1
+// indicate bursty submission.
+func (a *BlockAnalysis) CoefficientOfVariation() float64 {
+	mean := a.Mean()
+	if mean == 0 {
+		return 0
+	}
+	return a.StdDev() / mean
+}
+
+// analyzeBlocks counts the transactions in each of the blocks from fromHeight
+// to toHeight (inclusive) via the given RPC client.
+func analyzeBlocks(client *httpClient, fromHeight, toHeight int64) (*BlockAnalysis, error) {
+	if toHeight < fromHeight {
+		return nil, fmt.Errorf("no blocks were committed during the load test")
+	}
+	analysis := &BlockAnalysis{
+		FromHeight: fromHeight,
+		ToHeight:   toHeight,
+		TxCounts:   make([]int, toHeight-fromHeight+1),
+	}
+	// CometBFT returns at most blockchainMaxMetas block metas per request
+	for minHeight := fromHeight; minHeight <= toHeight; minHeight += blockchainMaxMetas {
+		maxHeight := minHeight + blockchainMaxMetas - 1
+		if maxHeight > toHeight {
+			maxHeight = toHeight
+		}
+		info, err := client.blockchain(minHeight, maxHeight)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query blocks %d to %d: %w", minHeight, maxHeight, err)
+		}
+		for _, meta := range info.BlockMetas {
+			height := int64(meta.Header.Height)
+			if height < fromHeight || height > toHeight {
+				continue
+			}
+			analysis.TxCounts[height-fromHeight] = int(meta.NumTxs)
+		}
+	}
+	return analysis, nil
+}
+
+// latestHeight returns the height of the latest block known to the given
+// RPC client's node.
+func latestHeight(client *httpClient) (int64, error) {
+	status, err := client.status()
+	if err != nil {
+		return 0, err
+	}
+	return int64(status.SyncInfo.LatestBlockHeight), nil
+}
+
+// newBlockAnalysisClient creates the RPC client through which the blocks
+// committed during a load test against the given endpoint are analyzed.
+func newBlockAnalysisClient(endpoint string) *httpClient {
+	client := newHttpRpcClient(rpcURLFromEndpoint(endpoint))
+	client.client.Timeout = blockAnalysisRequestTimeout
+	return client
+}
+
+// WriteBlockAnalysis writes a summary of the given analysis, along with a
+// histogram of the number of transactions per block, in human-readable form.
+func WriteBlockAnalysis(w io.Writer, a *BlockAnalysis) {
+	fmt.Fprintf(w, "Transactions per block (heights %d to %d, %d blocks, %d txs)\n", a.FromHeight, a.ToHeight, a.Blocks(), a.Total())
+	if a.Blocks() == 0 {
+		return
+	}
+	minTxs, maxTxs := a.TxCounts[0], a.TxCounts[0]
+	for _, n := range a.TxCounts {
+		minTxs = min(minTxs, n)
+		maxTxs = max(maxTxs, n)
+	}
+	fmt.Fprintf(w, "min %d, mean %.1f, max %d, stddev %.1f, coefficient of variation %.2f\n",
+		minTxs, a.Mean(), maxTxs, a.StdDev(), a.CoefficientOfVariation())
+
+	// bucket blocks by their transaction counts, with at least one count per
+	// bucket
+	bucketWidth := (maxTxs - minTxs + blockHistogramBuckets) / blockHistogramBuckets
+	buckets := make([]int, (maxTxs-minTxs)/bucketWidth+1)
+	for _, n := range a.TxCounts {
+		buckets[(n-minTxs)/bucketWidth]++
+	}
+	largest := 0
+	for _, blocks := range buckets {
+		largest = max(largest, blocks)
+	}
+	for i, blocks := range buckets {
+		lo := minTxs + i*bucketWidth
+		label := fmt.Sprintf("%d", lo)
+		if bucketWidth > 1 {
+			label = fmt.Sprintf("%d-%d", lo, lo+bucketWidth-1)
+		}
+		bar := strings.Repeat("#", (blocks*blockHistogramWidth+largest-1)/largest)
+		fmt.Fprintf(w, "%15s txs | %-*s %d\n", label, blockHistogramWidth, bar, blocks)
+	}
+}
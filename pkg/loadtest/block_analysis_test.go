@@ -0,0 +1,40 @@
+package loadtest_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/1119-Labs/perpx-load-test/pkg/loadtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlockAnalysis(t *testing.T) {
+	even := &loadtest.BlockAnalysis{FromHeight: 10, ToHeight: 13, TxCounts: []int{50, 50, 50, 50}}
+	assert.Equal(t, 4, even.Blocks())
+	assert.Equal(t, 200, even.Total())
+	assert.Equal(t, 50.0, even.Mean())
+	assert.Equal(t, 0.0, even.CoefficientOfVariation())
+
+	bursty := &loadtest.BlockAnalysis{FromHeight: 10, ToHeight: 13, TxCounts: []int{0, 100, 0, 100}}
+	assert.Equal(t, 50.0, bursty.Mean())
+	assert.InDelta(t, 50.0, bursty.StdDev(), 1e-9)
+	assert.InDelta(t, 1.0, bursty.CoefficientOfVariation(), 1e-9)
+
+	var sb strings.Builder
+	loadtest.WriteBlockAnalysis(&sb, bursty)
+	lines := strings.Split(strings.TrimSpace(sb.String()), "\n")
+	assert.Equal(t, "Transactions per block (heights 10 to 13, 4 blocks, 200 txs)", lines[0])
+	assert.Contains(t, lines[1], "coefficient of variation 1.00")
+	// 10 buckets of 11 txs each, of which only the first and last are used
+	assert.Len(t, lines, 12)
+	assert.Contains(t, lines[2], "0-10 txs |")
+	assert.True(t, strings.HasSuffix(lines[2], " 2"), lines[2])
+	assert.True(t, strings.HasSuffix(lines[6], " 0"), lines[6])
+	assert.Contains(t, lines[11], "99-109 txs |")
+
+	empty := &loadtest.BlockAnalysis{FromHeight: 10, ToHeight: 13, TxCounts: []int{0, 0, 0, 0}}
+	assert.Equal(t, 0.0, empty.CoefficientOfVariation())
+	sb.Reset()
+	loadtest.WriteBlockAnalysis(&sb, empty)
+	assert.Contains(t, sb.String(), "0 txs | ")
+}
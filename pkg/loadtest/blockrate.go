@@ -0,0 +1,167 @@
+package loadtest
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// How often the block rate poller samples the latest block.
+	blockRatePollInterval = 1 * time.Second
+	// The maximum time to wait for a single RPC request from the poller.
+	blockRateRequestTimeout = 5 * time.Second
+	// CometBFT returns at most this many block metas per blockchain request.
+	blockchainMaxMetas = 20
+)
+
+// BlockRateStats describes the rate at which the chain is committing blocks
+// (and transactions), as opposed to the rate at which we're sending them.
+type BlockRateStats struct {
+	Height       int64   // The latest block height observed.
+	BlocksPerSec float64 // Blocks committed per second, by block time, since the previous observed height.
+	TxsPerBlock  float64 // The average number of transactions in the blocks committed since the previous observed height.
+	Err          error   // The error from the most recent poll, if any.
+
+	HeightAdvanced time.Time // When (by our clock) the latest height was first observed.
+}
+
+// CommitTxRate is the approximate rate at which transactions are being
+// committed (tx/sec).
+func (s BlockRateStats) CommitTxRate() float64 {
+	return s.BlocksPerSec * s.TxsPerBlock
+}
+
+// blockRatePoller periodically polls a node's RPC status endpoint for the
+// latest block height and time, from which it derives the chain's commit
+// rate.
+type blockRatePoller struct {
+	client *httpClient
+
+	mtx   sync.RWMutex
+	stats BlockRateStats
+
+	lastHeight int64
+	lastTime   time.Time
+
+	stopc   chan struct{}
+	stopped chan struct{}
+}
+
+func newBlockRatePoller(endpoint string) *blockRatePoller {
+	client := newHttpRpcClient(rpcURLFromEndpoint(endpoint))
+	client.client.Timeout = blockRateRequestTimeout
+	return &blockRatePoller{
+		client:  client,
+		stopc:   make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+}
+
+// Start polls in a separate goroutine until Stop is called.
+func (p *blockRatePoller) Start() {
+	go func() {
+		defer close(p.stopped)
+		ticker := time.NewTicker(blockRatePollInterval)
+		defer ticker.Stop()
+		p.poll()
+		for {
+			select {
+			case <-ticker.C:
+				p.poll()
+			case <-p.stopc:
+				return
+			}
+		}
+	}()
+}
+
+// Stop halts polling and waits for any in-flight poll to complete.
+func (p *blockRatePoller) Stop() {
+	close(p.stopc)
+	<-p.stopped
+}
+
+// Stats returns the most recently computed block rate statistics.
+func (p *blockRatePoller) Stats() BlockRateStats {
+	p.mtx.RLock()
+	defer p.mtx.RUnlock()
+	return p.stats
+}
+
+func (p *blockRatePoller) poll() {
+	status, err := p.client.status()
+	if err != nil {
+		p.setErr(err)
+		return
+	}
+	height := int64(status.SyncInfo.LatestBlockHeight)
+	blockTime := status.SyncInfo.LatestBlockTime
+	if p.lastHeight == 0 || height <= p.lastHeight {
+		// nothing new to compute from yet
+		p.mtx.Lock()
+		if p.lastHeight == 0 {
+			p.lastHeight, p.lastTime = height, blockTime
+			p.stats.HeightAdvanced = time.Now()
+		}
+		p.stats.Height = height
+		p.stats.Err = nil
+		p.mtx.Unlock()
+		return
+	}
+
+	blocks := height - p.lastHeight
+	blocksPerSec := 0.0
+	if dt := blockTime.Sub(p.lastTime).Seconds(); dt > 0 {
+		blocksPerSec = float64(blocks) / dt
+	}
+	// If lots of blocks were committed since the last poll, we only sample
+	// the most recent ones.
+	minHeight := p.lastHeight + 1
+	if blocks > blockchainMaxMetas {
+		minHeight = height - blockchainMaxMetas + 1
+	}
+	info, err := p.client.blockchain(minHeight, height)
+	if err != nil {
+		p.setErr(err)
+		return
+	}
+	txsPerBlock := 0.0
+	if len(info.BlockMetas) > 0 {
+		totalTxs := 0
+		for _, meta := range info.BlockMetas {
+			totalTxs += int(meta.NumTxs)
+		}
+		txsPerBlock = float64(totalTxs) / float64(len(info.BlockMetas))
+	}
+
+	p.lastHeight, p.lastTime = height, blockTime
+	p.mtx.Lock()
+	p.stats = BlockRateStats{
+		Height:       height,
+		BlocksPerSec: blocksPerSec,
+		TxsPerBlock:  txsPerBlock,
+
+		HeightAdvanced: time.Now(),
+	}
+	p.mtx.Unlock()
+}
+
+func (p *blockRatePoller) setErr(err error) {
+	p.mtx.Lock()
+	p.stats.Err = err
+	p.mtx.Unlock()
+}
+
+// rpcURLFromEndpoint derives the CometBFT URI-over-HTTP RPC base URL from a
+// CometBFT WebSockets RPC endpoint (e.g. "ws://host:26657/websocket" ->
+// "http://host:26657").
+func rpcURLFromEndpoint(endpoint string) string {
+	httpURL := endpoint
+	if strings.HasPrefix(httpURL, "ws://") {
+		httpURL = "http://" + strings.TrimPrefix(httpURL, "ws://")
+	} else if strings.HasPrefix(httpURL, "wss://") {
+		httpURL = "https://" + strings.TrimPrefix(httpURL, "wss://")
+	}
+	return strings.TrimSuffix(httpURL, "/websocket")
+}
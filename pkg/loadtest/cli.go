@@ -4,11 +4,15 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"runtime"
 	"syscall"
+	"time"
 
+	"github.com/1119-Labs/perpx-load-test/internal/httpclient"
 	"github.com/1119-Labs/perpx-load-test/internal/logging"
-	"github.com/sirupsen/logrus"
+	"github.com/1119-Labs/perpx-load-test/pkg/configfile"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 // CLIVersion must be manually updated as new versions are released.
@@ -24,24 +28,81 @@ type CLIConfig struct {
 	AppShortDesc         string
 	AppLongDesc          string
 	DefaultClientFactory string
+	// The paths of modules whose linked versions should be reported by the
+	// version command (e.g. those defining the chain's transaction types).
+	VersionModules []string
+	// If set, notified of the outcome of every transaction sent by the load
+	// test (in standalone and worker mode).
+	ResultObserver ResultObserver
 }
 
-var flagVerbose bool
+var (
+	flagVerbose    bool
+	flagLogLevel   string
+	flagLogFormat  string
+	flagConfigFile string
+)
 
 func buildCLI(cli *CLIConfig, logger logging.Logger) *cobra.Command {
-	cobra.OnInitialize(func() { initLogLevel(logger) })
-	var cfg Config
+	var (
+		cfg       Config
+		coordCfg  CoordinatorConfig
+		workerCfg WorkerConfig
+	)
+	cfg.ResultObserver = cli.ResultObserver
+	workerCfg.ResultObserver = cli.ResultObserver
 	rootCmd := &cobra.Command{
 		Use:   cli.AppName,
 		Short: cli.AppShortDesc,
 		Long:  cli.AppLongDesc,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if len(flagConfigFile) > 0 {
+				if err := applyConfigFile(cmd, flagConfigFile); err != nil {
+					return err
+				}
+			}
+			if err := expandEnvFlags(&cfg, &workerCfg); err != nil {
+				return err
+			}
+			// the config file may have configured logging, so this must
+			// happen afterwards
+			return initLogging(logger, cfg.LogFile)
+		},
 		Run: func(cmd *cobra.Command, args []string) {
-			logger.Debug(fmt.Sprintf("Configuration: %s", cfg.ToJSON()))
+			if err := cfg.ResolveEndpoints(); err != nil {
+				logger.Error(err.Error())
+				os.Exit(1)
+			}
+			if err := cfg.ExpandPeerEndpoints(logger); err != nil {
+				logger.Error(err.Error())
+				os.Exit(1)
+			}
+			cfg.ApplyTargetTPS()
+			cfg.ResolveRandomSeed(logger)
+			logEffectiveConfig(logger, cfg)
 			if err := cfg.Validate(); err != nil {
 				logger.Error(err.Error())
 				os.Exit(1)
 			}
 
+			if cfg.ValidateOnly {
+				if err := ExecuteValidateOnly(cfg); err != nil {
+					os.Exit(1)
+				}
+				return
+			}
+			if len(cfg.OutputTxs) > 0 {
+				if err := ExecuteOutputTxs(cfg); err != nil {
+					os.Exit(1)
+				}
+				return
+			}
+			if cfg.FindMaxTPS {
+				if err := ExecuteFindMaxTPS(cfg); err != nil {
+					os.Exit(1)
+				}
+				return
+			}
 			if err := ExecuteStandalone(cfg); err != nil {
 				os.Exit(1)
 			}
@@ -49,29 +110,112 @@ func buildCLI(cli *CLIConfig, logger logging.Logger) *cobra.Command {
 	}
 	rootCmd.PersistentFlags().StringVar(&cfg.ClientFactory, "client-factory", cli.DefaultClientFactory, "The identifier of the client factory to use for generating load testing transactions")
 	rootCmd.PersistentFlags().IntVarP(&cfg.Connections, "connections", "c", 1, "The number of connections to open to each endpoint simultaneously")
-	rootCmd.PersistentFlags().IntVarP(&cfg.Time, "time", "T", 60, "The duration (in seconds) for which to handle the load test")
+	rootCmd.PersistentFlags().IntVarP(&cfg.Time, "time", "T", 60, "The duration (in seconds) for which to handle the load test - if --count is also set, the load test stops at whichever limit is reached first (set to 0 to only stop at the count limit)")
+	rootCmd.PersistentFlags().IntVar(&cfg.WarmupSeconds, "warmup-seconds", 0, "The number of seconds at the start of the load test during which transactions are sent but excluded from the final statistics and report")
 	rootCmd.PersistentFlags().IntVarP(&cfg.SendPeriod, "send-period", "p", 1, "The period (in seconds) at which to send batches of transactions")
 	rootCmd.PersistentFlags().IntVarP(&cfg.Rate, "rate", "r", 1000, "The number of transactions to generate each second on each connection, to each endpoint")
 	rootCmd.PersistentFlags().IntVarP(&cfg.Size, "size", "s", 250, "The size of each transaction, in bytes - must be greater than 40")
-	rootCmd.PersistentFlags().IntVarP(&cfg.Count, "count", "N", -1, "The maximum number of transactions to send - set to -1 to turn off this limit")
-	rootCmd.PersistentFlags().StringVar(&cfg.BroadcastTxMethod, "broadcast-tx-method", "async", "The broadcast_tx method to use when submitting transactions - can be async, sync or commit")
+	rootCmd.PersistentFlags().IntVarP(&cfg.Count, "count", "N", -1, "The total number of transactions to send across all connections and endpoints (split between the workers in coordinator/worker mode) - set to -1 to turn off this limit")
+	rootCmd.PersistentFlags().IntVar(&cfg.MsgsPerTx, "msgs-per-tx", 1, "The number of messages to pack into each transaction (gas is scaled accordingly)")
+	rootCmd.PersistentFlags().StringVar(&cfg.AmountDistribution, "amount-distribution", "", "The distribution from which to draw the amount sent by each message: fixed:N, uniform:min=A,max=B, lognormal:median=M,sigma=S[,max=B] or histogram:FILE (sends 1 base unit if empty)")
+	rootCmd.PersistentFlags().Int64Var(&cfg.RandomSeed, "random-seed", 0, "The seed from which all pseudo-random choices (amounts, send jitter, injected failures, fresh recipients, cold-start accounts, ...) are derived, so that a run can be reproduced exactly with the same seed and configuration (a time-based seed is generated and logged if 0)")
+	rootCmd.PersistentFlags().StringVar(&cfg.Bech32Prefix, "bech32-prefix", "", "The Bech32 prefix of the chain's account addresses, for forks that use their own (defaults to LOADTEST_BECH32_PREFIX, or perpx)")
+	rootCmd.PersistentFlags().StringVar(&cfg.KeyAlgo, "key-algo", "", "The algorithm of the chain's account keys: secp256k1, or eth_secp256k1 for EVM-enabled chains (defaults to LOADTEST_KEY_ALGO, or secp256k1)")
+	rootCmd.PersistentFlags().StringVar(&cfg.FeeBudget, "fee-budget", "", "Stop sending once the fees of all transactions generated across all workers would exceed these coins, e.g. 1000000000000000000aperpx (by default fees are only totalled)")
+	rootCmd.PersistentFlags().StringSliceVar(&cfg.FeeGranters, "fee-granters", []string{}, "A comma-separated list of accounts that pay the transaction fees via fee allowances (e.g. from the seed command's --fee-granters), assigned to the accounts round-robin so that no single granter's state becomes a bottleneck")
+	rootCmd.PersistentFlags().StringVar(&cfg.FeeDenom, "fee-denom", "", "The denom in which to pay transaction fees, for chains with a dedicated fee token (defaults to LOADTEST_FEE_DENOM, or the transfer denom)")
+	rootCmd.PersistentFlags().BoolVar(&cfg.SelfSend, "self-send", false, "Have each account send to its own address instead of the sink, so that balances only decrease by fees (for long soak tests)")
+	rootCmd.PersistentFlags().IntVar(&cfg.FreshRecipients, "fresh-recipients", 0, "Have each account cycle through this many deterministically generated, initially unfunded recipient addresses instead of the sink, to exercise account creation (0 disables)")
+	rootCmd.PersistentFlags().StringVar(&cfg.RecipientSalt, "recipient-salt", "", "The salt from which fresh recipient addresses are derived (a new salt is generated and logged for each run if empty)")
+	rootCmd.PersistentFlags().StringVar(&cfg.RecipientsFile, "recipients-file", "", "Have each account cycle through the recipient addresses in this file (one bech32 address per line) instead of the sink")
+	rootCmd.PersistentFlags().BoolVar(&cfg.RecipientsFileStrict, "recipients-file-strict", false, "Fail if the recipients file contains invalid lines, rather than skipping them with a warning")
+	rootCmd.PersistentFlags().StringVar(&cfg.Recipients, "recipients", "", "Have each account cycle through recipients queried from the chain instead of the sink: \"top-accounts:N\" sends to the N accounts with the largest balances of the transfer denom, for a realistically concentrated recipient distribution (falls back to the sink if the node can't be queried for them)")
+	rootCmd.PersistentFlags().StringVar(&cfg.HotAccount, "hot-account", "", "Have each account alternately send funds to this address and have it send them back (via authz grants from the seed command's --grant-hot-account) instead of sending to the sink, to stress writes to a single account")
+	rootCmd.PersistentFlags().IntVar(&cfg.SinksPerWorker, "sinks-per-worker", 0, "Have each account cycle through this many deterministically generated sink addresses of its own (the same on every run) instead of the shared sink, to model traders with a handful of counterparties (0 disables)")
+	rootCmd.PersistentFlags().StringVar(&cfg.PerpRoundTrip, "perp-round-trip", "", "Have each account alternately open and close a perp position with short-term IOC orders instead of sending funds, given as \"clob=ID,quantums=N,buy-subticks=N,sell-subticks=N[,good-til-blocks=N]\" (requires subaccount 0 of every account to hold collateral)")
+	rootCmd.PersistentFlags().StringVar(&cfg.WasmContract, "wasm-contract", "", "Have each account execute this CosmWasm contract with --wasm-msg instead of sending funds (only available on chains with the wasm module)")
+	rootCmd.PersistentFlags().StringVar(&cfg.WasmMsg, "wasm-msg", "", "The JSON message with which to execute the wasm contract, e.g. '{\"increment\":{}}'")
+	rootCmd.PersistentFlags().StringVar(&cfg.WasmFunds, "wasm-funds", "", "The funds to attach to each execution of the wasm contract (e.g. \"10aperpx\")")
+	rootCmd.PersistentFlags().StringVar(&cfg.StrategySequence, "strategy-sequence", "", "Have each account cycle through these strategies in this fixed order, one per message (e.g. \"bank-send,hot-account\"), for reproducible mixes of message types; bank-send sends as configured by the other flags, and hot-account requires --hot-account")
+	rootCmd.PersistentFlags().Float64Var(&cfg.InjectFailures, "inject-failures", 0, "Replace this percentage (0-100) of transactions with deliberately invalid ones that the chain rejects (e.g. for a wrong account sequence), to check how nodes and monitoring handle rejections - they are counted separately from the other transactions (0 disables)")
+	rootCmd.PersistentFlags().StringVar(&cfg.StrategyGas, "strategy-gas", "", "Override the gas limit allotted to each message of a strategy, given as comma-separated strategy=gas pairs (e.g. \"bank-send=150000,perp-round-trip=400000\"); strategies are bank-send, hot-account, perp-round-trip and wasm-execute")
+	rootCmd.PersistentFlags().StringVar(&cfg.StrategyGasPrice, "strategy-gas-price", "", "Override the price per unit of gas at which the fees of a strategy's transactions are paid, given as comma-separated strategy=price pairs in the fee denom (e.g. \"perp-round-trip=50000000000aperpx\"); strategies that aren't listed fall back to LOADTEST_GAS_PRICE_<strategy>, and then the chain's minimum gas price")
+	rootCmd.PersistentFlags().StringVar(&cfg.BroadcastTxMethod, "broadcast-tx-method", "async", "The broadcast_tx method to use when submitting transactions (also accepted as --broadcast-mode): async returns as soon as the node receives a transaction, giving the highest submission throughput but no visibility of CheckTx rejections; sync waits for CheckTx, so rejections (e.g. sequence mismatches, full mempool) are counted immediately at the cost of a lower send ceiling; commit waits for each transaction to be included in a block")
 	rootCmd.PersistentFlags().StringSliceVar(&cfg.Endpoints, "endpoints", []string{}, "A comma-separated list of URLs indicating CometBFT WebSockets RPC endpoints to which to connect")
+	rootCmd.PersistentFlags().StringVar(&cfg.EndpointsFile, "endpoints-file", "", "A file listing additional endpoints to connect to, one per line (ws://, wss://, http:// or https:// URLs, or host:port)")
+	rootCmd.PersistentFlags().StringVar(&cfg.EndpointsSRV, "endpoints-srv", "", "A DNS SRV name (e.g. _cometbft._tcp.nodes.example.com, optionally prefixed with wss://) to expand into additional endpoints at startup")
+	rootCmd.PersistentFlags().BoolVar(&cfg.AdaptiveRouting, "adaptive-routing", false, "Dynamically reweight the endpoints' rates in inverse proportion to how long they take to answer broadcast requests, steering load towards the fastest endpoints (on top of any endpoint weights)")
+	rootCmd.PersistentFlags().StringVar(&cfg.EndpointWeights, "endpoint-weights", "", "Comma-separated endpoint:weight pairs (endpoint being a URL, host:port or hostname) by which to distribute the total rate across endpoints, e.g. \"node1:3,node2:1\" (unlisted endpoints have weight 1)")
+	rootCmd.PersistentFlags().StringVar(&cfg.Spike, "spike", "", "Periodically multiply the send rate to test recovery from bursts of load, e.g. \"every=30s,factor=5,duration=3s\" (disabled if empty)")
+	rootCmd.PersistentFlags().IntVar(&cfg.TargetTPS, "target-tps", 0, "The total number of accepted transactions per second to aim for across all connections, continuously adjusting the rate to achieve it (overrides --rate; 0 to disable)")
+	rootCmd.PersistentFlags().BoolVar(&cfg.FindMaxTPS, "find-max-tps", false, "Instead of a single load test, search for the maximum sustainable TPS by running a probe of --time seconds at each of a series of target TPS values, doubling from --target-tps (or 100) until a probe fails and then bisecting (requires --confirm)")
+	rootCmd.PersistentFlags().Float64Var(&cfg.MinSuccessRate, "min-success-rate", 0.99, "With --find-max-tps, the fraction of sampled transactions that must be committed successfully for a probe's rate to be considered sustainable")
+	rootCmd.PersistentFlags().IntVar(&cfg.MaxCommitLatency, "max-commit-latency", 0, "With --find-max-tps, the 90th percentile commit latency of sampled transactions (in milliseconds) beyond which a probe's rate isn't considered sustainable (0 for no bound)")
+	rootCmd.PersistentFlags().StringVar(&cfg.AutoRefund, "auto-refund", "", "Periodically top up worker accounts whose balances run low with this amount (e.g. 1000000aperpx) from the seed account (LOADTEST_SEED_KEY or LOADTEST_SEED_PRIVATE_KEY), for long runs (disabled if empty)")
+	rootCmd.PersistentFlags().StringVar(&cfg.RefundThreshold, "refund-threshold", "", "With --auto-refund, top up worker accounts once their balance drops below this amount (defaults to half of the auto-refund amount)")
+	rootCmd.PersistentFlags().BoolVar(&cfg.ColdStart, "cold-start", false, "Benchmark cold starts: have each worker send from a never-before-seen account, created from the seed account just before it starts, and report the distribution of the time from each account's first broadcast to its first commit (requires --confirm)")
+	rootCmd.PersistentFlags().BoolVar(&cfg.AutoCreateAccounts, "auto-create-accounts", false, "Fund worker accounts that were never seeded from the seed account (LOADTEST_SEED_KEY or LOADTEST_SEED_PRIVATE_KEY) with LOADTEST_FUND_AMOUNT as they are first used, one transaction per account, so that small tests can skip the seed command")
+	rootCmd.PersistentFlags().StringVar(&cfg.Record, "record", "", "Record every signed transaction sent to this file, so that the run can later be reproduced byte for byte with the replay command")
+	rootCmd.PersistentFlags().IntVar(&cfg.MaxInFlight, "max-inflight", 0, "Block each connection/worker from generating further transactions while this many of its broadcasts are awaiting a response (plus, with --confirm, sampled transactions awaiting confirmation) (0 for no limit)")
+	rootCmd.PersistentFlags().IntVar(&cfg.ConnectionPoolSize, "connection-pool-size", 0, "Share this many WebSockets connections per endpoint between all of that endpoint's connections/workers, multiplexing their broadcasts, so that many more workers can be run than there are sockets available (0 gives each its own connection)")
+	rootCmd.PersistentFlags().IntVar(&cfg.MaxReconnectAttempts, "max-reconnect-attempts", 10, "If an endpoint drops a connection mid-run, shift its load to the remaining endpoints and try this many times (with exponential backoff) to reconnect before giving up on it (0 fails the load test immediately)")
+	rootCmd.PersistentFlags().IntVar(&cfg.ResyncEvery, "resync-every", 0, "Have each worker re-read its on-chain account sequence after every N transactions it sends and correct its local counter if it has drifted, e.g. due to rejected transactions (0 never resyncs)")
+	rootCmd.PersistentFlags().StringVar(&cfg.AccountNumber, "account-number", "", "Use these account numbers instead of querying them: the first worker's (the others following consecutively) or a comma-separated list with one per worker (requires --start-sequence; sequence drift won't be corrected)")
+	rootCmd.PersistentFlags().StringVar(&cfg.StartSequence, "start-sequence", "", "Use these starting sequences instead of querying them: one for all workers or a comma-separated list with one per worker (requires --account-number)")
+	rootCmd.PersistentFlags().IntVar(&cfg.WorkerStartStagger, "worker-start-stagger", 0, "Wait this many milliseconds between connecting successive workers, and have each worker start sending this much later than the previous one, so that workers come online gradually (0 starts them all at once)")
+	rootCmd.PersistentFlags().IntVar(&cfg.PrepareConcurrency, "prepare-concurrency", 16, "Before starting, prepare clients (e.g. query their account numbers and sequences) with this many concurrent requests, rather than all at once when the load test starts (0 prepares lazily)")
+	rootCmd.PersistentFlags().IntVar(&cfg.SignConcurrency, "sign-concurrency", 0, "Sign at most this many transactions at once across all connections/workers, so that runs with far more workers than CPUs don't have them all contend for the CPUs while signing (e.g. the number of CPUs; 0 signs each transaction as soon as it is generated)")
+	rootCmd.PersistentFlags().IntVar(&cfg.HTTPTimeout, "http-timeout", int(httpclient.DefaultTimeout/time.Second), "The maximum number of seconds to wait for each REST API request (account queries, confirmations, etc.)")
+	rootCmd.PersistentFlags().IntVar(&cfg.HTTPMaxIdleConns, "http-max-idle-conns", httpclient.DefaultMaxIdleConnsPerHost, "The maximum number of idle REST API connections to keep open to each host for reuse")
+	rootCmd.PersistentFlags().StringVar(&cfg.QueryTransport, "query-transport", "rest", "How clients query account state: rest, or grpc for lower latency when many clients query at once at startup (the timeout is --http-timeout)")
+	rootCmd.PersistentFlags().IntVar(&cfg.QueryRetries, "query-retries", 3, "How many times to retry a worker's account query that failed transiently (HTTP 429 or 5xx, a timeout, or an unreachable node), with exponential backoff from 500ms; a missing account is never retried")
+	rootCmd.PersistentFlags().BoolVar(&cfg.Compression, "compression", false, "Gzip compress REST and gRPC query responses (and gRPC requests), to save bandwidth against remote nodes")
+	rootCmd.PersistentFlags().BoolVar(&cfg.VerifySignatures, "verify-signatures", false, "Before broadcasting anything, verify the signature of a generated transaction locally against its public key and the node's chain ID, and abort with a diagnosis of the sign mode or chain ID misconfiguration if it doesn't verify")
 	rootCmd.PersistentFlags().StringVar(&cfg.UI, "ui", "plain", "UI mode for standalone execution: plain or tui")
-	rootCmd.PersistentFlags().StringVar(&cfg.EndpointSelectMethod, "endpoint-select-method", SelectSuppliedEndpoints, "The method by which to select endpoints")
-	rootCmd.PersistentFlags().IntVar(&cfg.ExpectPeers, "expect-peers", 0, "The minimum number of peers to expect when crawling the P2P network from the specified endpoint(s) prior to waiting for workers to connect")
-	rootCmd.PersistentFlags().IntVar(&cfg.MaxEndpoints, "max-endpoints", 0, "The maximum number of endpoints to use for testing, where 0 means unlimited")
-	rootCmd.PersistentFlags().IntVar(&cfg.PeerConnectTimeout, "peer-connect-timeout", 600, "The number of seconds to wait for all required peers to connect if expect-peers > 0")
-	rootCmd.PersistentFlags().IntVar(&cfg.MinConnectivity, "min-peer-connectivity", 0, "The minimum number of peers to which each peer must be connected before starting the load test")
+	rootCmd.PersistentFlags().StringVar(&cfg.EndpointSelectMethod, "endpoint-select-method", SelectSuppliedEndpoints, "With --expect-peers, which of the crawled nodes to load test: supplied (only the given endpoints), discovered (only the peers found by crawling) or any")
+	rootCmd.PersistentFlags().IntVar(&cfg.ExpectPeers, "expect-peers", 0, "Before starting (and, in coordinator mode, before accepting workers), crawl the P2P network from the given endpoint(s) until at least this many nodes have been found, e.g. to wait for a freshly started network to come up (0 disables waiting)")
+	rootCmd.PersistentFlags().IntVar(&cfg.MaxEndpoints, "max-endpoints", 0, "With --expect-peers, the maximum number of the selected nodes to load test (0 means unlimited)")
+	rootCmd.PersistentFlags().IntVar(&cfg.PeerConnectTimeout, "peer-connect-timeout", 600, "With --expect-peers, the number of seconds to wait for the required peers to connect before giving up")
+	rootCmd.PersistentFlags().BoolVar(&cfg.DiscoverPeers, "discover-peers", false, "Expand the single given endpoint into it and the RPC endpoints of all of its peers that are reachable, to load test the whole network from one node")
+	rootCmd.PersistentFlags().IntVar(&cfg.MinConnectivity, "min-peer-connectivity", 0, "With --expect-peers, also wait until every node found is connected to at least this many peers (at most --expect-peers; 0 disables)")
 	rootCmd.PersistentFlags().StringVar(&cfg.StatsOutputFile, "stats-output", "", "Where to store aggregate statistics (in CSV format) for the load test")
-	rootCmd.PersistentFlags().BoolVarP(&flagVerbose, "verbose", "v", false, "Increase output logging verbosity to DEBUG level")
+	rootCmd.PersistentFlags().StringVar(&cfg.ReportJSONFile, "report-json", "", "Where to store a JSON report summarizing the load test (totals, rates, per-endpoint breakdown, error categories and confirmation results)")
+	rootCmd.PersistentFlags().BoolVar(&cfg.BlockAnalysis, "block-analysis", false, "After the load test, print a histogram of the number of transactions in each block committed during the run, along with its coefficient of variation, to show how evenly the load was spread across blocks (standalone mode only)")
+	rootCmd.PersistentFlags().BoolVar(&cfg.Confirm, "confirm", false, "Sample submitted transactions and poll the REST API to confirm that they were actually committed")
+	rootCmd.PersistentFlags().IntVar(&cfg.ConfirmEvery, "confirm-every", 10, "When confirming, sample every Nth transaction sent on each connection")
+	rootCmd.PersistentFlags().IntVar(&cfg.ConfirmTimeout, "confirm-timeout", 30, "When confirming, the maximum number of seconds to wait for a sampled transaction to be committed")
+	rootCmd.PersistentFlags().StringVar(&cfg.ConfirmTransport, "confirm-transport", ConfirmTransportREST, "When confirming, how to learn of sampled transactions' commits: rest to poll the REST API, or ws to subscribe to them via the WebSockets endpoints (falling back to polling where subscriptions aren't available)")
+	rootCmd.PersistentFlags().Float64Var(&cfg.Jitter, "jitter", 0, "Randomly offset the start of each connection's batch of transactions by up to this fraction of the send period, to avoid synchronized bursts (0 disables jitter)")
+	rootCmd.PersistentFlags().IntVar(&cfg.MempoolFullBackoff, "mempool-full-backoff", 0, "When a node rejects a transaction because its mempool is full, stop sending on that connection for this many milliseconds (0 disables backoff)")
+	rootCmd.PersistentFlags().IntVar(&cfg.HaltTimeout, "halt-timeout", 30, "Warn that the chain appears halted once the latest block height hasn't advanced for this many seconds, as polled from the first endpoint (0 disables)")
+	rootCmd.PersistentFlags().BoolVar(&cfg.StopOnHalt, "stop-on-halt", false, "Stop the load test (draining as for --drain-timeout) once the chain appears halted, instead of only warning")
+	rootCmd.PersistentFlags().IntVar(&cfg.DrainTimeout, "drain-timeout", 0, "On Ctrl+C, stop generating transactions but wait up to this many seconds for in-flight transactions and confirmations to settle before writing final statistics (0 stops immediately)")
+	rootCmd.PersistentFlags().BoolVar(&cfg.ValidateOnly, "validate-only", false, "Generate a single transaction, have the node simulate and check it without broadcasting it, print it along with the results and exit (no load is generated)")
+	rootCmd.PersistentFlags().StringVar(&cfg.OutputTxs, "output-txs", "", "Generate --count signed transactions, write them to this file in the recording format (for broadcasting elsewhere, or with the replay command) and exit without broadcasting them")
+	rootCmd.PersistentFlags().StringVar(&cfg.CPUProfile, "cpuprofile", "", "Write a CPU profile of the load test generator to this file, for analysis with \"go tool pprof\" (standalone mode only)")
+	rootCmd.PersistentFlags().StringVar(&cfg.MemProfile, "memprofile", "", "Write a heap profile of the load test generator to this file once the load test is done, for analysis with \"go tool pprof\" (standalone mode only)")
+	rootCmd.PersistentFlags().StringVar(&cfg.HealthAddr, "health-addr", "", "If set, serve /healthz, /readyz and /status HTTP endpoints on this address (e.g. :8080) for the duration of a standalone load test")
+	rootCmd.PersistentFlags().StringVar(&flagConfigFile, "config", "", "Read options from the \"loadtest\" and \"shared\" sections of this YAML/TOML config file (flags and environment variables take precedence)")
+	rootCmd.PersistentFlags().BoolVarP(&flagVerbose, "verbose", "v", false, "Increase output logging verbosity to DEBUG level (shorthand for --log-level debug)")
+	rootCmd.PersistentFlags().StringVar(&flagLogLevel, "log-level", "info", "The minimum level of log messages to output: trace, debug, info, warn or error (ignored in TUI mode, which only logs errors after the UI stops)")
+	rootCmd.PersistentFlags().StringVar(&flagLogFormat, "log-format", logging.FormatText, "The format in which to output log messages: text or json")
+	rootCmd.PersistentFlags().StringVar(&cfg.LogFile, "log-file", "", "Append log messages to this file instead of writing them to the terminal (in TUI mode, this retains full logging while the UI is shown)")
 
-	var coordCfg CoordinatorConfig
 	coordCmd := &cobra.Command{
 		Use:   "coordinator",
 		Short: "Start load test application in COORDINATOR mode",
 		Run: func(cmd *cobra.Command, args []string) {
-			logger.Debug(fmt.Sprintf("Configuration: %s", cfg.ToJSON()))
-			logger.Debug(fmt.Sprintf("Coordinator configuration: %s", coordCfg.ToJSON()))
+			if err := cfg.ResolveEndpoints(); err != nil {
+				logger.Error(err.Error())
+				os.Exit(1)
+			}
+			if err := cfg.ExpandPeerEndpoints(logger); err != nil {
+				logger.Error(err.Error())
+				os.Exit(1)
+			}
+			cfg.ResolveRandomSeed(logger)
+			logEffectiveConfig(logger, cfg)
+			logger.Info(fmt.Sprintf("Coordinator configuration: %s", coordCfg.ToJSON()))
 			if err := cfg.Validate(); err != nil {
 				logger.Error(err.Error())
 				os.Exit(1)
@@ -80,6 +224,18 @@ func buildCLI(cli *CLIConfig, logger logging.Logger) *cobra.Command {
 				logger.Error(err.Error())
 				os.Exit(1)
 			}
+			if cfg.Count > -1 && cfg.Count < coordCfg.ExpectWorkers {
+				logger.Error(fmt.Sprintf("--count (%d) is split between the workers, so it must be at least --expect-workers (%d)", cfg.Count, coordCfg.ExpectWorkers))
+				os.Exit(1)
+			}
+			if cfg.TargetTPS > 0 {
+				logger.Error("--target-tps is only supported in standalone mode")
+				os.Exit(1)
+			}
+			if cfg.FindMaxTPS {
+				logger.Error("--find-max-tps is only supported in standalone mode")
+				os.Exit(1)
+			}
 			coord := NewCoordinator(&cfg, &coordCfg)
 			if err := coord.Run(); err != nil {
 				os.Exit(1)
@@ -92,7 +248,6 @@ func buildCLI(cli *CLIConfig, logger logging.Logger) *cobra.Command {
 	coordCmd.PersistentFlags().IntVar(&coordCfg.ShutdownWait, "shutdown-wait", 0, "The number of seconds to wait after testing completes prior to shutting down the web server")
 	coordCmd.PersistentFlags().IntVar(&coordCfg.LoadTestID, "load-test-id", 0, "The ID of the load test currently underway")
 
-	var workerCfg WorkerConfig
 	workerCmd := &cobra.Command{
 		Use:   "worker",
 		Short: "Start load test application in WORKER mode",
@@ -116,29 +271,202 @@ func buildCLI(cli *CLIConfig, logger logging.Logger) *cobra.Command {
 	workerCmd.PersistentFlags().StringVar(&workerCfg.CoordAddr, "coordinator", "ws://localhost:26670", "The WebSockets URL on which to find the coordinator node")
 	workerCmd.PersistentFlags().IntVar(&workerCfg.CoordConnectTimeout, "connect-timeout", 180, "The maximum number of seconds to keep trying to connect to the coordinator")
 
+	replayCmd := &cobra.Command{
+		Use:   "replay <recording>",
+		Short: "Replay the transactions recorded by an earlier run (see --record) against the given endpoints",
+		Long: `Replay the transactions recorded by an earlier run (see --record) against the given endpoints.
+
+The recorded transactions are sent exactly as they were signed, so their
+account numbers and sequences are only valid on a chain in the same state as
+the one they were recorded against (e.g. a fresh chain started from the same
+genesis file and seeded the same way). Each recorded connection is replayed in
+order at --rate, and the replay ends once all of them have been sent (or
+--time elapses, if given). Only the transactions and their order are
+reproduced, not the timing of the original run: the recorded send times are
+ignored.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			cfg.ReplayFile = args[0]
+			if !cmd.Flags().Changed("time") {
+				cfg.Time = 0
+			}
+			if err := cfg.ResolveEndpoints(); err != nil {
+				logger.Error(err.Error())
+				os.Exit(1)
+			}
+			if err := cfg.ExpandPeerEndpoints(logger); err != nil {
+				logger.Error(err.Error())
+				os.Exit(1)
+			}
+			cfg.ResolveRandomSeed(logger)
+			logEffectiveConfig(logger, cfg)
+			if err := cfg.Validate(); err != nil {
+				logger.Error(err.Error())
+				os.Exit(1)
+			}
+			if err := ExecuteStandalone(cfg); err != nil {
+				os.Exit(1)
+			}
+		},
+	}
+
+	var (
+		measureFrom     int64
+		measureTo       int64
+		measureDuration time.Duration
+	)
+	measureCmd := &cobra.Command{
+		Use:   "measure",
+		Short: "Report the transaction throughput the chain actually committed over a range of blocks",
+		Long: `Report the transaction throughput the chain actually committed over a range of
+blocks, by querying the first endpoint's RPC API for the blocks' transaction
+counts, times and sizes. This is independent of the load test's own counters,
+so it can be compared against the rate at which transactions were sent.
+
+Either give a height range with --from (and optionally --to, which defaults to
+the latest block), or have the command watch the chain for --duration.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if (measureFrom > 0) == (measureDuration > 0) {
+				logger.Error("exactly one of --from or --duration must be specified")
+				os.Exit(1)
+			}
+			if err := cfg.ResolveEndpoints(); err != nil {
+				logger.Error(err.Error())
+				os.Exit(1)
+			}
+			if err := ExecuteMeasure(cfg, measureFrom, measureTo, measureDuration, os.Stdout); err != nil {
+				logger.Error(err.Error())
+				os.Exit(1)
+			}
+		},
+	}
+	measureCmd.Flags().Int64Var(&measureFrom, "from", 0, "Measure the blocks committed after this height")
+	measureCmd.Flags().Int64Var(&measureTo, "to", 0, "With --from, measure the blocks up to and including this height (defaults to the latest block)")
+	measureCmd.Flags().DurationVar(&measureDuration, "duration", 0, "Measure the blocks committed from now until this much time has passed (e.g. 60s), instead of a height range")
+
 	versionCmd := &cobra.Command{
 		Use:   "version",
-		Short: "Display the version of cometbft-load-test and exit",
+		Short: fmt.Sprintf("Display the version of %s (and the modules it was built against) and exit", cli.AppName),
 		Run: func(cmd *cobra.Command, args []string) {
-			version := CLIVersion
-			if len(cliVersionCommitID) > 0 {
-				version = fmt.Sprintf("%s-%s", version, cliVersionCommitID)
+			fmt.Println(cli.AppName, Version())
+			fmt.Println("  go", runtime.Version())
+			for _, path := range cli.VersionModules {
+				version, ok := ModuleVersion(path)
+				if !ok {
+					version = "unknown"
+				}
+				fmt.Printf("  %s %s\n", path, version)
 			}
-			fmt.Println("cometbft-load-test", version)
 		},
 	}
 
+	rootCmd.SetGlobalNormalizationFunc(normalizeFlagName)
+
 	rootCmd.AddCommand(coordCmd)
 	rootCmd.AddCommand(workerCmd)
+	rootCmd.AddCommand(replayCmd)
+	rootCmd.AddCommand(measureCmd)
 	rootCmd.AddCommand(versionCmd)
 	return rootCmd
 }
 
-func initLogLevel(logger logging.Logger) {
+// expandEnvFlags expands "${VAR}" references (see configfile.ExpandEnv) in the
+// options that typically differ between environments, i.e. endpoints and
+// addresses.
+func expandEnvFlags(cfg *Config, workerCfg *WorkerConfig) error {
+	for i, endpoint := range cfg.Endpoints {
+		expanded, err := configfile.ExpandEnv(endpoint)
+		if err != nil {
+			return fmt.Errorf("--endpoints: %w", err)
+		}
+		cfg.Endpoints[i] = expanded
+	}
+	for name, val := range map[string]*string{
+		"endpoints-file":   &cfg.EndpointsFile,
+		"endpoints-srv":    &cfg.EndpointsSRV,
+		"endpoint-weights": &cfg.EndpointWeights,
+		"hot-account":      &cfg.HotAccount,
+		"recipients-file":  &cfg.RecipientsFile,
+		"health-addr":      &cfg.HealthAddr,
+		"coordinator":      &workerCfg.CoordAddr,
+	} {
+		expanded, err := configfile.ExpandEnv(*val)
+		if err != nil {
+			return fmt.Errorf("--%s: %w", name, err)
+		}
+		*val = expanded
+	}
+	return nil
+}
+
+// flagAliases maps alternative flag names onto the flags they stand for.
+var flagAliases = map[string]string{
+	"broadcast-mode": "broadcast-tx-method",
+}
+
+func normalizeFlagName(_ *pflag.FlagSet, name string) pflag.NormalizedName {
+	if alias, ok := flagAliases[name]; ok {
+		name = alias
+	}
+	return pflag.NormalizedName(name)
+}
+
+// applyConfigFile sets each flag from the "loadtest" section of the given
+// config file, unless it was explicitly given on the command line. Options
+// are named exactly like their flags.
+func applyConfigFile(cmd *cobra.Command, path string) error {
+	f, err := configfile.Load(path)
+	if err != nil {
+		return err
+	}
+	if err := f.ApplySharedEnv(); err != nil {
+		return err
+	}
+	args, err := configfile.Args(f.LoadTest, nil)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < len(args); i += 2 {
+		name := args[i][2:]
+		flag := cmd.Flags().Lookup(name)
+		if flag == nil {
+			return fmt.Errorf("unknown load test option in config file %s: %s", path, name)
+		}
+		if flag.Changed {
+			continue
+		}
+		if err := cmd.Flags().Set(name, args[i+1]); err != nil {
+			return fmt.Errorf("invalid value for load test option %s in config file %s: %w", name, path, err)
+		}
+	}
+	return nil
+}
+
+func initLogging(logger logging.Logger, logFile string) error {
+	level := flagLogLevel
 	if flagVerbose {
-		logrus.SetLevel(logrus.DebugLevel)
-		logger.Debug("Set logging level to DEBUG")
+		level = "debug"
+	}
+	if err := logging.Configure(level, flagLogFormat); err != nil {
+		return err
+	}
+	if len(logFile) > 0 {
+		if err := logging.SetOutputFile(logFile); err != nil {
+			return err
+		}
+	}
+	logger.Debug("Configured logging", "level", level, "format", flagLogFormat)
+	return nil
+}
+
+// logEffectiveConfig logs the fully resolved configuration (see
+// Config.EffectiveJSON) at startup. In TUI mode, it is only written to the
+// log file, if any, since it would otherwise garble the UI.
+func logEffectiveConfig(logger logging.Logger, cfg Config) {
+	if cfg.UI == "tui" && len(cfg.LogFile) == 0 {
+		return
 	}
+	logger.Info(fmt.Sprintf("Effective configuration: %s", cfg.EffectiveJSON()))
 }
 
 // Run must be executed from your `main` function in your Go code. This can be
@@ -1,6 +1,9 @@
 package loadtest
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+)
 
 // ClientFactory produces load testing clients.
 type ClientFactory interface {
@@ -20,9 +23,146 @@ type Client interface {
 	GenerateTx() ([]byte, error)
 }
 
+// ErrClientExhausted may be returned by a client's GenerateTx when it has no
+// more transactions to generate (e.g. at the end of a replayed recording),
+// which stops its transactor without error.
+var ErrClientExhausted = errors.New("client has no more transactions")
+
+// ClientPreparer may optionally be implemented by clients that need to
+// perform potentially slow initialization (e.g. querying account state)
+// before they can generate transactions. Rather than having every client do
+// so lazily when the load test starts, the transactor group prepares all of
+// its clients up front, with bounded concurrency.
+type ClientPreparer interface {
+	// Prepare must perform any initialization required prior to the first
+	// call to GenerateTx.
+	Prepare() error
+}
+
+// ClientResyncer may optionally be implemented by clients that track state
+// which can diverge from the chain's (e.g. locally incremented account
+// sequence numbers). Resync is called when such divergence is likely, e.g.
+// after transactions may have been lost along with a dropped connection.
+type ClientResyncer interface {
+	// Resync must re-fetch any such state from the chain. It is never called
+	// concurrently with GenerateTx.
+	Resync() error
+}
+
+// ClientSequenceChecker may optionally be implemented by clients that track
+// state which can drift from the chain's over long runs (e.g. locally
+// incremented account sequence numbers, which get ahead of the chain whenever
+// transactions are rejected). With --resync-every, CheckSequence is called
+// periodically while sending.
+type ClientSequenceChecker interface {
+	// CheckSequence must compare the locally tracked state with the chain's
+	// and correct it if it has drifted, returning whether it did so. Unlike
+	// Resync, it must leave state that is merely ahead of the chain because
+	// of transactions that have yet to be committed untouched. It is never
+	// called concurrently with GenerateTx.
+	CheckSequence() (bool, error)
+}
+
+// ClientValidator may optionally be implemented by clients that can have the
+// node check a sample transaction without broadcasting it, which is used by
+// --validate-only runs to catch misconfigurations before generating any load.
+type ClientValidator interface {
+	// ValidateTx must generate a transaction (without affecting the ones
+	// generated after it), have the node check it without committing it and
+	// return a human-readable description of the transaction and the results
+	// of the checks. An error must be returned if the node would reject the
+	// transaction.
+	ValidateTx() (string, error)
+}
+
+// ClientLifecycle may optionally be implemented by clients whose transactions
+// depend on state that must be set up on the chain first (e.g. orders to be
+// cancelled), or that should be cleaned up afterwards (e.g. open positions).
+// Each transactor calls Warmup before it starts sending, and Teardown once it
+// stops.
+type ClientLifecycle interface {
+	// Warmup must send any transactions required prior to the first call to
+	// GenerateTx. An error stops the transactor before it sends anything.
+	Warmup() error
+	// Teardown must send any transactions required after the last call to
+	// GenerateTx. It is only called if Warmup succeeded, and errors are only
+	// logged.
+	Teardown() error
+}
+
+// FeeTracker may optionally be implemented by client factories that total the
+// fees of the transactions generated by their clients, so that they can be
+// reported. Factories that support a fee budget have their clients return
+// ErrClientExhausted once it is used up.
+type FeeTracker interface {
+	// FeesSpent must return the total fees of the transactions generated so
+	// far as a human-readable list of coins, or an empty string if none were.
+	FeesSpent() string
+	// FeeBudgetExhausted must return whether the clients stopped generating
+	// transactions because their fees would have exceeded the fee budget.
+	FeeBudgetExhausted() bool
+}
+
+// AccountTracker may optionally be implemented by client factories that keep
+// track of the distinct accounts touched by the transactions generated by
+// their clients, so that they can be reported (e.g. to correlate a run with
+// the growth of the chain's state).
+type AccountTracker interface {
+	// AccountsTouched must return the number of distinct accounts that sent
+	// funds in the transactions generated so far (including their signers),
+	// the number that were sent funds by them, and the number that did
+	// either.
+	AccountsTouched() (senders, recipients, total int)
+}
+
+// FactoryStopper may optionally be implemented by client factories that run
+// background tasks on behalf of their clients (e.g. keeping their accounts
+// funded). The transactor group calls Stop once it has finished, so that the
+// tasks don't outlive the load test.
+type FactoryStopper interface {
+	// Stop must stop the factory's background tasks. It may be called more
+	// than once.
+	Stop()
+}
+
+// ClientTxExpirer may optionally be implemented by clients that give their
+// transactions a timeout height, past which the chain no longer commits them.
+// Expired transactions leave gaps in e.g. account sequence numbers, so when a
+// transaction sampled for confirmation isn't found in a block and turns out
+// to have expired, it is counted as such and the client is resynced (if it
+// implements ClientResyncer) before it generates any more transactions.
+type ClientTxExpirer interface {
+	// TxExpired must return whether the given transaction, which was
+	// generated by the client, can no longer be committed because its
+	// timeout height has passed. It may be called concurrently with
+	// GenerateTx.
+	TxExpired(tx []byte) (bool, error)
+}
+
+// ClientFailureInjector may optionally be implemented by clients that can
+// generate transactions that are bound to be rejected, which are mixed into
+// the load if Config.InjectFailures is set. Injected transactions and their
+// rejections are counted separately, so that they don't affect the other
+// statistics.
+type ClientFailureInjector interface {
+	// GenerateInvalidTx must generate a transaction that the chain rejects
+	// (e.g. because it carries a wrong account sequence number), without
+	// affecting the transactions subsequently generated by GenerateTx.
+	GenerateInvalidTx() ([]byte, error)
+}
+
 // Our global registry of client factories
 var clientFactories = map[string]ClientFactory{}
 
+// newClient instantiates a client using the configured client factory.
+func newClient(config *Config) (Client, error) {
+	clientFactory, exists := clientFactories[config.ClientFactory]
+	if !exists {
+		return nil, fmt.Errorf("unrecognized client factory: %s", config.ClientFactory)
+	}
+	return clientFactory.NewClient(*config)
+}
+
 // RegisterClientFactory allows us to programmatically register different client
 // factories to easily switch between different ones at runtime.
 func RegisterClientFactory(name string, factory ClientFactory) error {
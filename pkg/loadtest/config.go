@@ -3,6 +3,10 @@ package loadtest
 import (
 	"encoding/json"
 	"fmt"
+	"math"
+	"net/url"
+
+	"github.com/1119-Labs/perpx-load-test/pkg/configfile"
 )
 
 const (
@@ -21,22 +25,100 @@ var validEndpointSelectMethods = map[string]interface{}{
 // worker).
 type Config struct {
 	ClientFactory        string   `json:"client_factory"`         // Which client factory should we use for load testing?
-	Connections          int      `json:"connections"`            // The number of WebSockets connections to make to each target endpoint.
+	Connections          int      `json:"connections"`            // The number of WebSockets connections to make to each target endpoint (or, with ConnectionPoolSize, the number of workers sharing its pooled connections).
 	Time                 int      `json:"time"`                   // The total time, in seconds, for which to handle the load test.
+	WarmupSeconds        int      `json:"warmup_seconds"`         // The time, in seconds, at the start of the load test during which transactions are sent but excluded from the final statistics.
 	SendPeriod           int      `json:"send_period"`            // The period (in seconds) at which to send batches of transactions.
 	Rate                 int      `json:"rate"`                   // The number of transactions to generate, per send period.
 	Size                 int      `json:"size"`                   // The desired size of each generated transaction, in bytes.
-	Count                int      `json:"count"`                  // The maximum number of transactions to send. Set to -1 for unlimited.
+	Count                int      `json:"count"`                  // The maximum total number of transactions to send across all connections and endpoints (and, in coordinator/worker mode, all workers). Set to -1 for unlimited.
 	BroadcastTxMethod    string   `json:"broadcast_tx_method"`    // The broadcast_tx method to use (can be "sync", "async" or "commit").
 	Endpoints            []string `json:"endpoints"`              // A list of the CometBFT node endpoints to which to connect for this load test.
+	EndpointsFile        string   `json:"endpoints_file"`         // A file listing additional endpoints, one per line (see ResolveEndpoints).
+	EndpointsSRV         string   `json:"endpoints_srv"`          // A DNS SRV name to expand into additional endpoints at startup (see LookupSRVEndpoints).
 	EndpointSelectMethod string   `json:"endpoint_select_method"` // The method by which to select endpoints for load testing.
 	UI                   string   `json:"ui"`                     // UI mode for standalone execution: "plain" or "tui".
 	ExpectPeers          int      `json:"expect_peers"`           // The minimum number of peers to expect before starting a load test. Set to 0 by default (no minimum).
 	MaxEndpoints         int      `json:"max_endpoints"`          // The maximum number of endpoints to use for load testing. Set to 0 by default (no maximum).
 	MinConnectivity      int      `json:"min_connectivity"`       // The minimum number of peers to which each peer must be connected before starting the load test. Set to 0 by default (no minimum).
 	PeerConnectTimeout   int      `json:"peer_connect_timeout"`   // The maximum time to wait (in seconds) for all peers to connect, if ExpectPeers > 0.
+	DiscoverPeers        bool     `json:"discover_peers"`         // Should the single (bootstrap) endpoint be expanded into it and its reachable peers' endpoints (see ExpandPeerEndpoints)?
 	StatsOutputFile      string   `json:"stats_output_file"`      // Where to store the final aggregate statistics file (in CSV format).
+	ReportJSONFile       string   `json:"report_json_file"`       // Where to store the final JSON report summarizing the run (see Report).
+	BlockAnalysis        bool     `json:"block_analysis"`         // Should we print how the transactions committed during the run were distributed across blocks? Only relevant for standalone execution mode.
 	NoTrapInterrupts     bool     `json:"no_trap_interrupts"`     // Should we avoid trapping Ctrl+Break? Only relevant for standalone execution mode.
+	Confirm              bool     `json:"confirm"`                // Should we sample submitted transactions and confirm that they were committed?
+	ConfirmEvery         int      `json:"confirm_every"`          // Sample every Nth transaction sent on each connection for confirmation.
+	ConfirmTimeout       int      `json:"confirm_timeout"`        // The maximum time to wait (in seconds) for a sampled transaction to be committed.
+	ConfirmTransport     string   `json:"confirm_transport"`      // How sampled transactions are confirmed: "rest" (polling, the default) or "ws" (subscribing to their commits, falling back to polling).
+	DrainTimeout         int      `json:"drain_timeout"`          // On interrupt, how long to wait (in seconds) for in-flight work to settle. Set to 0 to stop immediately.
+	HaltTimeout          int      `json:"halt_timeout"`           // How long (in seconds) the chain's height may go without advancing before the chain is considered halted. Set to 0 to disable halt detection.
+	StopOnHalt           bool     `json:"stop_on_halt"`           // Should the load test stop (draining as on interrupt) once the chain appears halted, rather than only warn?
+	Jitter               float64  `json:"jitter"`                 // The fraction of the send period within which the start of each batch is randomly offset (0 disables jitter).
+	MsgsPerTx            int      `json:"msgs_per_tx"`            // The number of messages the client should pack into each generated transaction.
+	AmountDistribution   string   `json:"amount_distribution"`    // The distribution from which the amount sent by each message is drawn (e.g. "lognormal:median=1000,sigma=1.5"). Leave empty to always send 1 base unit.
+	RandomSeed           int64    `json:"random_seed"`            // The seed from which all of the run's pseudo-random choices are derived, so that runs with the same seed and configuration generate the same load. Set to 0 to generate one (see ResolveRandomSeed).
+	FeeDenom             string   `json:"fee_denom"`              // The denom in which transaction fees are paid, if different from the transfer denom.
+	FeeBudget            string   `json:"fee_budget"`             // The total fees (as coins, e.g. "1000000aperpx") after which to stop sending, if any.
+	FeeGranters          []string `json:"fee_granters"`           // If set, the (bech32) accounts that pay the senders' fees via fee allowances, assigned to the senders round-robin.
+	Bech32Prefix         string   `json:"bech32_prefix"`          // The Bech32 prefix of the chain's account addresses. Leave empty to use the client's default.
+	KeyAlgo              string   `json:"key_algo"`               // The algorithm of the chain's account keys (secp256k1 or eth_secp256k1). Leave empty to use the client's default.
+	AutoRefund           string   `json:"auto_refund"`            // If set, worker accounts whose balances run low are periodically topped up with this amount (e.g. "1000000aperpx") from the seed account.
+	RefundThreshold      string   `json:"refund_threshold"`       // The balance below which worker accounts are topped up. Leave empty to use half of the auto-refund amount.
+	AutoCreateAccounts   bool     `json:"auto_create_accounts"`   // Should worker accounts that don't exist yet be funded from the seed account, rather than failing the load test?
+	ColdStart            bool     `json:"cold_start"`             // Should each worker send from a never-before-seen account, created just before it starts, and the latency of each account's first commit be reported?
+	SelfSend             bool     `json:"self_send"`              // Should the client send funds back to each sender's own address instead of draining them into a sink?
+	FreshRecipients      int      `json:"fresh_recipients"`       // If > 0, each sender cycles through this many generated, initially unfunded recipient addresses instead of sending to a sink.
+	RecipientSalt        string   `json:"recipient_salt"`         // The salt from which fresh recipient addresses are derived. Leave empty to generate one per run.
+	RecipientsFile       string   `json:"recipients_file"`        // If set, senders cycle through the addresses in this file (one bech32 address per line) instead of sending to a sink.
+	RecipientsFileStrict bool     `json:"recipients_file_strict"` // Should invalid lines in the recipients file be fatal, rather than skipped?
+	Recipients           string   `json:"recipients"`             // If set, the source of the addresses senders cycle through instead of sending to a sink, e.g. "top-accounts:100" for the accounts with the largest balances.
+	HotAccount           string   `json:"hot_account"`            // If set, each sender alternately sends funds to this (bech32) address and has it send them back via authz, instead of sending to a sink.
+	SinksPerWorker       int      `json:"sinks_per_worker"`       // If > 0, each sender cycles through this many sink addresses of its own (the same on every run) instead of sending to the shared sink.
+	PerpRoundTrip        string   `json:"perp_round_trip"`        // If set, the orders (e.g. "clob=0,quantums=1000000,buy-subticks=200000,sell-subticks=100000") with which each sender alternately opens and closes a perp position, instead of sending funds.
+	WasmContract         string   `json:"wasm_contract"`          // If set, each sender executes this CosmWasm contract (with WasmMsg) instead of sending funds. Requires a chain with the wasm module.
+	WasmMsg              string   `json:"wasm_msg"`               // The JSON message with which the wasm contract is executed.
+	WasmFunds            string   `json:"wasm_funds"`             // The funds (e.g. "10aperpx") attached to each execution of the wasm contract. Leave empty to attach none.
+	StrategySequence     string   `json:"strategy_sequence"`      // If set, the strategies (e.g. "bank-send,hot-account") each sender cycles through in this fixed order, one per message.
+	StrategyGas          string   `json:"strategy_gas"`           // Per-strategy overrides (e.g. "bank-send=150000,perp-round-trip=400000") of the gas limit allotted to each message. Leave empty to use each strategy's own estimate.
+	StrategyGasPrice     string   `json:"strategy_gas_price"`     // Per-strategy overrides (e.g. "perp-round-trip=50000000000aperpx") of the price per unit of gas at which fees are paid. Leave empty to use the client's default.
+	InjectFailures       float64  `json:"inject_failures"`        // The percentage (0-100) of transactions to replace with deliberately invalid ones, which are counted separately. Set to 0 to disable.
+	MempoolFullBackoff   int      `json:"mempool_full_backoff"`   // How long (in milliseconds) a connection should stop sending after a mempool-full rejection. Set to 0 to disable backoff.
+	WorkerStartStagger   int      `json:"worker_start_stagger"`   // How long (in milliseconds) to wait between connecting, and between starting, successive workers. Set to 0 to start them all at once.
+	ValidateOnly         bool     `json:"validate_only"`          // Should we only generate and check a single transaction instead of running the load test? Only relevant for standalone execution mode.
+	OutputTxs            string   `json:"output_txs"`             // If set, Count transactions are generated and written to this file in the recording format (see TxRecorder) instead of running the load test. Only relevant for standalone execution mode.
+	HealthAddr           string   `json:"health_addr"`            // The "host:port" on which to serve health, readiness and status endpoints. Leave empty to disable.
+	EndpointWeights      string   `json:"endpoint_weights"`       // Comma-separated "endpoint:weight" pairs by which to distribute the total rate across endpoints (see EndpointWeights).
+	AdaptiveRouting      bool     `json:"adaptive_routing"`       // Whether to steer load towards the endpoints that answer broadcasts fastest.
+	Spike                string   `json:"spike"`                  // Periodic bursts of load, e.g. "every=30s,factor=5,duration=3s" (see SpikeSchedule). Leave empty to send at a steady rate.
+	TargetTPS            int      `json:"target_tps"`             // If set, the total number of accepted transactions per second to aim for, continuously adjusting the rate to achieve it (see ApplyTargetTPS and TPSController). Standalone mode only.
+	FindMaxTPS           bool     `json:"find_max_tps"`           // Should we search for the maximum sustainable TPS with a series of short probes (see ExecuteFindMaxTPS) instead of running a single load test? Standalone mode only.
+	MinSuccessRate       float64  `json:"min_success_rate"`       // When searching for the maximum sustainable TPS, the fraction of sampled transactions that must be committed successfully for a rate to be considered sustainable.
+	MaxCommitLatency     int      `json:"max_commit_latency"`     // When searching for the maximum sustainable TPS, the 90th percentile commit latency (in milliseconds) beyond which a rate isn't considered sustainable. Set to 0 for no bound.
+	ResyncEvery          int      `json:"resync_every"`           // Re-read each client's on-chain state (e.g. account sequence) after every N transactions it sends, correcting any drift. Set to 0 to never resync.
+	Record               string   `json:"record"`                 // If set, every transaction sent is recorded to this file (see TxRecorder) for later replay.
+	ReplayFile           string   `json:"replay_file"`            // If set, the transactions recorded in this file are replayed instead of generating new ones.
+	MaxInFlight          int      `json:"max_inflight"`           // The maximum number of unacknowledged transactions per transactor, beyond which it blocks until some are acknowledged. Set to 0 for no limit.
+	ConnectionPoolSize   int      `json:"connection_pool_size"`   // The number of WebSockets connections per endpoint over which that endpoint's transactors multiplex their broadcasts. Set to 0 to give each transactor its own connection.
+	MaxReconnectAttempts int      `json:"max_reconnect_attempts"` // How many consecutive times to try to reconnect to an endpoint that dropped its connection before giving up on it. Set to 0 to fail immediately.
+	AccountNumber        string   `json:"account_number"`         // If set, the workers' account numbers (the first worker's, the others following consecutively, or a comma-separated list with one per worker), which are then never queried.
+	StartSequence        string   `json:"start_sequence"`         // If set, the workers' starting sequences (one for all of them, or a comma-separated list with one per worker). Must be set along with AccountNumber.
+	PrepareConcurrency   int      `json:"prepare_concurrency"`    // How many clients to prepare (e.g. query account state for) concurrently before starting. Set to 0 to have clients prepare lazily.
+	SignConcurrency      int      `json:"sign_concurrency"`       // The maximum number of transactions that clients may sign at once. Set to 0 to have every client sign as soon as it generates a transaction.
+	HTTPTimeout          int      `json:"http_timeout"`           // The maximum time (in seconds) to wait for each REST API request.
+	HTTPMaxIdleConns     int      `json:"http_max_idle_conns"`    // The maximum number of idle REST API connections to keep open (per host) for reuse.
+	QueryTransport       string   `json:"query_transport"`        // How clients query account state: "rest" (the default) or "grpc".
+	QueryRetries         int      `json:"query_retries"`          // How many times clients retry an account query that failed transiently (e.g. with HTTP 503 or a timeout), with exponential backoff.
+	Compression          bool     `json:"compression"`            // Should REST and gRPC queries be gzip compressed, to save bandwidth against remote nodes?
+	LogFile              string   `json:"log_file"`               // Where to write logs instead of the terminal. In TUI mode, this allows for full logging without corrupting the screen.
+	CPUProfile           string   `json:"cpu_profile"`            // If set, a CPU profile of the load test is written to this file (standalone mode only).
+	MemProfile           string   `json:"mem_profile"`            // If set, a heap profile is written to this file once the load test is done (standalone mode only).
+
+	VerifySignatures bool `json:"verify_signatures"` // Should the first generated transaction's signature be verified locally, against the node's chain ID, before anything is broadcast?
+
+	// If set, notified of the outcome of every transaction. Not serialized,
+	// since it only applies to the process in which it is registered.
+	ResultObserver ResultObserver `json:"-"`
 }
 
 // CoordinatorConfig is the configuration options specific to a coordinator node.
@@ -53,6 +135,10 @@ type WorkerConfig struct {
 	ID                  string `json:"id"`              // A unique ID for this worker instance. Will show up in the metrics reported by the coordinator for this worker.
 	CoordAddr           string `json:"coord_addr"`      // The address at which to find the coordinator node.
 	CoordConnectTimeout int    `json:"connect_timeout"` // The maximum amount of time, in seconds, to allow for the coordinator to become available.
+
+	// If set, notified of the outcome of every transaction sent by this
+	// worker (see Config.ResultObserver).
+	ResultObserver ResultObserver `json:"-"`
 }
 
 var validBroadcastTxMethods = map[string]interface{}{
@@ -74,15 +160,21 @@ func (c Config) Validate() error {
 	if !factoryExists {
 		return fmt.Errorf("client factory \"%s\" does not exist", c.ClientFactory)
 	}
-	// client factory-specific configuration validation
-	if err := factory.ValidateConfig(c); err != nil {
-		return fmt.Errorf("invalid configuration for client factory \"%s\": %v", c.ClientFactory, err)
+	// client factory-specific configuration validation (replayed
+	// transactions don't come from the client factory)
+	if len(c.ReplayFile) == 0 {
+		if err := factory.ValidateConfig(c); err != nil {
+			return fmt.Errorf("invalid configuration for client factory \"%s\": %v", c.ClientFactory, err)
+		}
 	}
 	if c.Connections < 1 {
 		return fmt.Errorf("expected connections to be >= 1, but was %d", c.Connections)
 	}
-	if c.Time < 1 {
-		return fmt.Errorf("expected load test time to be >= 1 second, but was %d", c.Time)
+	if c.Time < 0 || (c.Time == 0 && c.Count < 1 && len(c.ReplayFile) == 0) {
+		return fmt.Errorf("expected load test time to be >= 1 second (or 0 if a transaction count limit is set or a recording is being replayed), but was %d", c.Time)
+	}
+	if c.WarmupSeconds < 0 || (c.Time > 0 && c.WarmupSeconds >= c.Time) {
+		return fmt.Errorf("expected warmup-seconds to be >= 0 and less than the load test time (%d seconds), but was %d", c.Time, c.WarmupSeconds)
 	}
 	if c.SendPeriod < 1 {
 		return fmt.Errorf("expected transaction send period to be >= 1 second, but was %d", c.SendPeriod)
@@ -99,6 +191,72 @@ func (c Config) Validate() error {
 	if len(c.Endpoints) == 0 {
 		return fmt.Errorf("expected at least one endpoint to conduct load test against, but found none")
 	}
+	if _, err := ParseEndpointWeights(c.EndpointWeights); err != nil {
+		return fmt.Errorf("invalid endpoint-weights: %w", err)
+	}
+	if _, err := ParseSpikeSchedule(c.Spike); err != nil {
+		return fmt.Errorf("invalid spike: %w", err)
+	}
+	if len(c.OutputTxs) > 0 {
+		switch {
+		case c.Count < 1:
+			return fmt.Errorf("output-txs requires the number of transactions to generate to be set with count")
+		case c.ValidateOnly:
+			return fmt.Errorf("output-txs cannot be combined with validate-only")
+		case len(c.ReplayFile) > 0 || len(c.Record) > 0:
+			return fmt.Errorf("output-txs cannot be combined with recording or replaying transactions")
+		}
+	}
+	if c.TargetTPS < 0 {
+		return fmt.Errorf("expected target-tps to be >= 0, but was %d", c.TargetTPS)
+	}
+	if c.TargetTPS > 0 && len(c.Spike) > 0 {
+		return fmt.Errorf("target-tps cannot be combined with spike, since the controller would counteract the spikes")
+	}
+	if c.TargetTPS > 0 && len(c.ReplayFile) > 0 {
+		return fmt.Errorf("target-tps cannot be used when replaying a recording")
+	}
+	if c.FindMaxTPS {
+		// each probe is judged by the outcomes of its sampled transactions,
+		// and runs for the load test time at a target TPS of its own
+		switch {
+		case !c.Confirm:
+			return fmt.Errorf("find-max-tps requires confirm to be enabled")
+		case c.MinSuccessRate <= 0 || c.MinSuccessRate > 1:
+			return fmt.Errorf("min-success-rate must be in the range (0, 1], but got %g", c.MinSuccessRate)
+		case c.Count != -1 || c.Time < 1:
+			return fmt.Errorf("find-max-tps requires a load test time for each probe, and cannot be combined with count")
+		case len(c.Spike) > 0:
+			return fmt.Errorf("find-max-tps cannot be combined with spike")
+		case len(c.ReplayFile) > 0 || len(c.Record) > 0 || len(c.OutputTxs) > 0 || c.ValidateOnly:
+			return fmt.Errorf("find-max-tps cannot be combined with recording or replaying transactions, output-txs or validate-only")
+		case c.ColdStart || len(c.FeeBudget) > 0:
+			return fmt.Errorf("find-max-tps cannot be combined with cold-start or fee-budget, which would apply to each probe separately")
+		case c.UI == "tui" || c.BlockAnalysis || len(c.StatsOutputFile) > 0 || len(c.HealthAddr) > 0:
+			return fmt.Errorf("find-max-tps cannot be combined with the tui, block-analysis, stats-output or health-addr")
+		}
+	}
+	if c.MaxCommitLatency < 0 {
+		return fmt.Errorf("max-commit-latency must be at least 0, but got %d", c.MaxCommitLatency)
+	}
+	if c.MaxCommitLatency > 0 && !c.FindMaxTPS {
+		return fmt.Errorf("max-commit-latency requires find-max-tps")
+	}
+	if c.AdaptiveRouting && len(c.ReplayFile) > 0 {
+		return fmt.Errorf("adaptive-routing cannot be used when replaying a recording")
+	}
+	if c.InjectFailures < 0 || c.InjectFailures > 100 {
+		return fmt.Errorf("inject-failures must be a percentage between 0 and 100, but got %g", c.InjectFailures)
+	}
+	if c.InjectFailures > 0 && len(c.ReplayFile) > 0 {
+		return fmt.Errorf("inject-failures cannot be used when replaying a recording")
+	}
+	if len(c.FeeGranters) > 0 && len(c.ReplayFile) > 0 {
+		return fmt.Errorf("fee-granters cannot be used when replaying a recording, since replayed transactions are already signed")
+	}
+	if len(c.FeeBudget) > 0 && len(c.ReplayFile) > 0 {
+		return fmt.Errorf("fee-budget cannot be used when replaying a recording, since the fees of replayed transactions aren't tracked")
+	}
 	if _, ok := validEndpointSelectMethods[c.EndpointSelectMethod]; !ok {
 		return fmt.Errorf("invalid endpoint-select-method: %s", c.EndpointSelectMethod)
 	}
@@ -112,6 +270,9 @@ func (c Config) Validate() error {
 	if c.ExpectPeers < 0 {
 		return fmt.Errorf("expect-peers must be at least 0, but got %d", c.ExpectPeers)
 	}
+	if c.DiscoverPeers && c.ExpectPeers > 0 {
+		return fmt.Errorf("discover-peers cannot be combined with expect-peers, which discovers peers itself")
+	}
 	if c.ExpectPeers > 0 && c.PeerConnectTimeout < 1 {
 		return fmt.Errorf("peer-connect-timeout must be at least 1 if expect-peers is non-zero, but got %d", c.PeerConnectTimeout)
 	}
@@ -121,16 +282,164 @@ func (c Config) Validate() error {
 	if c.MinConnectivity < 0 {
 		return fmt.Errorf("invalid value for min-peer-connectivity: %d", c.MinConnectivity)
 	}
+	// no node can be connected to more peers than the network is expected to
+	// have
+	if c.MinConnectivity > c.ExpectPeers {
+		return fmt.Errorf("min-peer-connectivity (%d) cannot exceed expect-peers (%d)", c.MinConnectivity, c.ExpectPeers)
+	}
+	if c.MsgsPerTx < 1 {
+		return fmt.Errorf("expected msgs-per-tx to be >= 1, but was %d", c.MsgsPerTx)
+	}
+	if c.FreshRecipients < 0 {
+		return fmt.Errorf("fresh-recipients must be at least 0, but got %d", c.FreshRecipients)
+	}
+	if c.SelfSend && c.FreshRecipients > 0 {
+		return fmt.Errorf("self-send and fresh-recipients are mutually exclusive")
+	}
+	if len(c.RecipientsFile) > 0 && (c.SelfSend || c.FreshRecipients > 0) {
+		return fmt.Errorf("recipients-file cannot be combined with self-send or fresh-recipients")
+	}
+	if len(c.Recipients) > 0 && (c.SelfSend || c.FreshRecipients > 0 || len(c.RecipientsFile) > 0) {
+		return fmt.Errorf("recipients cannot be combined with self-send, fresh-recipients or recipients-file")
+	}
+	if len(c.HotAccount) > 0 && (c.SelfSend || c.FreshRecipients > 0 || len(c.RecipientsFile) > 0 || len(c.Recipients) > 0) {
+		return fmt.Errorf("hot-account cannot be combined with self-send, fresh-recipients, recipients-file or recipients")
+	}
+	if c.SinksPerWorker < 0 {
+		return fmt.Errorf("sinks-per-worker must be at least 0, but got %d", c.SinksPerWorker)
+	}
+	if c.SinksPerWorker > 0 && (c.SelfSend || c.FreshRecipients > 0 || len(c.RecipientsFile) > 0 || len(c.Recipients) > 0 || len(c.HotAccount) > 0) {
+		return fmt.Errorf("sinks-per-worker cannot be combined with self-send, fresh-recipients, recipients-file, recipients or hot-account")
+	}
+	if len(c.PerpRoundTrip) > 0 {
+		if c.SelfSend || c.FreshRecipients > 0 || len(c.RecipientsFile) > 0 || len(c.Recipients) > 0 || len(c.HotAccount) > 0 || c.SinksPerWorker > 0 {
+			return fmt.Errorf("perp-round-trip cannot be combined with self-send, fresh-recipients, recipients-file, recipients, hot-account or sinks-per-worker")
+		}
+		if len(c.StrategySequence) > 0 {
+			return fmt.Errorf("perp-round-trip cannot be combined with strategy-sequence")
+		}
+		if c.MsgsPerTx != 1 {
+			return fmt.Errorf("perp-round-trip requires msgs-per-tx to be 1, but got %d", c.MsgsPerTx)
+		}
+		if c.InjectFailures > 0 {
+			// invalid transactions are made with wrong sequence numbers,
+			// which the chain doesn't check for short-term orders
+			return fmt.Errorf("perp-round-trip cannot be combined with inject-failures")
+		}
+	}
+	if len(c.WasmContract) > 0 {
+		if c.SelfSend || c.FreshRecipients > 0 || len(c.RecipientsFile) > 0 || len(c.Recipients) > 0 || len(c.HotAccount) > 0 || c.SinksPerWorker > 0 || len(c.PerpRoundTrip) > 0 {
+			return fmt.Errorf("wasm-contract cannot be combined with self-send, fresh-recipients, recipients-file, recipients, hot-account, sinks-per-worker or perp-round-trip")
+		}
+		if len(c.StrategySequence) > 0 {
+			return fmt.Errorf("wasm-contract cannot be combined with strategy-sequence")
+		}
+		if len(c.WasmMsg) == 0 {
+			return fmt.Errorf("wasm-contract requires wasm-msg")
+		}
+	} else if len(c.WasmMsg) > 0 || len(c.WasmFunds) > 0 {
+		return fmt.Errorf("wasm-msg and wasm-funds require wasm-contract")
+	}
+	if c.HTTPTimeout < 1 {
+		return fmt.Errorf("http-timeout must be at least 1, but got %d", c.HTTPTimeout)
+	}
+	if c.HTTPMaxIdleConns < 0 {
+		return fmt.Errorf("http-max-idle-conns must be at least 0, but got %d", c.HTTPMaxIdleConns)
+	}
+	if len(c.RefundThreshold) > 0 && len(c.AutoRefund) == 0 {
+		return fmt.Errorf("refund-threshold requires auto-refund")
+	}
+	if c.MaxInFlight < 0 {
+		return fmt.Errorf("max-inflight must be at least 0, but got %d", c.MaxInFlight)
+	}
+	if c.ConnectionPoolSize < 0 {
+		return fmt.Errorf("connection-pool-size must be at least 0, but got %d", c.ConnectionPoolSize)
+	}
+	if c.MaxReconnectAttempts < 0 {
+		return fmt.Errorf("max-reconnect-attempts must be at least 0, but got %d", c.MaxReconnectAttempts)
+	}
+	if c.ResyncEvery < 0 {
+		return fmt.Errorf("resync-every must be at least 0, but got %d", c.ResyncEvery)
+	}
+	if c.PrepareConcurrency < 0 {
+		return fmt.Errorf("prepare-concurrency must be at least 0, but got %d", c.PrepareConcurrency)
+	}
+	if c.SignConcurrency < 0 {
+		return fmt.Errorf("sign-concurrency must be at least 0, but got %d", c.SignConcurrency)
+	}
+	if c.Jitter < 0 || c.Jitter >= 1 {
+		return fmt.Errorf("jitter must be in the range [0, 1), but got %f", c.Jitter)
+	}
+	if c.WorkerStartStagger < 0 {
+		return fmt.Errorf("worker-start-stagger must be at least 0, but got %d", c.WorkerStartStagger)
+	}
+	if c.MempoolFullBackoff < 0 {
+		return fmt.Errorf("mempool-full-backoff must be at least 0, but got %d", c.MempoolFullBackoff)
+	}
+	if c.DrainTimeout < 0 {
+		return fmt.Errorf("drain-timeout must be at least 0, but got %d", c.DrainTimeout)
+	}
+	if c.HaltTimeout < 0 {
+		return fmt.Errorf("halt-timeout must be at least 0, but got %d", c.HaltTimeout)
+	}
+	if c.StopOnHalt && c.HaltTimeout == 0 {
+		return fmt.Errorf("stop-on-halt requires halt-timeout to be greater than 0")
+	}
+	if c.Confirm && c.ConfirmEvery < 1 {
+		return fmt.Errorf("confirm-every must be at least 1 if confirm is enabled, but got %d", c.ConfirmEvery)
+	}
+	if c.Confirm && c.ConfirmTimeout < 1 {
+		return fmt.Errorf("confirm-timeout must be at least 1 if confirm is enabled, but got %d", c.ConfirmTimeout)
+	}
+	if c.QueryRetries < 0 {
+		return fmt.Errorf("query-retries must be at least 0, but got %d", c.QueryRetries)
+	}
+	switch c.ConfirmTransport {
+	case "", ConfirmTransportREST, ConfirmTransportWS:
+	default:
+		return fmt.Errorf("invalid confirm-transport %q (must be %q or %q)", c.ConfirmTransport, ConfirmTransportREST, ConfirmTransportWS)
+	}
+	if c.ColdStart {
+		// first commits are detected by confirming the first transaction
+		// each account sends (and every ConfirmEvery-th after it)
+		if !c.Confirm {
+			return fmt.Errorf("cold-start requires confirm to be enabled")
+		}
+		if c.WarmupSeconds > 0 {
+			return fmt.Errorf("cold-start cannot be combined with warmup, since transactions aren't confirmed during the warmup period")
+		}
+		if len(c.ReplayFile) > 0 {
+			return fmt.Errorf("cold-start cannot be used when replaying a recording")
+		}
+	}
 	return nil
 }
 
 // MaxTxsPerEndpoint estimates the maximum number of transactions that this
-// configuration would generate for a single endpoint.
+// configuration would generate for a single endpoint. Since Count is a total
+// across all endpoints, it is an upper bound for any single endpoint.
 func (c Config) MaxTxsPerEndpoint() uint64 {
 	if c.Count > -1 {
 		return uint64(c.Count)
 	}
-	return uint64(c.Rate) * uint64(c.Time)
+	// weighted endpoints may be sent more than the configured rate
+	rate := c.Rate
+	if weights, err := ParseEndpointWeights(c.EndpointWeights); err == nil {
+		for _, r := range weights.Rates(c.Rate, c.Endpoints) {
+			if r > rate {
+				rate = r
+			}
+		}
+	}
+	// as may spikes
+	if spike, err := ParseSpikeSchedule(c.Spike); err == nil {
+		rate = spike.PeakRate(rate)
+	}
+	// and the target-TPS controller may scale the rate up
+	if c.TargetTPS > 0 {
+		rate = int(math.Ceil(float64(rate) * tpsMaxMultiplier))
+	}
+	return uint64(rate) * uint64(c.Time)
 }
 
 func (c CoordinatorConfig) ToJSON() string {
@@ -165,6 +474,29 @@ func (c Config) ToJSON() string {
 	return string(b)
 }
 
+// EffectiveJSON returns the fully resolved configuration as JSON, like
+// ToJSON, together with the shared settings that clients read from the
+// environment (see configfile.SharedEnv), so that it is clear exactly what a
+// run used. Private keys and the credentials of endpoint URLs are redacted.
+func (c Config) EffectiveJSON() string {
+	endpoints := make([]string, len(c.Endpoints))
+	for i, endpoint := range c.Endpoints {
+		endpoints[i] = endpoint
+		if u, err := url.Parse(endpoint); err == nil {
+			endpoints[i] = u.Redacted()
+		}
+	}
+	c.Endpoints = endpoints
+	b, err := json.Marshal(struct {
+		Config Config            `json:"config"`
+		Env    map[string]string `json:"env"`
+	}{c, configfile.SharedEnv()})
+	if err != nil {
+		return fmt.Sprintf("%v", c)
+	}
+	return string(b)
+}
+
 func (c WorkerConfig) Validate() error {
 	if len(c.ID) > 0 && !isValidWorkerID(c.ID) {
 		return fmt.Errorf("Invalid worker ID \"%s\": worker IDs can only be lowercase alphanumeric characters", c.ID)
@@ -0,0 +1,119 @@
+package loadtest_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/1119-Labs/perpx-load-test/internal/logging"
+	"github.com/1119-Labs/perpx-load-test/pkg/loadtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEffectiveJSON(t *testing.T) {
+	t.Setenv("LOADTEST_CHAIN_ID", "localperpxprotocol")
+	t.Setenv("LOADTEST_SEED_PRIVATE_KEY", "0123abcd")
+	cfg := loadtest.Config{
+		Rate:      100,
+		Endpoints: []string{"ws://user:secret@node1:26657/websocket", "ws://node2:26657/websocket"},
+	}
+
+	var effective struct {
+		Config loadtest.Config   `json:"config"`
+		Env    map[string]string `json:"env"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(cfg.EffectiveJSON()), &effective))
+	assert.Equal(t, 100, effective.Config.Rate)
+	assert.Equal(t, []string{"ws://user:xxxxx@node1:26657/websocket", "ws://node2:26657/websocket"}, effective.Config.Endpoints)
+	assert.Equal(t, "localperpxprotocol", effective.Env["LOADTEST_CHAIN_ID"])
+	assert.Equal(t, "[REDACTED]", effective.Env["LOADTEST_SEED_PRIVATE_KEY"])
+	// the configuration itself is left untouched
+	assert.Equal(t, "ws://user:secret@node1:26657/websocket", cfg.Endpoints[0])
+}
+
+func TestResolveRandomSeed(t *testing.T) {
+	cfg := loadtest.Config{RandomSeed: 42}
+	cfg.ResolveRandomSeed(logging.NewNoopLogger())
+	assert.Equal(t, int64(42), cfg.RandomSeed)
+
+	cfg.RandomSeed = 0
+	cfg.ResolveRandomSeed(logging.NewNoopLogger())
+	assert.NotZero(t, cfg.RandomSeed)
+
+	// each PRNG gets its own seed, but the same one for the same run seed
+	assert.Equal(t, loadtest.DeriveSeed(42, "transactor", 1), loadtest.DeriveSeed(42, "transactor", 1))
+	assert.NotEqual(t, loadtest.DeriveSeed(42, "transactor", 1), loadtest.DeriveSeed(42, "transactor", 2))
+	assert.NotEqual(t, loadtest.DeriveSeed(42, "transactor", 1), loadtest.DeriveSeed(42, "strategy", 1))
+	assert.NotEqual(t, loadtest.DeriveSeed(42, "transactor", 1), loadtest.DeriveSeed(43, "transactor", 1))
+}
+
+func TestValidatePeerWait(t *testing.T) {
+	cfg := loadtest.Config{
+		ClientFactory:        "kvstore",
+		Connections:          1,
+		Time:                 5,
+		SendPeriod:           1,
+		Rate:                 100,
+		Size:                 100,
+		Count:                -1,
+		MsgsPerTx:            1,
+		HTTPTimeout:          10,
+		BroadcastTxMethod:    "async",
+		Endpoints:            []string{"ws://localhost:26657/websocket"},
+		EndpointSelectMethod: loadtest.SelectAnyEndpoints,
+		PeerConnectTimeout:   600,
+		ExpectPeers:          4,
+		MinConnectivity:      3,
+		MaxEndpoints:         2,
+	}
+	require.NoError(t, cfg.Validate())
+
+	cfg.MinConnectivity = 5
+	assert.ErrorContains(t, cfg.Validate(), "min-peer-connectivity (5) cannot exceed expect-peers (4)")
+
+	// without waiting for peers, max-endpoints is ignored
+	cfg.ExpectPeers, cfg.MinConnectivity = 0, 0
+	require.NoError(t, cfg.Validate())
+	cfg.MinConnectivity = 1
+	assert.ErrorContains(t, cfg.Validate(), "cannot exceed expect-peers (0)")
+}
+
+func TestValidateFindMaxTPS(t *testing.T) {
+	cfg := loadtest.Config{
+		ClientFactory:        "kvstore",
+		Connections:          1,
+		Time:                 30,
+		SendPeriod:           1,
+		Rate:                 100,
+		Size:                 100,
+		Count:                -1,
+		MsgsPerTx:            1,
+		HTTPTimeout:          10,
+		BroadcastTxMethod:    "async",
+		Endpoints:            []string{"ws://localhost:26657/websocket"},
+		EndpointSelectMethod: loadtest.SelectSuppliedEndpoints,
+		FindMaxTPS:           true,
+		MinSuccessRate:       0.99,
+		MaxCommitLatency:     3000,
+	}
+	assert.ErrorContains(t, cfg.Validate(), "find-max-tps requires confirm to be enabled")
+
+	cfg.Confirm, cfg.ConfirmEvery, cfg.ConfirmTimeout = true, 10, 30
+	require.NoError(t, cfg.Validate())
+
+	cfg.MinSuccessRate = 1.5
+	assert.ErrorContains(t, cfg.Validate(), "min-success-rate must be in the range (0, 1]")
+	cfg.MinSuccessRate = 0.99
+
+	cfg.Count = 1000
+	assert.ErrorContains(t, cfg.Validate(), "cannot be combined with count")
+	cfg.Count = -1
+
+	cfg.UI = "tui"
+	assert.ErrorContains(t, cfg.Validate(), "find-max-tps cannot be combined with the tui")
+	cfg.UI = "plain"
+
+	// the latency bound only applies to the search
+	cfg.FindMaxTPS = false
+	assert.ErrorContains(t, cfg.Validate(), "max-commit-latency requires find-max-tps")
+}
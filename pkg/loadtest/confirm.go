@@ -0,0 +1,370 @@
+package loadtest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/1119-Labs/perpx-load-test/internal/logging"
+)
+
+const (
+	// How many goroutines concurrently poll the REST API for sampled
+	// transactions. This bounds the query load the confirmer itself imposes.
+	confirmWorkers = 8
+	// How many sampled transactions may be waiting for a confirmation worker
+	// before further samples are dropped.
+	confirmQueueSize = 10000
+	// How long to wait between successive polls for the same transaction.
+	confirmPollInterval = 500 * time.Millisecond
+)
+
+// pendingTx is a submitted transaction that has been sampled for
+// confirmation.
+type pendingTx struct {
+	hash        string                  // Upper-case hex-encoded SHA256 hash of the raw transaction bytes.
+	endpoint    string                  // The WebSockets endpoint to which the transaction was submitted.
+	restURL     string                  // The REST API on which to look the transaction up.
+	submittedAt time.Time               // When the transaction was written to the WebSockets connection.
+	onResult    func(res *restTxResult) // Called with the transaction's result once found in a block, or with nil if it wasn't found in time.
+	onResolved  func()                  // Called once the sample has been resolved (or abandoned), whatever the outcome.
+}
+
+// txConfirmer samples transactions submitted by a group of transactors and
+// polls the Cosmos SDK REST API until each one has been included in a block
+// (or a timeout expires). This allows us to distinguish between "accepted by
+// the mempool" and "actually committed" throughput. If subscribe is set, it
+// instead subscribes to each transaction's commit via the WebSockets endpoint
+// to which it was submitted, only polling for it if that fails.
+type txConfirmer struct {
+	timeout   time.Duration
+	client    *http.Client
+	subscribe bool
+	logger    logging.Logger
+
+	unsubscribableMtx sync.Mutex
+	unsubscribable    map[string]bool // The endpoints that turned out not to support subscriptions.
+
+	queueMtx sync.RWMutex
+	queue    chan pendingTx
+	closed   bool
+	wg       sync.WaitGroup
+
+	abortOnce sync.Once
+	abort     chan struct{} // Closed to make the workers give up on outstanding samples.
+
+	statsMtx  sync.RWMutex
+	stats     ConfirmStats
+	heights   map[int64]bool  // The heights of the blocks in which sampled transactions were found.
+	latencies []time.Duration // The time from each successfully committed sample's submission until its commit was learned of.
+}
+
+func newTxConfirmer(timeout time.Duration, client *http.Client, subscribe bool, logger logging.Logger) *txConfirmer {
+	return &txConfirmer{
+		timeout:        timeout,
+		client:         client,
+		subscribe:      subscribe,
+		logger:         logger,
+		unsubscribable: make(map[string]bool),
+		queue:          make(chan pendingTx, confirmQueueSize),
+		abort:          make(chan struct{}),
+		heights:        make(map[int64]bool),
+	}
+}
+
+// Start kicks off the confirmation workers.
+func (c *txConfirmer) Start() {
+	c.wg.Add(confirmWorkers)
+	for i := 0; i < confirmWorkers; i++ {
+		go c.worker()
+	}
+}
+
+// Close stops accepting new samples and waits for all queued samples to be
+// resolved (each of which is bounded by the confirmation timeout).
+func (c *txConfirmer) Close() {
+	c.closeQueue()
+	c.wg.Wait()
+}
+
+// CloseBy is like Close, but gives up on any samples that are still
+// outstanding at the given deadline. Returns false if the deadline was hit.
+func (c *txConfirmer) CloseBy(deadline time.Time) bool {
+	c.closeQueue()
+	done := make(chan struct{})
+	go func() {
+		c.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(time.Until(deadline)):
+		c.abortOnce.Do(func() { close(c.abort) })
+		<-done
+		return false
+	}
+}
+
+func (c *txConfirmer) closeQueue() {
+	c.queueMtx.Lock()
+	if !c.closed {
+		c.closed = true
+		close(c.queue)
+	}
+	c.queueMtx.Unlock()
+}
+
+// Submit queues the given raw transaction, submitted to the given WebSockets
+// endpoint, for confirmation, returning whether it was queued (in which case
+// onResolved, if given, will eventually be called). If the queue is full, the
+// sample is dropped rather than blocking the sender.
+func (c *txConfirmer) Submit(tx []byte, endpoint, restURL string, onResult func(res *restTxResult), onResolved func()) bool {
+	c.queueMtx.RLock()
+	defer c.queueMtx.RUnlock()
+	if c.closed {
+		return false
+	}
+	ptx := pendingTx{
+		hash:        txHash(tx),
+		endpoint:    endpoint,
+		restURL:     restURL,
+		submittedAt: time.Now(),
+		onResult:    onResult,
+		onResolved:  onResolved,
+	}
+	select {
+	case c.queue <- ptx:
+		c.updateStats(func(s *ConfirmStats) { s.Sampled++ })
+		return true
+	default:
+		c.updateStats(func(s *ConfirmStats) { s.Dropped++ })
+		return false
+	}
+}
+
+// CommitLatencies returns the time from the submission of each sample that
+// was committed successfully until its commit was learned of. When polling,
+// this overestimates the actual latency by up to the poll interval.
+func (c *txConfirmer) CommitLatencies() []time.Duration {
+	c.statsMtx.RLock()
+	defer c.statsMtx.RUnlock()
+	return append([]time.Duration(nil), c.latencies...)
+}
+
+// Stats returns a snapshot of the confirmation statistics gathered so far.
+func (c *txConfirmer) Stats() ConfirmStats {
+	c.statsMtx.RLock()
+	defer c.statsMtx.RUnlock()
+	return c.stats
+}
+
+func (c *txConfirmer) updateStats(fn func(s *ConfirmStats)) {
+	c.statsMtx.Lock()
+	fn(&c.stats)
+	c.statsMtx.Unlock()
+}
+
+func (c *txConfirmer) worker() {
+	defer c.wg.Done()
+	var sub *txSubscriber
+	if c.subscribe {
+		sub = newTxSubscriber(c.client.Timeout)
+		defer sub.Close()
+	}
+	for ptx := range c.queue {
+		select {
+		case <-c.abort:
+			// just drain the queue without resolving the remaining samples
+			if ptx.onResolved != nil {
+				ptx.onResolved()
+			}
+			continue
+		default:
+		}
+		c.confirm(ptx, sub)
+	}
+}
+
+// confirm resolves the given sample, waiting for its commit via the given
+// subscriber if set, and otherwise (or if that fails) polling for it.
+func (c *txConfirmer) confirm(ptx pendingTx, sub *txSubscriber) {
+	if ptx.onResolved != nil {
+		defer ptx.onResolved()
+	}
+	deadline := ptx.submittedAt.Add(c.timeout)
+	if sub != nil {
+		if res := c.awaitTx(ptx, sub, deadline); res != nil {
+			c.resolve(ptx, res)
+			return
+		}
+		// poll for whatever time is left (at least once, in case the
+		// transaction was committed while the subscription was failing)
+	}
+	for {
+		res, found, err := c.queryTx(ptx)
+		if err != nil {
+			c.logger.Debug("Failed to query transaction status", "hash", ptx.hash, "err", err)
+		}
+		if found {
+			c.resolve(ptx, res)
+			return
+		}
+		if time.Now().After(deadline) {
+			c.updateStats(func(s *ConfirmStats) { s.Missing++ })
+			if ptx.onResult != nil {
+				ptx.onResult(nil)
+			}
+			return
+		}
+		select {
+		case <-c.abort:
+			return
+		case <-time.After(confirmPollInterval):
+		}
+	}
+}
+
+// awaitTx waits for the given sample's commit via a subscription, returning
+// its result, or nil if the subscription failed or the deadline passed.
+func (c *txConfirmer) awaitTx(ptx pendingTx, sub *txSubscriber, deadline time.Time) *restTxResult {
+	c.unsubscribableMtx.Lock()
+	unsubscribable := c.unsubscribable[ptx.endpoint]
+	c.unsubscribableMtx.Unlock()
+	if unsubscribable {
+		return nil
+	}
+	id, err := sub.Subscribe(ptx.endpoint, ptx.hash)
+	var unavailableErr *subscriptionUnavailableError
+	if errors.As(err, &unavailableErr) {
+		c.unsubscribableMtx.Lock()
+		if !c.unsubscribable[ptx.endpoint] {
+			c.unsubscribable[ptx.endpoint] = true
+			c.logger.Info("Unable to subscribe to transactions, falling back to polling the REST API", "endpoint", ptx.endpoint, "err", err)
+		}
+		c.unsubscribableMtx.Unlock()
+		return nil
+	}
+	if err != nil {
+		c.logger.Debug("Failed to subscribe to transaction", "hash", ptx.hash, "err", err)
+		return nil
+	}
+	// The transaction may have been committed before we subscribed
+	if res, found, _ := c.queryTx(ptx); found {
+		sub.unsubscribe(ptx.endpoint, ptx.hash)
+		return res
+	}
+	res, err := sub.Wait(ptx.endpoint, ptx.hash, id, deadline, c.abort)
+	if err != nil {
+		c.logger.Debug("Failed to wait for transaction via subscription", "hash", ptx.hash, "err", err)
+		return nil
+	}
+	return res
+}
+
+// resolve accounts for the given sample having been found in a block.
+func (c *txConfirmer) resolve(ptx pendingTx, res *restTxResult) {
+	c.recordHeight(res.Height)
+	if res.Code != 0 {
+		c.logger.Debug("Transaction failed in block", "hash", ptx.hash, "height", res.Height, "code", res.Code, "log", res.RawLog)
+		c.updateStats(func(s *ConfirmStats) { s.Failed++ })
+	} else {
+		latency := time.Since(ptx.submittedAt)
+		c.statsMtx.Lock()
+		c.stats.Committed++
+		c.latencies = append(c.latencies, latency)
+		c.statsMtx.Unlock()
+	}
+	if ptx.onResult != nil {
+		ptx.onResult(res)
+	}
+}
+
+// recordHeight accounts for a sampled transaction having been found in the
+// block at the given height.
+func (c *txConfirmer) recordHeight(heightStr string) {
+	height, err := strconv.ParseInt(heightStr, 10, 64)
+	if err != nil || height < 1 {
+		return
+	}
+	c.statsMtx.Lock()
+	defer c.statsMtx.Unlock()
+	if c.heights[height] {
+		return
+	}
+	c.heights[height] = true
+	c.stats.Blocks++
+	if c.stats.MinHeight == 0 || height < c.stats.MinHeight {
+		c.stats.MinHeight = height
+	}
+	if height > c.stats.MaxHeight {
+		c.stats.MaxHeight = height
+	}
+}
+
+// restTxResult is the subset of the Cosmos SDK REST API's
+// /cosmos/tx/v1beta1/txs/{hash} "tx_response" object that we care about.
+type restTxResult struct {
+	Height    string `json:"height"`
+	TxHash    string `json:"txhash"`
+	Code      int    `json:"code"`
+	Codespace string `json:"codespace"`
+	RawLog    string `json:"raw_log"`
+	GasWanted string `json:"gas_wanted"`
+	GasUsed   string `json:"gas_used"`
+}
+
+// queryTx looks the given transaction up via the REST API. It returns whether
+// the transaction was found in a block.
+func (c *txConfirmer) queryTx(ptx pendingTx) (*restTxResult, bool, error) {
+	resp, err := c.client.Get(fmt.Sprintf("%s/cosmos/tx/v1beta1/txs/%s", ptx.restURL, ptx.hash))
+	if err != nil {
+		return nil, false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		// not yet committed
+		return nil, false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, false, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	var data struct {
+		TxResponse restTxResult `json:"tx_response"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return nil, false, err
+	}
+	if data.TxResponse.Height == "" || data.TxResponse.Height == "0" {
+		return nil, false, nil
+	}
+	return &data.TxResponse, true, nil
+}
+
+// txHash computes the CometBFT transaction hash (upper-case hex SHA256) of the
+// given raw transaction bytes.
+func txHash(tx []byte) string {
+	h := sha256.Sum256(tx)
+	return strings.ToUpper(hex.EncodeToString(h[:]))
+}
+
+// restURLFromEndpoint derives the Cosmos SDK REST API URL from a CometBFT
+// WebSockets RPC endpoint, using the same port conventions as the PerpX
+// client and seeder (36657 -> 31317, 26657 -> 1317).
+func restURLFromEndpoint(endpoint string) string {
+	httpURL := rpcURLFromEndpoint(endpoint)
+	if strings.Contains(httpURL, ":36657") {
+		return strings.Replace(httpURL, ":36657", ":31317", 1)
+	}
+	if strings.Contains(httpURL, ":26657") {
+		return strings.Replace(httpURL, ":26657", ":1317", 1)
+	}
+	return "http://localhost:31317"
+}
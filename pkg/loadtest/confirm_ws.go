@@ -0,0 +1,218 @@
+package loadtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/1119-Labs/perpx-load-test/internal/httpclient"
+	"github.com/gorilla/websocket"
+)
+
+// The transports via which sampled transactions can be confirmed.
+const (
+	ConfirmTransportREST = "rest" // Poll the REST API for each sampled transaction.
+	ConfirmTransportWS   = "ws"   // Subscribe to each sampled transaction's Tx event, falling back to polling.
+)
+
+// txSubscriber waits for sampled transactions to be committed by subscribing
+// to their Tx events via the nodes' WebSockets RPC endpoints, so that a
+// transaction's commit is learned of as soon as its block is committed,
+// rather than on the next poll. Each confirmation worker has its own, which
+// keeps one connection per endpoint with at most one subscription at a time,
+// well within CometBFT's default limits on subscriptions per client (5) and
+// on subscribing clients (100). It is not safe for concurrent use.
+type txSubscriber struct {
+	timeout time.Duration // How long to wait to connect and subscribe.
+	conns   map[string]*websocket.Conn
+	nextID  int
+}
+
+// wsMessage is a JSON-RPC response (or event notification) received via a
+// subscriber's connection.
+type wsMessage struct {
+	ID     json.RawMessage `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Message string `json:"message"`
+		Data    string `json:"data"`
+	} `json:"error"`
+}
+
+// txEvent is the relevant part of a Tx event notification.
+type txEvent struct {
+	Data struct {
+		Value struct {
+			TxResult struct {
+				Height string `json:"height"`
+				Result struct {
+					Code      int    `json:"code"`
+					Codespace string `json:"codespace"`
+					Log       string `json:"log"`
+					GasWanted string `json:"gas_wanted"`
+					GasUsed   string `json:"gas_used"`
+				} `json:"result"`
+			} `json:"TxResult"`
+		} `json:"value"`
+	} `json:"data"`
+}
+
+// subscriptionUnavailableError is returned by txSubscriber.Subscribe when the
+// endpoint doesn't support subscriptions at all (e.g. it can't be connected
+// to, or rejects them), as opposed to a subscription failing part way.
+type subscriptionUnavailableError struct {
+	Err error
+}
+
+func (e *subscriptionUnavailableError) Error() string {
+	return fmt.Sprintf("transaction subscriptions unavailable: %v", e.Err)
+}
+
+func (e *subscriptionUnavailableError) Unwrap() error { return e.Err }
+
+func newTxSubscriber(timeout time.Duration) *txSubscriber {
+	if timeout <= 0 {
+		// unlike a request, a subscription has no other bound on how long it
+		// takes to be acknowledged
+		timeout = httpclient.DefaultTimeout
+	}
+	return &txSubscriber{timeout: timeout, conns: make(map[string]*websocket.Conn)}
+}
+
+// Subscribe subscribes to the commit of the transaction with the given hash
+// via the given WebSockets endpoint, connecting to it first if need be. It
+// returns the subscription's ID, with which to Wait for the transaction.
+func (s *txSubscriber) Subscribe(endpoint, hash string) (int, error) {
+	conn, ok := s.conns[endpoint]
+	if !ok {
+		dialer := &websocket.Dialer{HandshakeTimeout: s.timeout}
+		var err error
+		conn, _, err = dialer.Dial(endpoint, nil)
+		if err != nil {
+			return 0, &subscriptionUnavailableError{Err: err}
+		}
+		s.conns[endpoint] = conn
+	}
+	s.nextID++
+	id := s.nextID
+	deadline := time.Now().Add(s.timeout)
+	_ = conn.SetWriteDeadline(deadline)
+	err := conn.WriteJSON(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      id,
+		"method":  "subscribe",
+		"params":  map[string]string{"query": fmt.Sprintf("tm.event='Tx' AND tx.hash='%s'", hash)},
+	})
+	if err != nil {
+		s.drop(endpoint)
+		return 0, fmt.Errorf("failed to send subscription request: %w", err)
+	}
+	// Skip whatever is left of previous subscriptions until the new one is
+	// acknowledged (or rejected)
+	msg, err := s.read(endpoint, id, deadline)
+	if err != nil {
+		return 0, err
+	}
+	if msg.Error != nil {
+		s.drop(endpoint)
+		return 0, &subscriptionUnavailableError{Err: fmt.Errorf("%s (%s)", msg.Error.Message, msg.Error.Data)}
+	}
+	return id, nil
+}
+
+// Wait waits until the transaction to which the given subscription was
+// made is committed, or the given deadline passes or abort is closed, in
+// which case it returns an error. The subscription is ended either way.
+func (s *txSubscriber) Wait(endpoint, hash string, id int, deadline time.Time, abort <-chan struct{}) (*restTxResult, error) {
+	conn := s.conns[endpoint]
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-abort:
+			// unblock the read below
+			_ = conn.Close()
+		case <-done:
+		}
+	}()
+	for {
+		msg, err := s.read(endpoint, id, deadline)
+		if err != nil {
+			return nil, err
+		}
+		if msg.Error != nil {
+			s.drop(endpoint)
+			return nil, fmt.Errorf("subscription failed: %s (%s)", msg.Error.Message, msg.Error.Data)
+		}
+		var event txEvent
+		if err := json.Unmarshal(msg.Result, &event); err != nil {
+			s.drop(endpoint)
+			return nil, fmt.Errorf("failed to decode event: %w", err)
+		}
+		txResult := event.Data.Value.TxResult
+		if len(txResult.Height) == 0 {
+			// not a transaction event
+			continue
+		}
+		s.unsubscribe(endpoint, hash)
+		return &restTxResult{
+			Height:    txResult.Height,
+			TxHash:    hash,
+			Code:      txResult.Result.Code,
+			Codespace: txResult.Result.Codespace,
+			RawLog:    txResult.Result.Log,
+			GasWanted: txResult.Result.GasWanted,
+			GasUsed:   txResult.Result.GasUsed,
+		}, nil
+	}
+}
+
+// Close closes all of the subscriber's connections.
+func (s *txSubscriber) Close() {
+	for endpoint := range s.conns {
+		s.drop(endpoint)
+	}
+}
+
+// unsubscribe ends the subscription to the given transaction's commit,
+// without waiting for the node to acknowledge it (see Subscribe).
+func (s *txSubscriber) unsubscribe(endpoint, hash string) {
+	conn := s.conns[endpoint]
+	s.nextID++
+	_ = conn.SetWriteDeadline(time.Now().Add(s.timeout))
+	err := conn.WriteJSON(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      s.nextID,
+		"method":  "unsubscribe",
+		"params":  map[string]string{"query": fmt.Sprintf("tm.event='Tx' AND tx.hash='%s'", hash)},
+	})
+	if err != nil {
+		s.drop(endpoint)
+	}
+}
+
+// read reads messages from the given endpoint's connection until one with
+// the given ID arrives, or the given deadline passes. The connection is
+// dropped on failure, since it can't be read from after a timeout.
+func (s *txSubscriber) read(endpoint string, id int, deadline time.Time) (*wsMessage, error) {
+	conn := s.conns[endpoint]
+	_ = conn.SetReadDeadline(deadline)
+	for {
+		var msg wsMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			s.drop(endpoint)
+			return nil, err
+		}
+		if string(msg.ID) == strconv.Itoa(id) {
+			return &msg, nil
+		}
+	}
+}
+
+func (s *txSubscriber) drop(endpoint string) {
+	if conn, ok := s.conns[endpoint]; ok {
+		_ = conn.Close()
+		delete(s.conns, endpoint)
+	}
+}
@@ -0,0 +1,300 @@
+package loadtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/1119-Labs/perpx-load-test/internal/logging"
+	"github.com/gorilla/websocket"
+)
+
+// pooledConn is a WebSockets connection to a CometBFT RPC endpoint over which
+// one or more transactors send their broadcast requests. Each request is given
+// a unique JSON-RPC ID so that its response can be routed back to the
+// transactor that sent it.
+type pooledConn struct {
+	remoteAddr string        // The full URL of the remote WebSockets endpoint.
+	latency    time.Duration // How long the initial WebSockets handshake took.
+	logger     logging.Logger
+
+	mtx     sync.Mutex // Guards the fields below, and serializes writes to ws.
+	ws      *websocket.Conn
+	healthy bool                   // False once the connection has failed, until it is re-established.
+	started bool                   // Whether the receive loop for ws has been started.
+	done    chan struct{}          // Closed once the receive loop for ws exits.
+	nextID  int                    // The JSON-RPC ID of the last request sent.
+	pending map[int]pendingRequest // The requests awaiting responses, by ID.
+	users   []*Transactor          // The transactors currently sending over this connection.
+	closing bool                   // Set once the last user has released the connection.
+}
+
+// pendingRequest is a broadcast request awaiting its response.
+type pendingRequest struct {
+	t        *Transactor
+	info     *TxInfo   // The transaction broadcast, if its result is being observed.
+	injected bool      // Whether the transaction was deliberately made invalid (see Config.InjectFailures).
+	sentAt   time.Time // When the request was written.
+}
+
+// dialPooledConn connects to the given (already validated) WebSockets URL.
+// Failures are returned as a *DialError.
+func dialPooledConn(remoteAddr string) (*pooledConn, error) {
+	ws, latency, err := dialWebSocket(remoteAddr)
+	if err != nil {
+		return nil, err
+	}
+	logger := logging.NewLogrusLogger(fmt.Sprintf("conn[%s]", remoteAddr))
+	logger.Info("Connected to remote CometBFT WebSockets RPC", "handshake", latency.String())
+	setPingHandler(ws)
+	return &pooledConn{
+		remoteAddr: remoteAddr,
+		latency:    latency,
+		logger:     logger,
+		ws:         ws,
+		healthy:    true,
+		done:       make(chan struct{}),
+		pending:    make(map[int]pendingRequest),
+	}, nil
+}
+
+// attach registers the given transactor as a user of the connection.
+func (c *pooledConn) attach(t *Transactor) {
+	c.mtx.Lock()
+	c.users = append(c.users, t)
+	c.mtx.Unlock()
+}
+
+// start launches the receive loop, if it isn't already running.
+func (c *pooledConn) start() {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if !c.started {
+		c.started = true
+		go c.receiveLoop(c.ws, c.done)
+	}
+}
+
+// writeTx broadcasts the given transaction on behalf of t. If info is set, it
+// is handed back to t along with the response. Responses to injected
+// transactions are handled separately (see Transactor.handleInjectedResponse).
+func (c *pooledConn) writeTx(t *Transactor, info *TxInfo, injected bool, method string, params json.RawMessage) error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.nextID++
+	_ = c.ws.SetWriteDeadline(time.Now().Add(connSendTimeout))
+	if err := c.ws.WriteJSON(RPCRequest{
+		JSONRPC: "2.0",
+		ID:      c.nextID,
+		Method:  method,
+		Params:  params,
+	}); err != nil {
+		return err
+	}
+	// the response can't be dispatched before we release the lock
+	c.pending[c.nextID] = pendingRequest{t: t, info: info, injected: injected, sentAt: time.Now()}
+	atomic.AddInt64(&t.inFlight, 1)
+	return nil
+}
+
+func (c *pooledConn) ping() error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	_ = c.ws.SetWriteDeadline(time.Now().Add(connSendTimeout))
+	return c.ws.WriteMessage(websocket.PingMessage, []byte{})
+}
+
+func (c *pooledConn) receiveLoop(ws *websocket.Conn, done chan struct{}) {
+	defer close(done)
+	for {
+		_, msg, err := ws.ReadMessage()
+		if err != nil {
+			if !websocket.IsCloseError(err, websocket.CloseNormalClosure) && !c.isClosing() {
+				c.logger.Error("Failed to read response on connection", "err", err)
+				c.lost(ws, err)
+			}
+			return
+		}
+		c.dispatch(msg)
+	}
+}
+
+// dispatch hands a JSON-RPC response to the transactor whose request it
+// answers.
+func (c *pooledConn) dispatch(msg []byte) {
+	res := &RPCResponse{}
+	if err := json.Unmarshal(msg, res); err != nil {
+		c.logger.Debug("Failed to decode broadcast_tx response", "err", err)
+		return
+	}
+	c.mtx.Lock()
+	req, ok := c.pending[res.ID]
+	delete(c.pending, res.ID)
+	c.mtx.Unlock()
+	if !ok {
+		c.logger.Debug("Received response to unknown request", "id", res.ID)
+		return
+	}
+	atomic.AddInt64(&req.t.inFlight, -1)
+	req.t.trackBroadcastLatency(time.Since(req.sentAt))
+	if req.injected {
+		req.t.handleInjectedResponse(res)
+		return
+	}
+	req.t.handleResponse(res, req.info)
+}
+
+func (c *pooledConn) isClosing() bool {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return c.closing
+}
+
+// lost notifies all of the connection's users that the given connection has
+// failed with the given error, unless it has already been replaced.
+func (c *pooledConn) lost(ws *websocket.Conn, err error) {
+	c.mtx.Lock()
+	if c.ws != ws {
+		c.mtx.Unlock()
+		return
+	}
+	c.healthy = false
+	users := append([]*Transactor(nil), c.users...)
+	c.mtx.Unlock()
+	for _, t := range users {
+		t.connLost(err)
+	}
+}
+
+// fail closes the connection after a failed write, which also unblocks the
+// receive loop so that the connection's other users find out.
+func (c *pooledConn) fail() {
+	c.mtx.Lock()
+	c.healthy = false
+	_ = c.ws.Close()
+	c.mtx.Unlock()
+}
+
+// reconnect re-establishes the connection after it has failed. If another user
+// has already done so, it returns immediately.
+func (c *pooledConn) reconnect() error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if c.healthy {
+		return nil
+	}
+	_ = c.ws.Close()
+	ws, _, err := dialWebSocket(c.remoteAddr)
+	if err != nil {
+		return err
+	}
+	setPingHandler(ws)
+	// responses to requests sent over the old connection will never arrive
+	c.pending = make(map[int]pendingRequest)
+	c.ws = ws
+	c.healthy = true
+	c.done = make(chan struct{})
+	c.started = true
+	go c.receiveLoop(ws, c.done)
+	return nil
+}
+
+// release unregisters the given transactor as a user of the connection. Once
+// the last user has released it, the connection is shut down, waiting for the
+// remote endpoint to acknowledge (and thus for any outstanding responses to be
+// handled) if the connection is healthy.
+func (c *pooledConn) release(t *Transactor) {
+	c.mtx.Lock()
+	for i, u := range c.users {
+		if u == t {
+			c.users = append(c.users[:i], c.users[i+1:]...)
+			break
+		}
+	}
+	if len(c.users) > 0 || c.closing {
+		c.mtx.Unlock()
+		return
+	}
+	c.closing = true
+	healthy, started, done := c.healthy, c.started, c.done
+	c.mtx.Unlock()
+	c.close(healthy, started, done)
+}
+
+func (c *pooledConn) close(healthy, started bool, done chan struct{}) {
+	if !healthy {
+		_ = c.ws.Close()
+		return
+	}
+	// try to cleanly shut down the connection
+	c.mtx.Lock()
+	_ = c.ws.SetWriteDeadline(time.Now().Add(connSendTimeout))
+	err := c.ws.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	c.mtx.Unlock()
+	if err != nil {
+		c.logger.Error("Failed to write close message", "err", err)
+		_ = c.ws.Close()
+		return
+	}
+	c.logger.Debug("Wrote close message to remote endpoint")
+	if !started {
+		_ = c.ws.Close()
+		return
+	}
+	select {
+	case <-done:
+	case <-time.After(connSendTimeout):
+		c.logger.Error("Timed out waiting for remote endpoint to close the connection")
+	}
+	_ = c.ws.Close()
+}
+
+// connPool hands out connections to a single endpoint, dialing up to size
+// connections and then sharing them between transactors in round-robin
+// fashion. A size of 0 gives every transactor a connection of its own.
+type connPool struct {
+	remoteAddr string
+	size       int
+	conns      []*pooledConn
+	next       int
+}
+
+func newConnPool(remoteAddr string, size int) (*connPool, error) {
+	u, err := validateWebSocketURL(remoteAddr)
+	if err != nil {
+		return nil, err
+	}
+	return &connPool{remoteAddr: u.String(), size: size}, nil
+}
+
+// get returns the connection that the next transactor should use, and whether
+// it had to be dialed (in which case err may hold a *DialError).
+func (p *connPool) get() (conn *pooledConn, dialed bool, err error) {
+	if p.size > 0 && len(p.conns) >= p.size {
+		conn = p.conns[p.next%len(p.conns)]
+		p.next++
+		return conn, false, nil
+	}
+	conn, err = dialPooledConn(p.remoteAddr)
+	if err != nil {
+		return nil, true, err
+	}
+	p.conns = append(p.conns, conn)
+	return conn, true, nil
+}
+
+// close shuts down any of the pool's connections that no transactor is using
+// (e.g. because the transactor could not be created).
+func (p *connPool) close() {
+	for _, c := range p.conns {
+		c.mtx.Lock()
+		unused := len(c.users) == 0 && !c.closing
+		c.closing = c.closing || unused
+		c.mtx.Unlock()
+		if unused {
+			_ = c.ws.Close()
+		}
+	}
+}
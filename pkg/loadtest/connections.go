@@ -0,0 +1,125 @@
+package loadtest
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"syscall"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// The kinds of failure we distinguish when connecting to an endpoint.
+const (
+	DialFailureDNS       = "dns"       // The endpoint's host name could not be resolved.
+	DialFailureRefused   = "refused"   // Nothing is listening on the endpoint's port.
+	DialFailureTimeout   = "timeout"   // The TCP connection or WebSockets handshake timed out.
+	DialFailureTLS       = "tls"       // The TLS handshake or certificate verification failed.
+	DialFailureHandshake = "handshake" // The server rejected the WebSockets upgrade (e.g. wrong path).
+	DialFailureOther     = "other"
+)
+
+// DialError is returned when we fail to connect to a remote WebSockets
+// endpoint.
+type DialError struct {
+	Endpoint string
+	Kind     string        // One of the DialFailure* constants.
+	Latency  time.Duration // How long the failed attempt took.
+	Err      error
+}
+
+func (e *DialError) Error() string {
+	return fmt.Sprintf("failed to connect to %s (%s): %v", e.Endpoint, e.Kind, e.Err)
+}
+
+func (e *DialError) Unwrap() error { return e.Err }
+
+// classifyDialError determines the kind of failure (see the DialFailure*
+// constants) from the error returned when dialing an endpoint.
+func classifyDialError(err error) string {
+	var (
+		dnsErr       *net.DNSError
+		recordErr    tls.RecordHeaderError
+		verifyErr    *tls.CertificateVerificationError
+		authorityErr x509.UnknownAuthorityError
+		hostnameErr  x509.HostnameError
+		invalidErr   x509.CertificateInvalidError
+		netErr       net.Error
+	)
+	switch {
+	case errors.As(err, &dnsErr):
+		return DialFailureDNS
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return DialFailureRefused
+	case errors.As(err, &recordErr), errors.As(err, &verifyErr), errors.As(err, &authorityErr),
+		errors.As(err, &hostnameErr), errors.As(err, &invalidErr):
+		return DialFailureTLS
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return DialFailureTimeout
+	case errors.Is(err, websocket.ErrBadHandshake):
+		return DialFailureHandshake
+	}
+	return DialFailureOther
+}
+
+// ConnectionResult describes the outcome of a single attempt to connect to an
+// endpoint.
+type ConnectionResult struct {
+	Endpoint string
+	Latency  time.Duration // The WebSockets handshake latency, or how long the failed attempt took.
+	Err      *DialError    // Nil if the connection succeeded.
+}
+
+// WriteConnectionSummary writes a table summarizing, per endpoint, how many
+// connections succeeded, their average handshake latency and the first
+// failure (if any).
+func WriteConnectionSummary(w io.Writer, results []ConnectionResult) {
+	type endpointSummary struct {
+		attempts  int
+		connected int
+		latency   time.Duration // The total latency of the successful connections.
+		failure   *DialError
+	}
+	var endpoints []string
+	summaries := make(map[string]*endpointSummary)
+	for _, r := range results {
+		s, ok := summaries[r.Endpoint]
+		if !ok {
+			s = &endpointSummary{}
+			summaries[r.Endpoint] = s
+			endpoints = append(endpoints, r.Endpoint)
+		}
+		s.attempts++
+		if r.Err != nil {
+			if s.failure == nil {
+				s.failure = r.Err
+			}
+			continue
+		}
+		s.connected++
+		s.latency += r.Latency
+	}
+
+	fmt.Fprintf(w, "%-44s %-10s %-10s %s\n", "ENDPOINT", "CONNECTED", "HANDSHAKE", "ERROR")
+	for _, endpoint := range endpoints {
+		s := summaries[endpoint]
+		handshake := "-"
+		if s.connected > 0 {
+			handshake = (s.latency / time.Duration(s.connected)).Round(time.Millisecond).String()
+		}
+		failure := ""
+		if s.failure != nil {
+			failure = fmt.Sprintf("%s: %v", s.failure.Kind, s.failure.Err)
+		}
+		fmt.Fprintf(w, "%-44s %-10s %-10s %s\n",
+			trimForTable(endpoint, 44),
+			fmt.Sprintf("%d/%d", s.connected, s.attempts),
+			handshake,
+			failure,
+		)
+	}
+}
@@ -0,0 +1,142 @@
+package loadtest_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/1119-Labs/perpx-load-test/pkg/loadtest"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddAllReportsConnectionFailures(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	good := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	defer good.Close()
+	notFound := httptest.NewServer(http.NotFoundHandler())
+	defer notFound.Close()
+
+	// grab a free port and release it so that nothing is listening on it
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	refusedAddr := l.Addr().String()
+	require.NoError(t, l.Close())
+
+	goodEndpoint := "ws://" + strings.TrimPrefix(good.URL, "http://") + "/websocket"
+	notFoundEndpoint := "ws://" + strings.TrimPrefix(notFound.URL, "http://") + "/websocket"
+	refusedEndpoint := "ws://" + refusedAddr + "/websocket"
+
+	cfg := loadtest.Config{
+		ClientFactory:     "kvstore",
+		Connections:       2,
+		Rate:              1,
+		Size:              40,
+		BroadcastTxMethod: "async",
+		Endpoints:         []string{goodEndpoint, refusedEndpoint, notFoundEndpoint},
+	}
+	tg := loadtest.NewTransactorGroup()
+	err = tg.AddAll(&cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to connect to 2 of 3 endpoint(s)")
+
+	results := tg.ConnectionResults()
+	require.Len(t, results, 6)
+	kinds := make(map[string][]string)
+	for _, r := range results {
+		if r.Err == nil {
+			kinds[r.Endpoint] = append(kinds[r.Endpoint], "ok")
+			continue
+		}
+		kinds[r.Endpoint] = append(kinds[r.Endpoint], r.Err.Kind)
+	}
+	assert.Equal(t, []string{"ok", "ok"}, kinds[goodEndpoint])
+	assert.Equal(t, []string{loadtest.DialFailureRefused, loadtest.DialFailureRefused}, kinds[refusedEndpoint])
+	assert.Equal(t, []string{loadtest.DialFailureHandshake, loadtest.DialFailureHandshake}, kinds[notFoundEndpoint])
+}
+
+func TestConnectionPoolSharesConnections(t *testing.T) {
+	var dials int32
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		atomic.AddInt32(&dials, 1)
+		for {
+			var req loadtest.RPCRequest
+			if err := conn.ReadJSON(&req); err != nil {
+				return
+			}
+			// reject every transaction, so that each response shows up in
+			// the error count of the transactor it was routed to
+			if err := conn.WriteJSON(loadtest.RPCResponse{
+				JSONRPC: "2.0",
+				ID:      req.ID,
+				Result:  json.RawMessage(`{"code":5,"codespace":"sdk"}`),
+			}); err != nil {
+				return
+			}
+		}
+	}))
+	defer server.Close()
+
+	cfg := loadtest.Config{
+		ClientFactory:      "kvstore",
+		Connections:        6,
+		ConnectionPoolSize: 2,
+		Rate:               5,
+		Size:               40,
+		Count:              30,
+		SendPeriod:         1,
+		BroadcastTxMethod:  "async",
+		Endpoints:          []string{"ws://" + strings.TrimPrefix(server.URL, "http://") + "/websocket"},
+	}
+	tg := loadtest.NewTransactorGroup()
+	require.NoError(t, tg.AddAll(&cfg))
+	require.Len(t, tg.ConnectionResults(), 2)
+	tg.Start()
+	require.NoError(t, tg.Wait())
+
+	assert.Equal(t, int32(2), atomic.LoadInt32(&dials))
+	assert.Equal(t, 30, tg.TxErrors())
+}
+
+func TestWriteConnectionSummary(t *testing.T) {
+	dialErr := &loadtest.DialError{
+		Endpoint: "ws://node2:36657/websocket",
+		Kind:     loadtest.DialFailureDNS,
+		Err:      &net.DNSError{Err: "no such host", Name: "node2"},
+	}
+	var buf bytes.Buffer
+	loadtest.WriteConnectionSummary(&buf, []loadtest.ConnectionResult{
+		{Endpoint: "ws://node1:36657/websocket", Latency: 10 * time.Millisecond},
+		{Endpoint: "ws://node2:36657/websocket", Err: dialErr},
+		{Endpoint: "ws://node1:36657/websocket", Latency: 20 * time.Millisecond},
+	})
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 3)
+	assert.Regexp(t, `^ENDPOINT\s+CONNECTED\s+HANDSHAKE\s+ERROR$`, lines[0])
+	assert.Regexp(t, `^ws://node1:36657/websocket\s+2/2\s+15ms\s*$`, lines[1])
+	assert.Regexp(t, `^ws://node2:36657/websocket\s+0/1\s+-\s+dns: lookup node2: no such host$`, lines[2])
+}
@@ -267,41 +267,24 @@ func (c *Coordinator) receiveTestingUpdates() error {
 	for {
 		select {
 		case msg := <-c.workerUpdate:
-			c.logger.Debug("Got update from worker", "msg", msg)
-			if _, exists := c.workers[msg.ID]; !exists {
-				c.logger.Error("Got message from unregistered worker - ignoring", "id", msg.ID)
-				continue
-			}
-			// keep track of how many transactions this worker has reported
-			if msg.TxCount > 0 {
-				c.totalTxsPerWorker[msg.ID] = msg.TxCount
-			}
-			// keep track of how many bytes this worker reported
-			if msg.TotalTxBytes > 0 {
-				c.totalBytesPerWorker[msg.ID] = msg.TotalTxBytes
+			if done, err := c.handleWorkerUpdate(msg, &completed); done || err != nil {
+				return err
 			}
 
-			switch msg.State {
-			case workerTesting:
-				c.logger.Debug("Update from remote worker", "id", msg.ID, "txCount", msg.TxCount)
-
-			case workerCompleted:
-				c.logger.Debug("Worker completed its testing", "id", msg.ID)
-				completed++
-				if completed >= c.coordCfg.ExpectWorkers {
-					c.logger.Info("All workers completed their load testing")
-					c.logTestingProgress(completed)
-					return nil
+		case req := <-c.workerUnregister:
+			// a worker sends its last updates (e.g. that it has completed)
+			// before unregistering, so they must be handled while it is
+			// still registered
+			for pending := true; pending; {
+				select {
+				case msg := <-c.workerUpdate:
+					if done, err := c.handleWorkerUpdate(msg, &completed); done || err != nil {
+						return err
+					}
+				default:
+					pending = false
 				}
-
-			case workerFailed:
-				return errors.New(msg.Error)
-
-			default:
-				return fmt.Errorf("unexpected state from remote worker: %s", msg.State)
 			}
-
-		case req := <-c.workerUnregister:
 			c.unregisterRemoteWorker(req.id)
 			if req.err != nil {
 				return fmt.Errorf("remote worker failed: %s", req.err.Error())
@@ -320,6 +303,46 @@ func (c *Coordinator) receiveTestingUpdates() error {
 	}
 }
 
+// handleWorkerUpdate processes an update from a worker during testing,
+// counting the workers that have completed. It returns true once all of the
+// expected workers have completed, and an error if a worker failed.
+func (c *Coordinator) handleWorkerUpdate(msg workerMsg, completed *int) (bool, error) {
+	c.logger.Debug("Got update from worker", "msg", msg)
+	if _, exists := c.workers[msg.ID]; !exists {
+		c.logger.Error("Got message from unregistered worker - ignoring", "id", msg.ID)
+		return false, nil
+	}
+	// keep track of how many transactions this worker has reported
+	if msg.TxCount > 0 {
+		c.totalTxsPerWorker[msg.ID] = msg.TxCount
+	}
+	// keep track of how many bytes this worker reported
+	if msg.TotalTxBytes > 0 {
+		c.totalBytesPerWorker[msg.ID] = msg.TotalTxBytes
+	}
+
+	switch msg.State {
+	case workerTesting:
+		c.logger.Debug("Update from remote worker", "id", msg.ID, "txCount", msg.TxCount)
+
+	case workerCompleted:
+		c.logger.Debug("Worker completed its testing", "id", msg.ID)
+		*completed++
+		if *completed >= c.coordCfg.ExpectWorkers {
+			c.logger.Info("All workers completed their load testing")
+			c.logTestingProgress(*completed)
+			return true, nil
+		}
+
+	case workerFailed:
+		return false, errors.New(msg.Error)
+
+	default:
+		return false, fmt.Errorf("unexpected state from remote worker: %s", msg.State)
+	}
+	return false, nil
+}
+
 func (c *Coordinator) RegisterRemoteWorker(rw *remoteWorker) error {
 	c.logger.Debug("Attempting to register remote worker")
 	resp := make(chan error, 1)
@@ -345,6 +368,7 @@ func (c *Coordinator) registerRemoteWorker(rw *remoteWorker) error {
 	if _, exists := c.workers[id]; exists {
 		return fmt.Errorf("worker with ID %s already exists", id)
 	}
+	rw.setCount(c.countShare())
 	c.workers[id] = rw
 	c.totalTxsPerWorker[id] = 0
 	c.totalBytesPerWorker[id] = 0
@@ -352,6 +376,24 @@ func (c *Coordinator) registerRemoteWorker(rw *remoteWorker) error {
 	return nil
 }
 
+// countShare returns the share of the total transaction count to allot to the
+// next worker to register (-1 if unlimited). What the registered workers
+// haven't been allotted is split evenly between the workers still expected,
+// with any remainder going to the first of them, so that the shares add up to
+// the total once all of them have registered, even if some workers
+// unregistered in the meantime.
+func (c *Coordinator) countShare() int {
+	if c.cfg.Count < 0 {
+		return -1
+	}
+	remaining := c.cfg.Count
+	for _, rw := range c.workers {
+		remaining -= rw.getCount()
+	}
+	expected := c.coordCfg.ExpectWorkers - len(c.workers)
+	return (remaining + expected - 1) / expected
+}
+
 func (c *Coordinator) UnregisterRemoteWorker(id string, err error) {
 	c.workerUnregister <- remoteWorkerUnregisterRequest{id: id, err: err}
 }
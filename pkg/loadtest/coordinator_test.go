@@ -0,0 +1,70 @@
+package loadtest_test
+
+import (
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/1119-Labs/perpx-load-test/pkg/loadtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCoordinatorSplitsCount(t *testing.T) {
+	l, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	bindAddr := l.Addr().String()
+	require.NoError(t, l.Close())
+
+	cfg := baseConfig("kvstore", silentServer(t), silentServer(t))
+	// what the base configuration leaves to the command line's defaults
+	cfg.Count, cfg.Time, cfg.MsgsPerTx, cfg.HTTPTimeout = 25, 0, 1, 10
+	cfg.EndpointSelectMethod = loadtest.SelectSuppliedEndpoints
+	cfg.NoTrapInterrupts = true
+	require.NoError(t, cfg.Validate())
+	coord := loadtest.NewCoordinator(&cfg, &loadtest.CoordinatorConfig{
+		BindAddr:             bindAddr,
+		ExpectWorkers:        2,
+		WorkerConnectTimeout: 10,
+	})
+	coordErr := make(chan error, 1)
+	go func() { coordErr <- coord.Run() }()
+
+	observers := make([]*recordingObserver, 2)
+	workerErrs := make(chan error, len(observers))
+	for i := range observers {
+		observers[i] = &recordingObserver{submitted: make(map[string]bool)}
+		worker, err := loadtest.NewWorker(&loadtest.WorkerConfig{
+			ID:                  fmt.Sprintf("worker%d", i),
+			CoordAddr:           "ws://" + bindAddr,
+			CoordConnectTimeout: 10,
+			ResultObserver:      observers[i],
+		})
+		require.NoError(t, err)
+		go func() { workerErrs <- worker.Run() }()
+	}
+	// a hung coordinator or worker fails the test rather than the package
+	wait := func(errs <-chan error) error {
+		select {
+		case err := <-errs:
+			return err
+		case <-time.After(30 * time.Second):
+			return fmt.Errorf("timed out waiting for the load test to complete")
+		}
+	}
+	for range observers {
+		require.NoError(t, wait(workerErrs))
+	}
+	require.NoError(t, wait(coordErr))
+
+	// each worker sends its share over its four connections, and the shares
+	// add up to the total
+	var counts []int
+	for _, o := range observers {
+		o.mtx.Lock()
+		counts = append(counts, len(o.submitted))
+		o.mtx.Unlock()
+	}
+	assert.ElementsMatch(t, []int{13, 12}, counts)
+}
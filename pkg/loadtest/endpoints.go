@@ -0,0 +1,138 @@
+package loadtest
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// NormalizeEndpoint converts an endpoint given as a WebSockets URL, an HTTP
+// RPC URL or a bare "host:port" into the WebSockets URL to which we connect.
+// HTTP(S) URLs are converted to WS(S) URLs, and the "/websocket" path is
+// added if no path was given.
+func NormalizeEndpoint(raw string) (string, error) {
+	endpoint := strings.TrimSpace(raw)
+	switch {
+	case strings.HasPrefix(endpoint, "http://"):
+		endpoint = "ws://" + strings.TrimPrefix(endpoint, "http://")
+	case strings.HasPrefix(endpoint, "https://"):
+		endpoint = "wss://" + strings.TrimPrefix(endpoint, "https://")
+	case !strings.Contains(endpoint, "://"):
+		endpoint = "ws://" + endpoint
+	}
+	u, err := validateWebSocketURL(endpoint)
+	if err != nil {
+		return "", err
+	}
+	if len(u.Path) == 0 || u.Path == "/" {
+		u.Path = "/websocket"
+	}
+	return u.String(), nil
+}
+
+// ReadEndpoints reads endpoints from the given reader, one per line, in any
+// of the forms accepted by NormalizeEndpoint. Blank lines and lines starting
+// with "#" are ignored.
+func ReadEndpoints(r io.Reader) ([]string, error) {
+	var endpoints []string
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if len(text) == 0 || strings.HasPrefix(text, "#") {
+			continue
+		}
+		endpoint, err := NormalizeEndpoint(text)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return endpoints, nil
+}
+
+// ReadEndpointsFile reads endpoints from the given file (see ReadEndpoints).
+func ReadEndpointsFile(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open endpoints file: %w", err)
+	}
+	defer f.Close()
+	endpoints, err := ReadEndpoints(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read endpoints file %s: %w", path, err)
+	}
+	return endpoints, nil
+}
+
+// LookupSRVEndpoints expands a DNS SRV name (e.g.
+// "_cometbft._tcp.nodes.example.com") into the WebSockets endpoints of the
+// targets it lists, in the order of their priority and weight. The name may be
+// prefixed with "ws://" (the default) or "wss://" to select the scheme.
+func LookupSRVEndpoints(name string) ([]string, error) {
+	scheme := "ws"
+	name = strings.TrimSpace(name)
+	if strings.HasPrefix(name, "wss://") {
+		scheme = "wss"
+	}
+	name = strings.TrimPrefix(strings.TrimPrefix(name, "wss://"), "ws://")
+	_, records, err := net.LookupSRV("", "", name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up SRV records for %s: %w", name, err)
+	}
+	endpoints := make([]string, 0, len(records))
+	for _, record := range records {
+		host := strings.TrimSuffix(record.Target, ".")
+		endpoint, err := NormalizeEndpoint(fmt.Sprintf("%s://%s", scheme, net.JoinHostPort(host, strconv.Itoa(int(record.Port)))))
+		if err != nil {
+			return nil, fmt.Errorf("invalid SRV record for %s: %w", name, err)
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+	if len(endpoints) == 0 {
+		return nil, fmt.Errorf("no SRV records found for %s", name)
+	}
+	return endpoints, nil
+}
+
+// ResolveEndpoints combines the endpoints given directly with those from the
+// endpoints file and DNS SRV lookup (if configured), normalizing each of them
+// (see NormalizeEndpoint) and removing duplicates while preserving order.
+func (c *Config) ResolveEndpoints() error {
+	endpoints := append([]string{}, c.Endpoints...)
+	if len(c.EndpointsFile) > 0 {
+		fromFile, err := ReadEndpointsFile(c.EndpointsFile)
+		if err != nil {
+			return err
+		}
+		endpoints = append(endpoints, fromFile...)
+	}
+	if len(c.EndpointsSRV) > 0 {
+		fromSRV, err := LookupSRVEndpoints(c.EndpointsSRV)
+		if err != nil {
+			return err
+		}
+		endpoints = append(endpoints, fromSRV...)
+	}
+
+	resolved := make([]string, 0, len(endpoints))
+	seen := make(map[string]bool)
+	for _, raw := range endpoints {
+		endpoint, err := NormalizeEndpoint(raw)
+		if err != nil {
+			return err
+		}
+		if !seen[endpoint] {
+			seen[endpoint] = true
+			resolved = append(resolved, endpoint)
+		}
+	}
+	c.Endpoints = resolved
+	return nil
+}
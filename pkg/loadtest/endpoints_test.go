@@ -0,0 +1,65 @@
+package loadtest_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/1119-Labs/perpx-load-test/pkg/loadtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeEndpoint(t *testing.T) {
+	testCases := []struct {
+		raw      string
+		expected string
+		err      bool
+	}{
+		{"ws://node1:36657/websocket", "ws://node1:36657/websocket", false},
+		{" wss://node1/custom ", "wss://node1/custom", false},
+		{"http://node1:26657", "ws://node1:26657/websocket", false},
+		{"https://node1:443/", "wss://node1:443/websocket", false},
+		{"node1:36657", "ws://node1:36657/websocket", false},
+		{"tcp://node1:26657", "", true},
+		{"ws://", "", true},
+	}
+	for _, tc := range testCases {
+		endpoint, err := loadtest.NormalizeEndpoint(tc.raw)
+		if tc.err {
+			assert.Error(t, err, tc.raw)
+			continue
+		}
+		require.NoError(t, err, tc.raw)
+		assert.Equal(t, tc.expected, endpoint, tc.raw)
+	}
+}
+
+func TestReadEndpoints(t *testing.T) {
+	endpoints, err := loadtest.ReadEndpoints(strings.NewReader("# sentries\nnode1:36657\n\n  http://node2:36657  \n"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ws://node1:36657/websocket", "ws://node2:36657/websocket"}, endpoints)
+
+	_, err = loadtest.ReadEndpoints(strings.NewReader("node1:36657\nftp://node2\n"))
+	assert.ErrorContains(t, err, "line 2")
+}
+
+func TestResolveEndpoints(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nodes.txt")
+	require.NoError(t, os.WriteFile(path, []byte("ws://node2:36657/websocket\nnode1:36657\nnode3:36657\n"), 0o600))
+
+	cfg := loadtest.Config{
+		Endpoints:     []string{"ws://node1:36657/websocket", "http://node2:36657"},
+		EndpointsFile: path,
+	}
+	require.NoError(t, cfg.ResolveEndpoints())
+	assert.Equal(t, []string{
+		"ws://node1:36657/websocket",
+		"ws://node2:36657/websocket",
+		"ws://node3:36657/websocket",
+	}, cfg.Endpoints)
+
+	cfg.EndpointsFile = filepath.Join(t.TempDir(), "missing.txt")
+	assert.Error(t, cfg.ResolveEndpoints())
+}
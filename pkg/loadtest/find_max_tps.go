@@ -0,0 +1,363 @@
+package loadtest
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/1119-Labs/perpx-load-test/internal/logging"
+)
+
+const (
+	// The target TPS of the first probe, if --target-tps isn't set.
+	findMaxTPSDefaultStart = 100
+	// The search ends once the highest sustainable and lowest unsustainable
+	// rates are within this fraction of the latter of each other.
+	findMaxTPSResolution = 0.05
+	// The most probes a search runs. If the rate can still be doubled after
+	// this many, the maximum found is only a lower bound.
+	findMaxTPSMaxProbes = 20
+	// The fraction of its target TPS that a probe must achieve (in accepted
+	// transactions) for its rate to be considered sustainable. Below this,
+	// the TPS controller couldn't make up for rejections or for connections
+	// that can't keep up.
+	findMaxTPSMinAchieved = 0.9
+	// How long to wait between probes, so that the transactions left in the
+	// mempool by one probe don't count against the next.
+	findMaxTPSCooldown = 10 * time.Second
+)
+
+// errSearchInterrupted is returned by a probe if the search was interrupted
+// (e.g. by Ctrl+C) before or while it ran.
+var errSearchInterrupted = errors.New("search interrupted")
+
+// TPSProbe is the outcome of one of the short load tests run at increasing
+// target TPS values while searching for the maximum sustainable TPS.
+type TPSProbe struct {
+	TargetTPS         int     `json:"target_tps"`             // The total number of accepted transactions per second the probe aimed for.
+	AchievedTPS       float64 `json:"achieved_tps"`           // The number of transactions per second accepted by the endpoints (excluding the warmup period).
+	Resolved          int     `json:"resolved"`               // The number of sampled transactions whose outcome is known.
+	CommitSuccessRate float64 `json:"commit_success_rate"`    // The fraction of resolved samples that were committed successfully.
+	P90LatencyMs      float64 `json:"p90_latency_ms"`         // The 90th percentile time from the submission of a successfully committed sample until its commit was learned of.
+	Sustainable       bool    `json:"sustainable"`            // Whether the chain kept up with the probe's rate.
+	Reason            string  `json:"reason,omitempty"`       // Why the probe's rate wasn't sustainable.
+	StopReason        string  `json:"stop_reason,omitempty"`  // Why the probe stopped, if not because its time was up (one of the StopReason* constants).
+	DurationSeconds   float64 `json:"duration_seconds"`       // The measured duration of the probe (excluding the warmup period).
+	Committed         int     `json:"committed"`              // Sampled transactions committed successfully.
+	Failed            int     `json:"failed"`                 // Sampled transactions committed with a non-zero result code.
+	Missing           int     `json:"missing"`                // Sampled transactions not found in a block before the confirmation timeout.
+	MempoolFull       int     `json:"mempool_full,omitempty"` // The number of broadcast requests rejected because the mempool was full.
+	TxErrors          int     `json:"tx_errors,omitempty"`    // The number of broadcast requests that were rejected.
+	TPSMultiplier     float64 `json:"tps_multiplier"`         // The multiplier the TPS controller applied to the configured rate when the probe stopped.
+}
+
+// MaxTPSReport summarizes a search for the maximum sustainable TPS (see
+// ExecuteFindMaxTPS).
+type MaxTPSReport struct {
+	Version        int        `json:"version"`                    // The version of the report format (see ReportVersion).
+	RandomSeed     int64      `json:"random_seed,omitempty"`      // The seed from which every probe's pseudo-random choices were derived (see --random-seed).
+	MaxTPS         int        `json:"max_tps"`                    // The highest target TPS that the chain sustained (0 if it sustained none of those probed).
+	LowerBoundOnly bool       `json:"lower_bound_only,omitempty"` // Whether the search ran out of probes before finding a rate the chain couldn't sustain, so the chain may sustain more than MaxTPS.
+	Interrupted    bool       `json:"interrupted,omitempty"`      // Whether the search was interrupted before it completed.
+	MinSuccessRate float64    `json:"min_success_rate"`           // The commit success rate below which a probe's rate wasn't considered sustainable.
+	MaxLatencyMs   int        `json:"max_latency_ms,omitempty"`   // The 90th percentile commit latency above which a probe's rate wasn't considered sustainable.
+	Probes         []TPSProbe `json:"probes"`                     // Every probe run, in order.
+}
+
+// Judge decides whether the given probe's rate was sustainable by the given
+// criteria, recording the reason if it wasn't.
+func (p *TPSProbe) Judge(minSuccessRate float64, maxLatencyMs int) {
+	p.Sustainable = false
+	switch {
+	case p.StopReason == StopReasonChainHalted:
+		p.Reason = "the chain halted"
+	case p.Resolved == 0:
+		p.Reason = "no sampled transactions were resolved"
+	case p.CommitSuccessRate < minSuccessRate:
+		p.Reason = fmt.Sprintf("commit success rate %.2f%% is below %.2f%%", p.CommitSuccessRate*100, minSuccessRate*100)
+	case maxLatencyMs > 0 && p.P90LatencyMs > float64(maxLatencyMs):
+		p.Reason = fmt.Sprintf("p90 commit latency %.0fms exceeds %dms", p.P90LatencyMs, maxLatencyMs)
+	case p.AchievedTPS < float64(p.TargetTPS)*findMaxTPSMinAchieved:
+		p.Reason = fmt.Sprintf("only %.1f of %d tx/s were accepted", p.AchievedTPS, p.TargetTPS)
+	default:
+		p.Sustainable = true
+		p.Reason = ""
+	}
+}
+
+// SearchMaxTPS searches for the highest target TPS that the chain sustains,
+// given a probe that runs a load test at a target TPS and judges whether it
+// was sustained (see TPSProbe.Judge). Starting from the given rate, the rate
+// is doubled until a probe fails, after which the highest sustainable and
+// lowest unsustainable rates are bisected until they're within
+// findMaxTPSResolution of each other. The report covers the probes run so
+// far, even if one of them fails.
+func SearchMaxTPS(start int, probe func(targetTPS int) (TPSProbe, error)) (MaxTPSReport, error) {
+	var r MaxTPSReport
+	// the highest sustainable and lowest unsustainable rates found so far
+	lo, hi := 0, 0
+	run := func(targetTPS int) error {
+		p, err := probe(targetTPS)
+		if err != nil {
+			return err
+		}
+		r.Probes = append(r.Probes, p)
+		if p.Sustainable {
+			lo = targetTPS
+		} else {
+			hi = targetTPS
+		}
+		r.MaxTPS = lo
+		return nil
+	}
+	for targetTPS := start; hi == 0; targetTPS *= 2 {
+		if len(r.Probes) == findMaxTPSMaxProbes {
+			r.LowerBoundOnly = true
+			return r, nil
+		}
+		if err := run(targetTPS); err != nil {
+			return r, err
+		}
+	}
+	for hi-lo > max(1, int(float64(hi)*findMaxTPSResolution)) && len(r.Probes) < findMaxTPSMaxProbes {
+		if err := run((lo + hi) / 2); err != nil {
+			return r, err
+		}
+	}
+	return r, nil
+}
+
+// tpsSearch runs the probes of a search for the maximum sustainable TPS,
+// each of which is a standalone load test at its own target TPS.
+type tpsSearch struct {
+	cfg    Config
+	logger logging.Logger
+
+	probes int // The number of probes started so far.
+
+	mtx         sync.Mutex
+	current     *TransactorGroup // The group running the current probe, if any.
+	interrupted bool
+	stop        chan struct{} // Closed once the search is interrupted.
+}
+
+// interrupt stops the current probe (draining it first, if configured to) and
+// the search.
+func (s *tpsSearch) interrupt() {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if s.interrupted {
+		return
+	}
+	s.interrupted = true
+	close(s.stop)
+	if s.current == nil {
+		return
+	}
+	if s.cfg.DrainTimeout > 0 {
+		s.logger.Info("Draining in-flight transactions", "timeout", fmt.Sprintf("%ds", s.cfg.DrainTimeout))
+		s.current.Drain(time.Duration(s.cfg.DrainTimeout) * time.Second)
+		return
+	}
+	s.current.Cancel()
+}
+
+// probe runs a load test for the configured time at the given target TPS,
+// after waiting for the previous probe's transactions to clear, and judges
+// whether the chain sustained it.
+func (s *tpsSearch) probe(targetTPS int) (TPSProbe, error) {
+	cfg := s.cfg
+	cfg.TargetTPS = targetTPS
+	cfg.ApplyTargetTPS()
+
+	if s.probes > 0 {
+		select {
+		case <-time.After(findMaxTPSCooldown):
+		case <-s.stop:
+			return TPSProbe{}, errSearchInterrupted
+		}
+	}
+
+	s.probes++
+	s.logger.Info("Probing target TPS", "probe", s.probes, "targetTPS", targetTPS, "rate", cfg.Rate, "time", fmt.Sprintf("%ds", cfg.Time))
+	tg := NewTransactorGroup()
+	tg.SetLogger(s.logger)
+	err := tg.AddAll(&cfg)
+	if err != nil {
+		WriteConnectionSummary(os.Stdout, tg.ConnectionResults())
+		return TPSProbe{}, err
+	}
+	s.mtx.Lock()
+	if s.interrupted {
+		s.mtx.Unlock()
+		tg.close()
+		return TPSProbe{}, errSearchInterrupted
+	}
+	s.current = tg
+	tg.Start()
+	s.mtx.Unlock()
+
+	err = tg.Wait()
+	s.mtx.Lock()
+	interrupted := s.interrupted
+	s.mtx.Unlock()
+	if interrupted {
+		// the probe's results are incomplete
+		return TPSProbe{}, errSearchInterrupted
+	}
+	if err != nil {
+		return TPSProbe{}, err
+	}
+
+	totals := tg.measuredTotals()
+	confirm := tg.ConfirmStats()
+	p := TPSProbe{
+		TargetTPS:         targetTPS,
+		Resolved:          confirm.Resolved(),
+		CommitSuccessRate: confirm.SuccessRate(),
+		P90LatencyMs:      NewLatencyReport(0, tg.CommitLatencies()).P90Ms,
+		DurationSeconds:   float64(cfg.Time - cfg.WarmupSeconds),
+		Committed:         confirm.Committed,
+		Failed:            confirm.Failed,
+		Missing:           confirm.Missing,
+		MempoolFull:       totals.mempoolFull,
+		TxErrors:          totals.errors,
+		TPSMultiplier:     tg.TPSMultiplier(),
+	}
+	// the group's own duration includes the time it took to confirm the
+	// samples, so the rate is measured over the probe's nominal duration
+	p.AchievedTPS = float64(totals.txs-totals.errors) / p.DurationSeconds
+	if reason := tg.StopReason(); reason != StopReasonTimeLimit {
+		p.StopReason = reason
+	}
+	p.Judge(cfg.MinSuccessRate, cfg.MaxCommitLatency)
+
+	fields := []interface{}{
+		"targetTPS", targetTPS,
+		"achievedTPS", fmt.Sprintf("%.1f", p.AchievedTPS),
+		"commitSuccessRate", fmt.Sprintf("%.2f%%", p.CommitSuccessRate*100),
+		"p90Latency", fmt.Sprintf("%.0fms", p.P90LatencyMs),
+		"sustainable", p.Sustainable,
+	}
+	if !p.Sustainable {
+		fields = append(fields, "reason", p.Reason)
+	}
+	s.logger.Info("Probe complete", fields...)
+	return p, nil
+}
+
+// ExecuteFindMaxTPS searches for the maximum TPS that the chain can sustain,
+// by running a series of standalone load tests ("probes") of the configured
+// time, each at its own target TPS (see SearchMaxTPS). A probe's rate is
+// sustainable if its sampled transactions are committed successfully at a
+// rate of at least MinSuccessRate, within MaxCommitLatency (if set), and the
+// endpoints accept close to the target TPS. Transaction confirmation must be
+// enabled.
+func ExecuteFindMaxTPS(cfg Config) error {
+	logger := logging.NewLogrusLogger("loadtest")
+
+	stopProfiling, err := startProfiling(cfg, logger)
+	if err != nil {
+		logger.Error(err.Error())
+		return err
+	}
+	defer stopProfiling()
+
+	// the network only needs to stabilize once
+	if cfg.ExpectPeers > 0 {
+		peers, err := waitForNetworkPeers(
+			cfg.Endpoints,
+			cfg.EndpointSelectMethod,
+			cfg.ExpectPeers,
+			cfg.MinConnectivity,
+			cfg.MaxEndpoints,
+			time.Duration(cfg.PeerConnectTimeout)*time.Second,
+			logger,
+		)
+		if err != nil {
+			logger.Error("Failed while waiting for peers to connect", "err", err)
+			return err
+		}
+		cfg.Endpoints = peers
+		logger.Debug("Updated list of endpoints for test", "endpoints", cfg.Endpoints)
+	}
+
+	start := cfg.TargetTPS
+	if start < 1 {
+		start = findMaxTPSDefaultStart
+	}
+	search := &tpsSearch{cfg: cfg, logger: logger, stop: make(chan struct{})}
+	if !cfg.NoTrapInterrupts {
+		cancelTrap := trapInterrupts(search.interrupt, logger)
+		defer close(cancelTrap)
+	}
+
+	logger.Info("Searching for the maximum sustainable TPS",
+		"startTPS", start,
+		"minSuccessRate", cfg.MinSuccessRate,
+		"maxCommitLatency", fmt.Sprintf("%dms", cfg.MaxCommitLatency),
+	)
+	r, err := SearchMaxTPS(start, search.probe)
+	r.Version = ReportVersion
+	r.RandomSeed = cfg.RandomSeed
+	r.MinSuccessRate = cfg.MinSuccessRate
+	r.MaxLatencyMs = cfg.MaxCommitLatency
+	if errors.Is(err, errSearchInterrupted) {
+		r.Interrupted = true
+		err = nil
+	}
+	if err != nil {
+		logger.Error("Failed to search for the maximum sustainable TPS", "err", err)
+		return err
+	}
+
+	WriteMaxTPSReport(os.Stdout, r)
+	switch {
+	case r.Interrupted:
+		logger.Info("Search interrupted", "maxSustainableTPS", r.MaxTPS)
+	case r.LowerBoundOnly:
+		logger.Info("Every probe was sustained - the chain may sustain even more", "maxSustainableTPS", r.MaxTPS, "probes", len(r.Probes))
+	case r.MaxTPS == 0:
+		logger.Info("The chain didn't sustain any of the probed rates", "minProbedTPS", r.Probes[len(r.Probes)-1].TargetTPS)
+	default:
+		logger.Info("Search complete", "maxSustainableTPS", r.MaxTPS, "probes", len(r.Probes))
+	}
+
+	if len(cfg.ReportJSONFile) > 0 {
+		logger.Info("Writing JSON report", "outputFile", cfg.ReportJSONFile)
+		if err := writeReport(cfg.ReportJSONFile, r); err != nil {
+			logger.Error("Failed to write JSON report", "err", err)
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteMaxTPSReport writes a table of the given search's probes, followed by
+// the maximum sustainable TPS found, to the given writer.
+func WriteMaxTPSReport(w io.Writer, r MaxTPSReport) {
+	fmt.Fprintf(w, "%-8s %-12s %-10s %-12s %-8s %s\n", "TARGET", "ACHIEVED", "SUCCESS", "P90 LATENCY", "RESULT", "REASON")
+	for _, p := range r.Probes {
+		result := "ok"
+		if !p.Sustainable {
+			result = "fail"
+		}
+		fmt.Fprintf(w, "%-8d %-12.1f %-10s %-12s %-8s %s\n",
+			p.TargetTPS,
+			p.AchievedTPS,
+			fmt.Sprintf("%.2f%%", p.CommitSuccessRate*100),
+			fmt.Sprintf("%.0fms", p.P90LatencyMs),
+			result,
+			p.Reason,
+		)
+	}
+	switch {
+	case r.LowerBoundOnly:
+		fmt.Fprintf(w, "Maximum sustainable TPS: at least %d\n", r.MaxTPS)
+	default:
+		fmt.Fprintf(w, "Maximum sustainable TPS: %d\n", r.MaxTPS)
+	}
+}
@@ -0,0 +1,84 @@
+package loadtest_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/1119-Labs/perpx-load-test/pkg/loadtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sustaining returns a probe against a chain that sustains up to the given
+// TPS, recording the target of each probe.
+func sustaining(maxTPS int, targets *[]int) func(int) (loadtest.TPSProbe, error) {
+	return func(targetTPS int) (loadtest.TPSProbe, error) {
+		*targets = append(*targets, targetTPS)
+		p := loadtest.TPSProbe{TargetTPS: targetTPS, AchievedTPS: float64(targetTPS), Resolved: 100, CommitSuccessRate: 1}
+		if targetTPS > maxTPS {
+			p.CommitSuccessRate = 0.5
+		}
+		p.Judge(0.99, 0)
+		return p, nil
+	}
+}
+
+func TestSearchMaxTPS(t *testing.T) {
+	var targets []int
+	r, err := loadtest.SearchMaxTPS(100, sustaining(730, &targets))
+	require.NoError(t, err)
+	// doubling until 800 fails, then bisecting until within 5%
+	assert.Equal(t, []int{100, 200, 400, 800, 600, 700, 750, 725}, targets)
+	assert.Equal(t, 725, r.MaxTPS)
+	assert.False(t, r.LowerBoundOnly)
+	require.Len(t, r.Probes, len(targets))
+	assert.Contains(t, r.Probes[3].Reason, "commit success rate 50.00% is below 99.00%")
+
+	// a chain that sustains none of the rates is bisected towards 0
+	targets = nil
+	r, err = loadtest.SearchMaxTPS(10, sustaining(0, &targets))
+	require.NoError(t, err)
+	assert.Equal(t, []int{10, 5, 2, 1}, targets)
+	assert.Equal(t, 0, r.MaxTPS)
+
+	// a chain that sustains every rate only yields a lower bound
+	targets = nil
+	r, err = loadtest.SearchMaxTPS(1, sustaining(1<<30, &targets))
+	require.NoError(t, err)
+	assert.True(t, r.LowerBoundOnly)
+	assert.Equal(t, 1<<19, r.MaxTPS)
+
+	// a failed probe ends the search, keeping what was found so far
+	probes := 0
+	r, err = loadtest.SearchMaxTPS(100, func(targetTPS int) (loadtest.TPSProbe, error) {
+		if probes++; probes == 3 {
+			return loadtest.TPSProbe{}, errors.New("failed to connect")
+		}
+		return sustaining(1000, &targets)(targetTPS)
+	})
+	require.Error(t, err)
+	assert.Equal(t, 200, r.MaxTPS)
+}
+
+func TestTPSProbeJudge(t *testing.T) {
+	p := loadtest.TPSProbe{TargetTPS: 1000, AchievedTPS: 950, Resolved: 100, CommitSuccessRate: 0.995, P90LatencyMs: 2500}
+	p.Judge(0.99, 0)
+	assert.True(t, p.Sustainable)
+	assert.Empty(t, p.Reason)
+
+	p.Judge(0.99, 2000)
+	assert.False(t, p.Sustainable)
+	assert.Equal(t, "p90 commit latency 2500ms exceeds 2000ms", p.Reason)
+
+	p.AchievedTPS = 850
+	p.Judge(0.99, 0)
+	assert.Equal(t, "only 850.0 of 1000 tx/s were accepted", p.Reason)
+
+	p.Resolved = 0
+	p.Judge(0.99, 0)
+	assert.Equal(t, "no sampled transactions were resolved", p.Reason)
+
+	p.StopReason = loadtest.StopReasonChainHalted
+	p.Judge(0.99, 0)
+	assert.Equal(t, "the chain halted", p.Reason)
+}
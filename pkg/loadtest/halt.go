@@ -0,0 +1,131 @@
+package loadtest
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/1119-Labs/perpx-load-test/internal/logging"
+)
+
+// How often the halt watchdog polls for the latest block height.
+const haltPollInterval = 1 * time.Second
+
+// HaltWatchdog polls a node's RPC status endpoint for the latest block height
+// and detects when the chain stops producing blocks: once the height hasn't
+// advanced for its timeout, the chain appears to have halted, and the
+// transactions sent since go nowhere.
+type HaltWatchdog struct {
+	client  *httpClient
+	timeout time.Duration
+	onHalt  func() // Called (from the watchdog's goroutine) each time the chain appears to halt, if set.
+	logger  logging.Logger
+
+	mtx        sync.RWMutex
+	height     int64     // The latest block height observed.
+	advancedAt time.Time // When the height last advanced (zero until the first successful poll).
+	halted     bool      // Set while the chain appears halted.
+	halts      int       // How many times the chain has appeared to halt.
+
+	stopc   chan struct{}
+	stopped chan struct{}
+}
+
+// NewHaltWatchdog creates a watchdog that polls the node behind the given
+// endpoint, and considers the chain halted once its height hasn't advanced
+// for the given timeout.
+func NewHaltWatchdog(endpoint string, timeout time.Duration, logger logging.Logger) *HaltWatchdog {
+	client := newHttpRpcClient(rpcURLFromEndpoint(endpoint))
+	client.client.Timeout = blockRateRequestTimeout
+	return &HaltWatchdog{
+		client:  client,
+		timeout: timeout,
+		logger:  logger,
+		stopc:   make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+}
+
+// Start polls in a separate goroutine until Stop is called.
+func (w *HaltWatchdog) Start() {
+	go func() {
+		defer close(w.stopped)
+		ticker := time.NewTicker(haltPollInterval)
+		defer ticker.Stop()
+		w.poll()
+		for {
+			select {
+			case <-ticker.C:
+				w.poll()
+			case <-w.stopc:
+				return
+			}
+		}
+	}()
+}
+
+// Stop halts polling and waits for any in-flight poll to complete.
+func (w *HaltWatchdog) Stop() {
+	close(w.stopc)
+	<-w.stopped
+}
+
+func (w *HaltWatchdog) poll() {
+	status, err := w.client.status()
+	if err != nil {
+		// An unreachable node says nothing about the chain as a whole, so
+		// only successful polls count towards (or against) a halt
+		w.logger.Debug("Failed to poll the latest block height", "err", err)
+		return
+	}
+	w.Observe(int64(status.SyncInfo.LatestBlockHeight), time.Now())
+}
+
+// Observe records the latest block height as of the given time, and reports
+// the chain as halted if the height hasn't advanced for the timeout, or as
+// resumed once it advances again. It is called by the watchdog's poller, but
+// may also be fed heights directly (e.g. in tests).
+func (w *HaltWatchdog) Observe(height int64, now time.Time) {
+	w.mtx.Lock()
+	if height > w.height || w.advancedAt.IsZero() {
+		resumed := w.halted
+		stalled := now.Sub(w.advancedAt)
+		w.height, w.advancedAt, w.halted = height, now, false
+		w.mtx.Unlock()
+		if resumed {
+			w.logger.Info("Chain resumed producing blocks", "height", height, "stalled", stalled.Round(time.Second).String())
+		}
+		return
+	}
+	stalled := now.Sub(w.advancedAt)
+	halting := !w.halted && stalled >= w.timeout
+	if halting {
+		w.halted = true
+		w.halts++
+	}
+	w.mtx.Unlock()
+	if halting {
+		w.logger.Error(fmt.Sprintf("WARNING: chain appears halted - no new block for %s", stalled.Round(time.Second)), "height", height)
+		if w.onHalt != nil {
+			w.onHalt()
+		}
+	}
+}
+
+// Height returns the latest block height observed, and how long ago it
+// advanced (0 if no height has been observed yet).
+func (w *HaltWatchdog) Height() (height int64, sinceAdvanced time.Duration) {
+	w.mtx.RLock()
+	defer w.mtx.RUnlock()
+	if w.advancedAt.IsZero() {
+		return 0, 0
+	}
+	return w.height, time.Since(w.advancedAt)
+}
+
+// Halts returns how many times the chain has appeared to halt.
+func (w *HaltWatchdog) Halts() int {
+	w.mtx.RLock()
+	defer w.mtx.RUnlock()
+	return w.halts
+}
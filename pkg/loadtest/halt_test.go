@@ -0,0 +1,90 @@
+package loadtest_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/1119-Labs/perpx-load-test/internal/logging"
+	"github.com/1119-Labs/perpx-load-test/pkg/loadtest"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHaltWatchdog(t *testing.T) {
+	w := loadtest.NewHaltWatchdog("ws://localhost:26657/websocket", 10*time.Second, logging.NewNoopLogger())
+	start := time.Now()
+
+	w.Observe(100, start)
+	w.Observe(101, start.Add(time.Second))
+	// a slow chain isn't a halted one
+	w.Observe(101, start.Add(10*time.Second))
+	assert.Equal(t, 0, w.Halts())
+
+	w.Observe(101, start.Add(11*time.Second))
+	assert.Equal(t, 1, w.Halts())
+	// the same halt is only counted once
+	w.Observe(101, start.Add(20*time.Second))
+	assert.Equal(t, 1, w.Halts())
+
+	// once the chain resumes, it can halt again
+	w.Observe(102, start.Add(21*time.Second))
+	w.Observe(102, start.Add(31*time.Second))
+	assert.Equal(t, 2, w.Halts())
+
+	height, _ := w.Height()
+	assert.Equal(t, int64(102), height)
+}
+
+func TestStopOnHalt(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	// accepts broadcasts, but reports a chain that never advances
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/status" {
+			raw, _ := json.Marshal(map[string]interface{}{"sync_info": map[string]interface{}{
+				"latest_block_height": "42",
+				"latest_block_time":   time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+			}})
+			_ = json.NewEncoder(w).Encode(loadtest.RPCResponse{JSONRPC: "2.0", Result: raw})
+			return
+		}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	defer server.Close()
+
+	cfg := loadtest.Config{
+		ClientFactory:     "kvstore",
+		Connections:       1,
+		Rate:              5,
+		Size:              40,
+		Time:              10,
+		SendPeriod:        1,
+		BroadcastTxMethod: "async",
+		HaltTimeout:       1,
+		StopOnHalt:        true,
+		Endpoints:         []string{"ws://" + strings.TrimPrefix(server.URL, "http://") + "/websocket"},
+	}
+	tg := loadtest.NewTransactorGroup()
+	require.NoError(t, tg.AddAll(&cfg))
+	start := time.Now()
+	tg.Start()
+	require.NoError(t, tg.Wait())
+	assert.Less(t, time.Since(start), 5*time.Second)
+
+	report := tg.Report()
+	assert.Equal(t, loadtest.StopReasonChainHalted, report.StopReason)
+	assert.Equal(t, 1, report.ChainHalts)
+}
@@ -0,0 +1,157 @@
+package loadtest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/1119-Labs/perpx-load-test/internal/logging"
+)
+
+// Possible values of RunStatus.State.
+const (
+	RunStateConnecting = "connecting" // Waiting for peers and/or connecting to endpoints.
+	RunStateRunning    = "running"    // Transactors are connected and generating load.
+	RunStateDraining   = "draining"   // Interrupted, and waiting for in-flight transactions to settle.
+	RunStateCompleted  = "completed"  // The load test finished successfully.
+	RunStateFailed     = "failed"     // The load test failed.
+)
+
+// RunStatus is the JSON document served on the health server's /status path.
+type RunStatus struct {
+	State          string  `json:"state"`           // One of the RunState* constants.
+	ElapsedSeconds float64 `json:"elapsed_seconds"` // Time since transactors were started (0 while connecting).
+	Connections    int     `json:"connections"`     // The number of open WebSockets connections to endpoints.
+	TotalTxs       int     `json:"total_txs"`       // The total number of transactions sent so far.
+	TotalBytes     int64   `json:"total_bytes"`     // The total number of transaction bytes sent so far.
+	AvgTxRate      float64 `json:"avg_tx_rate"`     // The average number of transactions sent per second.
+	TxErrors       int     `json:"tx_errors"`       // The number of broadcast requests rejected so far.
+	MempoolFull    int     `json:"mempool_full"`    // The number of broadcast requests rejected so far because the mempool was full.
+	ErrorRate      float64 `json:"error_rate"`      // TxErrors as a fraction of TotalTxs.
+}
+
+// healthServer exposes liveness, readiness and basic run status over HTTP so
+// that orchestrators (e.g. Kubernetes) can tell whether a load test is
+// actually generating load.
+type healthServer struct {
+	svr    *http.Server
+	logger logging.Logger
+
+	mtx   sync.RWMutex
+	state string
+	tg    *TransactorGroup
+}
+
+func newHealthServer(addr string, logger logging.Logger) *healthServer {
+	h := &healthServer{
+		logger: logger,
+		state:  RunStateConnecting,
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", h.handleHealthz)
+	mux.HandleFunc("/readyz", h.handleReadyz)
+	mux.HandleFunc("/status", h.handleStatus)
+	h.svr = &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	return h
+}
+
+// Start serves the health endpoints in a separate goroutine.
+func (h *healthServer) Start() {
+	go func() {
+		h.logger.Info("Starting health server", "addr", h.svr.Addr)
+		if err := h.svr.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			h.logger.Error("Health server shut down", "err", err)
+		}
+	}()
+}
+
+// Stop gracefully shuts down the health server.
+func (h *healthServer) Stop() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := h.svr.Shutdown(ctx); err != nil {
+		h.logger.Error("Failed to gracefully shut down health server", "err", err)
+	}
+}
+
+// SetRunning marks the load test as underway, with statistics to be drawn
+// from the given transactor group. Does nothing on a nil server.
+func (h *healthServer) SetRunning(tg *TransactorGroup) {
+	if h == nil {
+		return
+	}
+	h.mtx.Lock()
+	h.state = RunStateRunning
+	h.tg = tg
+	h.mtx.Unlock()
+}
+
+// SetState updates the run state reported by the server. Does nothing on a
+// nil server.
+func (h *healthServer) SetState(state string) {
+	if h == nil {
+		return
+	}
+	h.mtx.Lock()
+	h.state = state
+	h.mtx.Unlock()
+}
+
+// Status returns a snapshot of the current run status.
+func (h *healthServer) Status() RunStatus {
+	h.mtx.RLock()
+	state, tg := h.state, h.tg
+	h.mtx.RUnlock()
+
+	status := RunStatus{State: state}
+	if tg == nil {
+		return status
+	}
+	status.Connections = len(tg.transactors)
+	status.TotalTxs = tg.totalTxs()
+	status.TotalBytes = tg.totalBytes()
+	status.TxErrors = tg.TxErrors()
+	status.MempoolFull = tg.MempoolFull()
+	if startTime := tg.getStartTime(); !startTime.IsZero() {
+		status.ElapsedSeconds = time.Since(startTime).Seconds()
+	}
+	if status.ElapsedSeconds > 0 {
+		status.AvgTxRate = float64(status.TotalTxs) / status.ElapsedSeconds
+	}
+	if status.TotalTxs > 0 {
+		status.ErrorRate = float64(status.TxErrors) / float64(status.TotalTxs)
+	}
+	return status
+}
+
+// The process is alive as long as it can serve requests.
+func (h *healthServer) handleHealthz(w http.ResponseWriter, _ *http.Request) {
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte("ok\n"))
+}
+
+// We're ready once the transactors are connected and have actually started
+// sending transactions.
+func (h *healthServer) handleReadyz(w http.ResponseWriter, _ *http.Request) {
+	status := h.Status()
+	if status.State != RunStateRunning || status.TotalTxs == 0 {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte(status.State + "\n"))
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte("ok\n"))
+}
+
+func (h *healthServer) handleStatus(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(h.Status()); err != nil {
+		h.logger.Error("Failed to write status response", "err", err)
+	}
+}
@@ -11,18 +11,48 @@ import (
 
 // ExecuteStandalone will run a standalone (non-coordinator/worker) load test.
 func ExecuteStandalone(cfg Config) error {
-	// If we're in TUI mode, keep logging extremely quiet to avoid corrupting the screen.
-	// We'll print errors after the UI stops.
+	return executeStandalone(cfg, nil)
+}
+
+// ExecuteStandaloneWithErrors is like ExecuteStandalone, but also calls
+// onError with each error encountered during the load test as it occurs (see
+// RunErrorHandler), categorized by kind. This allows code embedding the load
+// test to react to failures in real time, rather than only learning of the
+// error (if any) that ended the load test.
+func ExecuteStandaloneWithErrors(cfg Config, onError RunErrorHandler) error {
+	return executeStandalone(cfg, onError)
+}
+
+func executeStandalone(cfg Config, onError RunErrorHandler) error {
+	// If we're in TUI mode, keep logging extremely quiet to avoid corrupting
+	// the screen (unless logs are being written to a file instead). We'll
+	// print errors after the UI stops.
 	tuiMode := cfg.UI == "tui"
-	if tuiMode {
+	quietLogs := tuiMode && len(cfg.LogFile) == 0
+	if quietLogs {
 		logrus.SetLevel(logrus.ErrorLevel)
 	}
 
 	logger := logging.NewLogrusLogger("loadtest")
-	if tuiMode {
+	if quietLogs {
 		logger = logging.NewNoopLogger()
 	}
 
+	var health *healthServer
+	if len(cfg.HealthAddr) > 0 {
+		health = newHealthServer(cfg.HealthAddr, logger)
+		health.Start()
+		defer health.Stop()
+	}
+
+	stopProfiling, err := startProfiling(cfg, logger)
+	if err != nil {
+		logger.Error(err.Error())
+		health.SetState(RunStateFailed)
+		return err
+	}
+	defer stopProfiling()
+
 	logger.Debug("Attempting standalone load test against endpoints", "endpoints", cfg.Endpoints)
 
 	// if we need to wait for the network to stabilize first
@@ -38,6 +68,7 @@ func ExecuteStandalone(cfg Config) error {
 		)
 		if err != nil {
 			logger.Error("Failed while waiting for peers to connect", "err", err)
+			health.SetState(RunStateFailed)
 			return err
 		}
 		cfg.Endpoints = peers
@@ -47,55 +78,207 @@ func ExecuteStandalone(cfg Config) error {
 	logger.Info("Connecting to remote endpoints")
 	tg := NewTransactorGroup()
 	tg.SetLogger(logger)
-	if err := tg.AddAll(&cfg); err != nil {
+	tg.SetErrorHandler(onError)
+	err = tg.AddAll(&cfg)
+	WriteConnectionSummary(os.Stdout, tg.ConnectionResults())
+	if err != nil {
+		health.SetState(RunStateFailed)
 		return err
 	}
+	// The blocks committed from now on are analyzed once the load test is done
+	var blockClient *httpClient
+	var startHeight int64
+	if cfg.BlockAnalysis {
+		blockClient = newBlockAnalysisClient(cfg.Endpoints[0])
+		if startHeight, err = latestHeight(blockClient); err != nil {
+			logger.Error("Failed to query the starting height for the block analysis - skipping it", "err", err)
+			blockClient = nil
+		}
+	}
+
 	logger.Info("Initiating load test")
 	tg.Start()
+	health.SetRunning(tg)
 
 	var stopTUI func()
 	if tuiMode {
 		stopTUI = startStandaloneTUI(&cfg, tg)
-		defer stopTUI()
+		defer func() {
+			if stopTUI != nil {
+				stopTUI()
+			}
+		}()
 	}
 
 	var cancelTrap chan struct{}
 	if !cfg.NoTrapInterrupts {
 		// we want to know if the user hits Ctrl+Break
-		cancelTrap = trapInterrupts(func() { tg.Cancel() }, logger)
+		cancelTrap = trapInterrupts(func() {
+			if cfg.DrainTimeout > 0 {
+				logger.Info("Draining in-flight transactions", "timeout", fmt.Sprintf("%ds", cfg.DrainTimeout))
+				health.SetState(RunStateDraining)
+				tg.Drain(time.Duration(cfg.DrainTimeout) * time.Second)
+				return
+			}
+			tg.Cancel()
+		}, logger)
 		defer close(cancelTrap)
 	} else {
 		logger.Debug("Skipping trapping of interrupts (e.g. Ctrl+Break)")
 	}
 
 	if err := tg.Wait(); err != nil {
+		health.SetState(RunStateFailed)
 		if stopTUI != nil {
 			stopTUI()
 		}
+		logger.Error("Failed to execute load test", "err", err)
 		if tuiMode {
 			fmt.Fprintln(os.Stderr, err.Error())
-		} else {
-			logger.Error("Failed to execute load test", "err", err)
 		}
 		return err
 	}
 
+	if !quietLogs {
+		logger.Info("Load test stopped", "reason", tg.StopReason())
+	}
+
+	if !tg.WarmupComplete() && !quietLogs {
+		logger.Info("Load test ended before the warmup period elapsed - statistics include the warmup period", "warmup", fmt.Sprintf("%ds", cfg.WarmupSeconds))
+	}
+
+	totals := tg.measuredTotals()
+	if totals.errors > 0 && !quietLogs {
+		logger.Info("Some transactions were rejected",
+			"rejected", totals.errors,
+			"mempoolFull", totals.mempoolFull,
+		)
+	}
+
+	if totals.injected > 0 && !quietLogs {
+		logger.Info("Injected invalid transactions",
+			"sent", totals.injected,
+			"rejected", totals.injectedRejected,
+		)
+	}
+
+	if halts := tg.ChainHalts(); halts > 0 && !quietLogs {
+		logger.Info("The chain appeared halted during the load test", "halts", halts)
+	}
+
+	if fees := tg.FeesSpent(); len(fees) > 0 && !quietLogs {
+		logger.Info("Transaction fees", "total", fees, "budget", cfg.FeeBudget)
+	}
+
+	if accounts := tg.Accounts(); accounts != nil && !quietLogs {
+		fields := []interface{}{
+			"senders", accounts.Senders,
+			"recipients", accounts.Recipients,
+			"touched", accounts.Touched,
+		}
+		if accounts.Created != nil {
+			fields = append(fields, "created", *accounts.Created)
+		}
+		logger.Info("Accounts touched", fields...)
+	}
+
+	if gas := tg.GasStats(); gas.Samples > 0 && !quietLogs {
+		logger.Info("Observed gas usage",
+			"samples", gas.Samples,
+			"minGasUsed", gas.MinUsed,
+			"avgGasUsed", fmt.Sprintf("%.0f", gas.AvgUsed()),
+			"maxGasUsed", gas.MaxUsed,
+			"avgGasWanted", fmt.Sprintf("%.0f", gas.AvgWanted()),
+		)
+	}
+
+	if confirm := tg.ConfirmStats(); cfg.Confirm && !quietLogs {
+		totalTxs := totals.txs
+		estCommitted := int(float64(totalTxs) * confirm.SuccessRate())
+		logger.Info("Transaction confirmation results",
+			"sampled", confirm.Sampled,
+			"committed", confirm.Committed,
+			"failed", confirm.Failed,
+			"missing", confirm.Missing,
+			"dropped", confirm.Dropped,
+			"expired", tg.Expired(),
+			"commitSuccessRate", fmt.Sprintf("%.2f%%", confirm.SuccessRate()*100),
+			"acceptedTxs", totalTxs,
+			"estCommittedTxs", estCommitted,
+			"divergence", totalTxs-estCommitted,
+		)
+		if confirm.Blocks > 0 {
+			logger.Info("Sampled transactions were committed in blocks",
+				"minHeight", confirm.MinHeight,
+				"maxHeight", confirm.MaxHeight,
+				"blocks", confirm.Blocks,
+			)
+		}
+	}
+
+	if cfg.ColdStart && !quietLogs {
+		fc := NewLatencyReport(tg.FirstCommitLatencies())
+		logger.Info("First commit latency of new accounts",
+			"accounts", fc.Accounts,
+			"committed", fc.Committed,
+			"min", fmt.Sprintf("%.0fms", fc.MinMs),
+			"avg", fmt.Sprintf("%.0fms", fc.AvgMs),
+			"p50", fmt.Sprintf("%.0fms", fc.P50Ms),
+			"p90", fmt.Sprintf("%.0fms", fc.P90Ms),
+			"p99", fmt.Sprintf("%.0fms", fc.P99Ms),
+			"max", fmt.Sprintf("%.0fms", fc.MaxMs),
+		)
+	}
+
+	if blockClient != nil {
+		// the analysis is printed, so it mustn't be cleared by the TUI
+		if stopTUI != nil {
+			stopTUI()
+			stopTUI = nil
+		}
+		endHeight, err := latestHeight(blockClient)
+		var analysis *BlockAnalysis
+		if err == nil {
+			analysis, err = analyzeBlocks(blockClient, startHeight+1, endHeight)
+		}
+		if err != nil {
+			logger.Error("Failed to analyze the blocks committed during the load test", "err", err)
+		} else {
+			WriteBlockAnalysis(os.Stdout, analysis)
+		}
+	}
+
 	// if we need to write the final statistics
 	if len(cfg.StatsOutputFile) > 0 {
-		if !tuiMode {
+		if !quietLogs {
 			logger.Info("Writing aggregate statistics", "outputFile", cfg.StatsOutputFile)
 		}
 		if err := tg.WriteAggregateStats(cfg.StatsOutputFile); err != nil {
+			health.SetState(RunStateFailed)
+			logger.Error("Failed to write aggregate statistics", "err", err)
+			if tuiMode {
+				fmt.Fprintln(os.Stderr, err.Error())
+			}
+			return err
+		}
+	}
+
+	if len(cfg.ReportJSONFile) > 0 {
+		if !quietLogs {
+			logger.Info("Writing JSON report", "outputFile", cfg.ReportJSONFile)
+		}
+		if err := tg.WriteReport(cfg.ReportJSONFile); err != nil {
+			health.SetState(RunStateFailed)
+			logger.Error("Failed to write JSON report", "err", err)
 			if tuiMode {
 				fmt.Fprintln(os.Stderr, err.Error())
-			} else {
-				logger.Error("Failed to write aggregate statistics", "err", err)
 			}
 			return err
 		}
 	}
 
-	if !tuiMode {
+	health.SetState(RunStateCompleted)
+	if !quietLogs {
 		logger.Info("Load test complete!")
 	}
 	return nil
@@ -0,0 +1,170 @@
+package loadtest
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// ChainMeasurement describes the transactions committed by the chain over a
+// range of blocks, as observed through a node's RPC API rather than counted by
+// the load test itself.
+type ChainMeasurement struct {
+	FromHeight     int64         // The block at the start of the range (whose transactions are not counted).
+	ToHeight       int64         // The last block in the range.
+	Blocks         int           // The number of blocks committed after FromHeight, up to and including ToHeight.
+	Txs            int           // The number of transactions in those blocks.
+	Duration       time.Duration // The time between the FromHeight and ToHeight blocks.
+	MaxTxsPerBlock int           // The most transactions in any single block.
+	AvgBlockSize   float64       // The average size of the blocks, in bytes.
+	MaxBlockBytes  int64         // The chain's maximum block size at ToHeight (zero or negative if unknown or unlimited).
+}
+
+// TPS is the rate at which the chain committed transactions.
+func (m ChainMeasurement) TPS() float64 {
+	if m.Duration <= 0 {
+		return 0
+	}
+	return float64(m.Txs) / m.Duration.Seconds()
+}
+
+// AvgBlockTime is the average time between blocks.
+func (m ChainMeasurement) AvgBlockTime() time.Duration {
+	if m.Blocks == 0 {
+		return 0
+	}
+	return m.Duration / time.Duration(m.Blocks)
+}
+
+// AvgTxsPerBlock is the average number of transactions per block.
+func (m ChainMeasurement) AvgTxsPerBlock() float64 {
+	if m.Blocks == 0 {
+		return 0
+	}
+	return float64(m.Txs) / float64(m.Blocks)
+}
+
+// Fullness is the average block size as a fraction of the maximum block
+// size, or 0 if the maximum is unknown or unlimited.
+func (m ChainMeasurement) Fullness() float64 {
+	if m.MaxBlockBytes <= 0 {
+		return 0
+	}
+	return m.AvgBlockSize / float64(m.MaxBlockBytes)
+}
+
+// MeasureChain sums up the transactions committed in the blocks after
+// fromHeight, up to and including toHeight, by querying the RPC API
+// corresponding to the given CometBFT WebSockets RPC endpoint.
+func MeasureChain(endpoint string, fromHeight, toHeight int64) (ChainMeasurement, error) {
+	if fromHeight < 1 || toHeight <= fromHeight {
+		return ChainMeasurement{}, fmt.Errorf("expected 1 <= from height < to height, but got %d and %d", fromHeight, toHeight)
+	}
+	client := newHttpRpcClient(rpcURLFromEndpoint(endpoint))
+	client.client.Timeout = blockRateRequestTimeout
+
+	m := ChainMeasurement{FromHeight: fromHeight, ToHeight: toHeight}
+	var fromTime, toTime time.Time
+	var totalSize int64
+	// CometBFT returns a limited number of block metas per request, newest
+	// first
+	for maxHeight := toHeight; maxHeight >= fromHeight; maxHeight -= blockchainMaxMetas {
+		minHeight := maxHeight - blockchainMaxMetas + 1
+		if minHeight < fromHeight {
+			minHeight = fromHeight
+		}
+		info, err := client.blockchain(minHeight, maxHeight)
+		if err != nil {
+			return ChainMeasurement{}, err
+		}
+		if int64(len(info.BlockMetas)) != maxHeight-minHeight+1 {
+			return ChainMeasurement{}, fmt.Errorf("expected %d blocks between heights %d and %d, but got %d (has the node pruned them, or not reached height %d yet?)",
+				maxHeight-minHeight+1, minHeight, maxHeight, len(info.BlockMetas), toHeight)
+		}
+		for _, meta := range info.BlockMetas {
+			height := int64(meta.Header.Height)
+			switch height {
+			case fromHeight:
+				fromTime = meta.Header.Time
+				continue
+			case toHeight:
+				toTime = meta.Header.Time
+			}
+			m.Blocks++
+			m.Txs += int(meta.NumTxs)
+			totalSize += int64(meta.BlockSize)
+			if int(meta.NumTxs) > m.MaxTxsPerBlock {
+				m.MaxTxsPerBlock = int(meta.NumTxs)
+			}
+		}
+	}
+	m.Duration = toTime.Sub(fromTime)
+	if m.Blocks > 0 {
+		m.AvgBlockSize = float64(totalSize) / float64(m.Blocks)
+	}
+	// not all nodes expose the consensus parameters, and the fullness is only
+	// a nice-to-have
+	if params, err := client.consensusParams(toHeight); err == nil {
+		m.MaxBlockBytes = int64(params.ConsensusParams.Block.MaxBytes)
+	}
+	return m, nil
+}
+
+// LatestHeight returns the height of the latest block committed by the node
+// behind the given CometBFT WebSockets RPC endpoint.
+func LatestHeight(endpoint string) (int64, error) {
+	client := newHttpRpcClient(rpcURLFromEndpoint(endpoint))
+	client.client.Timeout = blockRateRequestTimeout
+	status, err := client.status()
+	if err != nil {
+		return 0, err
+	}
+	return int64(status.SyncInfo.LatestBlockHeight), nil
+}
+
+// WriteChainMeasurement writes a human-readable summary of the given
+// measurement.
+func WriteChainMeasurement(w io.Writer, m ChainMeasurement) {
+	fmt.Fprintf(w, "Blocks:             %d (heights %d to %d)\n", m.Blocks, m.FromHeight+1, m.ToHeight)
+	fmt.Fprintf(w, "Duration:           %s\n", m.Duration.Round(time.Millisecond))
+	fmt.Fprintf(w, "Transactions:       %d\n", m.Txs)
+	fmt.Fprintf(w, "Chain TPS:          %.2f tx/s\n", m.TPS())
+	fmt.Fprintf(w, "Avg block time:     %s\n", m.AvgBlockTime().Round(time.Millisecond))
+	fmt.Fprintf(w, "Avg txs per block:  %.1f (max %d)\n", m.AvgTxsPerBlock(), m.MaxTxsPerBlock)
+	if m.MaxBlockBytes > 0 {
+		fmt.Fprintf(w, "Avg block fullness: %.1f%% (%.0f of %d bytes)\n", m.Fullness()*100, m.AvgBlockSize, m.MaxBlockBytes)
+	} else {
+		fmt.Fprintf(w, "Avg block size:     %.0f bytes (no maximum block size)\n", m.AvgBlockSize)
+	}
+}
+
+// ExecuteMeasure measures the chain's throughput through the first of the
+// configured endpoints, either over the given height range or, if duration is
+// non-zero, over the blocks committed from now until the duration elapses.
+// If toHeight is 0, the range extends to the latest block.
+func ExecuteMeasure(cfg Config, fromHeight, toHeight int64, duration time.Duration, w io.Writer) error {
+	if len(cfg.Endpoints) == 0 {
+		return fmt.Errorf("at least one endpoint must be specified")
+	}
+	endpoint := cfg.Endpoints[0]
+	var err error
+	if duration > 0 {
+		if fromHeight, err = LatestHeight(endpoint); err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "Measuring from height %d for %s...\n", fromHeight, duration)
+		time.Sleep(duration)
+		toHeight = 0
+	}
+	if toHeight == 0 {
+		if toHeight, err = LatestHeight(endpoint); err != nil {
+			return err
+		}
+	}
+	m, err := MeasureChain(endpoint, fromHeight, toHeight)
+	if err != nil {
+		return err
+	}
+	WriteChainMeasurement(w, m)
+	return nil
+}
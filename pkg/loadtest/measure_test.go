@@ -0,0 +1,93 @@
+package loadtest_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/1119-Labs/perpx-load-test/pkg/loadtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeChainRPC serves the blockchain, status and consensus_params RPC
+// endpoints for a chain of the given height, whose block i contains i
+// transactions, is 1000 bytes in size and was committed i*500ms after the
+// genesis time.
+func fakeChainRPC(t *testing.T, height int64) string {
+	genesis := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	blockTime := func(h int64) time.Time { return genesis.Add(time.Duration(h) * 500 * time.Millisecond) }
+	reply := func(w http.ResponseWriter, result interface{}) {
+		raw, err := json.Marshal(result)
+		require.NoError(t, err)
+		require.NoError(t, json.NewEncoder(w).Encode(loadtest.RPCResponse{JSONRPC: "2.0", Result: raw}))
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/status":
+			reply(w, map[string]interface{}{"sync_info": map[string]interface{}{
+				"latest_block_height": strconv.FormatInt(height, 10),
+				"latest_block_time":   blockTime(height),
+			}})
+		case "/consensus_params":
+			reply(w, map[string]interface{}{"consensus_params": map[string]interface{}{
+				"block": map[string]string{"max_bytes": "4000", "max_gas": "-1"},
+			}})
+		case "/blockchain":
+			minHeight, _ := strconv.ParseInt(r.URL.Query().Get("minHeight"), 10, 64)
+			maxHeight, _ := strconv.ParseInt(r.URL.Query().Get("maxHeight"), 10, 64)
+			if maxHeight > height {
+				maxHeight = height
+			}
+			if maxHeight-minHeight >= 20 {
+				minHeight = maxHeight - 19
+			}
+			var metas []interface{}
+			for h := maxHeight; h >= minHeight; h-- {
+				metas = append(metas, map[string]interface{}{
+					"header":     map[string]interface{}{"height": strconv.FormatInt(h, 10), "time": blockTime(h)},
+					"block_size": "1000",
+					"num_txs":    strconv.FormatInt(h, 10),
+				})
+			}
+			reply(w, map[string]interface{}{"last_height": strconv.FormatInt(height, 10), "block_metas": metas})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return "ws://" + strings.TrimPrefix(server.URL, "http://") + "/websocket"
+}
+
+func TestMeasureChain(t *testing.T) {
+	endpoint := fakeChainRPC(t, 50)
+	m, err := loadtest.MeasureChain(endpoint, 10, 45)
+	require.NoError(t, err)
+	assert.Equal(t, 35, m.Blocks)
+	assert.Equal(t, (11+45)*35/2, m.Txs)
+	assert.Equal(t, 45, m.MaxTxsPerBlock)
+	assert.Equal(t, 17500*time.Millisecond, m.Duration)
+	assert.Equal(t, 500*time.Millisecond, m.AvgBlockTime())
+	assert.InDelta(t, 56.0, m.TPS(), 1e-9)
+	assert.InDelta(t, 0.25, m.Fullness(), 1e-9)
+
+	_, err = loadtest.MeasureChain(endpoint, 45, 10)
+	assert.Error(t, err)
+	_, err = loadtest.MeasureChain(endpoint, 40, 60)
+	assert.ErrorContains(t, err, "not reached height 60")
+}
+
+func TestExecuteMeasureDefaultsToLatestHeight(t *testing.T) {
+	endpoint := fakeChainRPC(t, 30)
+	var buf bytes.Buffer
+	require.NoError(t, loadtest.ExecuteMeasure(loadtest.Config{Endpoints: []string{endpoint}}, 20, 0, 0, &buf))
+	assert.Contains(t, buf.String(), "Blocks:             10 (heights 21 to 30)\n")
+	assert.Contains(t, buf.String(), fmt.Sprintf("Chain TPS:          %.2f tx/s\n", float64(21+30)*10/2/5))
+	assert.Contains(t, buf.String(), "Avg block fullness: 25.0% (1000 of 4000 bytes)\n")
+}
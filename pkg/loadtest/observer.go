@@ -0,0 +1,87 @@
+package loadtest
+
+import (
+	"fmt"
+	"time"
+)
+
+// Categories of transaction errors that don't originate from the node's
+// response to a broadcast request.
+const (
+	// The transaction could not be written to its endpoint's connection.
+	TxErrorCategoryConnection = "connection"
+	// The transaction was sampled for confirmation, but was not found in a
+	// block before the confirmation timeout expired.
+	TxErrorCategoryUnconfirmed = "unconfirmed"
+	// The transaction was sampled for confirmation, was not found in a block
+	// and, according to its client, can no longer be committed because its
+	// timeout height has passed (see ClientTxExpirer).
+	TxErrorCategoryExpired = "expired"
+)
+
+// ResultObserver is notified of the outcome of each transaction sent by the
+// load test, e.g. to gather custom metrics or to make assertions about a run.
+// Register one through CLIConfig.ResultObserver or Config.ResultObserver.
+//
+// Observers are called from the transactors' send and receive paths (and the
+// confirmation workers), so implementations must be safe for concurrent use
+// and should return quickly.
+type ResultObserver interface {
+	// OnSubmit is called just before a transaction is broadcast.
+	OnSubmit(tx TxInfo)
+	// OnConfirm is called when a transaction is known to have been committed
+	// successfully, which is only the case for transactions broadcast with
+	// broadcast_tx_commit and for those sampled for confirmation (see
+	// Config.Confirm).
+	OnConfirm(tx TxInfo, result TxConfirmation)
+	// OnError is called when a transaction was rejected by its endpoint,
+	// failed in its block, could not be sent, or could not be confirmed.
+	OnError(tx TxInfo, err *TxError)
+}
+
+// TxInfo identifies a transaction sent by the load test.
+type TxInfo struct {
+	Hash        string    // The upper-case hex-encoded SHA256 hash of the raw transaction.
+	Endpoint    string    // The WebSockets URL of the endpoint to which the transaction was sent.
+	Transactor  int       // The ID of the transactor that sent the transaction (its stream ID in recordings).
+	SubmittedAt time.Time // When the transaction was broadcast.
+}
+
+// TxConfirmation describes a successfully committed transaction.
+type TxConfirmation struct {
+	Height    int64 // The height of the block in which the transaction was committed.
+	GasUsed   int64
+	GasWanted int64
+}
+
+// TxError describes why a transaction failed.
+type TxError struct {
+	Category  string // The category under which the error is counted in the statistics (e.g. "sdk/32"), or one of the TxErrorCategory constants.
+	Codespace string // The codespace of a non-zero result code.
+	Code      uint32 // The non-zero result code, if the node returned one.
+	Log       string // The node's error message or log, if any.
+}
+
+func (e *TxError) Error() string {
+	if len(e.Log) == 0 {
+		return e.Category
+	}
+	return fmt.Sprintf("%s: %s", e.Category, e.Log)
+}
+
+func rpcTxError(e *RPCError) *TxError {
+	log := e.Message
+	if len(e.Data) > 0 {
+		log = e.Data
+	}
+	return &TxError{Category: rpcErrorCategory(e), Log: log}
+}
+
+func resultTxError(codespace string, code uint32, log string) *TxError {
+	return &TxError{
+		Category:  resultErrorCategory(codespace, code),
+		Codespace: codespace,
+		Code:      code,
+		Log:       log,
+	}
+}
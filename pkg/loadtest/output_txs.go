@@ -0,0 +1,61 @@
+package loadtest
+
+import (
+	"fmt"
+
+	"github.com/1119-Labs/perpx-load-test/internal/logging"
+)
+
+// ExecuteOutputTxs has a client for each connection that the load test would
+// make generate cfg.Count signed transactions between them, taking turns, and
+// writes them to cfg.OutputTxs in the recording format (see TxRecorder)
+// instead of broadcasting them. Each client's transactions form a stream in
+// the output, in the order in which they must be broadcast, so the output
+// can be broadcast by external tools or by the replay command.
+func ExecuteOutputTxs(cfg Config) error {
+	logger := logging.NewLogrusLogger("loadtest")
+
+	clientFactory, exists := clientFactories[cfg.ClientFactory]
+	if !exists {
+		return fmt.Errorf("unrecognized client factory: %s", cfg.ClientFactory)
+	}
+	streams := cfg.Connections * len(cfg.Endpoints)
+	if streams > cfg.Count {
+		streams = cfg.Count
+	}
+	clients := make([]Client, streams)
+	for i := range clients {
+		client, err := clientFactory.NewClient(cfg)
+		if err != nil {
+			logger.Error("Failed to create client", "err", err)
+			return err
+		}
+		clients[i] = client
+	}
+
+	recorder, err := NewTxRecorder(cfg.OutputTxs)
+	if err != nil {
+		logger.Error("Failed to create output file", "err", err)
+		return err
+	}
+	for i := 0; i < cfg.Count; i++ {
+		stream := i % streams
+		tx, err := clients[stream].GenerateTx()
+		if err != nil {
+			_ = recorder.Close()
+			logger.Error("Failed to generate transaction", "stream", stream, "err", err)
+			return err
+		}
+		if err := recorder.Record(stream, tx); err != nil {
+			_ = recorder.Close()
+			logger.Error("Failed to write transaction", "err", err)
+			return err
+		}
+	}
+	if err := recorder.Close(); err != nil {
+		logger.Error("Failed to write transactions", "err", err)
+		return err
+	}
+	logger.Info("Wrote transactions without broadcasting them", "file", cfg.OutputTxs, "txs", cfg.Count, "streams", streams)
+	return nil
+}
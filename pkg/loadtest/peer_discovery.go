@@ -0,0 +1,140 @@
+package loadtest
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/1119-Labs/perpx-load-test/internal/logging"
+)
+
+// How long to wait for a discovered peer's RPC endpoint to respond before
+// considering it unreachable.
+const peerProbeTimeout = 5 * time.Second
+
+// The default CometBFT RPC port, assumed for peers that don't advertise
+// their RPC address.
+const defaultRPCPort = "26657"
+
+// ExpandPeerEndpoints replaces the single configured (bootstrap) endpoint with
+// it and the endpoints of its reachable peers, if DiscoverPeers is set (see
+// DiscoverPeerEndpoints). It must be called once the endpoints have been
+// resolved, and before the target TPS is applied.
+func (c *Config) ExpandPeerEndpoints(logger logging.Logger) error {
+	if !c.DiscoverPeers {
+		return nil
+	}
+	if len(c.Endpoints) != 1 {
+		return fmt.Errorf("discover-peers expands a single bootstrap endpoint, but got %d endpoints", len(c.Endpoints))
+	}
+	endpoints, err := DiscoverPeerEndpoints(c.Endpoints[0], logger)
+	if err != nil {
+		return err
+	}
+	c.Endpoints = endpoints
+	return nil
+}
+
+// DiscoverPeerEndpoints queries the bootstrap endpoint's node for its peers
+// (via its net_info RPC API), and returns the bootstrap endpoint followed by
+// the endpoints of the peers whose RPC API is reachable from here and serves
+// the same network. Each peer's endpoint is made up of the IP address from
+// which the bootstrap node sees it and the port of the RPC address it
+// advertises, with the bootstrap endpoint's scheme and path. Peers that can't
+// be reached (e.g. because their RPC API only listens on localhost, or they
+// are on a private network) are skipped and logged.
+func DiscoverPeerEndpoints(bootstrap string, logger logging.Logger) ([]string, error) {
+	u, err := url.Parse(bootstrap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse bootstrap endpoint %s: %w", bootstrap, err)
+	}
+	client := newHttpRpcClient(rpcURLFromEndpoint(bootstrap))
+	client.client.Timeout = peerProbeTimeout
+	status, err := client.status()
+	if err != nil {
+		return nil, fmt.Errorf("failed to query bootstrap endpoint %s: %w", bootstrap, err)
+	}
+	netInfo, err := client.netInfo()
+	if err != nil {
+		return nil, fmt.Errorf("failed to query the peers of bootstrap endpoint %s: %w", bootstrap, err)
+	}
+	logger.Info("Discovering peer endpoints", "bootstrap", bootstrap, "peers", len(netInfo.Peers))
+
+	endpoints := []string{bootstrap}
+	seen := map[string]bool{bootstrap: true}
+	var candidates []string
+	for _, peer := range netInfo.Peers {
+		endpoint, err := peerEndpoint(u, peer)
+		if err != nil {
+			logger.Info("Skipping peer", "moniker", peer.NodeInfo.Moniker, "reason", err)
+			continue
+		}
+		if !seen[endpoint] {
+			seen[endpoint] = true
+			candidates = append(candidates, endpoint)
+		}
+	}
+
+	// probe the candidates concurrently, but keep them in the node's order
+	reachable := make([]bool, len(candidates))
+	var wg sync.WaitGroup
+	for i, endpoint := range candidates {
+		wg.Add(1)
+		go func(i int, endpoint string) {
+			defer wg.Done()
+			if err := probePeer(endpoint, status.NodeInfo.Network); err != nil {
+				logger.Info("Skipping unreachable peer", "endpoint", endpoint, "reason", err)
+				return
+			}
+			reachable[i] = true
+		}(i, endpoint)
+	}
+	wg.Wait()
+	for i, endpoint := range candidates {
+		if reachable[i] {
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+	logger.Info("Discovered peer endpoints", "reachable", len(endpoints)-1, "skipped", len(netInfo.Peers)-(len(endpoints)-1))
+	return endpoints, nil
+}
+
+// peerEndpoint derives the endpoint of the given peer's RPC API from the
+// bootstrap endpoint u, or returns why the peer has no usable one.
+func peerEndpoint(u *url.URL, peer Peer) (string, error) {
+	ip := net.ParseIP(peer.RemoteIP)
+	if ip == nil || ip.IsUnspecified() {
+		return "", fmt.Errorf("invalid remote IP %q", peer.RemoteIP)
+	}
+	port := defaultRPCPort
+	if rpcAddr := peer.NodeInfo.Other.RPCAddress; len(rpcAddr) > 0 {
+		rpcURL, err := url.Parse(rpcAddr)
+		if err != nil || len(rpcURL.Port()) == 0 {
+			return "", fmt.Errorf("invalid RPC address %q", rpcAddr)
+		}
+		if rpcIP := net.ParseIP(rpcURL.Hostname()); (rpcIP != nil && rpcIP.IsLoopback()) || rpcURL.Hostname() == "localhost" {
+			return "", fmt.Errorf("RPC API only listens on %s", rpcURL.Host)
+		}
+		port = rpcURL.Port()
+	}
+	endpoint := *u
+	endpoint.Host = net.JoinHostPort(ip.String(), port)
+	return endpoint.String(), nil
+}
+
+// probePeer checks that the RPC API behind the given endpoint responds, and
+// serves the given network.
+func probePeer(endpoint, network string) error {
+	client := newHttpRpcClient(rpcURLFromEndpoint(endpoint))
+	client.client.Timeout = peerProbeTimeout
+	status, err := client.status()
+	if err != nil {
+		return err
+	}
+	if status.NodeInfo.Network != network {
+		return fmt.Errorf("serves network %q rather than %q", status.NodeInfo.Network, network)
+	}
+	return nil
+}
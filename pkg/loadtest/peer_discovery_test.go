@@ -0,0 +1,78 @@
+package loadtest_test
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/1119-Labs/perpx-load-test/internal/logging"
+	"github.com/1119-Labs/perpx-load-test/pkg/loadtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// nodeHandler serves the status and net_info RPC APIs of a node on the given
+// network with the given peers.
+func nodeHandler(network string, peers func() []map[string]interface{}) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var result interface{}
+		switch r.URL.Path {
+		case "/status":
+			result = map[string]interface{}{"node_info": map[string]interface{}{"network": network}}
+		case "/net_info":
+			result = map[string]interface{}{"n_peers": "0", "peers": peers()}
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		raw, _ := json.Marshal(result)
+		_ = json.NewEncoder(w).Encode(loadtest.RPCResponse{JSONRPC: "2.0", Result: raw})
+	})
+}
+
+func newPeer(remoteIP, rpcAddr string) map[string]interface{} {
+	return map[string]interface{}{
+		"remote_ip": remoteIP,
+		"node_info": map[string]interface{}{"other": map[string]interface{}{"rpc_address": rpcAddr}},
+	}
+}
+
+func serverPort(server *httptest.Server) string {
+	_, port, _ := net.SplitHostPort(strings.TrimPrefix(server.URL, "http://"))
+	return port
+}
+
+func TestDiscoverPeerEndpoints(t *testing.T) {
+	noPeers := func() []map[string]interface{} { return nil }
+	reachable := httptest.NewServer(nodeHandler("perpx-1", noPeers))
+	defer reachable.Close()
+	otherNetwork := httptest.NewServer(nodeHandler("perpx-2", noPeers))
+	defer otherNetwork.Close()
+	gone := httptest.NewServer(nodeHandler("perpx-1", noPeers))
+	gone.Close()
+
+	var bootstrapPort string
+	bootstrap := httptest.NewServer(nodeHandler("perpx-1", func() []map[string]interface{} {
+		return []map[string]interface{}{
+			newPeer("127.0.0.1", "tcp://0.0.0.0:"+serverPort(reachable)),
+			newPeer("127.0.0.1", "tcp://0.0.0.0:"+serverPort(otherNetwork)),
+			newPeer("10.0.0.5", "tcp://127.0.0.1:26657"),
+			newPeer("127.0.0.1", "tcp://0.0.0.0:"+serverPort(gone)),
+			newPeer("127.0.0.1", "tcp://0.0.0.0:"+bootstrapPort),
+			newPeer("", "tcp://0.0.0.0:26657"),
+		}
+	}))
+	defer bootstrap.Close()
+	bootstrapPort = serverPort(bootstrap)
+
+	endpoint := "ws://127.0.0.1:" + bootstrapPort + "/websocket"
+	endpoints, err := loadtest.DiscoverPeerEndpoints(endpoint, logging.NewNoopLogger())
+	require.NoError(t, err)
+	assert.Equal(t, []string{endpoint, "ws://127.0.0.1:" + serverPort(reachable) + "/websocket"}, endpoints)
+
+	cfg := loadtest.Config{DiscoverPeers: true, Endpoints: []string{endpoint, "ws://node2:26657/websocket"}}
+	assert.Error(t, cfg.ExpandPeerEndpoints(logging.NewNoopLogger()))
+}
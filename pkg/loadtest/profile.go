@@ -0,0 +1,62 @@
+package loadtest
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/pprof"
+
+	"github.com/1119-Labs/perpx-load-test/internal/logging"
+)
+
+// startProfiling starts writing a CPU profile of the load test to
+// cfg.CPUProfile, if set. It returns a function to call once the load test is
+// done, which stops the CPU profile and writes a heap profile to
+// cfg.MemProfile, if set.
+func startProfiling(cfg Config, logger logging.Logger) (func(), error) {
+	var cpuFile *os.File
+	if len(cfg.CPUProfile) > 0 {
+		f, err := os.Create(cfg.CPUProfile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create CPU profile: %w", err)
+		}
+		if err := pprof.StartCPUProfile(f); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("failed to start CPU profile: %w", err)
+		}
+		cpuFile = f
+	}
+	return func() {
+		if cpuFile != nil {
+			pprof.StopCPUProfile()
+			if err := cpuFile.Close(); err != nil {
+				logger.Error("Failed to write CPU profile", "file", cfg.CPUProfile, "err", err)
+			} else {
+				logger.Info("Wrote CPU profile", "file", cfg.CPUProfile)
+			}
+		}
+		if len(cfg.MemProfile) > 0 {
+			if err := writeHeapProfile(cfg.MemProfile); err != nil {
+				logger.Error("Failed to write memory profile", "file", cfg.MemProfile, "err", err)
+			} else {
+				logger.Info("Wrote memory profile", "file", cfg.MemProfile)
+			}
+		}
+	}, nil
+}
+
+// writeHeapProfile writes a heap profile to the given file.
+func writeHeapProfile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	// collect garbage first, so that the profile's in-use figures are up to
+	// date
+	runtime.GC()
+	if err := pprof.WriteHeapProfile(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
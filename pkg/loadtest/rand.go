@@ -2,7 +2,12 @@ package loadtest
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
 	"fmt"
+	"time"
+
+	"github.com/1119-Labs/perpx-load-test/internal/logging"
 )
 
 const (
@@ -31,3 +36,24 @@ func randStr(length int) string {
 
 	return string(chars)
 }
+
+// ResolveRandomSeed generates a random seed from the current time if none is
+// configured, and logs it so that the run can be reproduced. It must be called
+// before the configuration is sent to any workers, so that they all derive
+// their PRNGs from the same seed.
+func (c *Config) ResolveRandomSeed(logger logging.Logger) {
+	if c.RandomSeed != 0 {
+		return
+	}
+	c.RandomSeed = time.Now().UnixNano()
+	logger.Info("Generated random seed (use --random-seed to reproduce the same load)", "seed", c.RandomSeed)
+}
+
+// DeriveSeed derives the seed of one of a run's PRNGs from the run's random
+// seed, the kind of PRNG (e.g. "transactor") and the ID of its owner (e.g. the
+// transactor's), so that every PRNG draws its own sequence, but the same one
+// in every run with the same random seed.
+func DeriveSeed(seed int64, kind string, id int) int64 {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%d/%s/%d", seed, kind, id)))
+	return int64(binary.BigEndian.Uint64(sum[:8]))
+}
@@ -0,0 +1,206 @@
+package loadtest
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// A transaction recording (written with --record and read by the replay
+// command) consists of the 8-byte header "PXLTREC" followed by the format
+// version (currently 1), and then one entry per transaction, in the order in
+// which the transactions were sent:
+//
+//	uvarint  stream ID (the transactor that sent the transaction)
+//	uvarint  offset in microseconds since the recording started
+//	uvarint  length of the transaction in bytes
+//	[]byte   the raw signed transaction, exactly as broadcast
+//
+// Transactions within a stream must be replayed in order (e.g. because they
+// carry consecutive account sequence numbers), whereas separate streams are
+// independent of one another. The offsets are informational: replays send
+// each stream at the configured rate rather than at the recorded times.
+const (
+	recordMagic   = "PXLTREC"
+	recordVersion = 1
+
+	// Guards against allocating absurd amounts of memory when reading a
+	// corrupt recording.
+	maxRecordedTxSize = 16 * 1024 * 1024
+)
+
+// RecordedTx is a single transaction read from a recording.
+type RecordedTx struct {
+	Stream int           // The stream (i.e. transactor) that sent the transaction.
+	Offset time.Duration // When the transaction was sent, relative to the start of the recording.
+	Tx     []byte        // The raw transaction bytes.
+}
+
+// TxRecorder writes the transactions sent by a group of transactors to a
+// recording. It is safe for concurrent use.
+type TxRecorder struct {
+	mtx       sync.Mutex
+	f         *os.File
+	w         *bufio.Writer
+	startTime time.Time
+	buf       [3 * binary.MaxVarintLen64]byte
+}
+
+// NewTxRecorder creates (or truncates) the recording at the given path.
+func NewTxRecorder(filename string) (*TxRecorder, error) {
+	f, err := os.Create(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create transaction recording: %w", err)
+	}
+	w := bufio.NewWriter(f)
+	if _, err := w.WriteString(recordMagic); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	if err := w.WriteByte(recordVersion); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return &TxRecorder{f: f, w: w, startTime: time.Now()}, nil
+}
+
+// Record appends the given transaction, sent by the given stream, to the
+// recording.
+func (r *TxRecorder) Record(stream int, tx []byte) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	n := binary.PutUvarint(r.buf[:], uint64(stream))
+	n += binary.PutUvarint(r.buf[n:], uint64(time.Since(r.startTime)/time.Microsecond))
+	n += binary.PutUvarint(r.buf[n:], uint64(len(tx)))
+	if _, err := r.w.Write(r.buf[:n]); err != nil {
+		return fmt.Errorf("failed to write to transaction recording: %w", err)
+	}
+	if _, err := r.w.Write(tx); err != nil {
+		return fmt.Errorf("failed to write to transaction recording: %w", err)
+	}
+	return nil
+}
+
+// Close flushes and closes the recording.
+func (r *TxRecorder) Close() error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	if err := r.w.Flush(); err != nil {
+		_ = r.f.Close()
+		return fmt.Errorf("failed to write to transaction recording: %w", err)
+	}
+	return r.f.Close()
+}
+
+// TxRecordReader reads transactions from a recording.
+type TxRecordReader struct {
+	r *bufio.Reader
+}
+
+// NewTxRecordReader checks the recording's header and returns a reader for
+// its transactions.
+func NewTxRecordReader(r io.Reader) (*TxRecordReader, error) {
+	br := bufio.NewReader(r)
+	header := make([]byte, len(recordMagic)+1)
+	if _, err := io.ReadFull(br, header); err != nil || string(header[:len(recordMagic)]) != recordMagic {
+		return nil, fmt.Errorf("not a transaction recording")
+	}
+	if v := header[len(recordMagic)]; v != recordVersion {
+		return nil, fmt.Errorf("unsupported transaction recording version %d (expected %d)", v, recordVersion)
+	}
+	return &TxRecordReader{r: br}, nil
+}
+
+// Next returns the next transaction in the recording, or io.EOF once there
+// are no more.
+func (r *TxRecordReader) Next() (RecordedTx, error) {
+	stream, err := binary.ReadUvarint(r.r)
+	if err != nil {
+		// a clean end of the recording
+		return RecordedTx{}, err
+	}
+	offset, err := binary.ReadUvarint(r.r)
+	if err != nil {
+		return RecordedTx{}, truncatedRecording(err)
+	}
+	size, err := binary.ReadUvarint(r.r)
+	if err != nil {
+		return RecordedTx{}, truncatedRecording(err)
+	}
+	if size > maxRecordedTxSize {
+		return RecordedTx{}, fmt.Errorf("corrupt transaction recording: transaction of %d bytes", size)
+	}
+	tx := make([]byte, size)
+	if _, err := io.ReadFull(r.r, tx); err != nil {
+		return RecordedTx{}, truncatedRecording(err)
+	}
+	return RecordedTx{
+		Stream: int(stream),
+		Offset: time.Duration(offset) * time.Microsecond,
+		Tx:     tx,
+	}, nil
+}
+
+func truncatedRecording(err error) error {
+	if errors.Is(err, io.EOF) {
+		err = io.ErrUnexpectedEOF
+	}
+	return fmt.Errorf("truncated transaction recording: %w", err)
+}
+
+// ReadTxStreams reads the recording at the given path, returning the
+// transactions of each of its streams (in the order in which they were sent),
+// ordered by stream ID.
+func ReadTxStreams(filename string) ([][][]byte, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open transaction recording: %w", err)
+	}
+	defer f.Close()
+	reader, err := NewTxRecordReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	byStream := make(map[int][][]byte)
+	for {
+		rtx, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", filename, err)
+		}
+		byStream[rtx.Stream] = append(byStream[rtx.Stream], rtx.Tx)
+	}
+	ids := make([]int, 0, len(byStream))
+	for id := range byStream {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	streams := make([][][]byte, len(ids))
+	for i, id := range ids {
+		streams[i] = byStream[id]
+	}
+	return streams, nil
+}
+
+// replayClient generates the transactions of a single recorded stream.
+type replayClient struct {
+	txs  [][]byte
+	next int
+}
+
+func (c *replayClient) GenerateTx() ([]byte, error) {
+	if c.next >= len(c.txs) {
+		return nil, ErrClientExhausted
+	}
+	tx := c.txs[c.next]
+	c.next++
+	return tx, nil
+}
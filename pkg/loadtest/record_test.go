@@ -0,0 +1,173 @@
+package loadtest_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/1119-Labs/perpx-load-test/pkg/loadtest"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxRecordingRoundTrip(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "txs.bin")
+	r, err := loadtest.NewTxRecorder(filename)
+	require.NoError(t, err)
+	require.NoError(t, r.Record(1, []byte("b1")))
+	require.NoError(t, r.Record(0, []byte("a1")))
+	require.NoError(t, r.Record(1, []byte("b2")))
+	require.NoError(t, r.Record(0, []byte{}))
+	require.NoError(t, r.Close())
+
+	f, err := os.Open(filename)
+	require.NoError(t, err)
+	defer f.Close()
+	reader, err := loadtest.NewTxRecordReader(f)
+	require.NoError(t, err)
+	first, err := reader.Next()
+	require.NoError(t, err)
+	assert.Equal(t, 1, first.Stream)
+	assert.Equal(t, []byte("b1"), first.Tx)
+
+	streams, err := loadtest.ReadTxStreams(filename)
+	require.NoError(t, err)
+	assert.Equal(t, [][][]byte{
+		{[]byte("a1"), {}},
+		{[]byte("b1"), []byte("b2")},
+	}, streams)
+}
+
+func TestTxRecordReaderRejectsInvalidRecordings(t *testing.T) {
+	_, err := loadtest.NewTxRecordReader(strings.NewReader("not a recording"))
+	assert.EqualError(t, err, "not a transaction recording")
+
+	_, err = loadtest.NewTxRecordReader(strings.NewReader("PXLTREC\x02"))
+	assert.EqualError(t, err, "unsupported transaction recording version 2 (expected 1)")
+
+	// a transaction that claims to be longer than the data that follows
+	reader, err := loadtest.NewTxRecordReader(strings.NewReader("PXLTREC\x01\x00\x00\x05abc"))
+	require.NoError(t, err)
+	_, err = reader.Next()
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+}
+
+// txCapturingServer accepts broadcast_tx requests over WebSockets and records
+// the raw transactions it receives.
+func txCapturingServer(t *testing.T) (endpoint string, txs func() [][]byte) {
+	var (
+		mtx      sync.Mutex
+		received [][]byte
+	)
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			var req loadtest.RPCRequest
+			if err := conn.ReadJSON(&req); err != nil {
+				return
+			}
+			var params struct {
+				Tx string `json:"tx"`
+			}
+			if err := json.Unmarshal(req.Params, &params); err != nil {
+				return
+			}
+			tx, err := base64.StdEncoding.DecodeString(params.Tx)
+			if err != nil {
+				return
+			}
+			mtx.Lock()
+			received = append(received, tx)
+			mtx.Unlock()
+		}
+	}))
+	t.Cleanup(server.Close)
+	return "ws://" + strings.TrimPrefix(server.URL, "http://") + "/websocket", func() [][]byte {
+		mtx.Lock()
+		defer mtx.Unlock()
+		return append([][]byte(nil), received...)
+	}
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "txs.bin")
+	endpoint, recorded := txCapturingServer(t)
+	cfg := loadtest.Config{
+		ClientFactory:     "kvstore",
+		Connections:       2,
+		Rate:              5,
+		Size:              40,
+		Count:             10,
+		SendPeriod:        1,
+		BroadcastTxMethod: "async",
+		Endpoints:         []string{endpoint},
+		Record:            filename,
+	}
+	tg := loadtest.NewTransactorGroup()
+	require.NoError(t, tg.AddAll(&cfg))
+	tg.Start()
+	require.NoError(t, tg.Wait())
+
+	endpoint, replayed := txCapturingServer(t)
+	cfg = loadtest.Config{
+		ClientFactory:     "kvstore",
+		Connections:       1,
+		Rate:              3,
+		SendPeriod:        1,
+		BroadcastTxMethod: "async",
+		Endpoints:         []string{endpoint},
+		ReplayFile:        filename,
+	}
+	tg = loadtest.NewTransactorGroup()
+	require.NoError(t, tg.AddAll(&cfg))
+	tg.Start()
+	require.NoError(t, tg.Wait())
+
+	// streams are replayed concurrently, so only their contents must match
+	sortTxs := func(txs [][]byte) [][]byte {
+		sort.Slice(txs, func(i, j int) bool { return bytes.Compare(txs[i], txs[j]) < 0 })
+		return txs
+	}
+	require.Len(t, recorded(), 10)
+	assert.Equal(t, sortTxs(recorded()), sortTxs(replayed()))
+	assert.Equal(t, 10, tg.Report().TotalTxs)
+}
+
+func TestExecuteOutputTxs(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "txs.bin")
+	cfg := loadtest.Config{
+		ClientFactory: "kvstore",
+		Connections:   2,
+		Count:         5,
+		Size:          40,
+		Endpoints:     []string{"ws://localhost:26657/websocket"},
+		OutputTxs:     filename,
+	}
+	require.NoError(t, loadtest.ExecuteOutputTxs(cfg))
+
+	streams, err := loadtest.ReadTxStreams(filename)
+	require.NoError(t, err)
+	require.Len(t, streams, 2)
+	assert.Len(t, streams[0], 3)
+	assert.Len(t, streams[1], 2)
+	for _, stream := range streams {
+		for _, tx := range stream {
+			assert.Len(t, tx, 40)
+		}
+	}
+}
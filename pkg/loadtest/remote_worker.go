@@ -23,6 +23,7 @@ type remoteWorker struct {
 	mtx           sync.RWMutex
 	id            string
 	txCount       int
+	count         int // The worker's share of the total transaction count (-1 if unlimited).
 	state         workerState
 	logger        logging.Logger
 	stateMetric   prometheus.Gauge // A numeric representation of the state variable.
@@ -159,6 +160,7 @@ func (rw *remoteWorker) registerRemoteWorker() error {
 		return err
 	}
 	cfg := rw.coord.config()
+	cfg.Count = rw.getCount()
 	// tell the worker it's been accepted and give it its configuration
 	return rw.sock.WriteWorkerMsg(workerMsg{
 		ID:     rw.id,
@@ -273,6 +275,18 @@ func (rw *remoteWorker) TxCount() int {
 	return rw.txCount
 }
 
+func (rw *remoteWorker) setCount(count int) {
+	rw.mtx.Lock()
+	rw.count = count
+	rw.mtx.Unlock()
+}
+
+func (rw *remoteWorker) getCount() int {
+	rw.mtx.RLock()
+	defer rw.mtx.RUnlock()
+	return rw.count
+}
+
 func (rw *remoteWorker) getState() workerState {
 	rw.mtx.RLock()
 	defer rw.mtx.RUnlock()
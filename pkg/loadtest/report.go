@@ -0,0 +1,161 @@
+package loadtest
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"sort"
+	"time"
+)
+
+// ReportVersion is the version of the Report format. It will be incremented
+// whenever a change is made to the format that is not backwards-compatible
+// (i.e. fields are removed, renamed or change meaning).
+const ReportVersion = 1
+
+// Possible values of Report.StopReason. When both --time and --count are
+// set, the load test stops at whichever limit is reached first.
+const (
+	StopReasonTimeLimit   = "time_limit"   // The --time limit was reached.
+	StopReasonCountLimit  = "count_limit"  // The --count limit was reached.
+	StopReasonFeeBudget   = "fee_budget"   // The --fee-budget was used up.
+	StopReasonInterrupted = "interrupted"  // The load test was interrupted (e.g. by Ctrl+C) and drained.
+	StopReasonChainHalted = "chain_halted" // The chain stopped producing blocks, with --stop-on-halt.
+)
+
+// Report is a machine-readable summary of a load test run, written to the file
+// given by the --report-json flag. It is intended to be archived and compared
+// between runs (e.g. in CI), so its JSON representation must remain stable.
+type Report struct {
+	Version          int              `json:"version"`                     // The version of the report format (see ReportVersion).
+	DurationSeconds  float64          `json:"duration_seconds"`            // The time from when the transactors started until the report was generated.
+	StopReason       string           `json:"stop_reason"`                 // Why the load test stopped (one of the StopReason* constants).
+	RandomSeed       int64            `json:"random_seed,omitempty"`       // The seed from which the run's pseudo-random choices were derived (see --random-seed).
+	TotalTxs         int              `json:"total_txs"`                   // The total number of transactions sent.
+	TotalBytes       int64            `json:"total_bytes"`                 // The cumulative number of bytes sent as transactions.
+	AvgTxRate        float64          `json:"avg_tx_rate"`                 // The average rate at which transactions were sent (tx/sec).
+	PeakTxRate       float64          `json:"peak_tx_rate"`                // The highest rate at which transactions were sent over a single progress interval (tx/sec).
+	AvgDataRate      float64          `json:"avg_data_rate"`               // The average rate at which transaction data was sent (bytes/sec).
+	AvgDataMbps      float64          `json:"avg_data_mbps"`               // AvgDataRate in megabits per second, for comparison with network bandwidth.
+	AvgTxSize        float64          `json:"avg_tx_size"`                 // The average size of each transaction (bytes/tx).
+	MinTxSize        int              `json:"min_tx_size"`                 // The size of the smallest transaction sent (bytes).
+	MaxTxSize        int              `json:"max_tx_size"`                 // The size of the largest transaction sent (bytes).
+	TxErrors         int              `json:"tx_errors"`                   // The number of broadcast requests that were rejected.
+	MempoolFull      int              `json:"mempool_full"`                // The number of broadcast requests rejected because the mempool was full (also counted in TxErrors).
+	ErrorCategories  map[string]int   `json:"error_categories"`            // The number of rejected broadcast requests, by error category.
+	FeesSpent        string           `json:"fees_spent,omitempty"`        // The total fees of the transactions generated, if the client factory tracks them.
+	Injected         int              `json:"injected,omitempty"`          // The number of deliberately invalid transactions sent (see --inject-failures), which are not included in the other statistics.
+	InjectedRejected int              `json:"injected_rejected,omitempty"` // The number of injected transactions that were rejected, as intended.
+	ChainHalts       int              `json:"chain_halts,omitempty"`       // The number of times the chain appeared to halt (see --halt-timeout).
+	Endpoints        []EndpointReport `json:"endpoints"`                   // Per-endpoint breakdown, in the order in which the endpoints were connected.
+	Confirm          *ConfirmReport   `json:"confirm,omitempty"`           // Only present if transaction confirmation was enabled.
+	FirstCommit      *LatencyReport   `json:"first_commit,omitempty"`      // The time from each account's first broadcast to its first commit. Only present in cold start mode (see --cold-start).
+	Accounts         *AccountsReport  `json:"accounts,omitempty"`          // The accounts touched by the load test. Only present if the client factory tracks them, or transaction confirmation was enabled.
+}
+
+// EndpointReport summarizes the load sent to a single endpoint.
+type EndpointReport struct {
+	Endpoint    string  `json:"endpoint"`     // The WebSockets URL of the endpoint.
+	Connections int     `json:"connections"`  // The number of connections made to the endpoint.
+	TotalTxs    int     `json:"total_txs"`    // The total number of transactions sent to the endpoint.
+	TotalBytes  int64   `json:"total_bytes"`  // The cumulative number of bytes sent to the endpoint as transactions.
+	AvgTxRate   float64 `json:"avg_tx_rate"`  // The average rate at which transactions were sent to the endpoint (tx/sec).
+	TxErrors    int     `json:"tx_errors"`    // The number of broadcast requests rejected by the endpoint.
+	MempoolFull int     `json:"mempool_full"` // The number of broadcast requests rejected by the endpoint because its mempool was full.
+}
+
+// ConfirmReport summarizes the outcomes of the transactions sampled for
+// confirmation.
+type ConfirmReport struct {
+	Sampled           int     `json:"sampled"`             // The number of submitted transactions queued for confirmation.
+	Committed         int     `json:"committed"`           // Sampled transactions committed successfully.
+	Failed            int     `json:"failed"`              // Sampled transactions committed with a non-zero result code.
+	Missing           int     `json:"missing"`             // Sampled transactions not found in a block before the confirmation timeout.
+	Dropped           int     `json:"dropped"`             // Samples dropped because the confirmation queue was full.
+	Expired           int     `json:"expired"`             // Missing samples that expired because their timeout height passed (also counted in Missing).
+	CommitSuccessRate float64 `json:"commit_success_rate"` // The fraction of resolved samples that were committed successfully.
+
+	MinHeight int64 `json:"min_height,omitempty"` // The lowest block height in which a sampled transaction was found.
+	MaxHeight int64 `json:"max_height,omitempty"` // The highest block height in which a sampled transaction was found.
+	Blocks    int   `json:"blocks"`               // The number of distinct blocks in which sampled transactions were found.
+}
+
+// AccountsReport summarizes the accounts touched by the load test, for state
+// growth analysis.
+type AccountsReport struct {
+	Senders    int  `json:"senders"`           // Distinct accounts that sent funds (including the transactions' signers), as generated.
+	Recipients int  `json:"recipients"`        // Distinct accounts that were sent funds, as generated.
+	Touched    int  `json:"touched"`           // Distinct accounts that sent or were sent funds, as generated.
+	Created    *int `json:"created,omitempty"` // How many accounts the chain gained during the run. Only present if transaction confirmation was enabled, and the chain's accounts could be counted.
+}
+
+// LatencyReport summarizes a distribution of latencies, such as those of the
+// accounts' first commits in cold start mode.
+type LatencyReport struct {
+	Accounts  int     `json:"accounts"`  // The number of accounts that sent any transactions.
+	Committed int     `json:"committed"` // The number of accounts of which a transaction was confirmed as committed, whose latencies make up the distribution.
+	MinMs     float64 `json:"min_ms"`
+	AvgMs     float64 `json:"avg_ms"`
+	P50Ms     float64 `json:"p50_ms"`
+	P90Ms     float64 `json:"p90_ms"`
+	P99Ms     float64 `json:"p99_ms"`
+	MaxMs     float64 `json:"max_ms"`
+}
+
+// NewLatencyReport summarizes the given latencies of the given number of
+// accounts, using nearest-rank percentiles.
+func NewLatencyReport(accounts int, latencies []time.Duration) *LatencyReport {
+	r := &LatencyReport{Accounts: accounts, Committed: len(latencies)}
+	if len(latencies) == 0 {
+		return r
+	}
+	sorted := append([]time.Duration(nil), latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	ms := func(d time.Duration) float64 { return float64(d) / float64(time.Millisecond) }
+	percentile := func(p float64) float64 {
+		return ms(sorted[int(math.Ceil(p*float64(len(sorted))))-1])
+	}
+	var total time.Duration
+	for _, d := range sorted {
+		total += d
+	}
+	r.MinMs = ms(sorted[0])
+	r.AvgMs = ms(total / time.Duration(len(sorted)))
+	r.P50Ms = percentile(0.5)
+	r.P90Ms = percentile(0.9)
+	r.P99Ms = percentile(0.99)
+	r.MaxMs = ms(sorted[len(sorted)-1])
+	return r
+}
+
+// Compute fills in the report's derived statistics.
+func (r *Report) Compute() {
+	r.AvgTxRate = 0
+	r.AvgDataRate = 0
+	r.AvgTxSize = 0
+	if r.DurationSeconds > 0 {
+		r.AvgTxRate = float64(r.TotalTxs) / r.DurationSeconds
+		r.AvgDataRate = float64(r.TotalBytes) / r.DurationSeconds
+	}
+	r.AvgDataMbps = r.AvgDataRate * 8 / 1e6
+	if r.TotalTxs > 0 {
+		r.AvgTxSize = float64(r.TotalBytes) / float64(r.TotalTxs)
+	}
+	// runs shorter than a single progress interval won't have had their peak
+	// rate sampled
+	if r.PeakTxRate < r.AvgTxRate {
+		r.PeakTxRate = r.AvgTxRate
+	}
+}
+
+func writeReport(filename string, r interface{}) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
@@ -0,0 +1,76 @@
+package loadtest_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/1119-Labs/perpx-load-test/pkg/loadtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportCompute(t *testing.T) {
+	r := loadtest.Report{
+		DurationSeconds: 10,
+		TotalTxs:        1000,
+		TotalBytes:      250000,
+		PeakTxRate:      50, // not sampled over a full interval
+	}
+	r.Compute()
+	assert.Equal(t, 100.0, r.AvgTxRate)
+	assert.Equal(t, 25000.0, r.AvgDataRate)
+	assert.Equal(t, 0.2, r.AvgDataMbps)
+	assert.Equal(t, 250.0, r.AvgTxSize)
+	assert.Equal(t, 100.0, r.PeakTxRate)
+
+	r.PeakTxRate = 150
+	r.Compute()
+	assert.Equal(t, 150.0, r.PeakTxRate)
+}
+
+func TestReportJSON(t *testing.T) {
+	r := loadtest.Report{
+		Version:         loadtest.ReportVersion,
+		TotalTxs:        10,
+		ErrorCategories: map[string]int{"rpc: mempool is full": 2},
+		Endpoints: []loadtest.EndpointReport{
+			{Endpoint: "ws://localhost:26657/websocket", Connections: 2, TotalTxs: 10, TxErrors: 2},
+		},
+	}
+	b, err := json.Marshal(r)
+	require.NoError(t, err)
+
+	var fields map[string]interface{}
+	require.NoError(t, json.Unmarshal(b, &fields))
+	assert.Equal(t, float64(loadtest.ReportVersion), fields["version"])
+	assert.Equal(t, float64(10), fields["total_txs"])
+	assert.NotContains(t, fields, "confirm")
+	assert.NotContains(t, fields, "first_commit")
+
+	var decoded loadtest.Report
+	require.NoError(t, json.Unmarshal(b, &decoded))
+	assert.Equal(t, r, decoded)
+}
+
+func TestLatencyReport(t *testing.T) {
+	var latencies []time.Duration
+	// 100 accounts, committed after 100ms, 200ms, ..., 10s (in no
+	// particular order)
+	for i := 100; i >= 1; i-- {
+		latencies = append(latencies, time.Duration(i)*100*time.Millisecond)
+	}
+	r := loadtest.NewLatencyReport(120, latencies)
+	assert.Equal(t, 120, r.Accounts)
+	assert.Equal(t, 100, r.Committed)
+	assert.Equal(t, 100.0, r.MinMs)
+	assert.Equal(t, 5050.0, r.AvgMs)
+	assert.Equal(t, 5000.0, r.P50Ms)
+	assert.Equal(t, 9000.0, r.P90Ms)
+	assert.Equal(t, 9900.0, r.P99Ms)
+	assert.Equal(t, 10000.0, r.MaxMs)
+	assert.Equal(t, 100*time.Millisecond, latencies[99], "the latencies mustn't be reordered")
+
+	empty := loadtest.NewLatencyReport(3, nil)
+	assert.Equal(t, &loadtest.LatencyReport{Accounts: 3}, empty)
+}
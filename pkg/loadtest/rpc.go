@@ -120,6 +120,86 @@ type RPCError struct {
 	Data    string `json:"data,omitempty"`
 }
 
+// ResultBroadcastTx corresponds to the JSON-RPC response format produced by
+// the CometBFT v0.38.x broadcast_tx_sync and broadcast_tx_async RPC APIs.
+type ResultBroadcastTx struct {
+	Code      uint32   `json:"code"`
+	Log       string   `json:"log"`
+	Codespace string   `json:"codespace"`
+	Hash      HexBytes `json:"hash"`
+}
+
+// ResultBroadcastTxCommit corresponds to the JSON-RPC response format produced
+// by the CometBFT v0.38.x broadcast_tx_commit RPC API.
+type ResultBroadcastTxCommit struct {
+	CheckTx  TxResult     `json:"check_tx"`
+	TxResult TxResult     `json:"tx_result"`
+	Hash     HexBytes     `json:"hash"`
+	Height   JSONStrInt64 `json:"height"`
+}
+
+// TxResult is the subset of the CometBFT CheckTx/ExecTx result that we care
+// about when accounting for transaction outcomes.
+type TxResult struct {
+	Code      uint32       `json:"code"`
+	Log       string       `json:"log"`
+	Codespace string       `json:"codespace"`
+	GasWanted JSONStrInt64 `json:"gas_wanted"`
+	GasUsed   JSONStrInt64 `json:"gas_used"`
+}
+
+// ResultStatus is the subset of the JSON-RPC response format produced by the
+// CometBFT v0.38.x status RPC API that we care about.
+type ResultStatus struct {
+	NodeInfo DefaultNodeInfo `json:"node_info"`
+	SyncInfo SyncInfo        `json:"sync_info"`
+}
+
+// SyncInfo describes the latest block known to a node.
+type SyncInfo struct {
+	LatestBlockHeight JSONStrInt64 `json:"latest_block_height"`
+	LatestBlockTime   time.Time    `json:"latest_block_time"`
+}
+
+// ResultBlockchainInfo is the subset of the JSON-RPC response format produced
+// by the CometBFT v0.38.x blockchain RPC API that we care about.
+type ResultBlockchainInfo struct {
+	LastHeight JSONStrInt64 `json:"last_height"`
+	BlockMetas []BlockMeta  `json:"block_metas"`
+}
+
+// BlockMeta summarizes a single block.
+type BlockMeta struct {
+	Header    BlockHeader `json:"header"`
+	BlockSize JSONStrInt  `json:"block_size"`
+	NumTxs    JSONStrInt  `json:"num_txs"`
+}
+
+// BlockHeader is the subset of a block header that we care about.
+type BlockHeader struct {
+	Height JSONStrInt64 `json:"height"`
+	Time   time.Time    `json:"time"`
+}
+
+// ResultConsensusParams is the subset of the JSON-RPC response format
+// produced by the CometBFT v0.38.x consensus_params RPC API that we care
+// about.
+type ResultConsensusParams struct {
+	BlockHeight     JSONStrInt64    `json:"block_height"`
+	ConsensusParams ConsensusParams `json:"consensus_params"`
+}
+
+// ConsensusParams holds the consensus parameters that we care about.
+type ConsensusParams struct {
+	Block BlockParams `json:"block"`
+}
+
+// BlockParams limits the size of blocks (-1 meaning unlimited).
+type BlockParams struct {
+	MaxBytes JSONStrInt64 `json:"max_bytes"`
+	MaxGas   JSONStrInt64 `json:"max_gas"`
+}
+
 // NetInfo corresponds to the JSON-RPC response format produced by the
 // CometBFT v0.34.x net_info RPC API.
 type NetInfo struct {
@@ -222,27 +302,64 @@ func newHttpRpcClient(addr string) *httpClient {
 }
 
 func (c *httpClient) netInfo() (*NetInfo, error) {
-	httpRes, err := c.client.Get(c.addr + "/net_info")
+	netInfo := &NetInfo{}
+	if err := c.call("net_info", "/net_info", netInfo); err != nil {
+		return nil, err
+	}
+	return netInfo, nil
+}
+
+func (c *httpClient) status() (*ResultStatus, error) {
+	status := &ResultStatus{}
+	if err := c.call("status", "/status", status); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// blockchain returns the metadata for the blocks with heights in the range
+// [minHeight, maxHeight]. CometBFT returns at most 20 blocks per request, in
+// descending order of height.
+func (c *httpClient) blockchain(minHeight, maxHeight int64) (*ResultBlockchainInfo, error) {
+	info := &ResultBlockchainInfo{}
+	path := fmt.Sprintf("/blockchain?minHeight=%d&maxHeight=%d", minHeight, maxHeight)
+	if err := c.call("blockchain", path, info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+func (c *httpClient) consensusParams(height int64) (*ResultConsensusParams, error) {
+	params := &ResultConsensusParams{}
+	if err := c.call("consensus_params", fmt.Sprintf("/consensus_params?height=%d", height), params); err != nil {
+		return nil, err
+	}
+	return params, nil
+}
+
+// call performs a GET request against the given URI-over-HTTP RPC path and
+// unmarshals the result into the given value.
+func (c *httpClient) call(method, path string, result interface{}) error {
+	httpRes, err := c.client.Get(c.addr + path)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get net_info for peer %s: %w", c.addr, err)
+		return fmt.Errorf("failed to get %s for peer %s: %w", method, c.addr, err)
 	}
 	defer httpRes.Body.Close()
 
 	resBytes, err := io.ReadAll(httpRes.Body)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	res := &RPCResponse{}
 	if err := json.Unmarshal(resBytes, res); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal net_info response for peer %s: %w", c.addr, err)
+		return fmt.Errorf("failed to unmarshal %s response for peer %s: %w", method, c.addr, err)
 	}
 	if res.Error != nil && res.Error.Code != 0 {
-		return nil, fmt.Errorf("got error code %d when attempting to get net_info for %s: %s", res.Error.Code, c.addr, res.Error.Message)
+		return fmt.Errorf("got error code %d when attempting to get %s for %s: %s", res.Error.Code, method, c.addr, res.Error.Message)
 	}
-	netInfo := &NetInfo{}
-	if err := json.Unmarshal(res.Result, netInfo); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal net_info inner response for peer %s: %w", c.addr, err)
+	if err := json.Unmarshal(res.Result, result); err != nil {
+		return fmt.Errorf("failed to unmarshal %s inner response for peer %s: %w", method, c.addr, err)
 	}
-	return netInfo, nil
+	return nil
 }
@@ -0,0 +1,83 @@
+package loadtest
+
+import (
+	"fmt"
+	"time"
+)
+
+// RunErrorKind categorizes the errors reported to a RunErrorHandler.
+type RunErrorKind string
+
+const (
+	// A connection to an endpoint could not be established, was lost, or
+	// could not be re-established. Err is a *DialError if the initial
+	// connection attempt failed.
+	RunErrorConnection RunErrorKind = "connection"
+	// The client failed to generate a transaction, which stops its
+	// transactor.
+	RunErrorClient RunErrorKind = "client"
+	// A transaction was rejected or failed. Err is a *TxError.
+	RunErrorTx RunErrorKind = "tx"
+	// Any other error that stops a transactor (e.g. failing to record a
+	// transaction).
+	RunErrorOther RunErrorKind = "other"
+)
+
+// RunError is an error encountered while running a load test, as streamed to
+// a RunErrorHandler. Most of them are not fatal to the load test as a whole.
+type RunError struct {
+	Kind     RunErrorKind
+	Endpoint string    // The WebSockets URL of the endpoint concerned.
+	Time     time.Time // When the error occurred.
+	Err      error
+}
+
+func (e *RunError) Error() string {
+	return fmt.Sprintf("%s error (%s): %v", e.Kind, e.Endpoint, e.Err)
+}
+
+func (e *RunError) Unwrap() error { return e.Err }
+
+// RunErrorHandler is called with each error encountered during a load test,
+// as it occurs. It is called concurrently from the transactors' send and
+// receive paths, so it must be safe for concurrent use and should return
+// quickly.
+type RunErrorHandler func(err *RunError)
+
+// clientError wraps errors returned by the client when generating
+// transactions.
+type clientError struct {
+	err error
+}
+
+func (e *clientError) Error() string { return e.err.Error() }
+func (e *clientError) Unwrap() error { return e.err }
+
+// errorObserver reports transaction errors to a RunErrorHandler, passing all
+// results on to the next observer, if any.
+type errorObserver struct {
+	next    ResultObserver
+	handler RunErrorHandler
+}
+
+func (o *errorObserver) OnSubmit(tx TxInfo) {
+	if o.next != nil {
+		o.next.OnSubmit(tx)
+	}
+}
+
+func (o *errorObserver) OnConfirm(tx TxInfo, result TxConfirmation) {
+	if o.next != nil {
+		o.next.OnConfirm(tx, result)
+	}
+}
+
+func (o *errorObserver) OnError(tx TxInfo, err *TxError) {
+	// failures to write are reported as connection errors by the transactor
+	if err.Category != TxErrorCategoryConnection {
+		o.handler(&RunError{Kind: RunErrorTx, Endpoint: tx.Endpoint, Time: time.Now(), Err: err})
+	}
+	if o.next != nil {
+		o.next.OnError(tx, err)
+	}
+}
@@ -0,0 +1,77 @@
+package loadtest
+
+import "strings"
+
+// sparklineLevels are the characters with which a Sparkline renders its
+// samples, from the lowest to the highest. They're plain ASCII so that the
+// TUI works in any terminal.
+const sparklineLevels = "_.-:=+*#"
+
+// Sparkline keeps the most recent samples of a series (e.g. the transaction
+// rate, once per second) in a ring buffer, and renders them as a line of
+// characters whose heights are relative to the largest sample.
+type Sparkline struct {
+	samples []float64
+	next    int // The index at which the next sample is written.
+	count   int // The number of samples written, up to len(samples).
+}
+
+// NewSparkline creates a sparkline that keeps the given number of samples.
+func NewSparkline(size int) *Sparkline {
+	if size < 1 {
+		size = 1
+	}
+	return &Sparkline{samples: make([]float64, size)}
+}
+
+// Add records a sample, replacing the oldest one if the sparkline is full.
+func (s *Sparkline) Add(v float64) {
+	s.samples[s.next] = v
+	s.next = (s.next + 1) % len(s.samples)
+	if s.count < len(s.samples) {
+		s.count++
+	}
+}
+
+// Samples returns the recorded samples, oldest first.
+func (s *Sparkline) Samples() []float64 {
+	samples := make([]float64, 0, s.count)
+	start := (s.next - s.count + len(s.samples)) % len(s.samples)
+	for i := 0; i < s.count; i++ {
+		samples = append(samples, s.samples[(start+i)%len(s.samples)])
+	}
+	return samples
+}
+
+// Range returns the smallest and largest of the recorded samples (both 0 if
+// there are none).
+func (s *Sparkline) Range() (min, max float64) {
+	for i, v := range s.Samples() {
+		if i == 0 || v < min {
+			min = v
+		}
+		if i == 0 || v > max {
+			max = v
+		}
+	}
+	return min, max
+}
+
+// String renders the recorded samples, oldest first, left-padded with spaces
+// to the sparkline's size so that it keeps its width as it fills up.
+// Samples are scaled from 0 to the largest one, so that a steady series is
+// drawn flat at the top rather than exaggerating small fluctuations.
+func (s *Sparkline) String() string {
+	_, max := s.Range()
+	var b strings.Builder
+	b.WriteString(strings.Repeat(" ", len(s.samples)-s.count))
+	top := len(sparklineLevels) - 1
+	for _, v := range s.Samples() {
+		level := 0
+		if max > 0 && v > 0 {
+			level = int(v/max*float64(top) + 0.5)
+		}
+		b.WriteByte(sparklineLevels[level])
+	}
+	return b.String()
+}
@@ -0,0 +1,31 @@
+package loadtest_test
+
+import (
+	"testing"
+
+	"github.com/1119-Labs/perpx-load-test/pkg/loadtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSparkline(t *testing.T) {
+	s := loadtest.NewSparkline(5)
+	assert.Equal(t, "     ", s.String())
+	min, max := s.Range()
+	assert.Equal(t, 0.0, min)
+	assert.Equal(t, 0.0, max)
+
+	s.Add(0)
+	s.Add(70)
+	s.Add(35)
+	assert.Equal(t, "  _#=", s.String())
+
+	// the oldest samples are replaced once it's full
+	s.Add(70)
+	s.Add(70)
+	s.Add(10)
+	assert.Equal(t, []float64{70, 35, 70, 70, 10}, s.Samples())
+	assert.Equal(t, "#=##.", s.String())
+	min, max = s.Range()
+	assert.Equal(t, 10.0, min)
+	assert.Equal(t, 70.0, max)
+}
@@ -0,0 +1,103 @@
+package loadtest
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SpikeSchedule describes periodic bursts of load: every Every, the send rate
+// is multiplied by Factor for Duration, after which it returns to baseline.
+// The first spike starts Every after sending starts.
+//
+// A nil *SpikeSchedule means no spikes.
+type SpikeSchedule struct {
+	Every    time.Duration
+	Factor   float64
+	Duration time.Duration
+}
+
+// ParseSpikeSchedule parses a spike schedule of the form
+// "every=30s,factor=5,duration=3s". An empty string results in a nil
+// schedule.
+func ParseSpikeSchedule(s string) (*SpikeSchedule, error) {
+	if len(strings.TrimSpace(s)) == 0 {
+		return nil, nil
+	}
+	spike := &SpikeSchedule{}
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if len(pair) == 0 {
+			continue
+		}
+		key, val, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid spike option %q: expected key=value", pair)
+		}
+		var err error
+		switch strings.TrimSpace(key) {
+		case "every":
+			spike.Every, err = time.ParseDuration(strings.TrimSpace(val))
+		case "factor":
+			spike.Factor, err = strconv.ParseFloat(strings.TrimSpace(val), 64)
+		case "duration":
+			spike.Duration, err = time.ParseDuration(strings.TrimSpace(val))
+		default:
+			return nil, fmt.Errorf("unknown spike option %q (expected every, factor or duration)", key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid spike option %q: %w", pair, err)
+		}
+	}
+	if spike.Every <= 0 {
+		return nil, fmt.Errorf("spike interval (every) must be > 0")
+	}
+	if spike.Factor <= 0 || math.IsInf(spike.Factor, 0) || math.IsNaN(spike.Factor) {
+		return nil, fmt.Errorf("spike factor must be > 0")
+	}
+	if spike.Duration <= 0 || spike.Duration >= spike.Every {
+		return nil, fmt.Errorf("spike duration must be > 0 and less than the spike interval (%s)", spike.Every)
+	}
+	return spike, nil
+}
+
+// Active returns whether a spike is in progress the given time after sending
+// started.
+func (s *SpikeSchedule) Active(elapsed time.Duration) bool {
+	if s == nil || elapsed < s.Every {
+		return false
+	}
+	return elapsed%s.Every < s.Duration
+}
+
+// Rate returns the send rate the given time after sending started, given the
+// baseline rate. During a spike the rate is at least 1.
+func (s *SpikeSchedule) Rate(rate int, elapsed time.Duration) int {
+	if !s.Active(elapsed) {
+		return rate
+	}
+	return s.PeakRate(rate)
+}
+
+// PeakRate returns the send rate during a spike, given the baseline rate.
+func (s *SpikeSchedule) PeakRate(rate int) int {
+	if s == nil {
+		return rate
+	}
+	r := int(math.Round(float64(rate) * s.Factor))
+	if r < 1 {
+		r = 1
+	}
+	return r
+}
+
+// MeanFactor returns the factor by which the spikes change the average send
+// rate over a long run.
+func (s *SpikeSchedule) MeanFactor() float64 {
+	if s == nil {
+		return 1
+	}
+	return 1 + (s.Factor-1)*s.Duration.Seconds()/s.Every.Seconds()
+}
@@ -0,0 +1,54 @@
+package loadtest_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/1119-Labs/perpx-load-test/pkg/loadtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpikeSchedule(t *testing.T) {
+	spike, err := loadtest.ParseSpikeSchedule("every=30s, factor=5,duration=3s")
+	require.NoError(t, err)
+	assert.Equal(t, &loadtest.SpikeSchedule{Every: 30 * time.Second, Factor: 5, Duration: 3 * time.Second}, spike)
+
+	testCases := []struct {
+		elapsed time.Duration
+		rate    int
+	}{
+		{0, 100},
+		{3 * time.Second, 100},
+		{30 * time.Second, 500},
+		{32 * time.Second, 500},
+		{33 * time.Second, 100},
+		{61 * time.Second, 500},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, tc.rate, spike.Rate(100, tc.elapsed), tc.elapsed)
+	}
+	assert.InDelta(t, 1.4, spike.MeanFactor(), 1e-9)
+
+	none, err := loadtest.ParseSpikeSchedule("")
+	require.NoError(t, err)
+	assert.Nil(t, none)
+	assert.Equal(t, 100, none.Rate(100, 30*time.Second))
+	assert.Equal(t, 1.0, none.MeanFactor())
+}
+
+func TestParseSpikeScheduleErrors(t *testing.T) {
+	for _, s := range []string{
+		"every=30s",
+		"every=30s,factor=5",
+		"every=30s,factor=0,duration=3s",
+		"every=30s,factor=5,duration=30s",
+		"every=0s,factor=5,duration=3s",
+		"every=30,factor=5,duration=3s",
+		"every=30s,factor=5,duration=3s,foo=1",
+		"every",
+	} {
+		_, err := loadtest.ParseSpikeSchedule(s)
+		assert.Error(t, err, s)
+	}
+}
@@ -7,28 +7,154 @@ import (
 )
 
 type AggregateStats struct {
-	TotalTxs         int     // The total number of transactions sent.
-	TotalTimeSeconds float64 // The total time taken to send `TotalTxs` transactions.
-	TotalBytes       int64   // The cumulative number of bytes sent as transactions.
+	TotalTxs         int          // The total number of transactions sent.
+	TotalTimeSeconds float64      // The total time taken to send `TotalTxs` transactions.
+	TotalBytes       int64        // The cumulative number of bytes sent as transactions.
+	MinTxSize        int          // The size of the smallest transaction sent (bytes).
+	MaxTxSize        int          // The size of the largest transaction sent (bytes).
+	TxErrors         int          // The number of broadcast requests rejected by the node(s).
+	MempoolFull      int          // The number of broadcast requests rejected because the mempool was full.
+	Gas              GasStats     // Gas consumption of the committed transactions we observed.
+	Confirm          ConfirmStats // Outcomes of the transactions sampled for confirmation (if enabled).
 
 	// Computed statistics
 	AvgTxRate   float64 // The rate at which transactions were submitted (tx/sec).
 	AvgDataRate float64 // The rate at which data was transmitted in transactions (bytes/sec).
 	AvgTxSize   float64 // The average size of each transaction (bytes/tx).
+	AvgDataMbps float64 // AvgDataRate in megabits per second, for comparison with network bandwidth.
 }
 
 func (s *AggregateStats) String() string {
 	return fmt.Sprintf(
-		"AggregateStats{TotalTimeSeconds: %.3f, TotalTxs: %d, TotalBytes: %d, AvgTxRate: %.6f, AvgDataRate: %.6f, AvgTxSize: %.2f}",
+		"AggregateStats{TotalTimeSeconds: %.3f, TotalTxs: %d, TotalBytes: %d, AvgTxRate: %.6f, AvgDataRate: %.6f, AvgTxSize: %.2f, GasSamples: %d, MinGasUsed: %d, AvgGasUsed: %.2f, MaxGasUsed: %d}",
 		s.TotalTimeSeconds,
 		s.TotalTxs,
 		s.TotalBytes,
 		s.AvgTxRate,
 		s.AvgDataRate,
 		s.AvgTxSize,
+		s.Gas.Samples,
+		s.Gas.MinUsed,
+		s.Gas.AvgUsed(),
+		s.Gas.MaxUsed,
 	)
 }
 
+// TxSizeStats tracks the range of the sizes of the transactions sent.
+type TxSizeStats struct {
+	Count int // The number of transactions observed.
+	Min   int // The size of the smallest transaction observed (bytes).
+	Max   int // The size of the largest transaction observed (bytes).
+}
+
+// Add records the size of a single transaction.
+func (s *TxSizeStats) Add(size int) {
+	if s.Count == 0 || size < s.Min {
+		s.Min = size
+	}
+	if s.Count == 0 || size > s.Max {
+		s.Max = size
+	}
+	s.Count++
+}
+
+// Merge folds the given statistics into these ones.
+func (s *TxSizeStats) Merge(o TxSizeStats) {
+	if o.Count == 0 {
+		return
+	}
+	if s.Count == 0 || o.Min < s.Min {
+		s.Min = o.Min
+	}
+	if s.Count == 0 || o.Max > s.Max {
+		s.Max = o.Max
+	}
+	s.Count += o.Count
+}
+
+// GasStats summarizes the gas consumed by the committed transactions observed
+// during a load test.
+type GasStats struct {
+	Samples     int   // The number of committed transactions for which gas was observed.
+	MinUsed     int64 // The smallest gas_used value observed.
+	MaxUsed     int64 // The largest gas_used value observed.
+	TotalUsed   int64 // The sum of all gas_used values observed.
+	TotalWanted int64 // The sum of all gas_wanted values observed.
+}
+
+// ConfirmStats summarizes the outcomes of the submitted transactions that were
+// sampled for confirmation of their inclusion in a block.
+type ConfirmStats struct {
+	Sampled   int // The number of submitted transactions queued for confirmation.
+	Committed int // Sampled transactions committed successfully (code 0).
+	Failed    int // Sampled transactions committed with a non-zero result code.
+	Missing   int // Sampled transactions not found in a block before the confirmation timeout.
+	Dropped   int // Samples dropped because the confirmation queue was full.
+
+	MinHeight int64 // The lowest block height in which a sampled transaction was found (0 if none was).
+	MaxHeight int64 // The highest block height in which a sampled transaction was found (0 if none was).
+	Blocks    int   // The number of distinct blocks in which sampled transactions were found.
+}
+
+// Resolved returns the number of sampled transactions whose outcome is known.
+func (s ConfirmStats) Resolved() int {
+	return s.Committed + s.Failed + s.Missing
+}
+
+// SuccessRate returns the fraction of resolved samples that were committed
+// successfully.
+func (s ConfirmStats) SuccessRate() float64 {
+	if s.Resolved() == 0 {
+		return 0
+	}
+	return float64(s.Committed) / float64(s.Resolved())
+}
+
+// Add records the gas used/wanted by a single committed transaction.
+func (s *GasStats) Add(used, wanted int64) {
+	if s.Samples == 0 || used < s.MinUsed {
+		s.MinUsed = used
+	}
+	if s.Samples == 0 || used > s.MaxUsed {
+		s.MaxUsed = used
+	}
+	s.Samples++
+	s.TotalUsed += used
+	s.TotalWanted += wanted
+}
+
+// Merge folds the given statistics into these ones.
+func (s *GasStats) Merge(o GasStats) {
+	if o.Samples == 0 {
+		return
+	}
+	if s.Samples == 0 || o.MinUsed < s.MinUsed {
+		s.MinUsed = o.MinUsed
+	}
+	if s.Samples == 0 || o.MaxUsed > s.MaxUsed {
+		s.MaxUsed = o.MaxUsed
+	}
+	s.Samples += o.Samples
+	s.TotalUsed += o.TotalUsed
+	s.TotalWanted += o.TotalWanted
+}
+
+// AvgUsed returns the average gas_used per observed transaction.
+func (s GasStats) AvgUsed() float64 {
+	if s.Samples == 0 {
+		return 0
+	}
+	return float64(s.TotalUsed) / float64(s.Samples)
+}
+
+// AvgWanted returns the average gas_wanted per observed transaction.
+func (s GasStats) AvgWanted() float64 {
+	if s.Samples == 0 {
+		return 0
+	}
+	return float64(s.TotalWanted) / float64(s.Samples)
+}
+
 func (s *AggregateStats) Compute() {
 	s.AvgTxRate = 0
 	s.AvgDataRate = 0
@@ -37,6 +163,7 @@ func (s *AggregateStats) Compute() {
 		s.AvgTxRate = float64(s.TotalTxs) / s.TotalTimeSeconds
 		s.AvgDataRate = float64(s.TotalBytes) / s.TotalTimeSeconds
 	}
+	s.AvgDataMbps = s.AvgDataRate * 8 / 1e6
 	if s.TotalTxs > 0 {
 		s.AvgTxSize = float64(s.TotalBytes) / float64(s.TotalTxs)
 	}
@@ -60,7 +187,27 @@ func writeAggregateStats(filename string, stats AggregateStats) error {
 		{"total_bytes", fmt.Sprintf("%d", stats.TotalBytes), "bytes"},
 		{"avg_tx_rate", fmt.Sprintf("%.6f", stats.AvgTxRate), "transactions per second"},
 		{"avg_data_rate", fmt.Sprintf("%.6f", stats.AvgDataRate), "bytes per second"},
+		{"avg_data_mbps", fmt.Sprintf("%.6f", stats.AvgDataMbps), "megabits per second"},
 		{"avg_tx_size", fmt.Sprintf("%.2f", stats.AvgTxSize), "bytes per transaction"},
+		{"min_tx_size", fmt.Sprintf("%d", stats.MinTxSize), "bytes per transaction"},
+		{"max_tx_size", fmt.Sprintf("%d", stats.MaxTxSize), "bytes per transaction"},
+		{"tx_errors", fmt.Sprintf("%d", stats.TxErrors), "count"},
+		{"mempool_full", fmt.Sprintf("%d", stats.MempoolFull), "count"},
+		{"gas_samples", fmt.Sprintf("%d", stats.Gas.Samples), "count"},
+		{"min_gas_used", fmt.Sprintf("%d", stats.Gas.MinUsed), "gas per transaction"},
+		{"avg_gas_used", fmt.Sprintf("%.2f", stats.Gas.AvgUsed()), "gas per transaction"},
+		{"max_gas_used", fmt.Sprintf("%d", stats.Gas.MaxUsed), "gas per transaction"},
+		{"avg_gas_wanted", fmt.Sprintf("%.2f", stats.Gas.AvgWanted()), "gas per transaction"},
+	}
+	if stats.Confirm.Sampled > 0 {
+		records = append(records,
+			[]string{"confirm_sampled", fmt.Sprintf("%d", stats.Confirm.Sampled), "count"},
+			[]string{"confirm_committed", fmt.Sprintf("%d", stats.Confirm.Committed), "count"},
+			[]string{"confirm_failed", fmt.Sprintf("%d", stats.Confirm.Failed), "count"},
+			[]string{"confirm_missing", fmt.Sprintf("%d", stats.Confirm.Missing), "count"},
+			[]string{"confirm_dropped", fmt.Sprintf("%d", stats.Confirm.Dropped), "count"},
+			[]string{"commit_success_rate", fmt.Sprintf("%.6f", stats.Confirm.SuccessRate()), "fraction of resolved samples"},
+		)
 	}
 	return w.WriteAll(records)
 }
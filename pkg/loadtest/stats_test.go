@@ -0,0 +1,53 @@
+package loadtest_test
+
+import (
+	"testing"
+
+	"github.com/1119-Labs/perpx-load-test/pkg/loadtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGasStats(t *testing.T) {
+	var a loadtest.GasStats
+	assert.Equal(t, 0.0, a.AvgUsed())
+	a.Add(100, 200)
+	a.Add(50, 200)
+	a.Add(150, 200)
+	assert.Equal(t, 3, a.Samples)
+	assert.Equal(t, int64(50), a.MinUsed)
+	assert.Equal(t, int64(150), a.MaxUsed)
+	assert.Equal(t, 100.0, a.AvgUsed())
+	assert.Equal(t, 200.0, a.AvgWanted())
+
+	var b loadtest.GasStats
+	b.Merge(loadtest.GasStats{})
+	assert.Equal(t, 0, b.Samples)
+	b.Add(400, 500)
+	b.Merge(a)
+	assert.Equal(t, 4, b.Samples)
+	assert.Equal(t, int64(50), b.MinUsed)
+	assert.Equal(t, int64(400), b.MaxUsed)
+	assert.Equal(t, int64(700), b.TotalUsed)
+}
+
+func TestTxSizeStats(t *testing.T) {
+	var a loadtest.TxSizeStats
+	a.Add(250)
+	a.Add(180)
+	a.Add(300)
+	assert.Equal(t, loadtest.TxSizeStats{Count: 3, Min: 180, Max: 300}, a)
+
+	var b loadtest.TxSizeStats
+	b.Merge(loadtest.TxSizeStats{})
+	assert.Equal(t, 0, b.Count)
+	b.Add(400)
+	b.Merge(a)
+	assert.Equal(t, loadtest.TxSizeStats{Count: 4, Min: 180, Max: 400}, b)
+}
+
+func TestConfirmStatsSuccessRate(t *testing.T) {
+	assert.Equal(t, 0.0, loadtest.ConfirmStats{Sampled: 10}.SuccessRate())
+	s := loadtest.ConfirmStats{Sampled: 10, Committed: 6, Failed: 1, Missing: 1}
+	assert.Equal(t, 8, s.Resolved())
+	assert.Equal(t, 0.75, s.SuccessRate())
+}
@@ -0,0 +1,84 @@
+package loadtest
+
+import (
+	"math"
+	"sync"
+)
+
+const (
+	// The bounds of the multiplier that the target-TPS controller applies to
+	// the transactors' rates.
+	tpsMinMultiplier = 0.05
+	tpsMaxMultiplier = 3.0
+
+	// The controller's proportional and integral gains, relative to the
+	// target rate.
+	tpsProportionalGain = 0.3
+	tpsIntegralGain     = 0.5
+)
+
+// ApplyTargetTPS derives the per-connection rate from the target total rate,
+// if one is configured, by spreading it evenly across the connections to
+// every endpoint. It must be called once the endpoints have been resolved.
+func (c *Config) ApplyTargetTPS() {
+	if c.TargetTPS < 1 || c.Connections < 1 || len(c.Endpoints) == 0 {
+		return
+	}
+	conns := c.Connections * len(c.Endpoints)
+	c.Rate = int(math.Ceil(float64(c.TargetTPS*c.SendPeriod) / float64(conns)))
+	if c.Rate < 1 {
+		c.Rate = 1
+	}
+}
+
+// TPSController is a proportional-integral controller that adjusts a
+// multiplier of the configured send rate so that the rate at which
+// transactions are accepted by the endpoints matches a target, making up for
+// rejections and for connections that can't keep up.
+type TPSController struct {
+	target float64
+
+	mtx        sync.Mutex
+	integral   float64
+	multiplier float64
+}
+
+// NewTPSController creates a controller that aims for the given number of
+// accepted transactions per second, starting from a multiplier of 1.
+func NewTPSController(target float64) *TPSController {
+	return &TPSController{
+		target:     target,
+		integral:   1,
+		multiplier: 1,
+	}
+}
+
+// Update adjusts the multiplier given the rate (in transactions per second)
+// achieved since the previous update, and returns the new multiplier.
+func (c *TPSController) Update(achieved float64) float64 {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	// the error is relative to the target, so that the gains don't depend on
+	// the scale of the load test
+	e := (c.target - achieved) / c.target
+	// clamping the integral keeps it from winding up while the multiplier is
+	// at one of its bounds
+	c.integral = clampMultiplier(c.integral + tpsIntegralGain*e)
+	c.multiplier = clampMultiplier(c.integral + tpsProportionalGain*e)
+	return c.multiplier
+}
+
+// Multiplier returns the controller's current multiplier, or 1 if there is no
+// controller.
+func (c *TPSController) Multiplier() float64 {
+	if c == nil {
+		return 1
+	}
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return c.multiplier
+}
+
+func clampMultiplier(m float64) float64 {
+	return math.Min(math.Max(m, tpsMinMultiplier), tpsMaxMultiplier)
+}
@@ -0,0 +1,52 @@
+package loadtest_test
+
+import (
+	"testing"
+
+	"github.com/1119-Labs/perpx-load-test/pkg/loadtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyTargetTPS(t *testing.T) {
+	cfg := loadtest.Config{
+		TargetTPS:   1000,
+		Rate:        1,
+		Connections: 3,
+		SendPeriod:  2,
+		Endpoints:   []string{"ws://a:26657/websocket", "ws://b:26657/websocket"},
+	}
+	cfg.ApplyTargetTPS()
+	// 2000 txs per send period across 6 connections, rounded up
+	assert.Equal(t, 334, cfg.Rate)
+
+	cfg = loadtest.Config{Rate: 50, Connections: 1, SendPeriod: 1, Endpoints: cfg.Endpoints}
+	cfg.ApplyTargetTPS()
+	assert.Equal(t, 50, cfg.Rate)
+}
+
+func TestTPSController(t *testing.T) {
+	require.Equal(t, 1.0, (*loadtest.TPSController)(nil).Multiplier())
+
+	// the endpoints reject a fifth of what we send at the configured rate,
+	// which would achieve the target on its own
+	const target = 1000.0
+	c := loadtest.NewTPSController(target)
+	assert.Equal(t, 1.0, c.Multiplier())
+	for i := 0; i < 50; i++ {
+		c.Update(target * c.Multiplier() * 0.8)
+	}
+	assert.InDelta(t, 1.25, c.Multiplier(), 0.01)
+
+	// nothing gets through, so the multiplier is capped
+	for i := 0; i < 50; i++ {
+		c.Update(0)
+	}
+	assert.Equal(t, 3.0, c.Multiplier())
+	// and recovers promptly once the chain stops pushing back, since the
+	// integral doesn't wind up
+	for i := 0; i < 10; i++ {
+		c.Update(target * c.Multiplier())
+	}
+	assert.InDelta(t, 1.0, c.Multiplier(), 0.05)
+}
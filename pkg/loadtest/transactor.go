@@ -3,10 +3,15 @@ package loadtest
 import (
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
+	"math/rand"
 	"net/url"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/1119-Labs/perpx-load-test/internal/logging"
@@ -20,11 +25,37 @@ const (
 	connSendTimeout = 30 * time.Second
 	connPingPeriod  = (30 * 9 / 10) * time.Second
 
-	jsonRPCID = -1
+	// The Cosmos SDK's ErrMempoolIsFull, returned by the application-side
+	// mempool when it is at capacity.
+	sdkCodespace          = "sdk"
+	sdkCodeMempoolIsFull  = 20
+	cometMempoolIsFullMsg = "mempool is full" // The prefix of CometBFT's ErrMempoolIsFull message.
 
 	defaultProgressCallbackInterval = 5 * time.Second
+
+	// The backoff between attempts to reconnect to an endpoint after losing
+	// the connection, which doubles with each failed attempt.
+	reconnectBackoffMin = 1 * time.Second
+	reconnectBackoffMax = 30 * time.Second
+
+	// How often to check whether there's room for more transactions while
+	// blocked on backpressure.
+	backpressurePollInterval = 10 * time.Millisecond
 )
 
+// errEndpointUnavailable is the error with which a transactor stops after
+// giving up on reconnecting to its endpoint.
+var errEndpointUnavailable = errors.New("endpoint unavailable")
+
+// connError wraps errors caused by the connection to the remote endpoint
+// (rather than, say, by the client), which may be resolved by reconnecting.
+type connError struct {
+	err error
+}
+
+func (e *connError) Error() string { return e.err.Error() }
+func (e *connError) Unwrap() error { return e.err }
+
 // validateWebSocketURL parses and validates a user-provided WebSocket URL.
 // It ensures that only ws:// or wss:// URLs with a non-empty host and without
 // control characters are used for outbound connections.
@@ -46,33 +77,114 @@ func validateWebSocketURL(raw string) (*url.URL, error) {
 	return u, nil
 }
 
-// Transactor represents a single wire-level connection to a CometBFT RPC
-// endpoint, and this is responsible for sending transactions to that endpoint.
+// txBudget is a transaction count limit shared between transactors, so that a
+// group of transactors can collectively stop once a total number of
+// transactions has been sent. A nil budget is unlimited.
+type txBudget struct {
+	limit int64
+	used  int64 // The number of transactions reserved so far (atomic).
+}
+
+func newTxBudget(limit int) *txBudget {
+	if limit <= 0 {
+		return nil
+	}
+	return &txBudget{limit: int64(limit)}
+}
+
+// reserve claims a single transaction from the budget, returning false if the
+// budget has been exhausted.
+func (b *txBudget) reserve() bool {
+	if b == nil {
+		return true
+	}
+	if atomic.AddInt64(&b.used, 1) > b.limit {
+		atomic.AddInt64(&b.used, -1)
+		return false
+	}
+	return true
+}
+
+// release returns a previously reserved transaction to the budget (e.g. if it
+// could not be sent).
+func (b *txBudget) release() {
+	if b != nil {
+		atomic.AddInt64(&b.used, -1)
+	}
+}
+
+func (b *txBudget) exhausted() bool {
+	return b != nil && atomic.LoadInt64(&b.used) >= b.limit
+}
+
+// Transactor is responsible for sending transactions to a CometBFT RPC
+// endpoint over a WebSockets connection, which is either its own or shared
+// with other transactors (see Config.ConnectionPoolSize).
 type Transactor struct {
 	remoteAddr string  // The full URL of the remote WebSockets endpoint.
 	config     *Config // The configuration for the load test.
 
 	client            Client
 	logger            logging.Logger
-	conn              *websocket.Conn
+	conn              *pooledConn
 	broadcastTxMethod string
+	rate              int                   // The number of transactions to send per send period.
+	rateScale         func() float64        // If set, the rate is multiplied by this (e.g. to take over the load of endpoints that are down).
+	spike             *SpikeSchedule        // If set, the rate is periodically multiplied according to this schedule.
+	restURL           string                // The REST API URL corresponding to remoteAddr (used for confirmations).
+	confirmer         *txConfirmer          // If set, samples of our transactions are confirmed through this confirmer.
+	budget            *txBudget             // The (possibly shared) limit on the total number of transactions to send.
+	recorder          *TxRecorder           // If set, every transaction we send is recorded here.
+	errorHandler      RunErrorHandler       // If set, called with each error as it occurs.
+	injector          ClientFailureInjector // Only set if the client can generate invalid transactions and Config.InjectFailures is set.
+	rng               *rand.Rand            // Per-transactor PRNG (only accessed from the send loop).
 	wg                sync.WaitGroup
 
+	// Connection state
+	connected         int32                // 1 while the connection is believed to be healthy (atomic).
+	connStateCallback func(connected bool) // Called whenever the connection is lost or restored.
+	reconnectAttempts int                  // Consecutive failed reconnection attempts (only accessed from the send loop).
+	nextReconnect     time.Time            // When to make the next reconnection attempt (only accessed from the send loop).
+
 	// Rudimentary statistics
-	statsMtx  sync.RWMutex
-	startTime time.Time // When did the transaction sending start?
-	txCount   int       // How many transactions have been sent.
-	txBytes   int64     // How many transaction bytes have been sent, cumulatively.
-	txRate    float64   // The number of transactions sent, per second.
+	statsMtx         sync.RWMutex
+	startTime        time.Time      // When did the transaction sending start?
+	txCount          int            // How many transactions have been sent.
+	txBytes          int64          // How many transaction bytes have been sent, cumulatively.
+	txSizes          TxSizeStats    // The sizes of the transactions sent after the warmup period.
+	txRate           float64        // The number of transactions sent, per second.
+	gasStats         GasStats       // Gas consumption of the committed transactions we've observed.
+	txErrors         int            // How many of our broadcast requests were rejected (RPC error or non-zero result code).
+	errorCats        map[string]int // The number of rejected broadcast requests, by error category.
+	mempoolFull      int            // How many of our broadcast requests were rejected because the mempool was full.
+	injected         int            // How many deliberately invalid transactions have been sent (see Config.InjectFailures).
+	injectedRejected int            // How many of the injected transactions were rejected, as intended.
+	firstSentAt      time.Time      // When our first transaction was broadcast (only tracked with Config.ColdStart).
+	firstCommit      time.Duration  // How long after firstSentAt one of our transactions was first confirmed as committed (0 until then).
+
+	warmupEnd  time.Time     // Gas and confirmation samples are only collected after this time.
+	startDelay time.Duration // How long after starting to wait before sending any transactions.
+
+	inFlight     int64 // The number of broadcast requests for which we have not yet received a response (atomic).
+	confirming   int64 // The number of our sampled transactions awaiting confirmation (atomic).
+	blocked      int32 // 1 while generation is blocked because too many transactions are in flight (atomic).
+	backoffUntil int64 // Unix time (in nanoseconds) until which we refrain from sending because the mempool was full (atomic).
+
+	expired       int64 // How many of our sampled transactions expired before being committed (atomic).
+	resyncPending int32 // 1 if the client must be resynced before generating more transactions, e.g. after a transaction expired (atomic).
+
+	broadcastLatency   int64 // The total time (in nanoseconds) that our broadcast requests took to be answered (atomic).
+	broadcastResponses int64 // The number of broadcast requests that were answered (atomic).
 
 	progressCallbackMtx      sync.RWMutex
 	progressCallbackID       int                                      // A unique identifier for this transactor when calling the progress callback.
 	progressCallbackInterval time.Duration                            // How frequently to call the progress update callback.
 	progressCallback         func(id int, txCount int, txBytes int64) // Called with the total number of transactions executed so far.
 
-	stopMtx sync.RWMutex
-	stop    bool
-	stopErr error // Did an error occur that triggered the stop?
+	stopMtx       sync.RWMutex
+	stop          bool
+	stopErr       error     // Did an error occur that triggered the stop?
+	drainDeadline time.Time // If set, how long to wait for in-flight requests prior to closing the connection.
 }
 
 // NewTransactor initiates a WebSockets connection to the given host address.
@@ -82,37 +194,52 @@ func NewTransactor(remoteAddr string, config *Config) (*Transactor, error) {
 	if err != nil {
 		return nil, err
 	}
-	clientFactory, exists := clientFactories[config.ClientFactory]
-	if !exists {
-		return nil, fmt.Errorf("unrecognized client factory: %s", config.ClientFactory)
-	}
-	client, err := clientFactory.NewClient(*config)
+	conn, err := dialPooledConn(u.String())
 	if err != nil {
 		return nil, err
 	}
-	// Set a timeout for WebSocket dial to prevent hanging
-	// Create a new dialer instead of modifying the default one
-	dialer := &websocket.Dialer{
-		HandshakeTimeout: 10 * time.Second,
+	t, err := newTransactor(conn, config, nil, 0)
+	if err != nil {
+		conn.release(nil)
+		return nil, err
 	}
-	conn, resp, err := dialer.Dial(u.String(), nil)
+	return t, nil
+}
+
+// newTransactor creates a transactor that sends its transactions over the
+// given (possibly shared) connection. If client is nil, one is created using
+// the configured client factory.
+func newTransactor(conn *pooledConn, config *Config, client Client, id int) (*Transactor, error) {
+	spike, err := ParseSpikeSchedule(config.Spike)
 	if err != nil {
-		return nil, fmt.Errorf("failed to dial WebSocket endpoint %s: %w", remoteAddr, err)
+		return nil, err
 	}
-	if resp.StatusCode >= 400 {
-		return nil, fmt.Errorf("failed to connect to remote WebSockets endpoint %s: %s (status code %d)", remoteAddr, resp.Status, resp.StatusCode)
+	if client == nil {
+		if client, err = newClient(config); err != nil {
+			return nil, err
+		}
 	}
-	logger := logging.NewLogrusLogger(fmt.Sprintf("transactor[%s]", u.String()))
-	logger.Info("Connected to remote CometBFT WebSockets RPC")
-	return &Transactor{
-		remoteAddr:               u.String(),
+	t := &Transactor{
+		remoteAddr:               conn.remoteAddr,
 		config:                   config,
 		client:                   client,
-		logger:                   logger,
+		logger:                   logging.NewLogrusLogger(fmt.Sprintf("transactor[%s]", conn.remoteAddr)),
 		conn:                     conn,
+		connected:                1,
 		broadcastTxMethod:        "broadcast_tx_" + config.BroadcastTxMethod,
+		rate:                     config.Rate,
+		spike:                    spike,
+		restURL:                  restURLFromEndpoint(conn.remoteAddr),
+		budget:                   newTxBudget(config.Count),
+		rng:                      rand.New(rand.NewSource(DeriveSeed(config.RandomSeed, "transactor", id))),
+		errorCats:                make(map[string]int),
 		progressCallbackInterval: defaultProgressCallbackInterval,
-	}, nil
+	}
+	if config.InjectFailures > 0 {
+		t.injector, _ = client.(ClientFailureInjector)
+	}
+	conn.attach(t)
+	return t, nil
 }
 
 func (t *Transactor) SetProgressCallback(id int, interval time.Duration, callback func(int, int, int64)) {
@@ -123,12 +250,130 @@ func (t *Transactor) SetProgressCallback(id int, interval time.Duration, callbac
 	t.progressCallbackMtx.Unlock()
 }
 
-// Start kicks off the transactor's operations in separate goroutines (one for
-// reading from the WebSockets endpoint, and one for writing to it).
+// SetConfirmer configures the confirmer through which samples of this
+// transactor's transactions will be confirmed. Must be called before Start.
+func (t *Transactor) SetConfirmer(c *txConfirmer) {
+	t.confirmer = c
+}
+
+// SetRate overrides the number of transactions this transactor sends per send
+// period (which otherwise defaults to the configured rate). Must be called
+// before Start.
+func (t *Transactor) SetRate(rate int) {
+	t.rate = rate
+}
+
+// SetStartDelay configures how long this transactor waits after being started
+// before it sends its first transactions, so that the transactors of a group
+// can come online gradually. The time limit still counts from the start. Must
+// be called before Start.
+func (t *Transactor) SetStartDelay(d time.Duration) {
+	t.startDelay = d
+}
+
+// SetWarmupEnd configures the time until which this transactor is warming up,
+// during which its gas and confirmation samples are not collected. Must be
+// called before Start.
+func (t *Transactor) SetWarmupEnd(end time.Time) {
+	t.warmupEnd = end
+}
+
+func (t *Transactor) warmingUp() bool {
+	return !t.warmupEnd.IsZero() && time.Now().Before(t.warmupEnd)
+}
+
+// dialWebSocket connects to the given (already validated) WebSockets URL,
+// returning the connection along with how long the handshake took. Failures are
+// returned as a *DialError.
+func dialWebSocket(remoteAddr string) (*websocket.Conn, time.Duration, error) {
+	// Set a timeout for WebSocket dial to prevent hanging
+	// Create a new dialer instead of modifying the default one
+	dialer := &websocket.Dialer{
+		HandshakeTimeout: 10 * time.Second,
+	}
+	start := time.Now()
+	conn, resp, err := dialer.Dial(remoteAddr, nil)
+	latency := time.Since(start)
+	if err != nil {
+		if resp != nil {
+			err = fmt.Errorf("%w: %s", err, resp.Status)
+		}
+		return nil, latency, &DialError{Endpoint: remoteAddr, Kind: classifyDialError(err), Latency: latency, Err: err}
+	}
+	if resp.StatusCode >= 400 {
+		_ = conn.Close()
+		return nil, latency, &DialError{
+			Endpoint: remoteAddr,
+			Kind:     DialFailureHandshake,
+			Latency:  latency,
+			Err:      fmt.Errorf("%s (status code %d)", resp.Status, resp.StatusCode),
+		}
+	}
+	return conn, latency, nil
+}
+
+// SetRateScale configures a function by which this transactor's rate is
+// multiplied at the start of each send period. Must be called before Start.
+func (t *Transactor) SetRateScale(scale func() float64) {
+	t.rateScale = scale
+}
+
+// SetConnStateCallback configures a function to be called whenever this
+// transactor loses or restores its connection. The callback may be called from
+// either of the transactor's goroutines. Must be called before Start.
+func (t *Transactor) SetConnStateCallback(callback func(connected bool)) {
+	t.connStateCallback = callback
+}
+
+// Connected returns whether the transactor's connection is currently believed
+// to be healthy.
+func (t *Transactor) Connected() bool {
+	return atomic.LoadInt32(&t.connected) == 1
+}
+
+func (t *Transactor) setConnected(connected bool) {
+	var from, to int32 = 1, 0
+	if connected {
+		from, to = 0, 1
+	}
+	if atomic.CompareAndSwapInt32(&t.connected, from, to) && t.connStateCallback != nil {
+		t.connStateCallback(connected)
+	}
+}
+
+// SetRecorder configures the recorder to which every transaction sent by this
+// transactor is written. Must be called before Start.
+func (t *Transactor) SetRecorder(r *TxRecorder) {
+	t.recorder = r
+}
+
+// SetErrorHandler configures the handler to which errors are reported as they
+// occur. Must be called before Start.
+func (t *Transactor) SetErrorHandler(h RunErrorHandler) {
+	t.errorHandler = h
+}
+
+// reportError passes the given error on to the error handler, if any.
+func (t *Transactor) reportError(kind RunErrorKind, err error) {
+	if t.errorHandler != nil {
+		t.errorHandler(&RunError{Kind: kind, Endpoint: t.remoteAddr, Time: time.Now(), Err: err})
+	}
+}
+
+// SetTxBudget replaces this transactor's transaction count limit with the
+// given one, which may be shared with other transactors. Must be called
+// before Start.
+func (t *Transactor) SetTxBudget(b *txBudget) {
+	t.budget = b
+}
+
+// Start kicks off the transactor's operations in a separate goroutine that
+// writes to the WebSockets endpoint (responses are read by the connection,
+// which may be shared with other transactors).
 func (t *Transactor) Start() {
 	t.logger.Debug("Starting transactor")
-	t.wg.Add(2)
-	go t.receiveLoop()
+	t.conn.start()
+	t.wg.Add(1)
 	go t.sendLoop()
 }
 
@@ -138,6 +383,17 @@ func (t *Transactor) Cancel() {
 	t.setStop(fmt.Errorf("transactor operations cancelled"))
 }
 
+// Drain will indicate to the transactor that it must stop generating new
+// transactions, but that it may wait until the given deadline for responses to
+// its in-flight broadcast requests before closing its connection. Unlike
+// Cancel, a drained transactor does not report an error.
+func (t *Transactor) Drain(deadline time.Time) {
+	t.stopMtx.Lock()
+	t.stop = true
+	t.drainDeadline = deadline
+	t.stopMtx.Unlock()
+}
+
 // Wait will block until the transactor terminates.
 func (t *Transactor) Wait() error {
 	t.wg.Wait()
@@ -170,89 +426,563 @@ func (t *Transactor) GetTxRate() float64 {
 	return t.txRate
 }
 
-func (t *Transactor) receiveLoop() {
-	defer t.wg.Done()
-	for {
-		// right now we don't care about what we read back from the RPC endpoint
-		_, _, err := t.conn.ReadMessage()
-		if err != nil {
-			if !websocket.IsCloseError(err, websocket.CloseNormalClosure) {
-				t.logger.Error("Failed to read response on connection", "err", err)
-				return
+// GetGasStats returns a snapshot of the gas consumption observed thus far for
+// the transactions sent by this transactor.
+func (t *Transactor) GetGasStats() GasStats {
+	t.statsMtx.RLock()
+	defer t.statsMtx.RUnlock()
+	return t.gasStats
+}
+
+// GetTxErrors returns the number of broadcast requests sent by this
+// transactor that have been rejected thus far.
+func (t *Transactor) GetTxErrors() int {
+	t.statsMtx.RLock()
+	defer t.statsMtx.RUnlock()
+	return t.txErrors
+}
+
+// txStats is a snapshot of a transactor's cumulative counters.
+type txStats struct {
+	txs              int
+	bytes            int64
+	sizes            TxSizeStats // Only covers the transactions sent after the warmup period.
+	errors           int
+	mempoolFull      int
+	errorCats        map[string]int
+	injected         int // Deliberately invalid transactions sent, which are not included in txs.
+	injectedRejected int // Injected transactions that were rejected, which are not included in errors.
+}
+
+// sub returns the counters accumulated since the given earlier snapshot.
+func (s txStats) sub(o txStats) txStats {
+	d := txStats{
+		txs:              s.txs - o.txs,
+		bytes:            s.bytes - o.bytes,
+		sizes:            s.sizes,
+		errors:           s.errors - o.errors,
+		mempoolFull:      s.mempoolFull - o.mempoolFull,
+		errorCats:        make(map[string]int, len(s.errorCats)),
+		injected:         s.injected - o.injected,
+		injectedRejected: s.injectedRejected - o.injectedRejected,
+	}
+	for cat, count := range s.errorCats {
+		if count -= o.errorCats[cat]; count > 0 {
+			d.errorCats[cat] = count
+		}
+	}
+	return d
+}
+
+func (t *Transactor) getTxStats() txStats {
+	t.statsMtx.RLock()
+	defer t.statsMtx.RUnlock()
+	s := txStats{
+		txs:              t.txCount,
+		bytes:            t.txBytes,
+		sizes:            t.txSizes,
+		errors:           t.txErrors,
+		mempoolFull:      t.mempoolFull,
+		errorCats:        make(map[string]int, len(t.errorCats)),
+		injected:         t.injected,
+		injectedRejected: t.injectedRejected,
+	}
+	for cat, count := range t.errorCats {
+		s.errorCats[cat] = count
+	}
+	return s
+}
+
+// GetMempoolFull returns the number of broadcast requests sent by this
+// transactor that have been rejected thus far because the mempool was full.
+func (t *Transactor) GetMempoolFull() int {
+	t.statsMtx.RLock()
+	defer t.statsMtx.RUnlock()
+	return t.mempoolFull
+}
+
+// GetTxErrorCategories returns a copy of the number of rejected broadcast
+// requests thus far, keyed by error category.
+func (t *Transactor) GetTxErrorCategories() map[string]int {
+	return t.getTxStats().errorCats
+}
+
+// TrackGas records the gas used/wanted by a committed transaction sent by
+// this transactor. Samples observed while warming up are ignored.
+func (t *Transactor) TrackGas(used, wanted int64) {
+	if t.warmingUp() {
+		return
+	}
+	t.statsMtx.Lock()
+	t.gasStats.Add(used, wanted)
+	t.statsMtx.Unlock()
+}
+
+// handleResponse inspects a JSON-RPC response to one of our broadcast_tx
+// requests, counting rejected transactions. Only broadcast_tx_commit responses
+// carry execution results (and therefore gas usage). If info is set, the
+// result observer is notified of the outcome.
+func (t *Transactor) handleResponse(res *RPCResponse, info *TxInfo) {
+	if res.Error != nil {
+		t.logger.Debug("Broadcast request failed", "code", res.Error.Code, "message", res.Error.Message, "data", res.Error.Data)
+		t.trackTxError(rpcErrorCategory(res.Error))
+		if isMempoolFullRPCError(res.Error) {
+			t.trackMempoolFull()
+		}
+		if info != nil {
+			t.config.ResultObserver.OnError(*info, rpcTxError(res.Error))
+		}
+		return
+	}
+	if len(res.Result) == 0 {
+		return
+	}
+	switch t.broadcastTxMethod {
+	case "broadcast_tx_commit":
+		commit := &ResultBroadcastTxCommit{}
+		if err := json.Unmarshal(res.Result, commit); err != nil {
+			t.logger.Debug("Failed to decode broadcast_tx_commit result", "err", err)
+			return
+		}
+		if commit.CheckTx.Code != 0 {
+			t.trackTxError(resultErrorCategory(commit.CheckTx.Codespace, commit.CheckTx.Code))
+			if isMempoolFullResult(commit.CheckTx.Codespace, commit.CheckTx.Code) {
+				t.trackMempoolFull()
 			}
+		} else if commit.TxResult.Code != 0 {
+			t.trackTxError(resultErrorCategory(commit.TxResult.Codespace, commit.TxResult.Code))
 		}
-		if t.mustStop() {
+		if commit.Height > 0 {
+			t.TrackGas(int64(commit.TxResult.GasUsed), int64(commit.TxResult.GasWanted))
+		}
+		if info != nil {
+			switch {
+			case commit.CheckTx.Code != 0:
+				t.config.ResultObserver.OnError(*info, resultTxError(commit.CheckTx.Codespace, commit.CheckTx.Code, commit.CheckTx.Log))
+			case commit.TxResult.Code != 0:
+				t.config.ResultObserver.OnError(*info, resultTxError(commit.TxResult.Codespace, commit.TxResult.Code, commit.TxResult.Log))
+			case commit.Height > 0:
+				t.config.ResultObserver.OnConfirm(*info, TxConfirmation{
+					Height:    int64(commit.Height),
+					GasUsed:   int64(commit.TxResult.GasUsed),
+					GasWanted: int64(commit.TxResult.GasWanted),
+				})
+			}
+		}
+
+	default:
+		result := &ResultBroadcastTx{}
+		if err := json.Unmarshal(res.Result, result); err != nil {
+			t.logger.Debug("Failed to decode broadcast_tx result", "err", err)
 			return
 		}
+		if result.Code != 0 {
+			t.logger.Debug("Transaction rejected", "code", result.Code, "codespace", result.Codespace, "log", result.Log)
+			t.trackTxError(resultErrorCategory(result.Codespace, result.Code))
+			if isMempoolFullResult(result.Codespace, result.Code) {
+				t.trackMempoolFull()
+			}
+			if info != nil {
+				t.config.ResultObserver.OnError(*info, resultTxError(result.Codespace, result.Code, result.Log))
+			}
+		}
 	}
 }
 
-func (t *Transactor) sendLoop() {
-	defer t.wg.Done()
-	t.conn.SetPingHandler(func(message string) error {
-		err := t.conn.WriteControl(websocket.PongMessage, []byte(message), time.Now().Add(connSendTimeout))
+// handleInjectedResponse inspects the response to the broadcast of one of our
+// deliberately invalid transactions, counting whether it was rejected as
+// intended. Such rejections are not counted as errors. With the async
+// broadcast method, rejections by CheckTx aren't reported, so injected
+// transactions are only found to be rejected if they're malformed.
+func (t *Transactor) handleInjectedResponse(res *RPCResponse) {
+	rejected := res.Error != nil
+	if !rejected && len(res.Result) > 0 {
+		switch t.broadcastTxMethod {
+		case "broadcast_tx_commit":
+			commit := &ResultBroadcastTxCommit{}
+			rejected = json.Unmarshal(res.Result, commit) == nil && (commit.CheckTx.Code != 0 || commit.TxResult.Code != 0)
+		default:
+			result := &ResultBroadcastTx{}
+			rejected = json.Unmarshal(res.Result, result) == nil && result.Code != 0
+		}
+	}
+	if !rejected {
+		t.logger.Debug("Injected invalid transaction was not rejected")
+		return
+	}
+	t.statsMtx.Lock()
+	t.injectedRejected++
+	t.statsMtx.Unlock()
+}
+
+// confirmResult returns the callback through which the confirmer reports the
+// result of the given sampled transaction: its result once it has been found
+// in a block, or nil if it wasn't found in time.
+func (t *Transactor) confirmResult(tx []byte, info *TxInfo) func(res *restTxResult) {
+	return func(res *restTxResult) {
+		if res == nil {
+			category := TxErrorCategoryUnconfirmed
+			if t.txExpired(tx) {
+				category = TxErrorCategoryExpired
+			}
+			if info != nil {
+				t.config.ResultObserver.OnError(*info, &TxError{Category: category})
+			}
+			return
+		}
+		gasUsed, _ := strconv.ParseInt(res.GasUsed, 10, 64)
+		gasWanted, _ := strconv.ParseInt(res.GasWanted, 10, 64)
+		t.TrackGas(gasUsed, gasWanted)
+		if res.Code == 0 {
+			t.trackFirstCommit()
+		}
+		if info == nil {
+			return
+		}
+		if res.Code != 0 {
+			t.config.ResultObserver.OnError(*info, resultTxError(res.Codespace, uint32(res.Code), res.RawLog))
+			return
+		}
+		height, _ := strconv.ParseInt(res.Height, 10, 64)
+		t.config.ResultObserver.OnConfirm(*info, TxConfirmation{Height: height, GasUsed: gasUsed, GasWanted: gasWanted})
+	}
+}
+
+// txExpired returns whether the given transaction, which wasn't found in a
+// block, has expired according to the client. If so, the expiry is counted
+// and the client is scheduled to be resynced, since the transaction's account
+// sequence number was never used.
+func (t *Transactor) txExpired(tx []byte) bool {
+	expirer, ok := t.client.(ClientTxExpirer)
+	if !ok {
+		return false
+	}
+	expired, err := expirer.TxExpired(tx)
+	if err != nil {
+		t.logger.Debug("Failed to check whether unconfirmed transaction expired", "err", err)
+		return false
+	}
+	if !expired {
+		return false
+	}
+	atomic.AddInt64(&t.expired, 1)
+	atomic.StoreInt32(&t.resyncPending, 1)
+	t.logger.Debug("Sampled transaction expired before being committed", "hash", txHash(tx))
+	return true
+}
+
+// GetExpired returns how many of this transactor's sampled transactions
+// expired before being committed.
+func (t *Transactor) GetExpired() int {
+	return int(atomic.LoadInt64(&t.expired))
+}
+
+// resyncIfPending resyncs the client if that was scheduled (e.g. because one
+// of its transactions expired), before it generates any more transactions.
+func (t *Transactor) resyncIfPending() {
+	if !atomic.CompareAndSwapInt32(&t.resyncPending, 1, 0) {
+		return
+	}
+	r, ok := t.client.(ClientResyncer)
+	if !ok {
+		return
+	}
+	if err := r.Resync(); err != nil {
+		t.logger.Error("Failed to resync client after a transaction expired", "err", err)
+		return
+	}
+	t.logger.Info("Resynced client after a transaction expired")
+}
+
+// isMempoolFullRPCError returns whether the given JSON-RPC error indicates
+// that CometBFT's mempool is full.
+func isMempoolFullRPCError(e *RPCError) bool {
+	return strings.Contains(e.Data, cometMempoolIsFullMsg) || strings.Contains(e.Message, cometMempoolIsFullMsg)
+}
+
+// isMempoolFullResult returns whether the given CheckTx result indicates that
+// the application's mempool is full.
+func isMempoolFullResult(codespace string, code uint32) bool {
+	return codespace == sdkCodespace && code == sdkCodeMempoolIsFull
+}
+
+// rpcErrorCategory classifies a JSON-RPC error for reporting purposes.
+// CometBFT reports most broadcast failures as a generic "Internal error"
+// whose data carries the actual reason (e.g. "mempool is full: number of txs
+// 5000 (max: 5000)..."), so we use the leading part of the data, which omits
+// the varying details.
+func rpcErrorCategory(e *RPCError) string {
+	reason := e.Data
+	if i := strings.Index(reason, ":"); i >= 0 {
+		reason = reason[:i]
+	}
+	reason = strings.TrimSpace(reason)
+	if len(reason) == 0 {
+		reason = e.Message
+	}
+	return fmt.Sprintf("rpc: %s", reason)
+}
+
+// resultErrorCategory classifies a non-zero CheckTx/ExecTx result code for
+// reporting purposes (e.g. "sdk/32" for an incorrect account sequence).
+func resultErrorCategory(codespace string, code uint32) string {
+	if len(codespace) == 0 {
+		codespace = "unknown"
+	}
+	return fmt.Sprintf("%s/%d", codespace, code)
+}
+
+// connLost reports the given error that made the transactor's connection fail
+// and marks the transactor as disconnected, if reconnection is enabled
+// (otherwise the send loop only finds out once it fails to write to the
+// connection).
+func (t *Transactor) connLost(err error) {
+	t.reportError(RunErrorConnection, err)
+	if t.config.MaxReconnectAttempts <= 0 {
+		return
+	}
+	t.setConnected(false)
+}
+
+func setPingHandler(conn *websocket.Conn) {
+	conn.SetPingHandler(func(message string) error {
+		err := conn.WriteControl(websocket.PongMessage, []byte(message), time.Now().Add(connSendTimeout))
 		if err == websocket.ErrCloseSent {
 			return nil
 		}
 		return err
 	})
+}
+
+func (t *Transactor) sendLoop() {
+	defer t.wg.Done()
+
+	lifecycle, _ := t.client.(ClientLifecycle)
+	if lifecycle != nil {
+		t.logger.Debug("Warming up client")
+		if err := lifecycle.Warmup(); err != nil {
+			err = fmt.Errorf("client warmup failed: %w", err)
+			t.logger.Error("Failed to warm up client", "err", err)
+			t.reportError(RunErrorClient, err)
+			t.setStop(err)
+			t.close()
+			return
+		}
+	}
 
 	pingTicker := time.NewTicker(connPingPeriod)
-	timeLimitTicker := time.NewTicker(time.Duration(t.config.Time) * time.Second)
-	sendTicker := time.NewTicker(time.Duration(t.config.SendPeriod) * time.Second)
+	// a time limit of 0 means that only the transaction count limit applies
+	var timeLimit <-chan time.Time
+	if t.config.Time > 0 {
+		timeLimitTimer := time.NewTimer(time.Duration(t.config.Time) * time.Second)
+		defer timeLimitTimer.Stop()
+		timeLimit = timeLimitTimer.C
+	}
+	// the send ticker only starts once the start delay has passed, while
+	// the connection is kept alive in the meantime
+	var sendTicker *time.Ticker
+	var sendTick <-chan time.Time
+	startTimer := time.NewTimer(t.startDelay)
 	progressTicker := time.NewTicker(t.getProgressCallbackInterval())
 	defer func() {
 		pingTicker.Stop()
-		timeLimitTicker.Stop()
-		sendTicker.Stop()
+		startTimer.Stop()
+		if sendTicker != nil {
+			sendTicker.Stop()
+		}
 		progressTicker.Stop()
 	}()
 
 	for {
-		if t.config.Count > 0 && t.GetTxCount() >= t.config.Count {
-			t.logger.Info("Maximum transaction limit reached", "count", t.GetTxCount())
+		if t.budget.exhausted() {
+			t.logger.Info("Total transaction limit reached", "limit", t.config.Count, "count", t.GetTxCount())
 			t.setStop(nil)
 		}
 		select {
-		case <-sendTicker.C:
+		case <-startTimer.C:
+			sendTicker = time.NewTicker(time.Duration(t.config.SendPeriod) * time.Second)
+			sendTick = sendTicker.C
+
+		case <-sendTick:
+			if !t.Connected() {
+				t.reconnect()
+				break
+			}
 			if err := t.sendTransactions(); err != nil {
-				t.logger.Error("Failed to send transactions", "err", err)
-				t.setStop(err)
+				t.handleSendError("Failed to send transactions", err)
 			}
 
 		case <-progressTicker.C:
 			t.reportProgress()
 
 		case <-pingTicker.C:
+			if !t.Connected() {
+				break
+			}
 			if err := t.sendPing(); err != nil {
-				t.logger.Error("Failed to write ping message", "err", err)
-				t.setStop(err)
+				t.handleSendError("Failed to write ping message", &connError{err})
 			}
 
-		case <-timeLimitTicker.C:
+		case <-timeLimit:
 			t.logger.Info("Time limit reached for load testing")
 			t.setStop(nil)
 		}
 		if t.mustStop() {
+			// there's nothing to wait for if we've lost the connection
+			if t.Connected() {
+				t.waitForInFlight()
+			}
+			if lifecycle != nil {
+				t.logger.Debug("Tearing down client")
+				if err := lifecycle.Teardown(); err != nil {
+					t.logger.Error("Failed to tear down client", "err", err)
+				}
+			}
 			t.close()
 			return
 		}
 	}
 }
 
-func (t *Transactor) writeTx(tx []byte) error {
+// handleSendError deals with an error encountered by the send loop. Connection
+// errors lead to reconnection attempts (if enabled), whereas any other error
+// stops the transactor.
+func (t *Transactor) handleSendError(msg string, err error) {
+	var ce *connError
+	var cle *clientError
+	switch {
+	case errors.As(err, &ce):
+		t.reportError(RunErrorConnection, err)
+	case errors.As(err, &cle):
+		t.reportError(RunErrorClient, err)
+	default:
+		t.reportError(RunErrorOther, err)
+	}
+	if ce == nil || t.config.MaxReconnectAttempts <= 0 {
+		t.logger.Error(msg, "err", err)
+		t.setStop(err)
+		return
+	}
+	t.logger.Error(msg+": lost connection to endpoint, will try to reconnect", "err", err)
+	t.conn.fail()
+	t.setConnected(false)
+}
+
+// reconnect attempts to re-establish a lost connection, backing off
+// exponentially between failed attempts. Once the maximum number of attempts
+// has been made, it gives up and stops the transactor.
+func (t *Transactor) reconnect() {
+	if time.Now().Before(t.nextReconnect) {
+		return
+	}
+	if t.reconnectAttempts >= t.config.MaxReconnectAttempts {
+		t.logger.Error("Giving up on reconnecting to endpoint", "attempts", t.reconnectAttempts)
+		err := fmt.Errorf("%w: %s", errEndpointUnavailable, t.remoteAddr)
+		t.reportError(RunErrorConnection, err)
+		t.setStop(err)
+		return
+	}
+	t.reconnectAttempts++
+	if err := t.conn.reconnect(); err != nil {
+		backoff := reconnectBackoffMin << (t.reconnectAttempts - 1)
+		if backoff > reconnectBackoffMax || backoff <= 0 {
+			backoff = reconnectBackoffMax
+		}
+		t.nextReconnect = time.Now().Add(backoff)
+		t.logger.Error("Failed to reconnect to endpoint", "attempt", t.reconnectAttempts, "maxAttempts", t.config.MaxReconnectAttempts, "retryIn", backoff.String(), "err", err)
+		t.reportError(RunErrorConnection, err)
+		return
+	}
+	// responses to requests sent over the old connection will never arrive
+	atomic.StoreInt64(&t.inFlight, 0)
+
+	// transactions may have been lost along with the old connection, leaving
+	// gaps in e.g. account sequence numbers
+	if r, ok := t.client.(ClientResyncer); ok {
+		if err := r.Resync(); err != nil {
+			t.logger.Error("Failed to resync client after reconnecting", "err", err)
+		}
+	}
+	t.logger.Info("Reconnected to endpoint", "attempts", t.reconnectAttempts)
+	t.reconnectAttempts = 0
+	t.nextReconnect = time.Time{}
+	t.setConnected(true)
+}
+
+// waitForInFlight blocks until all in-flight broadcast requests have received
+// responses, or until the drain deadline expires. Does nothing if we're not
+// draining.
+func (t *Transactor) waitForInFlight() {
+	t.stopMtx.RLock()
+	deadline := t.drainDeadline
+	t.stopMtx.RUnlock()
+	if deadline.IsZero() {
+		return
+	}
+	for atomic.LoadInt64(&t.inFlight) > 0 && time.Now().Before(deadline) {
+		time.Sleep(50 * time.Millisecond)
+	}
+	if remaining := atomic.LoadInt64(&t.inFlight); remaining > 0 {
+		t.logger.Info("Drain timeout reached with requests still in flight", "inFlight", remaining)
+	}
+}
+
+// writeTx broadcasts the given transaction. If a result observer is
+// registered, it is notified of the submission (and of a failure to write),
+// and the returned info identifies the transaction to it, unless the
+// transaction was injected (see sendInvalidTx).
+func (t *Transactor) writeTx(tx []byte, injected bool) (*TxInfo, error) {
 	txBase64 := base64.StdEncoding.EncodeToString(tx)
 	paramsJSON, err := json.Marshal(map[string]interface{}{"tx": txBase64})
 	if err != nil {
-		return err
+		return nil, err
+	}
+	var info *TxInfo
+	if t.config.ResultObserver != nil && !injected {
+		info = &TxInfo{
+			Hash:        txHash(tx),
+			Endpoint:    t.remoteAddr,
+			Transactor:  t.progressCallbackID,
+			SubmittedAt: time.Now(),
+		}
+		t.config.ResultObserver.OnSubmit(*info)
+	}
+	if err := t.conn.writeTx(t, info, injected, t.broadcastTxMethod, json.RawMessage(paramsJSON)); err != nil {
+		if info != nil {
+			t.config.ResultObserver.OnError(*info, &TxError{Category: TxErrorCategoryConnection, Log: err.Error()})
+		}
+		return nil, err
+	}
+	if t.config.ColdStart && !injected {
+		t.statsMtx.Lock()
+		if t.firstSentAt.IsZero() {
+			t.firstSentAt = time.Now()
+		}
+		t.statsMtx.Unlock()
+	}
+	return info, nil
+}
+
+// trackFirstCommit records how long after our first broadcast one of our
+// transactions was first confirmed as committed (see Config.ColdStart).
+func (t *Transactor) trackFirstCommit() {
+	if !t.config.ColdStart {
+		return
+	}
+	t.statsMtx.Lock()
+	defer t.statsMtx.Unlock()
+	if t.firstCommit == 0 && !t.firstSentAt.IsZero() {
+		t.firstCommit = time.Since(t.firstSentAt)
 	}
-	_ = t.conn.SetWriteDeadline(time.Now().Add(connSendTimeout))
-	return t.conn.WriteJSON(RPCRequest{
-		JSONRPC: "2.0",
-		ID:      jsonRPCID,
-		Method:  t.broadcastTxMethod,
-		Params:  json.RawMessage(paramsJSON),
-	})
+}
+
+// getFirstCommit returns whether we have sent any transactions and, if one
+// of them has been confirmed as committed, how long after the first broadcast
+// that was (0 otherwise).
+func (t *Transactor) getFirstCommit() (sent bool, latency time.Duration) {
+	t.statsMtx.RLock()
+	defer t.statsMtx.RUnlock()
+	return !t.firstSentAt.IsZero(), t.firstCommit
 }
 
 func (t *Transactor) mustStop() bool {
@@ -271,40 +1001,194 @@ func (t *Transactor) setStop(err error) {
 }
 
 func (t *Transactor) sendTransactions() error {
+	t.resyncIfPending()
 	// send as many transactions as we can, up to the send rate
 	totalSent := t.GetTxCount()
-	toSend := t.config.Rate
-	if (t.config.Count > 0) && ((totalSent + toSend) > t.config.Count) {
-		toSend = t.config.Count - totalSent
-		t.logger.Debug("Nearing max transaction count", "totalSent", totalSent, "maxTxCount", t.config.Count, "toSend", toSend)
-	}
 	if totalSent == 0 {
 		t.trackStartTime()
 	}
-	var sent int
+	toSend := t.spike.Rate(t.rate, time.Since(t.getStartTime()))
+	if t.rateScale != nil {
+		toSend = int(math.Round(float64(toSend) * t.rateScale()))
+	}
+	var sent, injected int
 	var sentBytes int64
-	defer func() { t.trackSentTxs(sent, sentBytes) }()
+	var sizes TxSizeStats
+	defer func() {
+		// injected transactions are counted separately
+		t.trackSentTxs(sent-injected, sentBytes)
+		t.trackTxSizes(sizes)
+	}()
 	// This is very noisy at high TPS (printed every send period, per connection).
 	// Keep it at DEBUG so default INFO output stays readable.
 	t.logger.Debug("Sending batch of transactions", "toSend", toSend)
+	sendWindow := time.Duration(t.config.SendPeriod) * time.Second
+	if delay := t.jitterDelay(); delay > 0 {
+		// offset the start of this batch so that transactors don't all send
+		// in lock-step at the send period boundaries
+		time.Sleep(delay)
+		sendWindow -= delay
+	}
 	batchStartTime := time.Now()
 	for ; sent < toSend; sent++ {
+		// rather than hammering a node whose mempool is full, skip the rest
+		// of this batch
+		if t.backingOff() {
+			t.logger.Debug("Backing off because the mempool is full", "sent", sent, "toSend", toSend)
+			break
+		}
+		// hold off generating more transactions while too many are
+		// unacknowledged
+		if !t.waitForCapacity(batchStartTime.Add(sendWindow)) {
+			t.logger.Debug("Blocked on backpressure for the rest of the batch", "sent", sent, "toSend", toSend)
+			break
+		}
+		// mix in deliberately invalid transactions, which don't count
+		// towards the transaction limit
+		if t.injectFailure() {
+			if err := t.sendInvalidTx(); err != nil {
+				return err
+			}
+			injected++
+			continue
+		}
+		// stop early if the total transaction limit has been reached
+		if !t.budget.reserve() {
+			break
+		}
 		tx, err := t.client.GenerateTx()
+		if errors.Is(err, ErrClientExhausted) {
+			t.budget.release()
+			t.logger.Info("Client has no more transactions to send", "count", totalSent+sent)
+			t.setStop(nil)
+			return nil
+		}
 		if err != nil {
-			return err
+			t.budget.release()
+			return &clientError{err}
 		}
-		if err := t.writeTx(tx); err != nil {
-			return err
+		info, err := t.writeTx(tx, false)
+		if err != nil {
+			t.budget.release()
+			return &connError{err}
 		}
 		sentBytes += int64(len(tx))
+		if !t.warmingUp() {
+			sizes.Add(len(tx))
+		}
+		if t.recorder != nil {
+			if err := t.recorder.Record(t.progressCallbackID, tx); err != nil {
+				// the transaction has already been sent, so still counts
+				sent++
+				return &clientError{err}
+			}
+		}
+		if t.confirmer != nil && (totalSent+sent)%t.config.ConfirmEvery == 0 && !t.warmingUp() {
+			if t.confirmer.Submit(tx, t.remoteAddr, t.restURL, t.confirmResult(tx, info), t.confirmResolved) {
+				atomic.AddInt64(&t.confirming, 1)
+			}
+		}
+		if t.config.ResyncEvery > 0 && (totalSent+sent+1)%t.config.ResyncEvery == 0 {
+			t.checkSequence()
+		}
 		// if we have to make way for the next batch
-		if time.Since(batchStartTime) >= time.Duration(t.config.SendPeriod)*time.Second {
+		if time.Since(batchStartTime) >= sendWindow {
 			break
 		}
 	}
 	return nil
 }
 
+// injectFailure returns whether the next transaction should be a deliberately
+// invalid one, which is the case for the configured percentage of them (if the
+// client can generate them).
+func (t *Transactor) injectFailure() bool {
+	return t.injector != nil && t.rng.Float64()*100 < t.config.InjectFailures
+}
+
+// sendInvalidTx generates and broadcasts a transaction that the chain is bound
+// to reject (see ClientFailureInjector).
+func (t *Transactor) sendInvalidTx() error {
+	tx, err := t.injector.GenerateInvalidTx()
+	if err != nil {
+		return &clientError{err}
+	}
+	if _, err := t.writeTx(tx, true); err != nil {
+		return &connError{err}
+	}
+	t.statsMtx.Lock()
+	t.injected++
+	t.statsMtx.Unlock()
+	return nil
+}
+
+// pendingTxs returns the number of our transactions that are still awaiting
+// acknowledgement: broadcast requests without a response, plus sampled
+// transactions that have yet to be confirmed.
+func (t *Transactor) pendingTxs() int64 {
+	return atomic.LoadInt64(&t.inFlight) + atomic.LoadInt64(&t.confirming)
+}
+
+func (t *Transactor) confirmResolved() {
+	atomic.AddInt64(&t.confirming, -1)
+}
+
+// waitForCapacity blocks while the number of pending transactions is at the
+// configured maximum, returning false if the given deadline passes (or we're
+// told to stop) before there's room for another transaction.
+func (t *Transactor) waitForCapacity(deadline time.Time) bool {
+	limit := int64(t.config.MaxInFlight)
+	if limit <= 0 || t.pendingTxs() < limit {
+		return true
+	}
+	atomic.StoreInt32(&t.blocked, 1)
+	defer atomic.StoreInt32(&t.blocked, 0)
+	for t.pendingTxs() >= limit {
+		if t.mustStop() || !time.Now().Before(deadline) {
+			return false
+		}
+		time.Sleep(backpressurePollInterval)
+	}
+	return true
+}
+
+// Blocked returns whether the transactor is currently holding off generating
+// transactions because too many are in flight (see Config.MaxInFlight).
+func (t *Transactor) Blocked() bool {
+	return atomic.LoadInt32(&t.blocked) == 1
+}
+
+// checkSequence has the client correct any drift between its locally tracked
+// state and the chain's (see ClientSequenceChecker). Failures are logged rather
+// than stopping the transactor, since the check is only a safety net.
+func (t *Transactor) checkSequence() {
+	checker, ok := t.client.(ClientSequenceChecker)
+	if !ok {
+		return
+	}
+	corrected, err := checker.CheckSequence()
+	if err != nil {
+		t.logger.Error("Failed to check client sequence against the chain", "err", err)
+		return
+	}
+	if corrected {
+		t.logger.Info("Corrected client sequence that had drifted from the chain's")
+	}
+}
+
+// jitterDelay returns a random delay in [0, jitter*sendPeriod) by which to
+// offset the start of the next batch of transactions.
+func (t *Transactor) jitterDelay() time.Duration {
+	if t.config.Jitter <= 0 {
+		return 0
+	}
+	maxDelay := time.Duration(t.config.Jitter * float64(time.Duration(t.config.SendPeriod)*time.Second))
+	if maxDelay <= 0 {
+		return 0
+	}
+	return time.Duration(t.rng.Int63n(int64(maxDelay)))
+}
+
 func (t *Transactor) trackStartTime() {
 	t.statsMtx.Lock()
 	t.startTime = time.Now()
@@ -312,6 +1196,12 @@ func (t *Transactor) trackStartTime() {
 	t.statsMtx.Unlock()
 }
 
+func (t *Transactor) getStartTime() time.Time {
+	t.statsMtx.RLock()
+	defer t.statsMtx.RUnlock()
+	return t.startTime
+}
+
 func (t *Transactor) trackSentTxs(count int, byteCount int64) {
 	t.statsMtx.Lock()
 	defer t.statsMtx.Unlock()
@@ -326,9 +1216,52 @@ func (t *Transactor) trackSentTxs(count int, byteCount int64) {
 	}
 }
 
+func (t *Transactor) trackTxSizes(sizes TxSizeStats) {
+	t.statsMtx.Lock()
+	t.txSizes.Merge(sizes)
+	t.statsMtx.Unlock()
+}
+
+// trackMempoolFull counts a mempool-full rejection and, if configured, backs
+// off from sending further transactions for a while.
+func (t *Transactor) trackMempoolFull() {
+	t.statsMtx.Lock()
+	t.mempoolFull++
+	t.statsMtx.Unlock()
+	if t.config.MempoolFullBackoff > 0 {
+		until := time.Now().Add(time.Duration(t.config.MempoolFullBackoff) * time.Millisecond)
+		atomic.StoreInt64(&t.backoffUntil, until.UnixNano())
+	}
+}
+
+// trackBroadcastLatency records how long a broadcast request took to be
+// answered.
+func (t *Transactor) trackBroadcastLatency(latency time.Duration) {
+	atomic.AddInt64(&t.broadcastLatency, int64(latency))
+	atomic.AddInt64(&t.broadcastResponses, 1)
+}
+
+// getBroadcastLatency returns the total time that our broadcast requests took
+// to be answered, and how many were answered.
+func (t *Transactor) getBroadcastLatency() (time.Duration, int64) {
+	return time.Duration(atomic.LoadInt64(&t.broadcastLatency)), atomic.LoadInt64(&t.broadcastResponses)
+}
+
+// backingOff returns whether we are currently refraining from sending because
+// the mempool was recently full.
+func (t *Transactor) backingOff() bool {
+	return time.Now().UnixNano() < atomic.LoadInt64(&t.backoffUntil)
+}
+
+func (t *Transactor) trackTxError(category string) {
+	t.statsMtx.Lock()
+	t.txErrors++
+	t.errorCats[category]++
+	t.statsMtx.Unlock()
+}
+
 func (t *Transactor) sendPing() error {
-	_ = t.conn.SetWriteDeadline(time.Now().Add(connSendTimeout))
-	return t.conn.WriteMessage(websocket.PingMessage, []byte{})
+	return t.conn.ping()
 }
 
 func (t *Transactor) reportProgress() {
@@ -350,13 +1283,8 @@ func (t *Transactor) getProgressCallbackInterval() time.Duration {
 	return t.progressCallbackInterval
 }
 
+// close releases the transactor's connection, which is shut down once none of
+// its transactors are using it any more.
 func (t *Transactor) close() {
-	// try to cleanly shut down the connection
-	_ = t.conn.SetWriteDeadline(time.Now().Add(connSendTimeout))
-	err := t.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
-	if err != nil {
-		t.logger.Error("Failed to write close message", "err", err)
-	} else {
-		t.logger.Debug("Wrote close message to remote endpoint")
-	}
+	t.conn.release(t)
 }
@@ -1,9 +1,14 @@
 package loadtest
 
 import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/1119-Labs/perpx-load-test/internal/httpclient"
 	"github.com/1119-Labs/perpx-load-test/internal/logging"
 )
 
@@ -17,10 +22,63 @@ type TransactorGroup struct {
 	txCounts  map[int]int   // The counts of all of the total transactions per transactor.
 	txBytes   map[int]int64 // The total number of transaction bytes sent per transactor.
 
+	warmup         time.Duration // How long after starting to wait before collecting statistics.
+	warmupTimer    *time.Timer   // Fires at the end of the warmup period.
+	warmupEnd      time.Time     // When the warmup period ended (zero until then).
+	warmupBaseline []txStats     // Each transactor's counters at the end of the warmup period.
+
+	peakTxRate       float64   // The highest transaction rate observed over a single progress reporting interval.
+	lastProgressTime time.Time // When we last sampled the total transaction count for the peak rate.
+	lastProgressTxs  int       // The total transaction count at lastProgressTime.
+
 	progressCallbackMtx      sync.RWMutex
 	progressCallbackInterval time.Duration
 	progressCallback         func(g *TransactorGroup, txCount int, txBytes int64)
 
+	confirmer *txConfirmer   // Only set if transaction confirmation is enabled.
+	budget    *txBudget      // Shared by all transactors so that the transaction count limit applies to the group as a whole.
+	fees      FeeTracker     // Only set if the client factory tracks the fees of the transactions it generates.
+	accounts  AccountTracker // Only set if the client factory tracks the accounts touched by the transactions it generates.
+	stopper   FactoryStopper // Only set if the client factory runs background tasks on behalf of its clients.
+
+	// With confirmation enabled, the chain's accounts are counted before and
+	// after the run, to tell how many it gained.
+	accountsMtx     sync.Mutex
+	accountsBefore  int  // The number of accounts when the group started.
+	accountsCounted bool // Set if accountsBefore could be counted.
+	accountsCreated *int // Set once the number of accounts gained is known.
+
+	connResults []ConnectionResult   // The outcome of each connection attempt made by AddAll.
+	connPools   map[string]*connPool // The connections to each endpoint, shared by its transactors.
+	recorder    *TxRecorder          // Only set if the transactions sent are being recorded.
+
+	errorHandler RunErrorHandler // If set, errors are reported to this as they occur.
+
+	connMtx   sync.RWMutex
+	rateScale float64 // By how much the surviving transactors scale up their rates to make up for disconnected ones.
+	paused    bool    // Set while the generation of transactions is paused (see Pause).
+
+	tps                  *TPSController // Only set if a target TPS is configured.
+	tpsInterval          time.Duration  // How often the controller adjusts the rate.
+	stopTPSController    chan struct{}  // Close this to stop the controller.
+	tpsControllerStopped chan struct{}  // Closed when the controller goroutine has completely stopped.
+
+	router         *AdaptiveRouter // Only set if adaptive routing is enabled.
+	routerInterval time.Duration   // How often the endpoints are reweighted.
+	stopRouter     chan struct{}   // Close this to stop the router.
+	routerStopped  chan struct{}   // Closed when the router goroutine has completely stopped.
+
+	httpClient *http.Client // Only set if overridden with SetHTTPClient.
+
+	halt      *HaltWatchdog // Only set if chain halt detection is enabled.
+	coldStart bool          // Set if the transactors track their first commits (see Config.ColdStart).
+
+	startStagger time.Duration // How long to wait between connecting, and between starting, successive transactors.
+
+	drainMtx      sync.RWMutex
+	drainDeadline time.Time // Set if we are draining rather than running to completion.
+	haltStopped   bool      // Set if we are draining because the chain appears halted.
+
 	stopProgressReporter    chan struct{} // Close this to stop the progress reporter.
 	progressReporterStopped chan struct{} // Closed when the progress reporter goroutine has completely stopped.
 
@@ -32,13 +90,30 @@ func NewTransactorGroup() *TransactorGroup {
 		transactors:              make([]*Transactor, 0),
 		txCounts:                 make(map[int]int),
 		txBytes:                  make(map[int]int64),
+		connPools:                make(map[string]*connPool),
 		progressCallbackInterval: defaultProgressCallbackInterval,
+		rateScale:                1,
 		stopProgressReporter:     make(chan struct{}, 1),
 		progressReporterStopped:  make(chan struct{}, 1),
 		logger:                   logging.NewNoopLogger(),
 	}
 }
 
+// SetErrorHandler configures the handler to which the group's transactors
+// report errors as they occur (including failures to connect). Must be called
+// before AddAll.
+func (g *TransactorGroup) SetErrorHandler(h RunErrorHandler) {
+	g.errorHandler = h
+}
+
+// SetHTTPClient configures the HTTP client with which the group queries the
+// REST API (e.g. to confirm sampled transactions), instead of one set up from
+// the configured HTTPTimeout, HTTPMaxIdleConns and Compression. Must be called
+// before AddAll.
+func (g *TransactorGroup) SetHTTPClient(client *http.Client) {
+	g.httpClient = client
+}
+
 func (g *TransactorGroup) SetLogger(logger logging.Logger) {
 	g.logger = logger
 }
@@ -47,29 +122,450 @@ func (g *TransactorGroup) SetLogger(logger logging.Logger) {
 // instantiation fails it'll automatically shut down and close all other
 // transactors, returning the error.
 func (g *TransactorGroup) Add(remoteAddr string, config *Config) error {
-	t, err := NewTransactor(remoteAddr, config)
-	if err != nil {
+	if err := g.add(remoteAddr, config, nil); err != nil {
 		g.close()
 		return err
 	}
+	return nil
+}
+
+// add connects a new transactor to the given endpoint, recording the outcome
+// of the connection attempt. If client is nil, the transactor's client is
+// created using the configured client factory.
+func (g *TransactorGroup) add(remoteAddr string, config *Config, client Client) error {
+	pool, ok := g.connPools[remoteAddr]
+	if !ok {
+		var err error
+		if pool, err = newConnPool(remoteAddr, config.ConnectionPoolSize); err != nil {
+			return err
+		}
+		g.connPools[remoteAddr] = pool
+	}
+	if g.startStagger > 0 && len(g.transactors) > 0 {
+		// connections are established gradually too
+		time.Sleep(g.startStagger)
+	}
+	conn, dialed, err := pool.get()
+	if dialed {
+		var dialErr *DialError
+		if errors.As(err, &dialErr) {
+			g.connResults = append(g.connResults, ConnectionResult{Endpoint: remoteAddr, Latency: dialErr.Latency, Err: dialErr})
+			if g.errorHandler != nil {
+				g.errorHandler(&RunError{Kind: RunErrorConnection, Endpoint: remoteAddr, Time: time.Now(), Err: dialErr})
+			}
+		} else if err == nil {
+			g.connResults = append(g.connResults, ConnectionResult{Endpoint: remoteAddr, Latency: conn.latency})
+		}
+	}
+	if err != nil {
+		return err
+	}
 	id := len(g.transactors)
+	t, err := newTransactor(conn, config, client, id)
+	if err != nil {
+		return err
+	}
 	t.SetProgressCallback(id, g.getProgressCallbackInterval()/2, g.trackTransactorProgress)
+	if g.confirmer != nil {
+		t.SetConfirmer(g.confirmer)
+	}
+	if g.budget == nil {
+		g.budget = newTxBudget(config.Count)
+	}
+	t.SetTxBudget(g.budget)
+	t.SetRateScale(func() float64 {
+		return g.getRateScale() * g.router.Weight(remoteAddr)
+	})
+	if g.recorder != nil {
+		t.SetRecorder(g.recorder)
+	}
+	if g.errorHandler != nil {
+		t.SetErrorHandler(g.errorHandler)
+	}
+	t.SetConnStateCallback(func(connected bool) {
+		g.connStateChanged(remoteAddr, connected)
+	})
 	g.transactors = append(g.transactors, t)
 	g.logger.Debug("Added transactor", "remoteAddr", remoteAddr)
 	return nil
 }
 
 func (g *TransactorGroup) AddAll(cfg *Config) error {
-	for _, endpoint := range cfg.Endpoints {
+	if g.errorHandler != nil {
+		// rejected transactions are reported through the result observer,
+		// which we don't want to register in the caller's config
+		observed := *cfg
+		observed.ResultObserver = &errorObserver{next: cfg.ResultObserver, handler: g.errorHandler}
+		cfg = &observed
+	}
+	g.warmup = time.Duration(cfg.WarmupSeconds) * time.Second
+	g.coldStart = cfg.ColdStart
+	g.startStagger = time.Duration(cfg.WorkerStartStagger) * time.Millisecond
+	if cfg.TargetTPS > 0 && g.tps == nil {
+		g.tps = NewTPSController(float64(cfg.TargetTPS))
+		g.tpsInterval = time.Duration(cfg.SendPeriod) * time.Second
+		g.stopTPSController = make(chan struct{})
+		g.tpsControllerStopped = make(chan struct{})
+	}
+	if cfg.Confirm && g.confirmer == nil {
+		httpClient := g.httpClient
+		if httpClient == nil {
+			httpClient = httpclient.New(time.Duration(cfg.HTTPTimeout)*time.Second, cfg.HTTPMaxIdleConns, cfg.Compression)
+		}
+		g.confirmer = newTxConfirmer(time.Duration(cfg.ConfirmTimeout)*time.Second, httpClient, cfg.ConfirmTransport == ConfirmTransportWS, g.logger)
+	}
+	if cfg.HaltTimeout > 0 && g.halt == nil && len(cfg.Endpoints) > 0 {
+		g.halt = NewHaltWatchdog(cfg.Endpoints[0], time.Duration(cfg.HaltTimeout)*time.Second, g.logger)
+		if cfg.StopOnHalt {
+			drainTimeout := time.Duration(cfg.DrainTimeout) * time.Second
+			g.halt.onHalt = func() { g.stopOnHalt(drainTimeout) }
+		}
+	}
+	if len(cfg.Record) > 0 && g.recorder == nil {
+		recorder, err := NewTxRecorder(cfg.Record)
+		if err != nil {
+			return err
+		}
+		g.recorder = recorder
+	}
+	if len(cfg.ReplayFile) > 0 {
+		return g.addReplay(cfg)
+	}
+	if fees, ok := clientFactories[cfg.ClientFactory].(FeeTracker); ok {
+		g.fees = fees
+	}
+	if accounts, ok := clientFactories[cfg.ClientFactory].(AccountTracker); ok {
+		g.accounts = accounts
+	}
+	if stopper, ok := clientFactories[cfg.ClientFactory].(FactoryStopper); ok {
+		g.stopper = stopper
+	}
+	weights, err := ParseEndpointWeights(cfg.EndpointWeights)
+	if err != nil {
+		return err
+	}
+	if unmatched := weights.Unmatched(cfg.Endpoints); len(unmatched) > 0 {
+		g.logger.Error("Some endpoint weights don't match any endpoint and will be ignored", "weights", unmatched)
+	}
+	rates := weights.Rates(cfg.Rate, cfg.Endpoints)
+	// we try every endpoint before giving up so that all of the connection
+	// failures can be reported at once
+	attempts := cfg.Connections
+	if cfg.ConnectionPoolSize > 0 && cfg.ConnectionPoolSize < attempts {
+		attempts = cfg.ConnectionPoolSize
+	}
+	var failed []string
+	for i, endpoint := range cfg.Endpoints {
+		if len(weights) > 0 {
+			g.logger.Info("Weighted endpoint rate", "endpoint", endpoint, "weight", weights.Weight(endpoint), "rate", rates[i])
+		}
 		for c := 0; c < cfg.Connections; c++ {
-			if err := g.Add(endpoint, cfg); err != nil {
+			err := g.add(endpoint, cfg, nil)
+			var dialErr *DialError
+			if errors.As(err, &dialErr) {
+				g.logger.Error("Failed to connect to endpoint", "endpoint", endpoint, "kind", dialErr.Kind, "err", dialErr.Err)
+				failed = append(failed, endpoint)
+				// further connections to the same endpoint are bound to fail
+				// the same way
+				for ; c < attempts-1; c++ {
+					g.connResults = append(g.connResults, ConnectionResult{Endpoint: endpoint, Err: dialErr})
+				}
+				break
+			}
+			if err != nil {
+				g.close()
 				return err
 			}
+			g.transactors[len(g.transactors)-1].SetRate(rates[i])
+		}
+	}
+	if len(failed) > 0 {
+		g.close()
+		return fmt.Errorf("failed to connect to %d of %d endpoint(s): %s", len(failed), len(cfg.Endpoints), strings.Join(failed, ", "))
+	}
+	if cfg.InjectFailures > 0 && len(g.transactors) > 0 && g.transactors[0].injector == nil {
+		g.logger.Error("The client can't generate invalid transactions, so none will be injected", "injectFailures", cfg.InjectFailures)
+	}
+	if cfg.AdaptiveRouting && g.router == nil {
+		baseRates := make(map[string]float64)
+		for _, t := range g.transactors {
+			baseRates[t.remoteAddr] += float64(t.rate)
+		}
+		g.router = NewAdaptiveRouter(baseRates)
+		g.routerInterval = time.Duration(cfg.SendPeriod) * time.Second
+		g.stopRouter = make(chan struct{})
+		g.routerStopped = make(chan struct{})
+	}
+	return g.prepareClients(cfg.PrepareConcurrency)
+}
+
+// addReplay adds a transactor for each stream in the recording being
+// replayed, spreading them across the endpoints, each of which sends the
+// stream's transactions exactly as recorded.
+func (g *TransactorGroup) addReplay(cfg *Config) error {
+	streams, err := ReadTxStreams(cfg.ReplayFile)
+	if err != nil {
+		g.close()
+		return err
+	}
+	if len(streams) == 0 {
+		g.close()
+		return fmt.Errorf("%s: recording contains no transactions", cfg.ReplayFile)
+	}
+	total := 0
+	for _, txs := range streams {
+		total += len(txs)
+	}
+	g.logger.Info("Replaying recorded transactions", "streams", len(streams), "txs", total)
+	var failed []string
+	for i, txs := range streams {
+		endpoint := cfg.Endpoints[i%len(cfg.Endpoints)]
+		err := g.add(endpoint, cfg, &replayClient{txs: txs})
+		var dialErr *DialError
+		if errors.As(err, &dialErr) {
+			g.logger.Error("Failed to connect to endpoint", "endpoint", endpoint, "kind", dialErr.Kind, "err", dialErr.Err)
+			failed = append(failed, endpoint)
+			continue
+		}
+		if err != nil {
+			g.close()
+			return err
 		}
 	}
+	if len(failed) > 0 {
+		g.close()
+		return fmt.Errorf("failed to connect to endpoint(s): %s", strings.Join(failed, ", "))
+	}
 	return nil
 }
 
+// prepareClients prepares all of the group's clients that implement
+// ClientPreparer, with at most the given number being prepared at a time. If
+// concurrency is 0, clients are left to prepare themselves lazily.
+func (g *TransactorGroup) prepareClients(concurrency int) error {
+	var preparers []ClientPreparer
+	for _, t := range g.transactors {
+		if p, ok := t.client.(ClientPreparer); ok {
+			preparers = append(preparers, p)
+		}
+	}
+	if len(preparers) == 0 || concurrency < 1 {
+		return nil
+	}
+
+	g.logger.Info("Preparing clients", "clients", len(preparers), "concurrency", concurrency)
+	startTime := time.Now()
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	sem := make(chan struct{}, concurrency)
+	for _, p := range preparers {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(p ClientPreparer) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			if err := p.Prepare(); err != nil {
+				errOnce.Do(func() { firstErr = err })
+			}
+		}(p)
+	}
+	wg.Wait()
+	if firstErr != nil {
+		g.close()
+		return fmt.Errorf("failed to prepare clients: %w", firstErr)
+	}
+	g.logger.Info("Prepared clients", "clients", len(preparers), "elapsed", time.Since(startTime).Truncate(time.Millisecond).String())
+	return nil
+}
+
+// connStateChanged redistributes the load among the transactors whose
+// connections are healthy whenever a transactor loses or restores its
+// connection.
+func (g *TransactorGroup) connStateChanged(remoteAddr string, connected bool) {
+	totalRate, healthyRate := 0, 0
+	for _, t := range g.transactors {
+		totalRate += t.rate
+		if t.Connected() {
+			healthyRate += t.rate
+		}
+	}
+	scale := 1.0
+	if healthyRate > 0 {
+		scale = float64(totalRate) / float64(healthyRate)
+	}
+	g.connMtx.Lock()
+	g.rateScale = scale
+	g.connMtx.Unlock()
+
+	healthy, total := g.HealthyEndpoints()
+	if connected {
+		g.logger.Info("Endpoint connection restored", "endpoint", remoteAddr, "healthyEndpoints", healthy, "totalEndpoints", total, "rateScale", scale)
+	} else {
+		g.logger.Error("Endpoint connection lost, redistributing its load", "endpoint", remoteAddr, "healthyEndpoints", healthy, "totalEndpoints", total, "rateScale", scale)
+	}
+}
+
+// baseTxRate returns the total number of transactions per second that the
+// group's transactors are configured to send (taking endpoint weights into
+// account), outside of spikes and before their rates are scaled.
+func (g *TransactorGroup) baseTxRate(sendPeriod int) float64 {
+	total := 0
+	for _, t := range g.transactors {
+		total += t.rate
+	}
+	return float64(total) / float64(sendPeriod)
+}
+
+func (g *TransactorGroup) getRateScale() float64 {
+	g.connMtx.RLock()
+	defer g.connMtx.RUnlock()
+	if g.paused {
+		return 0
+	}
+	return g.rateScale * g.tps.Multiplier()
+}
+
+// Pause stops the group's transactors from generating new transactions, from
+// their next send period onwards, until Resume is called. Their connections
+// stay open and the time limit keeps counting down while paused.
+func (g *TransactorGroup) Pause() {
+	g.connMtx.Lock()
+	g.paused = true
+	g.connMtx.Unlock()
+	g.logger.Info("Paused transaction generation")
+}
+
+// Resume resumes the generation of transactions after a call to Pause.
+func (g *TransactorGroup) Resume() {
+	g.connMtx.Lock()
+	wasPaused := g.paused
+	g.paused = false
+	g.connMtx.Unlock()
+	if wasPaused {
+		g.logger.Info("Resumed transaction generation")
+	}
+}
+
+// Paused reports whether the generation of transactions is paused.
+func (g *TransactorGroup) Paused() bool {
+	g.connMtx.RLock()
+	defer g.connMtx.RUnlock()
+	return g.paused
+}
+
+// TPSMultiplier returns the multiplier that the target-TPS controller
+// currently applies to the configured rate, or 1 if no target is configured.
+func (g *TransactorGroup) TPSMultiplier() float64 {
+	return g.tps.Multiplier()
+}
+
+// controlTPS feeds the rate at which transactions are accepted (i.e. sent and
+// not rejected) to the target-TPS controller once per send period.
+func (g *TransactorGroup) controlTPS() {
+	defer close(g.tpsControllerStopped)
+
+	ticker := time.NewTicker(g.tpsInterval)
+	defer ticker.Stop()
+
+	lastTime, lastAccepted := time.Now(), g.acceptedTxs()
+	for {
+		select {
+		case <-ticker.C:
+			now, accepted := time.Now(), g.acceptedTxs()
+			// there's nothing to control while paused, and the integral
+			// would wind up if we tried
+			if !g.Paused() {
+				achieved := float64(accepted-lastAccepted) / now.Sub(lastTime).Seconds()
+				multiplier := g.tps.Update(achieved)
+				g.logger.Debug("Adjusted rate towards target TPS", "achieved", achieved, "multiplier", multiplier)
+			}
+			lastTime, lastAccepted = now, accepted
+
+		case <-g.stopTPSController:
+			return
+		}
+	}
+}
+
+// routeAdaptively reweights the endpoints according to their average
+// broadcast latencies once per send period.
+func (g *TransactorGroup) routeAdaptively() {
+	defer close(g.routerStopped)
+
+	ticker := time.NewTicker(g.routerInterval)
+	defer ticker.Stop()
+
+	lastLatency, lastResponses := g.broadcastLatencies()
+	for {
+		select {
+		case <-ticker.C:
+			latency, responses := g.broadcastLatencies()
+			averages := make(map[string]time.Duration)
+			for endpoint, total := range latency {
+				if n := responses[endpoint] - lastResponses[endpoint]; n > 0 {
+					averages[endpoint] = (total - lastLatency[endpoint]) / time.Duration(n)
+				}
+			}
+			weights := g.router.Update(averages)
+			g.logger.Debug("Reweighted endpoints by broadcast latency", "latencies", averages, "weights", weights)
+			lastLatency, lastResponses = latency, responses
+
+		case <-g.stopRouter:
+			return
+		}
+	}
+}
+
+// broadcastLatencies returns the total time that broadcast requests to each
+// endpoint took to be answered, and how many were answered.
+func (g *TransactorGroup) broadcastLatencies() (map[string]time.Duration, map[string]int64) {
+	latency := make(map[string]time.Duration)
+	responses := make(map[string]int64)
+	for _, t := range g.transactors {
+		l, n := t.getBroadcastLatency()
+		latency[t.remoteAddr] += l
+		responses[t.remoteAddr] += n
+	}
+	return latency, responses
+}
+
+// EndpointWeight returns the weight by which adaptive routing multiplies the
+// given endpoint's rate (1 if adaptive routing is disabled).
+func (g *TransactorGroup) EndpointWeight(endpoint string) float64 {
+	return g.router.Weight(endpoint)
+}
+
+// acceptedTxs returns the number of transactions sent so far that weren't
+// rejected by the endpoints.
+func (g *TransactorGroup) acceptedTxs() int {
+	accepted := 0
+	for _, t := range g.transactors {
+		accepted += t.GetTxCount() - t.GetTxErrors()
+	}
+	return accepted
+}
+
+// HealthyEndpoints returns the number of distinct endpoints to which at least
+// one of the group's transactors is currently connected, along with the total
+// number of distinct endpoints.
+func (g *TransactorGroup) HealthyEndpoints() (healthy, total int) {
+	endpoints := make(map[string]bool)
+	for _, t := range g.transactors {
+		endpoints[t.remoteAddr] = endpoints[t.remoteAddr] || t.Connected()
+	}
+	for _, connected := range endpoints {
+		if connected {
+			healthy++
+		}
+	}
+	return healthy, len(endpoints)
+}
+
 func (g *TransactorGroup) SetProgressCallback(interval time.Duration, callback func(*TransactorGroup, int, int64)) {
 	g.progressCallbackMtx.Lock()
 	g.progressCallbackInterval = interval
@@ -79,11 +575,109 @@ func (g *TransactorGroup) SetProgressCallback(interval time.Duration, callback f
 
 // Start will handle through all transactors and start them.
 func (g *TransactorGroup) Start() {
+	g.accountsBefore, g.accountsCounted = g.countAccounts()
 	go g.progressReporter()
-	for _, t := range g.transactors {
+	if g.tps != nil {
+		go g.controlTPS()
+	}
+	if g.router != nil {
+		go g.routeAdaptively()
+	}
+	if g.confirmer != nil {
+		g.confirmer.Start()
+	}
+	if g.halt != nil {
+		g.halt.Start()
+	}
+	var warmupEnd time.Time
+	if g.warmup > 0 {
+		warmupEnd = time.Now().Add(g.warmup)
+	}
+	for i, t := range g.transactors {
+		t.SetWarmupEnd(warmupEnd)
+		t.SetStartDelay(time.Duration(i) * g.startStagger)
 		t.Start()
 	}
 	g.setStartTime(time.Now())
+	if g.warmup > 0 {
+		g.warmupTimer = time.AfterFunc(g.warmup, g.endWarmup)
+	}
+}
+
+// endWarmup takes a snapshot of each transactor's counters, from which
+// statistics will be measured from now on.
+func (g *TransactorGroup) endWarmup() {
+	baseline := make([]txStats, len(g.transactors))
+	for i, t := range g.transactors {
+		baseline[i] = t.getTxStats()
+	}
+	g.statsMtx.Lock()
+	g.warmupEnd = time.Now()
+	g.warmupBaseline = baseline
+	g.statsMtx.Unlock()
+	g.logger.Info("Warmup period complete, collecting statistics")
+}
+
+// WarmupComplete returns false if a warmup period was configured but has not
+// yet elapsed.
+func (g *TransactorGroup) WarmupComplete() bool {
+	if g.warmup <= 0 {
+		return true
+	}
+	g.statsMtx.RLock()
+	defer g.statsMtx.RUnlock()
+	return !g.warmupEnd.IsZero()
+}
+
+// measureStartTime returns the time from which statistics are measured: the
+// end of the warmup period if it has elapsed, otherwise the start time.
+func (g *TransactorGroup) measureStartTime() time.Time {
+	g.statsMtx.RLock()
+	defer g.statsMtx.RUnlock()
+	if !g.warmupEnd.IsZero() {
+		return g.warmupEnd
+	}
+	return g.startTime
+}
+
+// measuredStats returns each transactor's counters, excluding anything
+// accumulated during the warmup period.
+func (g *TransactorGroup) measuredStats() []txStats {
+	g.statsMtx.RLock()
+	baseline := g.warmupBaseline
+	g.statsMtx.RUnlock()
+	stats := make([]txStats, len(g.transactors))
+	for i, t := range g.transactors {
+		stats[i] = t.getTxStats()
+		if baseline != nil {
+			stats[i] = stats[i].sub(baseline[i])
+		}
+	}
+	return stats
+}
+
+// measuredTotals returns the group's total counters, excluding anything
+// accumulated during the warmup period.
+func (g *TransactorGroup) measuredTotals() txStats {
+	totals := txStats{errorCats: make(map[string]int)}
+	for _, s := range g.measuredStats() {
+		totals.txs += s.txs
+		totals.bytes += s.bytes
+		totals.sizes.Merge(s.sizes)
+		totals.errors += s.errors
+		totals.mempoolFull += s.mempoolFull
+		totals.injected += s.injected
+		totals.injectedRejected += s.injectedRejected
+		for cat, count := range s.errorCats {
+			totals.errorCats[cat] += count
+		}
+	}
+	return totals
+}
+
+// ConnectionResults returns the outcome of each connection attempt made so far.
+func (g *TransactorGroup) ConnectionResults() []ConnectionResult {
+	return g.connResults
 }
 
 // Cancel signals to all transactors to stop their operations.
@@ -93,12 +687,113 @@ func (g *TransactorGroup) Cancel() {
 	}
 }
 
+// Drain signals to all transactors to stop generating new transactions, while
+// allowing up to the given timeout for in-flight requests and outstanding
+// confirmations to settle.
+func (g *TransactorGroup) Drain(timeout time.Duration) {
+	deadline := time.Now().Add(timeout)
+	g.drainMtx.Lock()
+	g.drainDeadline = deadline
+	g.drainMtx.Unlock()
+	for _, t := range g.transactors {
+		t.Drain(deadline)
+	}
+}
+
+// stopOnHalt drains the group's transactors because the chain appears
+// halted, allowing up to the given timeout for in-flight requests to settle.
+func (g *TransactorGroup) stopOnHalt(timeout time.Duration) {
+	g.drainMtx.Lock()
+	g.haltStopped = true
+	g.drainMtx.Unlock()
+	g.logger.Error("Stopping the load test, since the chain appears halted")
+	g.Drain(timeout)
+}
+
+// ChainHeight returns the latest block height observed by the chain halt
+// watchdog, and how long ago it advanced. ok is false if halt detection is
+// disabled or no height has been observed yet.
+func (g *TransactorGroup) ChainHeight() (height int64, sinceAdvanced time.Duration, ok bool) {
+	if g.halt == nil {
+		return 0, 0, false
+	}
+	height, sinceAdvanced = g.halt.Height()
+	return height, sinceAdvanced, height > 0
+}
+
+// FirstCommitLatencies returns how many of the transactors (i.e. accounts)
+// sent any transactions and, for each one whose transactions were confirmed
+// as committed, how long after its first broadcast the first of them was (see
+// Config.ColdStart).
+func (g *TransactorGroup) FirstCommitLatencies() (accounts int, latencies []time.Duration) {
+	for _, t := range g.transactors {
+		sent, latency := t.getFirstCommit()
+		if sent {
+			accounts++
+		}
+		if latency > 0 {
+			latencies = append(latencies, latency)
+		}
+	}
+	return accounts, latencies
+}
+
+// ChainHalts returns how many times the chain has appeared to halt during
+// the load test (always 0 if halt detection is disabled).
+func (g *TransactorGroup) ChainHalts() int {
+	if g.halt == nil {
+		return 0
+	}
+	return g.halt.Halts()
+}
+
+// StopReason returns why the group's transactors stopped (one of the
+// StopReason* constants). Only meaningful once Wait has returned.
+func (g *TransactorGroup) StopReason() string {
+	g.drainMtx.RLock()
+	haltStopped := g.haltStopped
+	g.drainMtx.RUnlock()
+	switch {
+	case haltStopped:
+		return StopReasonChainHalted
+	case !g.getDrainDeadline().IsZero():
+		return StopReasonInterrupted
+	case g.budget.exhausted():
+		return StopReasonCountLimit
+	case g.fees != nil && g.fees.FeeBudgetExhausted():
+		return StopReasonFeeBudget
+	default:
+		return StopReasonTimeLimit
+	}
+}
+
+func (g *TransactorGroup) getDrainDeadline() time.Time {
+	g.drainMtx.RLock()
+	defer g.drainMtx.RUnlock()
+	return g.drainDeadline
+}
+
 // Wait will wait for all transactors to complete, returning the first error
-// we encounter.
+// we encounter. Transactors that gave up on reconnecting to their endpoints
+// are only considered to have failed if all of them did.
 func (g *TransactorGroup) Wait() error {
 	defer func() {
 		close(g.stopProgressReporter)
 		<-g.progressReporterStopped
+		if g.tps != nil {
+			close(g.stopTPSController)
+			<-g.tpsControllerStopped
+		}
+		if g.router != nil {
+			close(g.stopRouter)
+			<-g.routerStopped
+		}
+		if g.halt != nil {
+			g.halt.Stop()
+		}
+		if g.stopper != nil {
+			g.stopper.Stop()
+		}
 	}()
 
 	var wg sync.WaitGroup
@@ -114,25 +809,236 @@ func (g *TransactorGroup) Wait() error {
 		}(i, t)
 	}
 	wg.Wait()
+	if g.warmupTimer != nil {
+		g.warmupTimer.Stop()
+	}
+	if g.recorder != nil {
+		if rerr := g.recorder.Close(); rerr != nil {
+			g.logger.Error("Failed to close transaction recording", "err", rerr)
+		}
+	}
+	// no more transactions will be submitted, so wait for any outstanding
+	// confirmations to resolve
+	if g.confirmer != nil {
+		if deadline := g.getDrainDeadline(); !deadline.IsZero() {
+			if !g.confirmer.CloseBy(deadline) {
+				g.logger.Info("Drain timeout reached with confirmations still outstanding")
+			}
+		} else {
+			g.confirmer.Close()
+		}
+		// confirmations resolving means that their transactions' blocks were
+		// committed, so the accounts they created can be counted
+		if after, ok := g.countAccounts(); ok && g.accountsCounted {
+			created := after - g.accountsBefore
+			g.accountsMtx.Lock()
+			g.accountsCreated = &created
+			g.accountsMtx.Unlock()
+		}
+	}
 	// collect the results
+	var unavailableErr error
+	unavailable := 0
 	for i := 0; i < len(g.transactors); i++ {
-		if e := <-errc; e != nil {
+		e := <-errc
+		if errors.Is(e, errEndpointUnavailable) {
+			unavailableErr = e
+			unavailable++
+			continue
+		}
+		if e != nil && err == nil {
 			err = e
-			break
 		}
 	}
+	if err == nil && unavailable > 0 && unavailable == len(g.transactors) {
+		err = fmt.Errorf("all endpoints became unavailable: %w", unavailableErr)
+	}
 	return err
 }
 
+// WriteAggregateStats writes the group's aggregate statistics (excluding the
+// warmup period) to the given CSV file.
 func (g *TransactorGroup) WriteAggregateStats(filename string) error {
+	totals := g.measuredTotals()
 	stats := AggregateStats{
-		TotalTxs:         g.totalTxs(),
-		TotalTimeSeconds: time.Since(g.getStartTime()).Seconds(),
-		TotalBytes:       g.totalBytes(),
+		TotalTxs:         totals.txs,
+		TotalTimeSeconds: time.Since(g.measureStartTime()).Seconds(),
+		TotalBytes:       totals.bytes,
+		MinTxSize:        totals.sizes.Min,
+		MaxTxSize:        totals.sizes.Max,
+		TxErrors:         totals.errors,
+		MempoolFull:      totals.mempoolFull,
+		Gas:              g.GasStats(),
+		Confirm:          g.ConfirmStats(),
 	}
 	return writeAggregateStats(filename, stats)
 }
 
+// Report builds a summary of the load test run so far (see Report),
+// excluding the warmup period.
+func (g *TransactorGroup) Report() Report {
+	byEndpoint := make(map[string]*EndpointReport)
+	endpoints := make([]string, 0)
+	for i, s := range g.measuredStats() {
+		addr := g.transactors[i].remoteAddr
+		ep := byEndpoint[addr]
+		if ep == nil {
+			ep = &EndpointReport{Endpoint: addr}
+			byEndpoint[addr] = ep
+			endpoints = append(endpoints, addr)
+		}
+		ep.Connections++
+		ep.TotalTxs += s.txs
+		ep.TotalBytes += s.bytes
+		ep.TxErrors += s.errors
+		ep.MempoolFull += s.mempoolFull
+	}
+
+	totals := g.measuredTotals()
+	r := Report{
+		Version:          ReportVersion,
+		DurationSeconds:  time.Since(g.measureStartTime()).Seconds(),
+		StopReason:       g.StopReason(),
+		TotalTxs:         totals.txs,
+		TotalBytes:       totals.bytes,
+		MinTxSize:        totals.sizes.Min,
+		MaxTxSize:        totals.sizes.Max,
+		PeakTxRate:       g.getPeakTxRate(),
+		TxErrors:         totals.errors,
+		MempoolFull:      totals.mempoolFull,
+		ErrorCategories:  totals.errorCats,
+		FeesSpent:        g.FeesSpent(),
+		Injected:         totals.injected,
+		InjectedRejected: totals.injectedRejected,
+		ChainHalts:       g.ChainHalts(),
+		FirstCommit:      g.firstCommitReport(),
+		Accounts:         g.Accounts(),
+		Endpoints:        make([]EndpointReport, 0, len(endpoints)),
+	}
+	if len(g.transactors) > 0 {
+		r.RandomSeed = g.transactors[0].config.RandomSeed
+	}
+	for _, endpoint := range endpoints {
+		ep := byEndpoint[endpoint]
+		if r.DurationSeconds > 0 {
+			ep.AvgTxRate = float64(ep.TotalTxs) / r.DurationSeconds
+		}
+		r.Endpoints = append(r.Endpoints, *ep)
+	}
+	if g.confirmer != nil {
+		confirm := g.confirmer.Stats()
+		r.Confirm = &ConfirmReport{
+			Sampled:           confirm.Sampled,
+			Committed:         confirm.Committed,
+			Failed:            confirm.Failed,
+			Missing:           confirm.Missing,
+			Dropped:           confirm.Dropped,
+			Expired:           g.Expired(),
+			CommitSuccessRate: confirm.SuccessRate(),
+			MinHeight:         confirm.MinHeight,
+			MaxHeight:         confirm.MaxHeight,
+			Blocks:            confirm.Blocks,
+		}
+	}
+	r.Compute()
+	return r
+}
+
+// firstCommitReport summarizes the transactors' first commit latencies, if
+// they're tracked.
+func (g *TransactorGroup) firstCommitReport() *LatencyReport {
+	if !g.coldStart {
+		return nil
+	}
+	return NewLatencyReport(g.FirstCommitLatencies())
+}
+
+// WriteReport writes a JSON report summarizing the load test run to the
+// given file.
+func (g *TransactorGroup) WriteReport(filename string) error {
+	return writeReport(filename, g.Report())
+}
+
+// CommitLatencies returns the commit latencies of the transactions sampled
+// for confirmation that were committed successfully, or nil if confirmation
+// is disabled.
+func (g *TransactorGroup) CommitLatencies() []time.Duration {
+	if g.confirmer == nil {
+		return nil
+	}
+	return g.confirmer.CommitLatencies()
+}
+
+// ConfirmStats returns the outcomes of the transactions sampled for
+// confirmation so far. Returns empty statistics if confirmation is disabled.
+func (g *TransactorGroup) ConfirmStats() ConfirmStats {
+	if g.confirmer == nil {
+		return ConfirmStats{}
+	}
+	return g.confirmer.Stats()
+}
+
+// Expired returns the total number of sampled transactions that expired
+// before being committed, across all transactors.
+func (g *TransactorGroup) Expired() int {
+	total := 0
+	for _, t := range g.transactors {
+		total += t.GetExpired()
+	}
+	return total
+}
+
+// TxErrors returns the total number of broadcast requests rejected across all
+// transactors so far.
+func (g *TransactorGroup) TxErrors() int {
+	total := 0
+	for _, t := range g.transactors {
+		total += t.GetTxErrors()
+	}
+	return total
+}
+
+// Blocked returns the number of transactors that are currently blocked on
+// backpressure (see Config.MaxInFlight).
+func (g *TransactorGroup) Blocked() int {
+	blocked := 0
+	for _, t := range g.transactors {
+		if t.Blocked() {
+			blocked++
+		}
+	}
+	return blocked
+}
+
+// FeesSpent returns the total fees of the transactions generated by the
+// group's clients (including any sent during the warmup period), or an empty
+// string if the client factory doesn't track them.
+func (g *TransactorGroup) FeesSpent() string {
+	if g.fees == nil {
+		return ""
+	}
+	return g.fees.FeesSpent()
+}
+
+// MempoolFull returns the total number of broadcast requests rejected across
+// all transactors so far because the mempool was full.
+func (g *TransactorGroup) MempoolFull() int {
+	total := 0
+	for _, t := range g.transactors {
+		total += t.GetMempoolFull()
+	}
+	return total
+}
+
+// GasStats returns the gas consumption observed across all transactors.
+func (g *TransactorGroup) GasStats() GasStats {
+	var stats GasStats
+	for _, t := range g.transactors {
+		stats.Merge(t.GetGasStats())
+	}
+	return stats
+}
+
 func (g *TransactorGroup) progressReporter() {
 	defer close(g.progressReporterStopped)
 
@@ -178,6 +1084,7 @@ func (g *TransactorGroup) getProgressCallbackInterval() time.Duration {
 func (g *TransactorGroup) reportProgress() {
 	totalTxs := g.totalTxs()
 	totalBytes := g.totalBytes()
+	g.trackPeakTxRate(totalTxs)
 
 	g.progressCallbackMtx.RLock()
 	if g.progressCallback != nil {
@@ -186,6 +1093,36 @@ func (g *TransactorGroup) reportProgress() {
 	g.progressCallbackMtx.RUnlock()
 }
 
+// trackPeakTxRate updates the peak transaction rate from the number of
+// transactions sent since the previous call.
+func (g *TransactorGroup) trackPeakTxRate(totalTxs int) {
+	now := time.Now()
+	g.statsMtx.Lock()
+	defer g.statsMtx.Unlock()
+	if g.startTime.IsZero() {
+		return
+	}
+	since := g.lastProgressTime
+	if since.IsZero() {
+		since = g.startTime
+	}
+	// rates observed during the warmup period don't count towards the peak
+	warmingUp := g.warmup > 0 && g.warmupEnd.IsZero()
+	if elapsed := now.Sub(since).Seconds(); elapsed > 0 && !warmingUp {
+		if rate := float64(totalTxs-g.lastProgressTxs) / elapsed; rate > g.peakTxRate {
+			g.peakTxRate = rate
+		}
+	}
+	g.lastProgressTime = now
+	g.lastProgressTxs = totalTxs
+}
+
+func (g *TransactorGroup) getPeakTxRate() float64 {
+	g.statsMtx.RLock()
+	defer g.statsMtx.RUnlock()
+	return g.peakTxRate
+}
+
 func (g *TransactorGroup) totalTxs() int {
 	g.statsMtx.RLock()
 	defer g.statsMtx.RUnlock()
@@ -210,4 +1147,13 @@ func (g *TransactorGroup) close() {
 	for _, t := range g.transactors {
 		t.close()
 	}
+	for _, p := range g.connPools {
+		p.close()
+	}
+	if g.recorder != nil {
+		_ = g.recorder.Close()
+	}
+	if g.stopper != nil {
+		g.stopper.Stop()
+	}
 }
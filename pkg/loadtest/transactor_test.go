@@ -0,0 +1,720 @@
+package loadtest_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/1119-Labs/perpx-load-test/pkg/loadtest"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaxInFlightBlocksGeneration(t *testing.T) {
+	cfg := baseConfig("kvstore", silentServer(t))
+	cfg.MaxInFlight = 3
+	tg := loadtest.NewTransactorGroup()
+	require.NoError(t, tg.AddAll(&cfg))
+	tg.Start()
+	assert.Eventually(t, func() bool { return tg.Blocked() == 2 }, 2*time.Second, 10*time.Millisecond)
+	require.NoError(t, tg.Wait())
+
+	assert.Equal(t, 6, tg.Report().TotalTxs)
+	assert.Equal(t, 0, tg.Blocked())
+}
+
+func TestPauseStopsGeneration(t *testing.T) {
+	cfg := baseConfig("kvstore", silentServer(t))
+	tg := loadtest.NewTransactorGroup()
+	require.NoError(t, tg.AddAll(&cfg))
+	tg.Pause()
+	assert.True(t, tg.Paused())
+	tg.Start()
+	require.NoError(t, tg.Wait())
+	assert.Equal(t, 0, tg.Report().TotalTxs)
+
+	tg.Resume()
+	assert.False(t, tg.Paused())
+}
+
+type recordingObserver struct {
+	mtx       sync.Mutex
+	submitted map[string]bool
+	errors    []*loadtest.TxError
+	unknown   int // Results for transactions that weren't submitted first.
+}
+
+func (o *recordingObserver) OnSubmit(tx loadtest.TxInfo) {
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+	o.submitted[tx.Hash] = true
+}
+
+func (o *recordingObserver) OnConfirm(tx loadtest.TxInfo, _ loadtest.TxConfirmation) {}
+
+func (o *recordingObserver) OnError(tx loadtest.TxInfo, err *loadtest.TxError) {
+	o.mtx.Lock()
+	defer o.mtx.Unlock()
+	if !o.submitted[tx.Hash] {
+		o.unknown++
+	}
+	o.errors = append(o.errors, err)
+}
+
+// baseConfig returns the configuration of a short load test by the given
+// client factory against the given endpoints, which the tests adjust as need
+// be: two connections to each endpoint, each sending 10 small transactions
+// per second for 2 seconds.
+func baseConfig(clientFactory string, endpoints ...string) loadtest.Config {
+	return loadtest.Config{
+		ClientFactory:     clientFactory,
+		Connections:       2,
+		Rate:              10,
+		Size:              40,
+		Time:              2,
+		SendPeriod:        1,
+		BroadcastTxMethod: "async",
+		Endpoints:         endpoints,
+	}
+}
+
+// silentServer serves a CometBFT WebSockets RPC endpoint that accepts
+// broadcasts but never responds to them.
+func silentServer(t *testing.T) string {
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	t.Cleanup(server.Close)
+	return "ws://" + strings.TrimPrefix(server.URL, "http://") + "/websocket"
+}
+
+// rejectingServer serves a CometBFT WebSockets RPC endpoint that rejects every
+// other broadcast with an "insufficient funds" result.
+func rejectingServer(t *testing.T) string {
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for i := 0; ; i++ {
+			var req loadtest.RPCRequest
+			if err := conn.ReadJSON(&req); err != nil {
+				return
+			}
+			result := `{"code":0,"log":"","codespace":"","hash":"00"}`
+			if i%2 == 1 {
+				result = `{"code":5,"log":"insufficient funds","codespace":"sdk","hash":"00"}`
+			}
+			res := fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":%s}`, req.ID, result)
+			if err := conn.WriteMessage(websocket.TextMessage, []byte(res)); err != nil {
+				return
+			}
+		}
+	}))
+	t.Cleanup(server.Close)
+	return "ws://" + strings.TrimPrefix(server.URL, "http://") + "/websocket"
+}
+
+// redirectTransport sends all requests to the given server, whatever their
+// host, so that the REST API derived from an endpoint can be faked.
+type redirectTransport struct {
+	target *url.URL
+}
+
+func (t redirectTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	r = r.Clone(r.Context())
+	r.URL.Scheme, r.URL.Host = t.target.Scheme, t.target.Host
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+// restServer serves a fake REST API on which no transaction is ever found,
+// counting the lookups, and returns an HTTP client that sends all REST API
+// requests to it.
+func restServer(t *testing.T, lookups *atomic.Int32) *http.Client {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/cosmos/tx/v1beta1/txs/") {
+			lookups.Add(1)
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	t.Cleanup(server.Close)
+	target, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	return &http.Client{Transport: redirectTransport{target: target}}
+}
+
+func TestResultObserver(t *testing.T) {
+	endpoint := rejectingServer(t)
+	observer := &recordingObserver{submitted: make(map[string]bool)}
+	cfg := baseConfig("kvstore", endpoint)
+	cfg.Connections, cfg.Count, cfg.Time = 1, 4, 0
+	cfg.BroadcastTxMethod = "sync"
+	cfg.ResultObserver = observer
+	tg := loadtest.NewTransactorGroup()
+	require.NoError(t, tg.AddAll(&cfg))
+	tg.Start()
+	require.NoError(t, tg.Wait())
+
+	observer.mtx.Lock()
+	defer observer.mtx.Unlock()
+	assert.Len(t, observer.submitted, 4)
+	require.Len(t, observer.errors, 2)
+	assert.Equal(t, 0, observer.unknown)
+	for _, err := range observer.errors {
+		assert.Equal(t, "sdk/5", err.Category)
+		assert.Equal(t, uint32(5), err.Code)
+		assert.Equal(t, "sdk/5: insufficient funds", err.Error())
+	}
+}
+
+// The observer is only meant for the process in which it is registered.
+func TestResultObserverNotSerialized(t *testing.T) {
+	cfg := loadtest.Config{ResultObserver: &recordingObserver{}}
+	data, err := json.Marshal(cfg)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "observer")
+}
+
+func TestErrorHandler(t *testing.T) {
+	var mtx sync.Mutex
+	var runErrs []*loadtest.RunError
+	handler := func(err *loadtest.RunError) {
+		mtx.Lock()
+		runErrs = append(runErrs, err)
+		mtx.Unlock()
+	}
+
+	t.Run("rejected transactions", func(t *testing.T) {
+		runErrs = nil
+		endpoint := rejectingServer(t)
+		observer := &recordingObserver{submitted: make(map[string]bool)}
+		cfg := baseConfig("kvstore", endpoint)
+		cfg.Connections, cfg.Count, cfg.Time = 1, 4, 0
+		cfg.BroadcastTxMethod = "sync"
+		cfg.ResultObserver = observer
+		tg := loadtest.NewTransactorGroup()
+		tg.SetErrorHandler(handler)
+		require.NoError(t, tg.AddAll(&cfg))
+		tg.Start()
+		require.NoError(t, tg.Wait())
+
+		mtx.Lock()
+		defer mtx.Unlock()
+		require.Len(t, runErrs, 2)
+		for _, runErr := range runErrs {
+			assert.Equal(t, loadtest.RunErrorTx, runErr.Kind)
+			assert.Equal(t, endpoint, runErr.Endpoint)
+			var txErr *loadtest.TxError
+			require.ErrorAs(t, runErr, &txErr)
+			assert.Equal(t, "sdk/5", txErr.Category)
+		}
+		// the caller's observer still sees every result, but isn't replaced
+		assert.Len(t, observer.errors, 2)
+		assert.Equal(t, observer, cfg.ResultObserver)
+	})
+
+	t.Run("failed connection", func(t *testing.T) {
+		runErrs = nil
+		cfg := baseConfig("kvstore", "ws://127.0.0.1:1/websocket")
+		cfg.Connections, cfg.Time = 1, 1
+		cfg.BroadcastTxMethod = "sync"
+		tg := loadtest.NewTransactorGroup()
+		tg.SetErrorHandler(handler)
+		require.Error(t, tg.AddAll(&cfg))
+
+		mtx.Lock()
+		defer mtx.Unlock()
+		require.Len(t, runErrs, 1)
+		assert.Equal(t, loadtest.RunErrorConnection, runErrs[0].Kind)
+		var dialErr *loadtest.DialError
+		assert.ErrorAs(t, runErrs[0], &dialErr)
+	})
+}
+
+// lifecycleClientFactory produces kvstore clients that count their warmups and
+// teardowns, failing their warmups if warmupErr is set.
+type lifecycleClientFactory struct {
+	*loadtest.KVStoreClientFactory
+	warmupErr              error
+	warmups, teardowns     atomic.Int32
+	sentBeforeWarmup       atomic.Bool
+	generatedAfterTeardown atomic.Bool
+}
+
+func (f *lifecycleClientFactory) NewClient(cfg loadtest.Config) (loadtest.Client, error) {
+	client, err := f.KVStoreClientFactory.NewClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &lifecycleClient{Client: client, factory: f}, nil
+}
+
+type lifecycleClient struct {
+	loadtest.Client
+	factory            *lifecycleClientFactory
+	warmedUp, tornDown bool
+}
+
+func (c *lifecycleClient) GenerateTx() ([]byte, error) {
+	if !c.warmedUp {
+		c.factory.sentBeforeWarmup.Store(true)
+	}
+	if c.tornDown {
+		c.factory.generatedAfterTeardown.Store(true)
+	}
+	return c.Client.GenerateTx()
+}
+
+func (c *lifecycleClient) Warmup() error {
+	c.factory.warmups.Add(1)
+	if c.factory.warmupErr != nil {
+		return c.factory.warmupErr
+	}
+	c.warmedUp = true
+	return nil
+}
+
+func (c *lifecycleClient) Teardown() error {
+	c.factory.teardowns.Add(1)
+	c.tornDown = true
+	return nil
+}
+
+func TestClientLifecycle(t *testing.T) {
+	endpoint := silentServer(t)
+	run := func(name string, factory *lifecycleClientFactory) (*loadtest.TransactorGroup, error) {
+		require.NoError(t, loadtest.RegisterClientFactory(name, factory))
+		cfg := baseConfig(name, endpoint)
+		cfg.Rate = 5
+		tg := loadtest.NewTransactorGroup()
+		require.NoError(t, tg.AddAll(&cfg))
+		tg.Start()
+		return tg, tg.Wait()
+	}
+
+	factory := &lifecycleClientFactory{KVStoreClientFactory: loadtest.NewKVStoreClientFactory()}
+	tg, err := run("lifecycle-test", factory)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), factory.warmups.Load())
+	assert.Equal(t, int32(2), factory.teardowns.Load())
+	assert.False(t, factory.sentBeforeWarmup.Load())
+	assert.False(t, factory.generatedAfterTeardown.Load())
+	assert.Positive(t, tg.Report().TotalTxs)
+
+	failing := &lifecycleClientFactory{KVStoreClientFactory: loadtest.NewKVStoreClientFactory(), warmupErr: fmt.Errorf("no liquidity")}
+	tg, err = run("lifecycle-failing-test", failing)
+	require.ErrorContains(t, err, "no liquidity")
+	assert.Equal(t, int32(2), failing.warmups.Load())
+	assert.Equal(t, int32(0), failing.teardowns.Load())
+	assert.Equal(t, 0, tg.Report().TotalTxs)
+}
+
+// feeClientFactory produces kvstore clients that each pay a fee of 1stake per
+// transaction, out of a budget shared by all of them.
+type feeClientFactory struct {
+	*loadtest.KVStoreClientFactory
+	budget, spent int64
+	mtx           sync.Mutex
+	exhausted     bool
+}
+
+func (f *feeClientFactory) NewClient(cfg loadtest.Config) (loadtest.Client, error) {
+	client, err := f.KVStoreClientFactory.NewClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &feeClient{Client: client, factory: f}, nil
+}
+
+func (f *feeClientFactory) FeesSpent() string {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	return fmt.Sprintf("%dstake", f.spent)
+}
+
+func (f *feeClientFactory) FeeBudgetExhausted() bool {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	return f.exhausted
+}
+
+type feeClient struct {
+	loadtest.Client
+	factory *feeClientFactory
+}
+
+func (c *feeClient) GenerateTx() ([]byte, error) {
+	c.factory.mtx.Lock()
+	if c.factory.spent >= c.factory.budget {
+		c.factory.exhausted = true
+		c.factory.mtx.Unlock()
+		return nil, loadtest.ErrClientExhausted
+	}
+	c.factory.spent++
+	c.factory.mtx.Unlock()
+	return c.Client.GenerateTx()
+}
+
+func TestFeeBudget(t *testing.T) {
+	factory := &feeClientFactory{KVStoreClientFactory: loadtest.NewKVStoreClientFactory(), budget: 7}
+	require.NoError(t, loadtest.RegisterClientFactory("fee-budget-test", factory))
+	cfg := baseConfig("fee-budget-test", silentServer(t))
+	cfg.Rate, cfg.Time = 5, 5
+	tg := loadtest.NewTransactorGroup()
+	require.NoError(t, tg.AddAll(&cfg))
+	start := time.Now()
+	tg.Start()
+	require.NoError(t, tg.Wait())
+	assert.Less(t, time.Since(start), 4*time.Second)
+
+	report := tg.Report()
+	assert.Equal(t, 7, report.TotalTxs)
+	assert.Equal(t, "7stake", report.FeesSpent)
+	assert.Equal(t, loadtest.StopReasonFeeBudget, report.StopReason)
+}
+
+// expiringClientFactory produces kvstore clients whose transactions all
+// expire, counting how often they're resynced.
+type expiringClientFactory struct {
+	*loadtest.KVStoreClientFactory
+	resyncs atomic.Int32
+}
+
+func (f *expiringClientFactory) NewClient(cfg loadtest.Config) (loadtest.Client, error) {
+	client, err := f.KVStoreClientFactory.NewClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &expiringClient{Client: client, factory: f}, nil
+}
+
+type expiringClient struct {
+	loadtest.Client
+	factory *expiringClientFactory
+}
+
+func (c *expiringClient) TxExpired([]byte) (bool, error) { return true, nil }
+
+func (c *expiringClient) Resync() error {
+	c.factory.resyncs.Add(1)
+	return nil
+}
+
+func TestExpiredTxsResyncClient(t *testing.T) {
+	factory := &expiringClientFactory{KVStoreClientFactory: loadtest.NewKVStoreClientFactory()}
+	require.NoError(t, loadtest.RegisterClientFactory("expiring-test", factory))
+	observer := &recordingObserver{submitted: make(map[string]bool)}
+	cfg := baseConfig("expiring-test", silentServer(t))
+	cfg.Connections, cfg.Rate, cfg.Time = 1, 2, 4
+	// the sampled transactions are never found on the REST API
+	cfg.Confirm, cfg.ConfirmEvery, cfg.ConfirmTimeout = true, 1, 1
+	cfg.ResultObserver = observer
+	var lookups atomic.Int32
+	tg := loadtest.NewTransactorGroup()
+	tg.SetHTTPClient(restServer(t, &lookups))
+	require.NoError(t, tg.AddAll(&cfg))
+	tg.Start()
+	require.NoError(t, tg.Wait())
+
+	assert.Positive(t, lookups.Load())
+	assert.Positive(t, tg.Expired())
+	assert.Positive(t, factory.resyncs.Load())
+	observer.mtx.Lock()
+	defer observer.mtx.Unlock()
+	require.NotEmpty(t, observer.errors)
+	assert.Equal(t, loadtest.TxErrorCategoryExpired, observer.errors[0].Category)
+}
+
+// injectingClientFactory produces kvstore clients that can generate invalid
+// transactions, which start with invalidTxPrefix.
+type injectingClientFactory struct {
+	*loadtest.KVStoreClientFactory
+}
+
+const invalidTxPrefix = "invalid"
+
+func (f *injectingClientFactory) NewClient(cfg loadtest.Config) (loadtest.Client, error) {
+	client, err := f.KVStoreClientFactory.NewClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &injectingClient{Client: client}, nil
+}
+
+type injectingClient struct {
+	loadtest.Client
+}
+
+func (c *injectingClient) GenerateInvalidTx() ([]byte, error) {
+	tx, err := c.Client.GenerateTx()
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(invalidTxPrefix), tx...), nil
+}
+
+func TestInjectFailures(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	// rejects invalid transactions, accepting all others
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			var req struct {
+				ID     int `json:"id"`
+				Params struct {
+					Tx []byte `json:"tx"`
+				} `json:"params"`
+			}
+			if err := conn.ReadJSON(&req); err != nil {
+				return
+			}
+			result := `{"code":0,"log":"","codespace":"","hash":"00"}`
+			if strings.HasPrefix(string(req.Params.Tx), invalidTxPrefix) {
+				result = `{"code":32,"log":"account sequence mismatch","codespace":"sdk","hash":"00"}`
+			}
+			res := fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":%s}`, req.ID, result)
+			if err := conn.WriteMessage(websocket.TextMessage, []byte(res)); err != nil {
+				return
+			}
+		}
+	}))
+	defer server.Close()
+
+	require.NoError(t, loadtest.RegisterClientFactory("inject-failures-test", &injectingClientFactory{loadtest.NewKVStoreClientFactory()}))
+	cfg := baseConfig("inject-failures-test", "ws://"+strings.TrimPrefix(server.URL, "http://")+"/websocket")
+	cfg.Connections, cfg.Rate, cfg.Count, cfg.Time = 1, 50, 40, 0
+	cfg.BroadcastTxMethod = "sync"
+	cfg.InjectFailures = 50
+	tg := loadtest.NewTransactorGroup()
+	require.NoError(t, tg.AddAll(&cfg))
+	tg.Start()
+	require.NoError(t, tg.Wait())
+
+	// the injected transactions neither count towards the limit nor as errors
+	report := tg.Report()
+	assert.Equal(t, 40, report.TotalTxs)
+	assert.Equal(t, 0, report.TxErrors)
+	assert.Positive(t, report.Injected)
+	assert.Equal(t, report.Injected, report.InjectedRejected)
+}
+
+func TestCountIsTotal(t *testing.T) {
+	cfg := baseConfig("kvstore", silentServer(t), silentServer(t))
+	cfg.Connections, cfg.Count, cfg.Time = 3, 25, 0
+	tg := loadtest.NewTransactorGroup()
+	require.NoError(t, tg.AddAll(&cfg))
+	tg.Start()
+	require.NoError(t, tg.Wait())
+
+	// the six connections send 60 transactions per second between them, but
+	// stop collectively once they've sent 25
+	report := tg.Report()
+	assert.Equal(t, 25, report.TotalTxs)
+	require.Len(t, report.Endpoints, 2)
+	assert.Equal(t, 25, report.Endpoints[0].TotalTxs+report.Endpoints[1].TotalTxs)
+}
+
+// slowServer serves a CometBFT WebSockets RPC endpoint that accepts every
+// broadcast, but only responds after the given delay, counting the broadcasts
+// received and the responses sent.
+func slowServer(t *testing.T, delay time.Duration, received, responded *atomic.Int32) string {
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		var writeMtx sync.Mutex
+		for {
+			var req loadtest.RPCRequest
+			if err := conn.ReadJSON(&req); err != nil {
+				return
+			}
+			received.Add(1)
+			time.AfterFunc(delay, func() {
+				res := fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":{"code":0,"log":"","codespace":"","hash":"00"}}`, req.ID)
+				writeMtx.Lock()
+				defer writeMtx.Unlock()
+				responded.Add(1)
+				if conn.WriteMessage(websocket.TextMessage, []byte(res)) != nil {
+					responded.Add(-1)
+				}
+			})
+		}
+	}))
+	t.Cleanup(server.Close)
+	return "ws://" + strings.TrimPrefix(server.URL, "http://") + "/websocket"
+}
+
+func TestDrainWaitsForInFlight(t *testing.T) {
+	var received, responded atomic.Int32
+	cfg := baseConfig("kvstore", slowServer(t, 500*time.Millisecond, &received, &responded))
+	cfg.Connections, cfg.Time = 1, 0
+	cfg.BroadcastTxMethod = "sync"
+	tg := loadtest.NewTransactorGroup()
+	require.NoError(t, tg.AddAll(&cfg))
+	tg.Start()
+	// drain while the first batch awaits its responses
+	require.Eventually(t, func() bool { return received.Load() > 0 }, 5*time.Second, 10*time.Millisecond)
+	start := time.Now()
+	tg.Drain(10 * time.Second)
+	require.NoError(t, tg.Wait())
+
+	// the transactor kept reading responses while draining, so it stopped as
+	// soon as the last of them arrived rather than at the drain deadline
+	assert.Less(t, time.Since(start), 5*time.Second)
+	assert.Equal(t, received.Load(), responded.Load())
+}
+
+func TestReconnectAfterDroppedConnection(t *testing.T) {
+	// the first connection is dropped after five broadcasts, while later
+	// ones are kept open
+	var conns, beforeDrop, afterDrop atomic.Int32
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		first := conns.Add(1) == 1
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+			if !first {
+				afterDrop.Add(1)
+				continue
+			}
+			if beforeDrop.Add(1) == 5 {
+				return
+			}
+		}
+	}))
+	t.Cleanup(server.Close)
+	endpoint := "ws://" + strings.TrimPrefix(server.URL, "http://") + "/websocket"
+
+	cfg := baseConfig("kvstore", endpoint)
+	cfg.Connections, cfg.Count, cfg.Time = 1, 25, 0
+	cfg.MaxReconnectAttempts = 3
+	tg := loadtest.NewTransactorGroup()
+	require.NoError(t, tg.AddAll(&cfg))
+	tg.Start()
+	require.NoError(t, tg.Wait())
+
+	// the transactor reconnected and kept sending, but the transactions sent
+	// before and after the drop still add up to the count
+	assert.Equal(t, int32(2), conns.Load())
+	assert.Positive(t, afterDrop.Load())
+	assert.LessOrEqual(t, int(beforeDrop.Load()+afterDrop.Load()), 25)
+	assert.Equal(t, 25, tg.Report().TotalTxs)
+}
+
+func TestWorkerStartStagger(t *testing.T) {
+	// the same server, addressed as two endpoints
+	endpoint := silentServer(t)
+	cfg := baseConfig("kvstore", endpoint, strings.Replace(endpoint, "127.0.0.1", "localhost", 1))
+	cfg.Connections, cfg.Rate = 1, 5
+	// the second worker only starts sending after the time limit
+	cfg.WorkerStartStagger = 1500
+	tg := loadtest.NewTransactorGroup()
+	start := time.Now()
+	require.NoError(t, tg.AddAll(&cfg))
+	// connections are staggered too
+	assert.GreaterOrEqual(t, time.Since(start), 1500*time.Millisecond)
+	tg.Start()
+	require.NoError(t, tg.Wait())
+
+	report := tg.Report()
+	require.Len(t, report.Endpoints, 2)
+	assert.Positive(t, report.Endpoints[0].TotalTxs)
+	assert.Equal(t, 0, report.Endpoints[1].TotalTxs)
+}
+
+func TestConfirmViaSubscription(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	var subscriptions atomic.Int32
+	// accepts broadcasts, and reports every transaction subscribed to as
+	// committed straight away
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			var req struct {
+				ID     int    `json:"id"`
+				Method string `json:"method"`
+			}
+			if err := conn.ReadJSON(&req); err != nil {
+				return
+			}
+			result := `{"code":0,"log":"","codespace":"","hash":"00"}`
+			switch req.Method {
+			case "subscribe":
+				subscriptions.Add(1)
+				result = `{}`
+			case "unsubscribe":
+				result = `{}`
+			}
+			res := fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":%s}`, req.ID, result)
+			if err := conn.WriteMessage(websocket.TextMessage, []byte(res)); err != nil {
+				return
+			}
+			if req.Method == "subscribe" {
+				event := fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"result":{"data":{"type":"tendermint/event/Tx","value":{"TxResult":{"height":"7","result":{"code":0,"gas_used":"10"}}}}}}`, req.ID)
+				if err := conn.WriteMessage(websocket.TextMessage, []byte(event)); err != nil {
+					return
+				}
+			}
+		}
+	}))
+	defer server.Close()
+
+	cfg := baseConfig("kvstore", "ws://"+strings.TrimPrefix(server.URL, "http://")+"/websocket")
+	cfg.Connections, cfg.Rate, cfg.Count, cfg.Time = 1, 5, 10, 0
+	cfg.Confirm, cfg.ConfirmEvery, cfg.ConfirmTimeout = true, 1, 5
+	cfg.ConfirmTransport = loadtest.ConfirmTransportWS
+	// the REST API never finds the samples, so they can only be confirmed
+	// via their subscriptions
+	var lookups atomic.Int32
+	tg := loadtest.NewTransactorGroup()
+	tg.SetHTTPClient(restServer(t, &lookups))
+	require.NoError(t, tg.AddAll(&cfg))
+	tg.Start()
+	require.NoError(t, tg.Wait())
+
+	stats := tg.ConfirmStats()
+	assert.Equal(t, 10, stats.Sampled)
+	assert.Equal(t, 10, stats.Committed)
+	assert.Equal(t, int64(7), stats.MinHeight)
+	assert.Equal(t, int32(10), subscriptions.Load())
+	// while being polled for as well, on the fake REST API
+	assert.Positive(t, lookups.Load())
+}
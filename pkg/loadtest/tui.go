@@ -2,10 +2,40 @@ package loadtest
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
 	"sort"
 	"strings"
 	"time"
+
+	"golang.org/x/term"
+)
+
+const (
+	// If the achieved transaction rate falls below this fraction of the target
+	// rate for tuiLagTicks consecutive ticks, the TUI flags the run as lagging.
+	tuiLagThreshold = 0.9
+	tuiLagTicks     = 3
+	// How many of the most recent per-second rates the TUI's sparkline shows.
+	tuiSparklineSamples = 60
+
+	// The width the TUI assumes if the terminal's width can't be queried.
+	tuiDefaultWidth = 100
+	// The widths of the TUI table's columns other than the endpoint column,
+	// including the spaces between them, and of the weight column shown with
+	// adaptive routing.
+	tuiNumericColumnsWidth = 2 + 12 + 2 + 10 + 2 + 12
+	tuiWeightColumnWidth   = 2 + 8
+	// Endpoints are truncated to fit the terminal, but to no fewer characters
+	// than this.
+	tuiMinEndpointColumnWidth = 16
+
+	ansiReset  = "\033[0m"
+	ansiDim    = "\033[2m"
+	ansiRed    = "\033[31m"
+	ansiGreen  = "\033[32m"
+	ansiYellow = "\033[33m"
 )
 
 // startStandaloneTUI starts a lightweight full-screen terminal UI that updates once per second.
@@ -24,17 +54,219 @@ func startStandaloneTUI(cfg *Config, tg *TransactorGroup) func() {
 		lastTotalByte = int64(0)
 		lastByEP      = map[string]int{}
 		lastByEPBytes = map[string]int64{}
+		lagTicks      = 0
+		rateHistory   = NewSparkline(tuiSparklineSamples)
 	)
 
-	hideCursor := func() { fmt.Fprint(os.Stdout, "\033[?25l") }
-	showCursor := func() { fmt.Fprint(os.Stdout, "\033[?25h") }
-	clearScreen := func() { fmt.Fprint(os.Stdout, "\033[H\033[2J") }
+	// The total rate we're aiming for across all connections (outside of
+	// spikes).
+	baseTxRate := tg.baseTxRate(cfg.SendPeriod)
+	// The config has already been validated.
+	spike, _ := ParseSpikeSchedule(cfg.Spike)
+
+	// Poll the chain's commit rate via the first endpoint, to put our send
+	// rate into context.
+	var blocks *blockRatePoller
+	if len(cfg.Endpoints) > 0 {
+		blocks = newBlockRatePoller(cfg.Endpoints[0])
+		blocks.Start()
+	}
+
+	// Handle the pause and resume keys if we can read them from the
+	// terminal, in which case it is in raw mode and needs carriage returns.
+	var out io.Writer = os.Stdout
+	restoreTerminal, keys := startTUIKeys(tg)
+	if keys {
+		out = crlfWriter{w: os.Stdout}
+	}
+
+	hideCursor := func() { fmt.Fprint(out, "\033[?25l") }
+	showCursor := func() { fmt.Fprint(out, "\033[?25h") }
+	clearScreen := func() { fmt.Fprint(out, "\033[H\033[2J") }
 
 	hideCursor()
 	clearScreen()
 
+	// The latest snapshot of the group's statistics, taken once per tick,
+	// which is drawn on every tick and redrawn whenever the terminal is
+	// resized.
+	var (
+		byEP         map[string]*tuiEndpointStats
+		totalTxs     int
+		instTxRate   float64
+		instByteRate float64
+		targetTxRate float64
+		achieved     float64
+		spikeLabel   string
+	)
+
+	elapsedTime := func() time.Duration {
+		if startTime := tg.getStartTime(); !startTime.IsZero() {
+			return time.Since(startTime)
+		}
+		return 0
+	}
+
+	draw := func() {
+		width := tuiWidth()
+		clearScreen()
+		elapsed := elapsedTime()
+
+		paused := tg.Paused()
+		if paused {
+			fmt.Fprintf(out, "PerpX Load Test (TUI)   %sPAUSED (press r to resume)%s\n", ansiYellow, ansiReset)
+		} else {
+			fmt.Fprintf(out, "PerpX Load Test (TUI)\n")
+		}
+		// Dim everything while warming up, since none of it will count
+		// towards the final statistics.
+		warmingUp := !tg.WarmupComplete()
+		if warmingUp {
+			remaining := time.Duration(cfg.WarmupSeconds)*time.Second - elapsed
+			if remaining < 0 {
+				remaining = 0
+			}
+			fmt.Fprintf(out, "%sWARMUP: %s remaining (excluded from final statistics)\n",
+				ansiDim, remaining.Truncate(time.Second).String())
+		}
+		timeLimit := "no limit"
+		if cfg.Time > 0 {
+			timeLimit = fmt.Sprintf("%ds", cfg.Time)
+		}
+		fmt.Fprintf(out, "elapsed: %s / %s   connections: %d   send_period: %ds   rate: %d tx/s/conn\n",
+			elapsed.Truncate(time.Second).String(),
+			timeLimit,
+			cfg.Connections*len(cfg.Endpoints),
+			cfg.SendPeriod,
+			cfg.Rate,
+		)
+		countLimit := ""
+		if cfg.Count > 0 {
+			countLimit = fmt.Sprintf(" / %d", cfg.Count)
+		}
+		fmt.Fprintf(out, "total: %d%s tx   inst: %.0f tx/s   inst data: %.1f KiB/s\n",
+			totalTxs, countLimit, instTxRate, instByteRate/1024.0,
+		)
+		minRate, maxRate := rateHistory.Range()
+		fmt.Fprintf(out, "last %ds: [%s]   min: %.0f tx/s   max: %.0f tx/s\n",
+			tuiSparklineSamples, rateHistory, minRate, maxRate,
+		)
+
+		targetLine := fmt.Sprintf("target: %.0f tx/s   achieved: %.0f%%%s", targetTxRate, achieved*100, spikeLabel)
+		if cfg.TargetTPS > 0 {
+			targetLine += fmt.Sprintf("   controller: x%.2f", tg.TPSMultiplier())
+		}
+		switch {
+		case warmingUp, paused:
+			fmt.Fprintf(out, "%s\n", targetLine)
+		case lagTicks >= tuiLagTicks:
+			fmt.Fprintf(out, "%s%s   LAGGING: client is sending below %.0f%% of target for %ds (likely client-bound)%s\n",
+				ansiRed, targetLine, tuiLagThreshold*100, lagTicks, ansiReset)
+		case lagTicks > 0:
+			fmt.Fprintf(out, "%s%s%s\n", ansiYellow, targetLine, ansiReset)
+		default:
+			fmt.Fprintf(out, "%s%s%s\n", ansiGreen, targetLine, ansiReset)
+		}
+		if blocks != nil {
+			bs := blocks.Stats()
+			// How long the chain has gone without a new block, to tell a
+			// halted chain from a slow one
+			advanced := ""
+			if !bs.HeightAdvanced.IsZero() {
+				since := time.Since(bs.HeightAdvanced)
+				advanced = fmt.Sprintf("   last height advanced %ds ago", int(since.Seconds()))
+				if cfg.HaltTimeout > 0 && since >= time.Duration(cfg.HaltTimeout)*time.Second {
+					advanced = ansiRed + advanced + " - chain appears halted" + ansiReset
+				}
+			}
+			switch {
+			case bs.Err != nil && bs.Height == 0:
+				fmt.Fprintf(out, "chain: unavailable (%v)\n", bs.Err)
+			case bs.BlocksPerSec == 0:
+				fmt.Fprintf(out, "chain: height %d   waiting for blocks...%s\n", bs.Height, advanced)
+			default:
+				fmt.Fprintf(out, "chain: height %d   %.2f blocks/s   %.0f tx/block   ~%.0f tx/s committed%s\n",
+					bs.Height, bs.BlocksPerSec, bs.TxsPerBlock, bs.CommitTxRate(), advanced)
+			}
+		}
+		fmt.Fprintf(out, "rejected: %d tx   mempool full: %d tx\n", tg.TxErrors(), tg.MempoolFull())
+		if fees := tg.FeesSpent(); len(fees) > 0 {
+			if len(cfg.FeeBudget) > 0 {
+				fmt.Fprintf(out, "fees: %s   budget: %s\n", fees, cfg.FeeBudget)
+			} else {
+				fmt.Fprintf(out, "fees: %s\n", fees)
+			}
+		}
+		if cfg.MaxInFlight > 0 {
+			if blocked := tg.Blocked(); blocked > 0 {
+				fmt.Fprintf(out, "%sbackpressure: %d/%d workers blocked (max %d tx in flight each)%s\n", ansiYellow, blocked, len(tg.transactors), cfg.MaxInFlight, ansiReset)
+			} else {
+				fmt.Fprintf(out, "backpressure: 0/%d workers blocked (max %d tx in flight each)\n", len(tg.transactors), cfg.MaxInFlight)
+			}
+		}
+		if healthy, total := tg.HealthyEndpoints(); healthy < total {
+			fmt.Fprintf(out, "%sendpoints: %d/%d healthy   DEGRADED (load redistributed to healthy endpoints)%s\n", ansiYellow, healthy, total, ansiReset)
+		} else {
+			fmt.Fprintf(out, "%s\n", trimForTable(fmt.Sprintf("endpoints: %d/%d healthy   %s", healthy, total, strings.Join(cfg.Endpoints, ", ")), width))
+		}
+		fmt.Fprintf(out, "\n")
+
+		// Sorted endpoints for stable display.
+		eps := make([]string, 0, len(byEP))
+		for ep := range byEP {
+			eps = append(eps, ep)
+		}
+		sort.Strings(eps)
+
+		// Table header, with the endpoint column taking up whatever width
+		// the numeric columns leave.
+		numWidth := tuiNumericColumnsWidth
+		if cfg.AdaptiveRouting {
+			numWidth += tuiWeightColumnWidth
+		}
+		epWidth := tuiEndpointColumnWidth(width-numWidth, eps)
+		if cfg.AdaptiveRouting {
+			fmt.Fprintf(out, "%-*s  %12s  %10s  %12s  %8s\n", epWidth, "endpoint", "txs", "tx/s", "KiB/s", "weight")
+		} else {
+			fmt.Fprintf(out, "%-*s  %12s  %10s  %12s\n", epWidth, "endpoint", "txs", "tx/s", "KiB/s")
+		}
+		fmt.Fprintf(out, "%s\n", strings.Repeat("-", epWidth+numWidth))
+
+		for _, ep := range eps {
+			stats := byEP[ep]
+			fmt.Fprintf(out, "%-*s  %12d  %10.0f  %12.1f",
+				epWidth,
+				trimForTable(ep, epWidth),
+				stats.txs,
+				stats.txRate,
+				stats.byteRate/1024.0,
+			)
+			if cfg.AdaptiveRouting {
+				fmt.Fprintf(out, "  %7.2fx", tg.EndpointWeight(ep))
+			}
+			fmt.Fprintln(out)
+		}
+
+		if warmingUp {
+			fmt.Fprint(out, ansiReset)
+		}
+
+		if keys {
+			fmt.Fprintf(out, "\nPress p to pause, r to resume, Ctrl+C to stop.\n")
+		} else {
+			fmt.Fprintf(out, "\nPress Ctrl+C to stop.\n")
+		}
+		_ = os.Stdout.Sync()
+	}
+
+	// Redraw straight away when the terminal is resized, rather than leave
+	// the screen garbled until the next tick.
+	resized := make(chan os.Signal, 1)
+	notifyResize(resized)
+
 	go func() {
 		defer close(stopped)
+		defer signal.Stop(resized)
 		ticker := time.NewTicker(1 * time.Second)
 		defer ticker.Stop()
 
@@ -47,90 +279,62 @@ func startStandaloneTUI(cfg *Config, tg *TransactorGroup) func() {
 					dt = 1
 				}
 
-				// Snapshot group stats.
-				type epAgg struct {
-					tx    int
-					bytes int64
-				}
-				byEP := map[string]*epAgg{}
-
+				// Snapshot group stats, mapping transactors to endpoints.
+				byEP = map[string]*tuiEndpointStats{}
 				tg.statsMtx.RLock()
-				startTime := tg.startTime
-				// Copy current txCounts/txBytes and map to endpoints.
 				for id, txc := range tg.txCounts {
 					ep := "unknown"
 					if id >= 0 && id < len(tg.transactors) {
 						ep = tg.transactors[id].remoteAddr
 					}
-					agg := byEP[ep]
-					if agg == nil {
-						agg = &epAgg{}
-						byEP[ep] = agg
+					stats := byEP[ep]
+					if stats == nil {
+						stats = &tuiEndpointStats{}
+						byEP[ep] = stats
 					}
-					agg.tx += txc
-					agg.bytes += tg.txBytes[id]
+					stats.txs += txc
+					stats.bytes += tg.txBytes[id]
 				}
 				tg.statsMtx.RUnlock()
 
-				totalTxs := 0
+				totalTxs = 0
 				totalBytes := int64(0)
-				for _, agg := range byEP {
-					totalTxs += agg.tx
-					totalBytes += agg.bytes
+				for ep, stats := range byEP {
+					totalTxs += stats.txs
+					totalBytes += stats.bytes
+					stats.txRate = float64(stats.txs-lastByEP[ep]) / dt
+					stats.byteRate = float64(stats.bytes-lastByEPBytes[ep]) / dt
 				}
 
 				// Compute instantaneous rates (delta since last tick).
-				instTxRate := float64(totalTxs-lastTotalTxs) / dt
-				instByteRate := float64(totalBytes-lastTotalByte) / dt
-
-				// Render.
-				clearScreen()
-				elapsed := 0 * time.Second
-				if !startTime.IsZero() {
-					elapsed = time.Since(startTime)
-				}
+				instTxRate = float64(totalTxs-lastTotalTxs) / dt
+				instByteRate = float64(totalBytes-lastTotalByte) / dt
+				rateHistory.Add(instTxRate)
 
-				fmt.Fprintf(os.Stdout, "PerpX Load Test (TUI)\n")
-				fmt.Fprintf(os.Stdout, "elapsed: %s / %ds   connections: %d   send_period: %ds   rate: %d tx/s/conn\n",
-					elapsed.Truncate(time.Second).String(),
-					cfg.Time,
-					cfg.Connections*len(cfg.Endpoints),
-					cfg.SendPeriod,
-					cfg.Rate,
-				)
-				fmt.Fprintf(os.Stdout, "total: %d tx   inst: %.0f tx/s   inst data: %.1f KiB/s\n",
-					totalTxs, instTxRate, instByteRate/1024.0,
-				)
-				fmt.Fprintf(os.Stdout, "endpoints: %s\n", strings.Join(cfg.Endpoints, ", "))
-				fmt.Fprintf(os.Stdout, "\n")
-
-				// Table header.
-				fmt.Fprintf(os.Stdout, "%-42s  %12s  %10s  %12s\n", "endpoint", "txs", "tx/s", "KiB/s")
-				fmt.Fprintf(os.Stdout, "%s\n", strings.Repeat("-", 82))
-
-				// Sorted endpoints for stable display.
-				eps := make([]string, 0, len(byEP))
-				for ep := range byEP {
-					eps = append(eps, ep)
+				// Compare the achieved rate against the target. We ignore the
+				// first tick (connections are still warming up) and the tail
+				// end of count-limited runs.
+				elapsed := elapsedTime()
+				targetTxRate = baseTxRate
+				if cfg.TargetTPS > 0 {
+					targetTxRate = float64(cfg.TargetTPS)
 				}
-				sort.Strings(eps)
-
-				for _, ep := range eps {
-					agg := byEP[ep]
-					prevTx := lastByEP[ep]
-					prevB := lastByEPBytes[ep]
-					epTxRate := float64(agg.tx-prevTx) / dt
-					epBRate := float64(agg.bytes-prevB) / dt
-					fmt.Fprintf(os.Stdout, "%-42s  %12d  %10.0f  %12.1f\n",
-						trimForTable(ep, 42),
-						agg.tx,
-						epTxRate,
-						epBRate/1024.0,
-					)
+				spikeLabel = ""
+				if spike.Active(elapsed) {
+					targetTxRate *= spike.Factor
+					spikeLabel = fmt.Sprintf("   SPIKE x%g", spike.Factor)
+				}
+				achieved = 0
+				if targetTxRate > 0 {
+					achieved = instTxRate / targetTxRate
+				}
+				if lastTotalTxs > 0 && tg.WarmupComplete() && !tg.Paused() && !tg.budget.exhausted() && achieved < tuiLagThreshold {
+					lagTicks++
+				} else {
+					lagTicks = 0
 				}
 
-				fmt.Fprintf(os.Stdout, "\nPress Ctrl+C to stop.\n")
-				_ = os.Stdout.Sync()
+				draw()
 
 				// Update last snapshot.
 				lastTime = now
@@ -138,9 +342,15 @@ func startStandaloneTUI(cfg *Config, tg *TransactorGroup) func() {
 				lastTotalByte = totalBytes
 				lastByEP = map[string]int{}
 				lastByEPBytes = map[string]int64{}
-				for ep, agg := range byEP {
-					lastByEP[ep] = agg.tx
-					lastByEPBytes[ep] = agg.bytes
+				for ep, stats := range byEP {
+					lastByEP[ep] = stats.txs
+					lastByEPBytes[ep] = stats.bytes
+				}
+
+			case <-resized:
+				// nothing to redraw before the first tick
+				if byEP != nil {
+					draw()
 				}
 
 			case <-stopc:
@@ -155,14 +365,51 @@ func startStandaloneTUI(cfg *Config, tg *TransactorGroup) func() {
 			// already stopped
 		default:
 			close(stopc)
+			if blocks != nil {
+				blocks.Stop()
+			}
 		}
 		<-stopped
 		// Restore terminal state.
 		clearScreen()
 		showCursor()
+		restoreTerminal()
 	}
 }
 
+// tuiEndpointStats are an endpoint's totals, and its rates since the
+// previous tick, as of the TUI's latest tick.
+type tuiEndpointStats struct {
+	txs      int
+	bytes    int64
+	txRate   float64
+	byteRate float64
+}
+
+// tuiWidth returns the width of the terminal on which the TUI is drawn, or
+// tuiDefaultWidth if it can't be queried (e.g. because stdout isn't a
+// terminal). It's queried before every frame, so that the layout follows the
+// terminal as it's resized.
+func tuiWidth() int {
+	width, _, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil || width <= 0 {
+		return tuiDefaultWidth
+	}
+	return width
+}
+
+// tuiEndpointColumnWidth returns the width of the TUI table's endpoint column,
+// given the width left over by its other columns: wide enough for the longest
+// of the given endpoints if there's room, but never narrower than
+// tuiMinEndpointColumnWidth, even if that means overflowing the terminal.
+func tuiEndpointColumnWidth(available int, endpoints []string) int {
+	width := len("endpoint")
+	for _, ep := range endpoints {
+		width = max(width, len(ep))
+	}
+	return max(min(width, available), tuiMinEndpointColumnWidth)
+}
+
 func trimForTable(s string, max int) string {
 	if len(s) <= max {
 		return s
@@ -172,7 +419,3 @@ func trimForTable(s string, max int) string {
 	}
 	return s[:max-3] + "..."
 }
-
-
-
-
@@ -0,0 +1,99 @@
+package loadtest
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"sync"
+
+	"golang.org/x/term"
+)
+
+// startTUIKeys puts the terminal into raw mode, if stdin is a terminal, and
+// handles key presses for the TUI: "p" pauses the generation of transactions
+// and "r" resumes it. Since raw mode stops the terminal from turning Ctrl+C
+// into an interrupt signal, we raise the signal ourselves when it is pressed.
+//
+// It returns a function that restores the terminal's previous state, and
+// whether keys are being handled at all. The goroutine reading stdin is left
+// blocked in its read once the terminal is restored, since reads from stdin
+// can't be interrupted; nothing else reads stdin while the load test runs.
+func startTUIKeys(tg *TransactorGroup) (restore func(), ok bool) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return func() {}, false
+	}
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return func() {}, false
+	}
+	var (
+		mtx      sync.Mutex
+		restored bool
+	)
+	restore = func() {
+		mtx.Lock()
+		defer mtx.Unlock()
+		if !restored {
+			_ = term.Restore(fd, state)
+			restored = true
+		}
+	}
+
+	go func() {
+		buf := make([]byte, 1)
+		for {
+			n, err := os.Stdin.Read(buf)
+			if err != nil {
+				return
+			}
+			mtx.Lock()
+			done := restored
+			mtx.Unlock()
+			if done {
+				return
+			}
+			if n == 0 {
+				continue
+			}
+			switch buf[0] {
+			case 'p', 'P':
+				if tg.Paused() {
+					tg.Resume()
+				} else {
+					tg.Pause()
+				}
+			case 'r', 'R':
+				tg.Resume()
+			case 0x03: // Ctrl+C
+				restore()
+				interruptSelf(tg)
+				return
+			}
+		}
+	}()
+	return restore, true
+}
+
+// interruptSelf sends an interrupt signal to our own process, as pressing
+// Ctrl+C would outside of raw mode, falling back to cancelling the load test
+// if the signal can't be sent.
+func interruptSelf(tg *TransactorGroup) {
+	if p, err := os.FindProcess(os.Getpid()); err == nil && p.Signal(os.Interrupt) == nil {
+		return
+	}
+	tg.Cancel()
+}
+
+// crlfWriter translates "\n" into "\r\n", since a terminal in raw mode no
+// longer returns to the start of the line on a line feed.
+type crlfWriter struct {
+	w io.Writer
+}
+
+func (c crlfWriter) Write(p []byte) (int, error) {
+	if _, err := c.w.Write(bytes.ReplaceAll(p, []byte("\n"), []byte("\r\n"))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
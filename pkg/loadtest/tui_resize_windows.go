@@ -0,0 +1,7 @@
+package loadtest
+
+import "os"
+
+// notifyResize does nothing, since Windows has no SIGWINCH: the TUI picks up
+// the terminal's new size on its next tick instead.
+func notifyResize(chan<- os.Signal) {}
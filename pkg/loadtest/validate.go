@@ -0,0 +1,48 @@
+package loadtest
+
+import (
+	"fmt"
+
+	"github.com/1119-Labs/perpx-load-test/internal/logging"
+)
+
+// ExecuteValidateOnly initializes a single client and has it generate a
+// transaction, which is checked against the node (if the client implements
+// ClientValidator) rather than broadcast. The transaction and the results of
+// the checks are printed. No load is generated.
+func ExecuteValidateOnly(cfg Config) error {
+	logger := logging.NewLogrusLogger("loadtest")
+
+	clientFactory, exists := clientFactories[cfg.ClientFactory]
+	if !exists {
+		return fmt.Errorf("unrecognized client factory: %s", cfg.ClientFactory)
+	}
+	logger.Info("Validating transaction generation", "clientFactory", cfg.ClientFactory, "endpoint", cfg.Endpoints[0])
+	client, err := clientFactory.NewClient(cfg)
+	if err != nil {
+		logger.Error("Failed to create client", "err", err)
+		return err
+	}
+
+	validator, ok := client.(ClientValidator)
+	if !ok {
+		// we can at least make sure that a transaction can be generated
+		tx, err := client.GenerateTx()
+		if err != nil {
+			logger.Error("Failed to generate transaction", "err", err)
+			return err
+		}
+		logger.Info("Generated transaction, but the client can't have the node check it", "bytes", len(tx))
+		return nil
+	}
+	description, err := validator.ValidateTx()
+	if len(description) > 0 {
+		fmt.Println(description)
+	}
+	if err != nil {
+		logger.Error("Transaction validation failed", "err", err)
+		return err
+	}
+	logger.Info("Transaction validation succeeded - no transactions were broadcast")
+	return nil
+}
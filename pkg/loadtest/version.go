@@ -0,0 +1,48 @@
+package loadtest
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// Version returns the version of this build: CLIVersion, suffixed with the
+// commit ID set through linker settings or, failing that, the VCS revision
+// recorded by the Go toolchain.
+func Version() string {
+	version := CLIVersion
+	commitID := cliVersionCommitID
+	if len(commitID) == 0 {
+		if info, ok := debug.ReadBuildInfo(); ok {
+			for _, setting := range info.Settings {
+				if setting.Key == "vcs.revision" {
+					commitID = setting.Value
+				}
+			}
+		}
+	}
+	if len(commitID) > 0 {
+		version = fmt.Sprintf("%s-%s", version, commitID)
+	}
+	return version
+}
+
+// ModuleVersion returns the version of the given module that was linked into
+// this binary, including its replacement (if any), e.g.
+// "v0.50.11 => github.com/org/fork v0.50.6". Returns false if the module isn't
+// a dependency of this binary, or if the binary lacks build information.
+func ModuleVersion(path string) (string, bool) {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "", false
+	}
+	for _, dep := range info.Deps {
+		if dep.Path != path {
+			continue
+		}
+		if dep.Replace != nil {
+			return fmt.Sprintf("%s => %s %s", dep.Version, dep.Replace.Path, dep.Replace.Version), true
+		}
+		return dep.Version, true
+	}
+	return "", false
+}
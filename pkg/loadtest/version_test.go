@@ -0,0 +1,22 @@
+package loadtest_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/1119-Labs/perpx-load-test/pkg/loadtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVersion(t *testing.T) {
+	assert.True(t, strings.HasPrefix(loadtest.Version(), loadtest.CLIVersion))
+}
+
+func TestModuleVersion(t *testing.T) {
+	version, ok := loadtest.ModuleVersion("github.com/stretchr/testify")
+	assert.True(t, ok)
+	assert.True(t, strings.HasPrefix(version, "v"), version)
+
+	_, ok = loadtest.ModuleVersion("example.com/no/such/module")
+	assert.False(t, ok)
+}
@@ -0,0 +1,99 @@
+package loadtest
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// EndpointWeights maps endpoint identifiers to the relative share of the load
+// that should be sent to them. An identifier may be an endpoint's full URL,
+// its "host:port" or just its hostname.
+type EndpointWeights map[string]float64
+
+// ParseEndpointWeights parses a comma-separated list of "endpoint:weight"
+// pairs, e.g. "node1:3,node2:1". Since the endpoint identifier may itself
+// contain colons, the weight is taken to follow the last colon.
+func ParseEndpointWeights(s string) (EndpointWeights, error) {
+	weights := make(EndpointWeights)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if len(pair) == 0 {
+			continue
+		}
+		sep := strings.LastIndex(pair, ":")
+		if sep <= 0 {
+			return nil, fmt.Errorf("invalid endpoint weight %q: expected endpoint:weight", pair)
+		}
+		weight, err := strconv.ParseFloat(pair[sep+1:], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid endpoint weight %q: %w", pair, err)
+		}
+		if weight <= 0 || math.IsInf(weight, 0) || math.IsNaN(weight) {
+			return nil, fmt.Errorf("invalid endpoint weight %q: weight must be > 0", pair)
+		}
+		weights[pair[:sep]] = weight
+	}
+	return weights, nil
+}
+
+// Weight returns the weight of the given endpoint URL, which defaults to 1 if
+// no weight was specified for it.
+func (w EndpointWeights) Weight(endpoint string) float64 {
+	for _, key := range endpointWeightKeys(endpoint) {
+		if weight, ok := w[key]; ok {
+			return weight
+		}
+	}
+	return 1
+}
+
+// Unmatched returns the identifiers that don't match any of the given
+// endpoints (most likely typos).
+func (w EndpointWeights) Unmatched(endpoints []string) []string {
+	matched := make(map[string]bool)
+	for _, endpoint := range endpoints {
+		for _, key := range endpointWeightKeys(endpoint) {
+			matched[key] = true
+		}
+	}
+	var unmatched []string
+	for key := range w {
+		if !matched[key] {
+			unmatched = append(unmatched, key)
+		}
+	}
+	return unmatched
+}
+
+// Rates distributes the given per-connection rate across the given
+// endpoints in proportion to their weights, such that the total rate across
+// all endpoints remains the same as if they were unweighted. Every endpoint
+// is given a rate of at least 1.
+func (w EndpointWeights) Rates(rate int, endpoints []string) []int {
+	totalWeight := 0.0
+	for _, endpoint := range endpoints {
+		totalWeight += w.Weight(endpoint)
+	}
+	rates := make([]int, len(endpoints))
+	for i, endpoint := range endpoints {
+		r := int(math.Round(float64(rate) * float64(len(endpoints)) * w.Weight(endpoint) / totalWeight))
+		if r < 1 {
+			r = 1
+		}
+		rates[i] = r
+	}
+	return rates
+}
+
+// endpointWeightKeys returns the identifiers by which the given endpoint URL
+// can be referred to, from most to least specific.
+func endpointWeightKeys(endpoint string) []string {
+	keys := []string{endpoint}
+	if u, err := url.Parse(endpoint); err == nil && len(u.Host) > 0 {
+		keys = append(keys, u.Host, u.Hostname())
+	}
+	return keys
+}
@@ -0,0 +1,39 @@
+package loadtest_test
+
+import (
+	"testing"
+
+	"github.com/1119-Labs/perpx-load-test/pkg/loadtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEndpointWeights(t *testing.T) {
+	endpoints := []string{
+		"ws://node1:36657/websocket",
+		"ws://node2:36657/websocket",
+		"ws://node3:36657/websocket",
+	}
+	weights, err := loadtest.ParseEndpointWeights("node1:3, node2:36657:0.5,ws://node3:36657/websocket:1.5,node4:2")
+	require.NoError(t, err)
+
+	assert.Equal(t, 3.0, weights.Weight(endpoints[0]))
+	assert.Equal(t, 0.5, weights.Weight(endpoints[1]))
+	assert.Equal(t, 1.5, weights.Weight(endpoints[2]))
+	assert.Equal(t, 1.0, weights.Weight("ws://node5:36657/websocket"))
+	assert.Equal(t, []string{"node4"}, weights.Unmatched(endpoints))
+
+	// the total rate is preserved
+	assert.Equal(t, []int{1800, 300, 900}, weights.Rates(1000, endpoints))
+
+	none, err := loadtest.ParseEndpointWeights("")
+	require.NoError(t, err)
+	assert.Equal(t, []int{1000, 1000, 1000}, none.Rates(1000, endpoints))
+}
+
+func TestParseEndpointWeightsErrors(t *testing.T) {
+	for _, s := range []string{"node1", "node1:", ":3", "node1:abc", "node1:0", "node1:-1"} {
+		_, err := loadtest.ParseEndpointWeights(s)
+		assert.Error(t, err, s)
+	}
+}
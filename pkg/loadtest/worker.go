@@ -2,6 +2,7 @@ package loadtest
 
 import (
 	"fmt"
+	"os"
 	"strings"
 	"sync"
 	"time"
@@ -209,7 +210,8 @@ func (w *Worker) register() error {
 
 	w.setCfg(*resp.Config)
 	w.logger.Info("Successfully registered with coordinator")
-	w.logger.Debug("Got load testing configuration from coordinator", "cfg", w.Config().ToJSON())
+	// the coordinator's configuration, but this worker's environment
+	w.logger.Info("Got load testing configuration from coordinator", "cfg", w.Config().EffectiveJSON())
 	return nil
 }
 
@@ -247,7 +249,10 @@ func (w *Worker) executeLoadTest() error {
 	w.logger.Info("Connecting to remote endpoints")
 	tg := NewTransactorGroup()
 	cfg := w.Config()
-	if err := tg.AddAll(&cfg); err != nil {
+	cfg.ResultObserver = w.workerCfg.ResultObserver
+	err := tg.AddAll(&cfg)
+	WriteConnectionSummary(os.Stdout, tg.ConnectionResults())
+	if err != nil {
 		return err
 	}
 	tg.SetProgressCallback(workerUpdateInterval, w.reportProgress)
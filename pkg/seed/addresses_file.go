@@ -0,0 +1,50 @@
+package seed
+
+import (
+	"fmt"
+	"os"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	"github.com/1119-Labs/perpx-load-test/pkg/strategies"
+)
+
+// readAddressesFile reads the addresses to fund from the file at the given
+// path, one bech32 address per line, in the same format as the load test's
+// recipients file. Every invalid line is reported, and since funding the
+// wrong addresses can't be undone, any invalid line is an error. Addresses
+// listed more than once are only funded once.
+func readAddressesFile(path string) ([]sdk.AccAddress, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open addresses file: %w", err)
+	}
+	defer f.Close()
+	listed, invalid, err := strategies.ReadRecipients(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read addresses file %s: %w", path, err)
+	}
+	for _, lineErr := range invalid {
+		fmt.Printf("  Invalid address in %s: %v\n", path, lineErr)
+	}
+	if len(invalid) > 0 {
+		return nil, fmt.Errorf("addresses file %s contains %d invalid line(s)", path, len(invalid))
+	}
+	seen := make(map[string]bool, len(listed))
+	addrs := make([]sdk.AccAddress, 0, len(listed))
+	for _, addr := range listed {
+		if seen[addr] {
+			continue
+		}
+		seen[addr] = true
+		parsed, err := sdk.AccAddressFromBech32(addr)
+		if err != nil {
+			return nil, err
+		}
+		addrs = append(addrs, parsed)
+	}
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("addresses file %s contains no addresses", path)
+	}
+	return addrs, nil
+}
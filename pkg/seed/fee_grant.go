@@ -0,0 +1,139 @@
+package seed
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"cosmossdk.io/x/feegrant"
+	"github.com/1119-Labs/perpx-load-test/pkg/accounts"
+)
+
+// grantFeeAllowances funds the cfg.FeeGranters fee granter accounts, if
+// configured to, and has each of them grant a fee allowance to the workers
+// assigned to it: worker i to granter i mod cfg.FeeGranters, exactly as the
+// load test client assigns them. Workers that already have their allowance
+// are skipped, so that seeding can be repeated.
+func grantFeeAllowances(ctx context.Context, cfg Config, funder *Funder, workers []sdk.AccAddress, granterFundCoins sdk.Coins) error {
+	if cfg.FeeGranters == 0 {
+		return nil
+	}
+	granterAddrs := make([]sdk.AccAddress, cfg.FeeGranters)
+	for i := range granterAddrs {
+		granterAddrs[i] = sdk.AccAddress(accounts.FeeGranterPrivKey(i).PubKey().Address())
+	}
+
+	// Fund the granters that can't yet pay for their workers' fees
+	var needsFunding []sdk.AccAddress
+	for _, addr := range granterAddrs {
+		balance, err := funder.BalanceIn(addr, granterFundCoins)
+		if err != nil || !balance.IsAllGTE(granterFundCoins) {
+			needsFunding = append(needsFunding, addr)
+		}
+	}
+	if len(needsFunding) > 0 {
+		fmt.Printf("Funding %d fee granters with %s each...\n", len(needsFunding), granterFundCoins)
+		totalBatches := (len(needsFunding) + cfg.BatchSize - 1) / cfg.BatchSize
+		for i := 0; i < len(needsFunding); i += cfg.BatchSize {
+			end := min(i+cfg.BatchSize, len(needsFunding))
+			if err := fundBatch(ctx, funder, needsFunding[i:end], granterFundCoins, (i/cfg.BatchSize)+1, totalBatches); err != nil {
+				return fmt.Errorf("failed to fund fee granters: %w", err)
+			}
+		}
+	}
+
+	for g := range granterAddrs {
+		granter, err := newKeyFunder(cfg, funder.restClient, accounts.FeeGranterPrivKey(g))
+		if err != nil {
+			return fmt.Errorf("fee granter %d: %w", g, err)
+		}
+		var grantees []sdk.AccAddress
+		for i := g; i < len(workers); i += cfg.FeeGranters {
+			if !granter.HasFeeAllowance(workers[i]) {
+				grantees = append(grantees, workers[i])
+			}
+		}
+		if len(grantees) == 0 {
+			continue
+		}
+		fmt.Printf("Fee granter %d (%s) granting fee allowances to %d workers in batches of %d...\n",
+			g, granter.Address().String(), len(grantees), cfg.BatchSize)
+		totalBatches := (len(grantees) + cfg.BatchSize - 1) / cfg.BatchSize
+		for i := 0; i < len(grantees); i += cfg.BatchSize {
+			end := min(i+cfg.BatchSize, len(grantees))
+			_, span := tracer().Start(ctx, "fee_grant_batch", trace.WithAttributes(
+				attribute.Int("granter", g),
+				attribute.Int("batch", (i/cfg.BatchSize)+1),
+				attribute.Int("accounts", end-i),
+			))
+			txHash, height, err := granter.GrantFeeAllowance(grantees[i:end])
+			endSpan(span, err)
+			if err != nil {
+				return fmt.Errorf("fee granter %d failed to grant fee allowances: %w", g, err)
+			}
+			fmt.Printf("  Batch %d/%d: %d fee allowances included in block %s (tx hash: %s)\n",
+				(i/cfg.BatchSize)+1, totalBatches, end-i, height, txHash)
+		}
+	}
+
+	granterStrs := make([]string, len(granterAddrs))
+	for i, addr := range granterAddrs {
+		granterStrs[i] = addr.String()
+	}
+	fmt.Printf("Use --fee-granters %s when running the load test\n", strings.Join(granterStrs, ","))
+	return nil
+}
+
+// feeAllowanceMsgs creates the messages granting each of the grantees an
+// unlimited allowance to pay their transaction fees from the funder's
+// account.
+func (f *Funder) feeAllowanceMsgs(grantees []sdk.AccAddress) ([]sdk.Msg, error) {
+	msgs := make([]sdk.Msg, 0, len(grantees))
+	for _, addr := range grantees {
+		grant, err := feegrant.NewMsgGrantAllowance(&feegrant.BasicAllowance{}, f.addr, addr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create fee allowance for %s: %w", addr.String(), err)
+		}
+		msgs = append(msgs, grant)
+	}
+	return msgs, nil
+}
+
+// GrantFeeAllowance grants each of the grantees an allowance to pay their
+// fees from the funder's account in a single transaction, and waits for it to
+// be included in a block. Returns the transaction's hash and the height at
+// which it was included.
+func (f *Funder) GrantFeeAllowance(grantees []sdk.AccAddress) (txHash, height string, err error) {
+	msgs, err := f.feeAllowanceMsgs(grantees)
+	if err != nil {
+		return "", "", err
+	}
+	txBytes, err := f.sign(msgs)
+	if err != nil {
+		return "", "", err
+	}
+	if txHash, err = f.send(txBytes); err != nil {
+		return "", "", err
+	}
+	height, err = f.waitForTx(txHash)
+	return txHash, height, err
+}
+
+// HasFeeAllowance reports whether the funder's account has already granted
+// the grantee a fee allowance. Since the chain rejects a second allowance for
+// the same grantee, it errs on the side of false (so that a failure to query
+// the allowance surfaces when granting it instead).
+func (f *Funder) HasFeeAllowance(grantee sdk.AccAddress) bool {
+	allowanceURL := fmt.Sprintf("%s/cosmos/feegrant/v1beta1/allowance/%s/%s", f.restURL, f.addr.String(), grantee.String())
+	resp, err := f.restClient.Get(allowanceURL)
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+	return resp.StatusCode == http.StatusOK
+}
@@ -0,0 +1,554 @@
+package seed
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/cosmos/cosmos-sdk/crypto/hd"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	"github.com/cosmos/cosmos-sdk/types/tx/signing"
+	"github.com/cosmos/cosmos-sdk/x/authz"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"google.golang.org/grpc"
+
+	"cosmossdk.io/math"
+	"github.com/1119-Labs/perpx-chain/protocol/app"
+
+	"github.com/1119-Labs/perpx-load-test/pkg/accounts"
+)
+
+const (
+	// Minimum gas price: 25000000000aperpx per unit of gas (from cmd/perpxd/cmd/config.go)
+	minGasPrice = 25000000000
+
+	// The common dev key name which, outside of --seed-from-genesis mode,
+	// transparently stands for devMnemonic.
+	devKeyName = "alice"
+	// NOTE: This is the actual alice validator mnemonic from protocol/deployment/localnet/config.yml
+	// This is a development-only mnemonic and MUST NOT be used in production.
+	devMnemonic = "merge panther lobster crazy road hollow amused security before critic about cliff exhibit cause coyote talent happy where lion river tobacco option coconut small"
+)
+
+// Funder transfers funds from the seed account to other accounts, packing
+// the transfers into multi-message transactions which it signs, broadcasts
+// and waits to be committed. Funders are not safe for concurrent use.
+type Funder struct {
+	cfg        Config
+	encCfg     app.EncodingConfig
+	privKey    cryptotypes.PrivKey                // Unset when signing on a Ledger device.
+	ledgerKey  cryptotypes.LedgerPrivKeyAminoJSON // Only set when signing on a Ledger device.
+	pubKey     cryptotypes.PubKey
+	addr       sdk.AccAddress
+	restURL    string
+	grpcAddr   string
+	restClient *http.Client
+	queryConn  *grpc.ClientConn // Set once queries have fallen back to gRPC because the REST API is unavailable.
+
+	accountNum uint64
+	sequence   uint64
+}
+
+// NewFunder derives the seed account's key from the given configuration (or
+// gets it from a Ledger device) and queries its account number and sequence.
+// With SeedFromGenesis, the key must control an account funded at genesis.
+func NewFunder(cfg Config, restClient *http.Client) (*Funder, error) {
+	f := &Funder{
+		cfg:        cfg,
+		encCfg:     app.GetEncodingConfig(),
+		restURL:    restURLFor(cfg.RPC),
+		grpcAddr:   grpcAddrFor(cfg.RPC),
+		restClient: restClient,
+	}
+	var err error
+	switch {
+	case cfg.Ledger && cfg.SeedFromGenesis:
+		return nil, fmt.Errorf("--ledger cannot be combined with --seed-from-genesis")
+	case cfg.Ledger && accounts.KeyAlgo() != accounts.KeyAlgoSecp256k1:
+		return nil, fmt.Errorf("--ledger only supports %s keys", accounts.KeyAlgoSecp256k1)
+	case cfg.Ledger:
+		f.ledgerKey, err = ledgerSeedKey(cfg)
+		if err == nil {
+			f.pubKey = f.ledgerKey.PubKey()
+		}
+	case cfg.SeedFromGenesis:
+		f.privKey, err = genesisSeedPrivKey(cfg, restClient)
+	default:
+		f.privKey, err = seedPrivKey(cfg)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if f.privKey != nil {
+		f.pubKey = f.privKey.PubKey()
+	}
+	f.addr = sdk.AccAddress(f.pubKey.Address())
+	if cfg.Ledger {
+		fmt.Printf("  Seed account on Ledger device: %s\n", f.addr)
+	}
+	if err := f.Sync(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// newKeyFunder creates a funder that signs with the given private key rather
+// than the seed account's (e.g. that of a fee granter account), and queries
+// its account number and sequence.
+func newKeyFunder(cfg Config, restClient *http.Client, privKey cryptotypes.PrivKey) (*Funder, error) {
+	f := &Funder{
+		cfg:        cfg,
+		encCfg:     app.GetEncodingConfig(),
+		privKey:    privKey,
+		pubKey:     privKey.PubKey(),
+		restURL:    restURLFor(cfg.RPC),
+		grpcAddr:   grpcAddrFor(cfg.RPC),
+		restClient: restClient,
+	}
+	f.addr = sdk.AccAddress(f.pubKey.Address())
+	if err := f.Sync(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// seedPrivKey derives the seed account's private key from either the
+// hex-encoded private key or the mnemonic in the given configuration.
+func seedPrivKey(cfg Config) (cryptotypes.PrivKey, error) {
+	// If private key is provided, use it directly (takes precedence)
+	if cfg.SeedPrivateKey != "" {
+		// Parse hex-encoded private key
+		keyBytes, err := hex.DecodeString(strings.TrimPrefix(cfg.SeedPrivateKey, "0x"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode private key (must be hex-encoded): %w", err)
+		}
+		if len(keyBytes) != 32 {
+			return nil, fmt.Errorf("invalid private key length: expected 32 bytes, got %d", len(keyBytes))
+		}
+		// Create a private key of the configured algorithm from bytes
+		privKeyBytes, _ := btcec.PrivKeyFromBytes(keyBytes)
+		return accounts.NewPrivKey(privKeyBytes.Serialize()), nil
+	}
+
+	// Fall back to mnemonic-based key derivation
+	// If the user passed the common dev key name "alice", transparently
+	// substitute the actual alice validator mnemonic from localnet config.yml
+	// so the command works out-of-the-box.
+	seedKey := cfg.SeedKey
+	if seedKey == devKeyName {
+		seedKey = devMnemonic
+	}
+
+	// Treat SeedKey as either a full mnemonic (contains spaces) or fail fast.
+	// In the future this can be extended to look up named keys from a keyring.
+	if !strings.Contains(seedKey, " ") {
+		return nil, fmt.Errorf("seed-key %q is not a mnemonic; please provide a mnemonic, use \"alice\", or use --seed-private-key", seedKey)
+	}
+	// Both key algorithms derive secp256k1 keys via BIP-44, but on the path
+	// of their own coin type
+	hdPath := hd.CreateHDPath(accounts.CoinType(), 0, 0).String()
+	derivedPriv, err := hd.Secp256k1.Derive()(seedKey, "", hdPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key from mnemonic: %w", err)
+	}
+	return accounts.NewPrivKey(derivedPriv), nil
+}
+
+// restURLFor converts an RPC URL (port 36657 or 26657) to the corresponding
+// REST API URL (port 31317 or 1317).
+//
+// We use the REST API for queries to avoid gRPC frame size limits: the
+// "http2: frame too large" error occurs with gRPC when responses are large.
+func restURLFor(rpc string) string {
+	restURL := strings.Replace(rpc, ":36657", ":31317", 1)
+	if !strings.Contains(restURL, ":31317") {
+		// If port wasn't 36657, try to infer REST port or use default
+		restURL = strings.Replace(rpc, ":26657", ":1317", 1)
+		if !strings.Contains(restURL, ":1317") {
+			// Default to localhost:31317 if we can't determine
+			restURL = "http://localhost:31317"
+		}
+	}
+	return restURL
+}
+
+// grpcAddrFor converts an RPC URL (port 36657 or 26657) to the corresponding
+// gRPC address (port 39090 or 9090), which we use for broadcasting.
+func grpcAddrFor(rpc string) string {
+	grpcURL := strings.Replace(rpc, ":36657", ":39090", 1)
+	if !strings.Contains(grpcURL, ":39090") {
+		grpcURL = strings.Replace(rpc, ":26657", ":9090", 1)
+		if !strings.Contains(grpcURL, ":9090") {
+			grpcURL = "http://localhost:39090"
+		}
+	}
+	return strings.TrimPrefix(grpcURL, "http://")
+}
+
+// Address returns the address of the seed account.
+func (f *Funder) Address() sdk.AccAddress {
+	return f.addr
+}
+
+// Sync (re-)queries the seed account's account number and sequence, e.g.
+// after a funding transaction failed and may or may not have consumed a
+// sequence number.
+func (f *Funder) Sync() error {
+	if f.queryConn != nil {
+		return f.syncGRPC()
+	}
+	accountURL := fmt.Sprintf("%s/cosmos/auth/v1beta1/accounts/%s", f.restURL, f.addr.String())
+	accountResp, err := f.restClient.Get(accountURL)
+	if err != nil {
+		if f.fallBackToGRPC(err) {
+			return f.syncGRPC()
+		}
+		return fmt.Errorf("failed to query seed account: %w", err)
+	}
+	defer accountResp.Body.Close()
+
+	if accountResp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(accountResp.Body)
+		return fmt.Errorf("failed to query seed account: HTTP %d: %s", accountResp.StatusCode, string(body))
+	}
+
+	var accountData struct {
+		Account struct {
+			Type          string `json:"@type"`
+			Address       string `json:"address"`
+			AccountNumber string `json:"account_number"`
+			Sequence      string `json:"sequence"`
+			// The accounts of EVM-enabled chains wrap the base account
+			BaseAccount *struct {
+				AccountNumber string `json:"account_number"`
+				Sequence      string `json:"sequence"`
+			} `json:"base_account"`
+		} `json:"account"`
+	}
+	if err := json.NewDecoder(accountResp.Body).Decode(&accountData); err != nil {
+		return fmt.Errorf("failed to decode account response: %w", err)
+	}
+	if base := accountData.Account.BaseAccount; base != nil {
+		accountData.Account.AccountNumber = base.AccountNumber
+		accountData.Account.Sequence = base.Sequence
+	}
+
+	// Parse account number and sequence
+	accountNum, err := strconv.ParseUint(accountData.Account.AccountNumber, 10, 64)
+	if err != nil {
+		return fmt.Errorf("failed to parse account number: %w", err)
+	}
+	sequence, err := strconv.ParseUint(accountData.Account.Sequence, 10, 64)
+	if err != nil {
+		return fmt.Errorf("failed to parse sequence: %w", err)
+	}
+	f.accountNum = accountNum
+	f.sequence = sequence
+	return nil
+}
+
+// Balance queries all of the given account's balances.
+func (f *Funder) Balance(addr sdk.AccAddress) (sdk.Coins, error) {
+	if f.queryConn != nil {
+		return f.balanceGRPC(addr)
+	}
+	balanceURL := fmt.Sprintf("%s/cosmos/bank/v1beta1/balances/%s", f.restURL, addr.String())
+	balanceResp, err := f.restClient.Get(balanceURL)
+	if err != nil {
+		if f.fallBackToGRPC(err) {
+			return f.balanceGRPC(addr)
+		}
+		return nil, fmt.Errorf("failed to query balance: %w", err)
+	}
+	defer balanceResp.Body.Close()
+
+	if balanceResp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(balanceResp.Body)
+		return nil, fmt.Errorf("failed to query balance: HTTP %d: %s", balanceResp.StatusCode, string(body))
+	}
+
+	var balanceData struct {
+		Balances []struct {
+			Denom  string `json:"denom"`
+			Amount string `json:"amount"`
+		} `json:"balances"`
+	}
+	if err := json.NewDecoder(balanceResp.Body).Decode(&balanceData); err != nil {
+		return nil, fmt.Errorf("failed to decode balance response: %w", err)
+	}
+
+	balance := sdk.NewCoins()
+	for _, bal := range balanceData.Balances {
+		amount, ok := math.NewIntFromString(bal.Amount)
+		if !ok {
+			return nil, fmt.Errorf("invalid amount: %s", bal.Amount)
+		}
+		balance = balance.Add(sdk.NewCoin(bal.Denom, amount))
+	}
+	return balance, nil
+}
+
+// BalanceOf queries the given account's balance of a single denom, which
+// makes for a smaller response than Balance when only one denom matters.
+func (f *Funder) BalanceOf(addr sdk.AccAddress, denom string) (sdk.Coin, error) {
+	if f.queryConn != nil {
+		return f.balanceOfGRPC(addr, denom)
+	}
+	balanceURL := fmt.Sprintf("%s/cosmos/bank/v1beta1/balances/%s/by_denom?denom=%s", f.restURL, addr.String(), url.QueryEscape(denom))
+	balanceResp, err := f.restClient.Get(balanceURL)
+	if err != nil {
+		if f.fallBackToGRPC(err) {
+			return f.balanceOfGRPC(addr, denom)
+		}
+		return sdk.Coin{}, fmt.Errorf("failed to query balance: %w", err)
+	}
+	defer balanceResp.Body.Close()
+
+	if balanceResp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(balanceResp.Body)
+		return sdk.Coin{}, fmt.Errorf("failed to query balance: HTTP %d: %s", balanceResp.StatusCode, string(body))
+	}
+
+	var balanceData struct {
+		Balance struct {
+			Denom  string `json:"denom"`
+			Amount string `json:"amount"`
+		} `json:"balance"`
+	}
+	if err := json.NewDecoder(balanceResp.Body).Decode(&balanceData); err != nil {
+		return sdk.Coin{}, fmt.Errorf("failed to decode balance response: %w", err)
+	}
+	amount, ok := math.NewIntFromString(balanceData.Balance.Amount)
+	if !ok {
+		return sdk.Coin{}, fmt.Errorf("invalid amount: %s", balanceData.Balance.Amount)
+	}
+	return sdk.NewCoin(denom, amount), nil
+}
+
+// BalanceIn queries the given account's balances of the denoms of the given
+// coins (e.g. the amount with which it is funded), using the smaller by-denom
+// query when there's only one of them.
+func (f *Funder) BalanceIn(addr sdk.AccAddress, coins sdk.Coins) (sdk.Coins, error) {
+	if len(coins) != 1 {
+		return f.Balance(addr)
+	}
+	balance, err := f.BalanceOf(addr, coins[0].Denom)
+	if err != nil {
+		return nil, err
+	}
+	return sdk.NewCoins(balance), nil
+}
+
+// Fund sends the given amount to each of the recipients in a single
+// transaction, and waits for it to be included in a block. Returns the
+// transaction's hash and the height at which it was included.
+func (f *Funder) Fund(recipients []sdk.AccAddress, amount sdk.Coins) (txHash, height string, err error) {
+	txHash, err = f.broadcast(recipients, amount)
+	if err != nil {
+		return "", "", err
+	}
+	height, err = f.waitForTx(txHash)
+	return txHash, height, err
+}
+
+// broadcast signs and broadcasts a transaction sending the given amount to
+// each of the recipients, returning its hash once it has passed CheckTx.
+func (f *Funder) broadcast(recipients []sdk.AccAddress, amount sdk.Coins) (string, error) {
+	txBytes, err := f.sign(f.fundMsgs(recipients, amount))
+	if err != nil {
+		return "", err
+	}
+	return f.send(txBytes)
+}
+
+// fundMsgs creates the messages sending the given amount to each of the
+// recipients.
+func (f *Funder) fundMsgs(recipients []sdk.AccAddress, amount sdk.Coins) []sdk.Msg {
+	msgs := make([]sdk.Msg, 0, len(recipients))
+	for _, addr := range recipients {
+		msgs = append(msgs, &banktypes.MsgSend{
+			FromAddress: f.addr.String(),
+			ToAddress:   addr.String(),
+			Amount:      amount,
+		})
+	}
+	return msgs
+}
+
+// grantMsgs creates the messages authorizing each of the grantees to send
+// funds from the seed account via authz (see strategies.HotAccountStrategy).
+func (f *Funder) grantMsgs(grantees []sdk.AccAddress) ([]sdk.Msg, error) {
+	msgs := make([]sdk.Msg, 0, len(grantees))
+	for _, addr := range grantees {
+		grant, err := authz.NewMsgGrant(f.addr, addr, authz.NewGenericAuthorization(sdk.MsgTypeURL(&banktypes.MsgSend{})), nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create authz grant for %s: %w", addr.String(), err)
+		}
+		msgs = append(msgs, grant)
+	}
+	return msgs, nil
+}
+
+// Grant authorizes each of the grantees to send funds from the seed account
+// via authz in a single transaction, and waits for it to be included in a
+// block. Returns the transaction's hash and the height at which it was
+// included.
+func (f *Funder) Grant(grantees []sdk.AccAddress) (txHash, height string, err error) {
+	msgs, err := f.grantMsgs(grantees)
+	if err != nil {
+		return "", "", err
+	}
+	txBytes, err := f.sign(msgs)
+	if err != nil {
+		return "", "", err
+	}
+	if txHash, err = f.send(txBytes); err != nil {
+		return "", "", err
+	}
+	height, err = f.waitForTx(txHash)
+	return txHash, height, err
+}
+
+// sign builds, signs and encodes a transaction containing the given messages,
+// using the seed account's current sequence.
+func (f *Funder) sign(msgs []sdk.Msg) ([]byte, error) {
+	gasLimit, feeCoins := txFee(f.cfg, len(msgs))
+	p := TxParams{
+		PubKey:        f.pubKey,
+		Address:       f.addr.String(),
+		ChainID:       f.cfg.ChainID,
+		AccountNumber: f.accountNum,
+		Sequence:      f.sequence,
+		FeeCoins:      feeCoins,
+		GasLimit:      gasLimit,
+		SignMode:      signing.SignMode_SIGN_MODE_DIRECT,
+	}
+	sign := PrivKeySigner(f.encCfg.TxConfig, f.privKey)
+	// Ledger devices can't sign in SIGN_MODE_DIRECT
+	if f.ledgerKey != nil {
+		p.SignMode = signing.SignMode_SIGN_MODE_LEGACY_AMINO_JSON
+		sign = f.signOnLedger
+	}
+	return BuildTx(f.encCfg.TxConfig, p, msgs, sign)
+}
+
+// send broadcasts the given signed transaction, returning its hash once it has
+// passed CheckTx.
+func (f *Funder) send(txBytes []byte) (string, error) {
+	// Broadcast transaction (using sync mode to ensure it's included)
+	grpcConn, err := f.dialGRPC()
+	if err != nil {
+		return "", fmt.Errorf("failed to connect to gRPC for broadcasting: %w", err)
+	}
+	defer grpcConn.Close()
+	txClient := txtypes.NewServiceClient(grpcConn)
+	// Use BROADCAST_MODE_SYNC (BROADCAST_MODE_BLOCK is deprecated and not supported in SDK v0.47+)
+	broadcastResp, err := txClient.BroadcastTx(context.Background(), &txtypes.BroadcastTxRequest{
+		Mode:    txtypes.BroadcastMode_BROADCAST_MODE_SYNC,
+		TxBytes: txBytes,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to broadcast transaction: %w", err)
+	}
+	if broadcastResp.TxResponse.Code != 0 {
+		return "", fmt.Errorf("transaction failed: %s", broadcastResp.TxResponse.RawLog)
+	}
+
+	// The transaction passed CheckTx, so it has consumed our sequence number
+	f.sequence++
+	return broadcastResp.TxResponse.TxHash, nil
+}
+
+// waitForTx waits until the transaction has been included in a block
+// (returning the block's height), failed, or timed out. It subscribes to the
+// transaction's commit via the node's WebSockets endpoint where possible, and
+// otherwise polls the transaction's status every ConfirmInterval.
+func (f *Funder) waitForTx(txHash string) (string, error) {
+	timeout := time.Duration(f.cfg.ConfirmTimeout) * time.Second
+	deadline := time.Now().Add(timeout)
+	if sub, err := subscribeTx(f.cfg.RPC, txHash, f.restClient.Timeout); err == nil {
+		defer sub.Close()
+		// The transaction may have been committed before we subscribed
+		if height, _, err := f.queryTx(txHash); len(height) > 0 || err != nil {
+			return height, err
+		}
+		height, err := sub.Wait(deadline)
+		var subErr *subscriptionError
+		if !errors.As(err, &subErr) {
+			return height, err
+		}
+		// fall back to polling for whatever time is left
+	}
+
+	var lastErr error // The last unexpected response while polling, if any.
+	for time.Now().Before(deadline) {
+		height, queryErr, err := f.queryTx(txHash)
+		if len(height) > 0 || err != nil {
+			return height, err
+		}
+		if queryErr != nil {
+			lastErr = queryErr
+		}
+		time.Sleep(time.Duration(f.cfg.ConfirmInterval) * time.Millisecond)
+	}
+	if lastErr != nil {
+		return "", fmt.Errorf("transaction %s was not included in a block within %v (last error querying its status: %v)", txHash, timeout, lastErr)
+	}
+	return "", fmt.Errorf("transaction %s was not included in a block within %v (transaction may have failed or been rejected)", txHash, timeout)
+}
+
+// queryTx queries the transaction's status via the REST API once. It returns
+// the height of the block in which the transaction was included, or an empty
+// height if it hasn't been yet. err is only set if the transaction failed in
+// its block, while queryErr is set if its status couldn't be queried.
+func (f *Funder) queryTx(txHash string) (height string, queryErr, err error) {
+	if f.queryConn != nil {
+		return f.queryTxGRPC(txHash)
+	}
+	txStatusURL := fmt.Sprintf("%s/cosmos/tx/v1beta1/txs/%s", f.restURL, txHash)
+	txStatusResp, getErr := f.restClient.Get(txStatusURL)
+	if getErr != nil {
+		if f.fallBackToGRPC(getErr) {
+			return f.queryTxGRPC(txHash)
+		}
+		return "", getErr, nil
+	}
+	defer txStatusResp.Body.Close()
+	if txStatusResp.StatusCode == http.StatusNotFound {
+		// Transaction not found yet
+		return "", nil, nil
+	}
+	if txStatusResp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(txStatusResp.Body)
+		return "", fmt.Errorf("HTTP %d: %s", txStatusResp.StatusCode, string(body)), nil
+	}
+	var txStatusData struct {
+		TxResponse struct {
+			Height string `json:"height"`
+			Code   int    `json:"code"`
+			RawLog string `json:"raw_log"`
+		} `json:"tx_response"`
+	}
+	if decodeErr := json.NewDecoder(txStatusResp.Body).Decode(&txStatusData); decodeErr != nil {
+		return "", decodeErr, nil
+	}
+	if txStatusData.TxResponse.Height == "" || txStatusData.TxResponse.Height == "0" {
+		return "", nil, nil
+	}
+	if txStatusData.TxResponse.Code != 0 {
+		return "", nil, fmt.Errorf("transaction failed in block %s: code %d, log: %s",
+			txStatusData.TxResponse.Height, txStatusData.TxResponse.Code, txStatusData.TxResponse.RawLog)
+	}
+	return txStatusData.TxResponse.Height, nil, nil
+}
@@ -0,0 +1,147 @@
+package seed
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+
+	"cosmossdk.io/math"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// How many of the best-funded genesis accounts to list when the seed key
+// doesn't control any of them.
+const maxListedGenesisAccounts = 5
+
+// genesisBalance is an account's balance of a single denom at genesis.
+type genesisBalance struct {
+	Address string
+	Amount  math.Int
+}
+
+// genesisDoc is the subset of a genesis file that we care about.
+type genesisDoc struct {
+	ChainID  string `json:"chain_id"`
+	AppState struct {
+		Bank struct {
+			Balances []struct {
+				Address string `json:"address"`
+				Coins   []struct {
+					Denom  string `json:"denom"`
+					Amount string `json:"amount"`
+				} `json:"coins"`
+			} `json:"balances"`
+		} `json:"bank"`
+	} `json:"app_state"`
+}
+
+// readGenesis reads the genesis file at the configured path or, if none is
+// configured, fetches the genesis document from the node's RPC API.
+func readGenesis(cfg Config, client *http.Client) (*genesisDoc, error) {
+	var data []byte
+	if len(cfg.GenesisFile) > 0 {
+		var err error
+		if data, err = os.ReadFile(cfg.GenesisFile); err != nil {
+			return nil, fmt.Errorf("failed to read genesis file: %w", err)
+		}
+	} else {
+		genesisURL := strings.TrimSuffix(cfg.RPC, "/") + "/genesis"
+		resp, err := client.Get(genesisURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query genesis via RPC at %s: %w", genesisURL, err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			return nil, fmt.Errorf("failed to query genesis: HTTP %d: %s (pass the node's genesis file with --genesis-file instead)", resp.StatusCode, string(body))
+		}
+		var rpcResp struct {
+			Result struct {
+				Genesis json.RawMessage `json:"genesis"`
+			} `json:"result"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&rpcResp); err != nil {
+			return nil, fmt.Errorf("failed to decode genesis response: %w", err)
+		}
+		data = rpcResp.Result.Genesis
+	}
+
+	var doc genesisDoc
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to decode genesis: %w", err)
+	}
+	return &doc, nil
+}
+
+// fundedAccounts returns the genesis accounts with a positive balance of the
+// given denom, best-funded first.
+func (g *genesisDoc) fundedAccounts(denom string) []genesisBalance {
+	var funded []genesisBalance
+	for _, balance := range g.AppState.Bank.Balances {
+		for _, coin := range balance.Coins {
+			if coin.Denom != denom {
+				continue
+			}
+			amount, ok := math.NewIntFromString(coin.Amount)
+			if ok && amount.IsPositive() {
+				funded = append(funded, genesisBalance{Address: balance.Address, Amount: amount})
+			}
+		}
+	}
+	sort.SliceStable(funded, func(i, j int) bool {
+		return funded[i].Amount.GT(funded[j].Amount)
+	})
+	return funded
+}
+
+// genesisSeedPrivKey returns the key of the genesis account to seed from: the
+// provided seed key, once it has been checked to control an account that was
+// funded with the transfer denom at genesis. The built-in development
+// mnemonic is only considered with --dev-mnemonic-fallback.
+func genesisSeedPrivKey(cfg Config, client *http.Client) (cryptotypes.PrivKey, error) {
+	doc, err := readGenesis(cfg, client)
+	if err != nil {
+		return nil, err
+	}
+	if len(doc.ChainID) > 0 && doc.ChainID != cfg.ChainID {
+		return nil, fmt.Errorf("genesis is for chain %q, but the chain ID is %q (pass --chain-id %s)", doc.ChainID, cfg.ChainID, doc.ChainID)
+	}
+	funded := doc.fundedAccounts(cfg.Denom)
+	if len(funded) == 0 {
+		return nil, fmt.Errorf("no account was funded with %s at genesis", cfg.Denom)
+	}
+
+	usingDevMnemonic := cfg.SeedPrivateKey == "" && cfg.SeedKey == devKeyName
+	if usingDevMnemonic && !cfg.DevMnemonicFallback {
+		return nil, fmt.Errorf("seeding from genesis requires the key of one of its funded accounts (%s): pass it with --seed-key or --seed-private-key, or pass --dev-mnemonic-fallback to use the built-in %q mnemonic", listGenesisAccounts(funded), devKeyName)
+	}
+	privKey, err := seedPrivKey(cfg)
+	if err != nil {
+		return nil, err
+	}
+	addr := sdk.AccAddress(privKey.PubKey().Address()).String()
+	for _, balance := range funded {
+		if balance.Address == addr {
+			fmt.Printf("  Faucet account from genesis: %s (%s%s at genesis)\n", addr, balance.Amount, cfg.Denom)
+			return privKey, nil
+		}
+	}
+	return nil, fmt.Errorf("the seed key's account %s was not funded with %s at genesis; funded accounts include %s", addr, cfg.Denom, listGenesisAccounts(funded))
+}
+
+func listGenesisAccounts(funded []genesisBalance) string {
+	listed := make([]string, 0, maxListedGenesisAccounts)
+	for i, balance := range funded {
+		if i == maxListedGenesisAccounts {
+			listed = append(listed, fmt.Sprintf("and %d more", len(funded)-i))
+			break
+		}
+		listed = append(listed, fmt.Sprintf("%s with %s", balance.Address, balance.Amount))
+	}
+	return strings.Join(listed, ", ")
+}
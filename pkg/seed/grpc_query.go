@@ -0,0 +1,120 @@
+package seed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"syscall"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	authtypes "github.com/cosmos/cosmos-sdk/x/auth/types"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/status"
+
+	"cosmossdk.io/math"
+)
+
+// fallBackToGRPC switches the funder's account and balance queries to the
+// node's gRPC API if the given error from a REST API query shows that the
+// REST API is unreachable (i.e. the connection to it was refused), as it is
+// on many hardened nodes that disable the REST gateway. It reports whether
+// the query should be retried via gRPC.
+func (f *Funder) fallBackToGRPC(restErr error) bool {
+	if !errors.Is(restErr, syscall.ECONNREFUSED) {
+		return false
+	}
+	conn, err := f.dialGRPC()
+	if err != nil {
+		return false
+	}
+	fmt.Printf("REST API at %s is unavailable (%v), querying via gRPC at %s instead\n", f.restURL, restErr, f.grpcAddr)
+	f.queryConn = conn
+	return true
+}
+
+// dialGRPC sets up a connection to the node's gRPC API, which gzip
+// compresses its requests (and asks for compressed responses) if so
+// configured.
+func (f *Funder) dialGRPC() (*grpc.ClientConn, error) {
+	opts := []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+	if f.cfg.Compression {
+		opts = append(opts, grpc.WithDefaultCallOptions(grpc.UseCompressor(gzip.Name)))
+	}
+	return grpc.Dial(f.grpcAddr, opts...)
+}
+
+// queryContext returns the context for a gRPC query, which times out like a
+// REST API request would.
+func (f *Funder) queryContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), f.restClient.Timeout)
+}
+
+// syncGRPC is Sync via the node's gRPC API.
+func (f *Funder) syncGRPC() error {
+	ctx, cancel := f.queryContext()
+	defer cancel()
+	resp, err := authtypes.NewQueryClient(f.queryConn).Account(ctx, &authtypes.QueryAccountRequest{Address: f.addr.String()})
+	if err != nil {
+		return fmt.Errorf("failed to query seed account via gRPC: %w", err)
+	}
+	var account sdk.AccountI
+	if err := f.encCfg.InterfaceRegistry.UnpackAny(resp.Account, &account); err != nil {
+		return fmt.Errorf("failed to decode account response: %w", err)
+	}
+	f.accountNum = account.GetAccountNumber()
+	f.sequence = account.GetSequence()
+	return nil
+}
+
+// balanceGRPC is Balance via the node's gRPC API.
+func (f *Funder) balanceGRPC(addr sdk.AccAddress) (sdk.Coins, error) {
+	ctx, cancel := f.queryContext()
+	defer cancel()
+	resp, err := banktypes.NewQueryClient(f.queryConn).AllBalances(ctx, &banktypes.QueryAllBalancesRequest{Address: addr.String()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query balance via gRPC: %w", err)
+	}
+	return resp.Balances, nil
+}
+
+// balanceOfGRPC is BalanceOf via the node's gRPC API.
+func (f *Funder) balanceOfGRPC(addr sdk.AccAddress, denom string) (sdk.Coin, error) {
+	ctx, cancel := f.queryContext()
+	defer cancel()
+	resp, err := banktypes.NewQueryClient(f.queryConn).Balance(ctx, &banktypes.QueryBalanceRequest{Address: addr.String(), Denom: denom})
+	if err != nil {
+		return sdk.Coin{}, fmt.Errorf("failed to query balance via gRPC: %w", err)
+	}
+	if resp.Balance == nil {
+		return sdk.NewCoin(denom, math.ZeroInt()), nil
+	}
+	return *resp.Balance, nil
+}
+
+// queryTxGRPC is queryTx via the node's gRPC API.
+func (f *Funder) queryTxGRPC(txHash string) (height string, queryErr, err error) {
+	ctx, cancel := f.queryContext()
+	defer cancel()
+	resp, getErr := txtypes.NewServiceClient(f.queryConn).GetTx(ctx, &txtypes.GetTxRequest{Hash: txHash})
+	if status.Code(getErr) == codes.NotFound {
+		// Transaction not found yet
+		return "", nil, nil
+	}
+	if getErr != nil {
+		return "", getErr, nil
+	}
+	if resp.TxResponse == nil || resp.TxResponse.Height == 0 {
+		return "", nil, nil
+	}
+	height = fmt.Sprintf("%d", resp.TxResponse.Height)
+	if resp.TxResponse.Code != 0 {
+		return "", nil, fmt.Errorf("transaction failed in block %s: code %d, log: %s",
+			height, resp.TxResponse.Code, resp.TxResponse.RawLog)
+	}
+	return height, nil, nil
+}
@@ -0,0 +1,65 @@
+package seed
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/crypto/hd"
+	"github.com/cosmos/cosmos-sdk/crypto/ledger"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/tx/signing"
+	authsigning "github.com/cosmos/cosmos-sdk/x/auth/signing"
+)
+
+// ledgerHDPath returns the HD path of the seed account's key on a Ledger
+// device: the standard Cosmos path with the configured address index.
+func ledgerHDPath(cfg Config) *hd.BIP44Params {
+	return hd.NewFundraiserParams(0, sdk.CoinType, cfg.LedgerIndex)
+}
+
+// ledgerSeedKey connects to the Ledger device (which must be unlocked, with
+// its Cosmos app open) and returns a handle to the seed account's key on it.
+// The private key never leaves the device: signing with the handle asks the
+// device to sign, which the operator confirms on the device.
+//
+// Ledger support requires the binary to be built with cgo and the "ledger"
+// build tag; otherwise this fails.
+func ledgerSeedKey(cfg Config) (cryptotypes.LedgerPrivKeyAminoJSON, error) {
+	key, err := ledger.NewPrivKeySecp256k1Unsafe(*ledgerHDPath(cfg))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the seed key from the Ledger device (build with -tags ledger for Ledger support): %w", err)
+	}
+	return key, nil
+}
+
+// signOnLedger signs the transaction being built on the Ledger device. The
+// device's Cosmos app signs the transaction's legacy Amino JSON encoding,
+// which it can display for the operator to confirm, so that's the sign mode
+// used.
+func (f *Funder) signOnLedger(signerData authsigning.SignerData, txBuilder client.TxBuilder) (signing.SignatureV2, error) {
+	signBytes, err := authsigning.GetSignBytesAdapter(
+		context.Background(),
+		f.encCfg.TxConfig.SignModeHandler(),
+		signing.SignMode_SIGN_MODE_LEGACY_AMINO_JSON,
+		signerData,
+		txBuilder.GetTx(),
+	)
+	if err != nil {
+		return signing.SignatureV2{}, fmt.Errorf("failed to get sign bytes: %w", err)
+	}
+	fmt.Println("  Confirm the transaction on the Ledger device...")
+	sig, err := f.ledgerKey.SignLedgerAminoJSON(signBytes)
+	if err != nil {
+		return signing.SignatureV2{}, fmt.Errorf("failed to sign on the Ledger device: %w", err)
+	}
+	return signing.SignatureV2{
+		PubKey: f.pubKey,
+		Data: &signing.SingleSignatureData{
+			SignMode:  signing.SignMode_SIGN_MODE_LEGACY_AMINO_JSON,
+			Signature: sig,
+		},
+		Sequence: f.sequence,
+	}, nil
+}
@@ -2,32 +2,23 @@ package seed
 
 import (
 	"context"
-	"crypto/sha256"
-	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
-	"net/http"
+	"net/url"
 	"os"
 	"strconv"
 	"strings"
 	"time"
 
-	"github.com/btcsuite/btcd/btcec/v2"
-	"github.com/cosmos/cosmos-sdk/client/tx"
-	"github.com/cosmos/cosmos-sdk/crypto/hd"
-	"github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
-	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
 	sdk "github.com/cosmos/cosmos-sdk/types"
-	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
-	"github.com/cosmos/cosmos-sdk/types/tx/signing"
-	authsigning "github.com/cosmos/cosmos-sdk/x/auth/signing"
-	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
 	"cosmossdk.io/math"
-	"github.com/1119-Labs/perpx-chain/protocol/app"
+	"github.com/1119-Labs/perpx-load-test/internal/httpclient"
+	"github.com/1119-Labs/perpx-load-test/pkg/accounts"
+	"github.com/1119-Labs/perpx-load-test/pkg/configfile"
 )
 
 const (
@@ -35,55 +26,213 @@ const (
 	defaultFundAmount = "1000000aperpx"
 	defaultDenom      = "aperpx"
 	defaultChainID    = "localperpxprotocol"
+	defaultGasPerMsg  = 100000
+
+	defaultConfirmTimeout  = 30  // seconds
+	defaultConfirmInterval = 500 // milliseconds
 )
 
+// seedOptions maps each of the seeder's options (as named in config files) to
+// the environment variable that overrides it, if any.
+var seedOptions = map[string]string{
+	"workers":                 "",
+	"addresses-file":          "",
+	"seed-key":                "LOADTEST_SEED_KEY",
+	"seed-private-key":        "LOADTEST_SEED_PRIVATE_KEY",
+	"rpc":                     "LOADTEST_RPC",
+	"chain-id":                "LOADTEST_CHAIN_ID",
+	"bech32-prefix":           "LOADTEST_BECH32_PREFIX",
+	"key-algo":                "LOADTEST_KEY_ALGO",
+	"denom":                   "LOADTEST_DENOM",
+	"fee-denom":               "LOADTEST_FEE_DENOM",
+	"fund-amount":             "LOADTEST_FUND_AMOUNT",
+	"fee-fund-amount":         "LOADTEST_FEE_FUND_AMOUNT",
+	"batch-size":              "",
+	"gas-per-msg":             "LOADTEST_GAS_PER_MSG",
+	"gas-limit":               "LOADTEST_GAS_LIMIT",
+	"http-timeout":            "",
+	"http-max-idle-conns":     "",
+	"compression":             "",
+	"confirm-timeout":         "",
+	"confirm-interval":        "",
+	"continue-on-error":       "",
+	"ledger":                  "",
+	"ledger-index":            "",
+	"otel-endpoint":           "",
+	"grant-hot-account":       "",
+	"fee-granters":            "",
+	"fee-granter-fund-amount": "",
+	"seed-from-genesis":       "",
+	"genesis-file":            "",
+	"dev-mnemonic-fallback":   "",
+}
+
 // Config holds seeding configuration
 type Config struct {
-	Workers        int
-	SeedKey        string
-	SeedPrivateKey string // Optional: hex-encoded private key (takes precedence over SeedKey)
-	RPC            string
-	ChainID        string
-	Denom          string
-	FundAmount     string
-	BatchSize      int
+	Workers          int
+	AddressesFile    string // Optional: a file of bech32 addresses to fund instead of the worker accounts.
+	SeedKey          string
+	SeedPrivateKey   string // Optional: hex-encoded private key (takes precedence over SeedKey)
+	Ledger           bool   // Should the seed account's key be the one on a Ledger device, which signs the funding transactions (instead of SeedKey or SeedPrivateKey)?
+	LedgerIndex      uint32 // The address index, in the HD path of the key on the Ledger device.
+	RPC              string
+	ChainID          string
+	Bech32Prefix     string // The Bech32 prefix of the chain's account addresses.
+	KeyAlgo          string // The algorithm of the chain's account keys (see accounts.SetKeyAlgo).
+	Denom            string
+	FeeDenom         string // The denom in which fees are paid (defaults to Denom).
+	FundAmount       string
+	FeeFundAmount    string // Optional: amount of the fee denom to fund each account with, if it differs from the fund amount's denom.
+	BatchSize        int
+	GasPerMsg        uint64 // Gas limit allotted to each MsgSend in a batch transaction.
+	GasLimit         uint64 // Optional: flat gas limit for the whole batch transaction (overrides GasPerMsg when > 0).
+	HTTPTimeout      int    // The maximum time (in seconds) to wait for each REST API request.
+	HTTPMaxIdleConns int    // The maximum number of idle REST API connections to keep open for reuse.
+	Compression      bool   // Should REST and gRPC queries be gzip compressed?
+	ConfirmTimeout   int    // The maximum time (in seconds) to wait for each transaction to be included in a block.
+	ConfirmInterval  int    // How often (in milliseconds) to poll for the inclusion of a transaction, if its commit can't be subscribed to.
+	OTelEndpoint     string // Optional: the OTLP/HTTP endpoint to which to export traces of the seeding pipeline.
+	GrantHotAccount  bool   // Should the seed account authorize the workers to send from it via authz (for the load test's --hot-account)?
+	ContinueOnError  bool   // Should seeding proceed with the remaining batches when a batch fails, rather than stop?
+
+	SeedFromGenesis     bool   // Should the seed key be checked against the accounts funded at genesis, rather than trusted blindly?
+	GenesisFile         string // Optional: the genesis file to read (the genesis is fetched from the node if unset).
+	DevMnemonicFallback bool   // In SeedFromGenesis mode, may the "alice" key name stand for the built-in development mnemonic?
+
+	FeeGranters          int    // If > 0, the number of fee granter accounts to fund, each granting a fee allowance to its share of the workers (for the load test's --fee-granters).
+	FeeGranterFundAmount string // The amount of the fee denom to fund each fee granter account with.
+}
+
+// ToJSON returns the configuration as JSON, with the seed private key and
+// mnemonic seed keys (see configfile.RedactSeedKey), as well as the
+// credentials of the RPC URL, redacted.
+func (c Config) ToJSON() string {
+	c.SeedKey = configfile.RedactSeedKey(c.SeedKey)
+	if len(c.SeedPrivateKey) > 0 {
+		c.SeedPrivateKey = configfile.Redacted
+	}
+	if u, err := url.Parse(c.RPC); err == nil {
+		c.RPC = u.Redacted()
+	}
+	b, err := json.Marshal(c)
+	if err != nil {
+		return fmt.Sprintf("%v", c)
+	}
+	return string(b)
 }
 
-// Run executes the seed command
-func Run(args []string) {
-	cfg := parseArgs(args)
+// Run executes the seed command with the given command line arguments. It
+// is a thin wrapper around Execute that also prints a summary of the
+// configuration, returning any error rather than exiting so that it can be
+// embedded in other programs.
+func Run(args []string) error {
+	cfg, err := parseArgs(args)
+	if err == errHelp {
+		printHelp()
+		return nil
+	}
+	if err != nil {
+		return err
+	}
 
-	fmt.Printf("Seeding %d benchmark accounts...\n", cfg.Workers)
-	if cfg.SeedPrivateKey != "" {
+	if len(cfg.AddressesFile) > 0 {
+		fmt.Printf("Seeding the accounts listed in %s...\n", cfg.AddressesFile)
+	} else {
+		fmt.Printf("Seeding %d benchmark accounts...\n", cfg.Workers)
+	}
+	if cfg.Ledger {
+		fmt.Printf("  Seed key: Ledger device (%s)\n", ledgerHDPath(cfg))
+	} else if cfg.SeedPrivateKey != "" {
 		fmt.Printf("  Seed private key: [REDACTED] (using private key)\n")
 	} else {
-		fmt.Printf("  Seed key: %s\n", cfg.SeedKey)
+		fmt.Printf("  Seed key: %s\n", configfile.RedactSeedKey(cfg.SeedKey))
+	}
+	if cfg.SeedFromGenesis {
+		if len(cfg.GenesisFile) > 0 {
+			fmt.Printf("  Locating faucet account in genesis file: %s\n", cfg.GenesisFile)
+		} else {
+			fmt.Printf("  Locating faucet account in the node's genesis\n")
+		}
 	}
 	fmt.Printf("  RPC: %s\n", cfg.RPC)
 	fmt.Printf("  Chain ID: %s\n", cfg.ChainID)
 	fmt.Printf("  Fund amount per account: %s\n", cfg.FundAmount)
+	if len(cfg.FeeFundAmount) > 0 {
+		fmt.Printf("  Fee token fund amount per account: %s\n", cfg.FeeFundAmount)
+	}
+	if cfg.FeeDenom != cfg.Denom {
+		fmt.Printf("  Fee denom: %s\n", cfg.FeeDenom)
+	}
 	fmt.Printf("  Batch size: %d\n", cfg.BatchSize)
+	if cfg.GasLimit > 0 {
+		fmt.Printf("  Gas limit per tx: %d\n", cfg.GasLimit)
+	} else {
+		fmt.Printf("  Gas per message: %d\n", cfg.GasPerMsg)
+	}
+
+	if cfg.GrantHotAccount {
+		fmt.Printf("  Granting workers authz to send from the seed account (hot account)\n")
+	}
+	if cfg.FeeGranters > 0 {
+		fmt.Printf("  Fee granters: %d (funded with %s each)\n", cfg.FeeGranters, cfg.FeeGranterFundAmount)
+	}
+	if len(cfg.OTelEndpoint) > 0 {
+		fmt.Printf("  Exporting traces to: %s\n", cfg.OTelEndpoint)
+	}
 
-	if err := seedAccounts(cfg); err != nil {
-		fmt.Fprintf(os.Stderr, "Error seeding accounts: %v\n", err)
-		os.Exit(1)
+	fmt.Printf("  Effective configuration: %s\n", cfg.ToJSON())
+
+	if err := Execute(cfg); err != nil {
+		return fmt.Errorf("seeding accounts: %w", err)
 	}
 
 	fmt.Println("✓ Account seeding complete!")
+	return nil
+}
+
+// Execute funds the first cfg.Workers worker accounts (or the addresses in
+// cfg.AddressesFile) from the seed account, skipping those that are already
+// funded.
+func Execute(cfg Config) error {
+	if len(cfg.FeeDenom) == 0 {
+		cfg.FeeDenom = cfg.Denom
+	}
+	// All addresses are formatted with the chain's prefix
+	if err := accounts.SetBech32Prefix(cfg.Bech32Prefix); err != nil {
+		return err
+	}
+	// ...and all keys derived with the chain's algorithm
+	if err := accounts.SetKeyAlgo(cfg.KeyAlgo); err != nil {
+		return err
+	}
+	shutdownTracing, err := setupTracing(cfg.OTelEndpoint)
+	if err != nil {
+		return err
+	}
+	defer shutdownTracing()
+	ctx, span := tracer().Start(context.Background(), "seed")
+	span.SetAttributes(attribute.Int("workers", cfg.Workers), attribute.Int("batch_size", cfg.BatchSize))
+	err = seedAccounts(ctx, cfg)
+	endSpan(span, err)
+	return err
 }
 
-func parseArgs(args []string) Config {
-	cfg := Config{
-		Workers:        10,
-		SeedKey:        getEnv("LOADTEST_SEED_KEY", "alice"),
-		SeedPrivateKey: getEnv("LOADTEST_SEED_PRIVATE_KEY", ""),
-		RPC:            getEnv("LOADTEST_RPC", "http://localhost:36657"),
-		ChainID:        getEnv("LOADTEST_CHAIN_ID", defaultChainID),
-		Denom:          getEnv("LOADTEST_DENOM", defaultDenom),
-		FundAmount:     getEnv("LOADTEST_FUND_AMOUNT", defaultFundAmount),
-		BatchSize:      defaultBatchSize,
+// errHelp is returned by parseArgs when the help text was requested.
+var errHelp = errors.New("help requested")
+
+func parseArgs(args []string) (Config, error) {
+	// Options from a config file go first, so that they are overridden by
+	// both the environment and the actual command line arguments
+	if path := configfile.FindPath(args); len(path) > 0 {
+		fileArgs, err := configFileArgs(path)
+		if err != nil {
+			return Config{}, err
+		}
+		args = append(fileArgs, args...)
 	}
 
+	cfg := DefaultConfig()
+	var err error
 	for i := 0; i < len(args); i++ {
 		switch args[i] {
 		case "--workers", "-w":
@@ -91,6 +240,13 @@ func parseArgs(args []string) Config {
 				cfg.Workers, _ = strconv.Atoi(args[i+1])
 				i++
 			}
+		case "--addresses-file":
+			if i+1 < len(args) {
+				if cfg.AddressesFile, err = expandEnv("--addresses-file", args[i+1]); err != nil {
+					return Config{}, err
+				}
+				i++
+			}
 		case "--seed-key", "-k":
 			if i+1 < len(args) {
 				cfg.SeedKey = args[i+1]
@@ -103,7 +259,9 @@ func parseArgs(args []string) Config {
 			}
 		case "--rpc", "-r":
 			if i+1 < len(args) {
-				cfg.RPC = args[i+1]
+				if cfg.RPC, err = expandEnv("--rpc", args[i+1]); err != nil {
+					return Config{}, err
+				}
 				i++
 			}
 		case "--chain-id":
@@ -111,28 +269,199 @@ func parseArgs(args []string) Config {
 				cfg.ChainID = args[i+1]
 				i++
 			}
+		case "--bech32-prefix":
+			if i+1 < len(args) {
+				cfg.Bech32Prefix = args[i+1]
+				i++
+			}
+		case "--key-algo":
+			if i+1 < len(args) {
+				cfg.KeyAlgo = args[i+1]
+				i++
+			}
 		case "--denom":
 			if i+1 < len(args) {
 				cfg.Denom = args[i+1]
 				i++
 			}
+		case "--fee-denom":
+			if i+1 < len(args) {
+				cfg.FeeDenom = args[i+1]
+				i++
+			}
 		case "--fund-amount":
 			if i+1 < len(args) {
 				cfg.FundAmount = args[i+1]
 				i++
 			}
+		case "--fee-fund-amount":
+			if i+1 < len(args) {
+				cfg.FeeFundAmount = args[i+1]
+				i++
+			}
 		case "--batch-size":
 			if i+1 < len(args) {
 				cfg.BatchSize, _ = strconv.Atoi(args[i+1])
 				i++
 			}
+		case "--gas-per-msg":
+			if i+1 < len(args) {
+				cfg.GasPerMsg, _ = strconv.ParseUint(args[i+1], 10, 64)
+				i++
+			}
+		case "--gas-limit":
+			if i+1 < len(args) {
+				cfg.GasLimit, _ = strconv.ParseUint(args[i+1], 10, 64)
+				i++
+			}
+		case "--http-timeout":
+			if i+1 < len(args) {
+				cfg.HTTPTimeout, _ = strconv.Atoi(args[i+1])
+				i++
+			}
+		case "--http-max-idle-conns":
+			if i+1 < len(args) {
+				cfg.HTTPMaxIdleConns, _ = strconv.Atoi(args[i+1])
+				i++
+			}
+		case "--compression":
+			cfg.Compression = parseBoolFlag(args, &i)
+		case "--confirm-timeout":
+			if i+1 < len(args) {
+				cfg.ConfirmTimeout, _ = strconv.Atoi(args[i+1])
+				i++
+			}
+		case "--confirm-interval":
+			if i+1 < len(args) {
+				cfg.ConfirmInterval, _ = strconv.Atoi(args[i+1])
+				i++
+			}
+		case "--grant-hot-account":
+			cfg.GrantHotAccount = parseBoolFlag(args, &i)
+		case "--fee-granters":
+			if i+1 < len(args) {
+				cfg.FeeGranters, _ = strconv.Atoi(args[i+1])
+				i++
+			}
+		case "--fee-granter-fund-amount":
+			if i+1 < len(args) {
+				cfg.FeeGranterFundAmount = args[i+1]
+				i++
+			}
+		case "--ledger":
+			cfg.Ledger = parseBoolFlag(args, &i)
+		case "--ledger-index":
+			if i+1 < len(args) {
+				index, _ := strconv.ParseUint(args[i+1], 10, 32)
+				cfg.LedgerIndex = uint32(index)
+				i++
+			}
+		case "--continue-on-error":
+			cfg.ContinueOnError = parseBoolFlag(args, &i)
+		case "--seed-from-genesis":
+			cfg.SeedFromGenesis = parseBoolFlag(args, &i)
+		case "--genesis-file":
+			if i+1 < len(args) {
+				if cfg.GenesisFile, err = expandEnv("--genesis-file", args[i+1]); err != nil {
+					return Config{}, err
+				}
+				cfg.SeedFromGenesis = true
+				i++
+			}
+		case "--dev-mnemonic-fallback":
+			cfg.DevMnemonicFallback = parseBoolFlag(args, &i)
+		case "--otel-endpoint":
+			if i+1 < len(args) {
+				if cfg.OTelEndpoint, err = expandEnv("--otel-endpoint", args[i+1]); err != nil {
+					return Config{}, err
+				}
+				i++
+			}
+		case "--config":
+			// already handled above
+			i++
 		case "--help", "-h":
-			printHelp()
-			os.Exit(0)
+			return Config{}, errHelp
 		}
 	}
 
-	return cfg
+	// Unless configured otherwise, fees are paid in the transfer denom
+	if len(cfg.FeeDenom) == 0 {
+		cfg.FeeDenom = cfg.Denom
+	}
+
+	return cfg, nil
+}
+
+// DefaultConfig returns the seeder's default configuration, with any
+// overrides from the environment applied.
+func DefaultConfig() Config {
+	return Config{
+		Workers:          10,
+		SeedKey:          getEnv("LOADTEST_SEED_KEY", "alice"),
+		SeedPrivateKey:   getEnv("LOADTEST_SEED_PRIVATE_KEY", ""),
+		RPC:              getEnv("LOADTEST_RPC", "http://localhost:36657"),
+		ChainID:          getEnv("LOADTEST_CHAIN_ID", defaultChainID),
+		Bech32Prefix:     getEnv("LOADTEST_BECH32_PREFIX", accounts.DefaultBech32Prefix),
+		KeyAlgo:          getEnv("LOADTEST_KEY_ALGO", accounts.DefaultKeyAlgo),
+		Denom:            getEnv("LOADTEST_DENOM", defaultDenom),
+		FeeDenom:         getEnv("LOADTEST_FEE_DENOM", ""),
+		FundAmount:       getEnv("LOADTEST_FUND_AMOUNT", defaultFundAmount),
+		FeeFundAmount:    getEnv("LOADTEST_FEE_FUND_AMOUNT", ""),
+		BatchSize:        defaultBatchSize,
+		GasPerMsg:        getEnvUint64("LOADTEST_GAS_PER_MSG", defaultGasPerMsg),
+		GasLimit:         getEnvUint64("LOADTEST_GAS_LIMIT", 0),
+		HTTPTimeout:      int(httpclient.DefaultTimeout / time.Second),
+		HTTPMaxIdleConns: httpclient.DefaultMaxIdleConnsPerHost,
+		ConfirmTimeout:   defaultConfirmTimeout,
+		ConfirmInterval:  defaultConfirmInterval,
+	}
+}
+
+// configFileArgs loads the given config file, exports its shared settings
+// and returns its seed options as command line arguments. Options whose
+// environment variable is set are omitted, since the environment takes
+// precedence over the file.
+func configFileArgs(path string) ([]string, error) {
+	f, err := configfile.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.ApplySharedEnv(); err != nil {
+		return nil, err
+	}
+	for key := range f.Seed {
+		if _, ok := seedOptions[key]; !ok {
+			return nil, fmt.Errorf("unknown seed option in config file %s: %s", path, key)
+		}
+	}
+	return configfile.Args(f.Seed, func(key string) bool {
+		envVar := seedOptions[key]
+		return len(envVar) > 0 && len(os.Getenv(envVar)) > 0
+	})
+}
+
+// parseBoolFlag parses the boolean option at args[*i], which is set by its
+// mere presence, unless it is followed by an explicit value (as passed by
+// config files), in which case *i is advanced past the value.
+func parseBoolFlag(args []string, i *int) bool {
+	if *i+1 < len(args) {
+		if val, err := strconv.ParseBool(args[*i+1]); err == nil {
+			*i++
+			return val
+		}
+	}
+	return true
+}
+
+// expandEnv expands "${VAR}" references in the value of the given option
+// (see configfile.ExpandEnv).
+func expandEnv(option, val string) (string, error) {
+	expanded, err := configfile.ExpandEnv(val)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", option, err)
+	}
+	return expanded, nil
 }
 
 func getEnv(key, defaultValue string) string {
@@ -142,18 +471,63 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+func getEnvUint64(key string, defaultValue uint64) uint64 {
+	if val := os.Getenv(key); val != "" {
+		if parsed, err := strconv.ParseUint(val, 10, 64); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
 func printHelp() {
 	fmt.Println(`Usage: perpx-load-test seed [OPTIONS]
 
 Options:
   --workers, -w N          Number of workers to seed (default: 10)
+  --addresses-file FILE    Fund the bech32 addresses listed in this file (one per line) instead of
+                           the worker accounts
   --seed-key, -k KEY        Key name or mnemonic to use for seeding (default: alice)
   --seed-private-key, -p KEY  Hex-encoded private key to use for seeding (takes precedence over --seed-key)
+  --ledger                 Sign the funding transactions with the seed key on a Ledger device, which
+                           never leaves the device (requires a build with -tags ledger)
+  --ledger-index N         Address index of the seed key on the Ledger device (default: 0)
   --rpc, -r URL            RPC endpoint (default: http://localhost:36657)
   --chain-id ID            Chain ID (default: localperpxprotocol)
+  --bech32-prefix PREFIX   Bech32 prefix of the chain's account addresses (default: perpx)
+  --key-algo ALGO          Algorithm of the chain's account keys: secp256k1, or eth_secp256k1
+                           for EVM-enabled chains (coin type 60) (default: secp256k1)
   --denom DENOM            Token denomination (default: aperpx)
+  --fee-denom DENOM        Denomination in which fees are paid (default: --denom)
   --fund-amount AMOUNT      Amount to fund each account (default: 1000000aperpx)
+  --fee-fund-amount AMOUNT  Amount of the fee token to additionally fund each account with
   --batch-size N           Number of accounts to fund per transaction (default: 50)
+  --gas-per-msg N          Gas limit allotted to each message in a batch (default: 100000)
+  --gas-limit N            Flat gas limit for each batch transaction (overrides --gas-per-msg)
+  --http-timeout N         Seconds to wait for each REST API request (default: 10)
+  --http-max-idle-conns N  Idle REST API connections to keep open for reuse (default: 100)
+  --compression            Gzip compress REST and gRPC queries, to save bandwidth against remote nodes
+  --confirm-timeout N      Seconds to wait for each transaction to be included in a block (default: 30)
+  --confirm-interval N     Milliseconds between polls for a transaction's inclusion, if the node's
+                           WebSockets endpoint can't be subscribed to (default: 500)
+  --grant-hot-account      Authorize every worker to send funds from the seed account via authz,
+                           so that it can serve as the load test's --hot-account
+  --fee-granters N         Fund N fee granter accounts and have each grant a fee allowance to every
+                           Nth worker, for the load test's --fee-granters (default: 0, disabled)
+  --fee-granter-fund-amount AMOUNT  Amount of the fee token to fund each fee granter with
+  --continue-on-error      Log failed batches and carry on with the remaining ones instead of stopping,
+                           then list the workers that remain unfunded
+  --seed-from-genesis      Only seed from an account funded at genesis: the seed key is checked
+                           against the genesis balances, and "alice" no longer stands for the
+                           built-in development mnemonic (unless --dev-mnemonic-fallback)
+  --genesis-file FILE      Read the genesis balances from this file instead of fetching the
+                           genesis from the node (implies --seed-from-genesis)
+  --dev-mnemonic-fallback  With --seed-from-genesis, let "alice" stand for the built-in
+                           development mnemonic again
+  --otel-endpoint URL      Export OpenTelemetry traces of the seeding pipeline via OTLP/HTTP
+                           to this endpoint, e.g. http://localhost:4318 (disabled by default)
+  --config FILE            Read options from the "seed" and "shared" sections of a YAML/TOML
+                           config file (environment variables and flags take precedence)
   --help, -h               Show this help message
 
 Environment Variables:
@@ -161,449 +535,334 @@ Environment Variables:
   LOADTEST_SEED_PRIVATE_KEY    Override seed private key (hex-encoded)
   LOADTEST_RPC                 Override RPC endpoint
   LOADTEST_CHAIN_ID            Override chain ID
+  LOADTEST_BECH32_PREFIX       Override Bech32 address prefix
+  LOADTEST_KEY_ALGO            Override key algorithm
   LOADTEST_DENOM               Override denomination
-  LOADTEST_FUND_AMOUNT         Override fund amount`)
+  LOADTEST_FEE_DENOM           Override fee denomination
+  LOADTEST_FUND_AMOUNT         Override fund amount
+  LOADTEST_FEE_FUND_AMOUNT     Override fee token fund amount
+  LOADTEST_GAS_PER_MSG         Override gas per message
+  LOADTEST_GAS_LIMIT           Override flat gas limit per transaction`)
 }
 
-func seedAccounts(cfg Config) error {
+func seedAccounts(ctx context.Context, cfg Config) error {
+	if cfg.GasLimit == 0 && cfg.GasPerMsg == 0 {
+		return fmt.Errorf("gas-per-msg must be > 0 when no gas-limit is set")
+	}
+	if cfg.HTTPTimeout < 1 {
+		return fmt.Errorf("http-timeout must be at least 1, but got %d", cfg.HTTPTimeout)
+	}
+	if cfg.ConfirmTimeout < 1 {
+		return fmt.Errorf("confirm-timeout must be at least 1, but got %d", cfg.ConfirmTimeout)
+	}
+	if cfg.ConfirmInterval < 1 {
+		return fmt.Errorf("confirm-interval must be at least 1, but got %d", cfg.ConfirmInterval)
+	}
+	if len(cfg.AddressesFile) > 0 && cfg.GrantHotAccount {
+		return fmt.Errorf("grant-hot-account cannot be combined with addresses-file")
+	}
+	if cfg.FeeGranters < 0 {
+		return fmt.Errorf("fee-granters must be at least 0, but got %d", cfg.FeeGranters)
+	}
+	if cfg.FeeGranters > 0 && len(cfg.AddressesFile) > 0 {
+		return fmt.Errorf("fee-granters cannot be combined with addresses-file")
+	}
+	if cfg.FeeGranters > 0 && len(cfg.FeeGranterFundAmount) == 0 {
+		return fmt.Errorf("fee-granters requires fee-granter-fund-amount")
+	}
+
+	if err := sdk.ValidateDenom(cfg.Denom); err != nil {
+		return fmt.Errorf("invalid denom: %w", err)
+	}
+	if err := sdk.ValidateDenom(cfg.FeeDenom); err != nil {
+		return fmt.Errorf("invalid fee denom: %w", err)
+	}
+
 	// Parse fund amount
 	fundCoin, err := sdk.ParseCoinNormalized(cfg.FundAmount)
 	if err != nil {
 		return fmt.Errorf("invalid fund amount: %w", err)
 	}
-
-	// Calculate total needed
-	totalNeeded := fundCoin.Amount.Mul(math.NewInt(int64(cfg.Workers)))
-	estimatedFees := sdk.NewCoins(sdk.NewCoin(cfg.Denom, math.NewInt(int64(cfg.Workers)*10000))) // ~10k per tx
-	totalRequired := sdk.NewCoins(sdk.NewCoin(cfg.Denom, totalNeeded.Add(estimatedFees.AmountOf(cfg.Denom))))
-
-	fmt.Printf("Total required: %s\n", totalRequired)
-
-	// Setup encoding config
-	encCfg := app.GetEncodingConfig()
-
-	// Get or create seed key
-	var seedPrivKey cryptotypes.PrivKey
-	var seedAddr sdk.AccAddress
-
-	// If private key is provided, use it directly (takes precedence)
-	if cfg.SeedPrivateKey != "" {
-		// Parse hex-encoded private key
-		keyBytes, err := hex.DecodeString(strings.TrimPrefix(cfg.SeedPrivateKey, "0x"))
+	fundCoins := sdk.NewCoins(fundCoin)
+	feeFundCoin := sdk.NewCoin(cfg.FeeDenom, math.ZeroInt())
+	if len(cfg.FeeFundAmount) > 0 {
+		feeFundCoin, err = sdk.ParseCoinNormalized(cfg.FeeFundAmount)
 		if err != nil {
-			return fmt.Errorf("failed to decode private key (must be hex-encoded): %w", err)
+			return fmt.Errorf("invalid fee fund amount: %w", err)
 		}
-		if len(keyBytes) != 32 {
-			return fmt.Errorf("invalid private key length: expected 32 bytes, got %d", len(keyBytes))
+		if feeFundCoin.Denom != cfg.FeeDenom {
+			return fmt.Errorf("fee fund amount must be in the fee denom (%s), but got %s", cfg.FeeDenom, feeFundCoin.Denom)
 		}
-		// Create secp256k1 private key from bytes
-		privKeyBytes, _ := btcec.PrivKeyFromBytes(keyBytes)
-		seedPrivKey = &secp256k1.PrivKey{Key: privKeyBytes.Serialize()}
-		seedAddr = sdk.AccAddress(seedPrivKey.PubKey().Address())
-	} else {
-		// Fall back to mnemonic-based key derivation
-		// If the user passed the common dev key name "alice", transparently
-		// substitute the actual alice validator mnemonic from localnet config.yml
-		// so the command works out-of-the-box.
-		if cfg.SeedKey == "alice" {
-			// NOTE: This is the actual alice validator mnemonic from protocol/deployment/localnet/config.yml
-			// This is a development-only mnemonic and MUST NOT be used in production.
-			cfg.SeedKey = "merge panther lobster crazy road hollow amused security before critic about cliff exhibit cause coyote talent happy where lion river tobacco option coconut small"
+		fundCoins = fundCoins.Add(feeFundCoin)
+	}
+	var granterFundCoins sdk.Coins
+	if cfg.FeeGranters > 0 {
+		granterFundCoin, err := sdk.ParseCoinNormalized(cfg.FeeGranterFundAmount)
+		if err != nil {
+			return fmt.Errorf("invalid fee granter fund amount: %w", err)
 		}
-
-		// Treat SeedKey as either a full mnemonic (contains spaces) or fail fast.
-		// In the future this can be extended to look up named keys from a keyring.
-		if strings.Contains(cfg.SeedKey, " ") {
-			// It's a mnemonic
-			hdPath := hd.CreateHDPath(118, 0, 0).String()
-			derivedPriv, err := hd.Secp256k1.Derive()(cfg.SeedKey, "", hdPath)
-			if err != nil {
-				return fmt.Errorf("failed to derive key from mnemonic: %w", err)
-			}
-			seedPrivKey = hd.Secp256k1.Generate()(derivedPriv)
-			seedAddr = sdk.AccAddress(seedPrivKey.PubKey().Address())
-		} else {
-			return fmt.Errorf("seed-key %q is not a mnemonic; please provide a mnemonic, use \"alice\", or use --seed-private-key", cfg.SeedKey)
+		if granterFundCoin.Denom != cfg.FeeDenom {
+			return fmt.Errorf("fee granter fund amount must be in the fee denom (%s), but got %s", cfg.FeeDenom, granterFundCoin.Denom)
 		}
+		granterFundCoins = sdk.NewCoins(granterFundCoin)
 	}
 
-	fmt.Printf("Seed address: %s\n", seedAddr.String())
-
-	// Use REST API for balance queries to avoid gRPC frame size limits
-	// The "http2: frame too large" error occurs with gRPC when responses are large
-	// Convert RPC URL (port 36657) to REST API URL (port 31317)
-	restURL := strings.Replace(cfg.RPC, ":36657", ":31317", 1)
-	if !strings.Contains(restURL, ":31317") {
-		// If port wasn't 36657, try to infer REST port or use default
-		restURL = strings.Replace(cfg.RPC, ":26657", ":1317", 1)
-		if !strings.Contains(restURL, ":1317") {
-			// Default to localhost:31317 if we can't determine
-			restURL = "http://localhost:31317"
+	// The accounts to fund: the workers', derived deterministically (exactly
+	// as the load test client derives them), or the listed addresses
+	var benchAddrs []sdk.AccAddress
+	if len(cfg.AddressesFile) > 0 {
+		if benchAddrs, err = readAddressesFile(cfg.AddressesFile); err != nil {
+			return err
 		}
-	}
-
-	restClient := &http.Client{Timeout: 10 * time.Second}
-
-	// Check seed balance via REST API
-	balanceURL := fmt.Sprintf("%s/cosmos/bank/v1beta1/balances/%s", restURL, seedAddr.String())
-	balanceResp, err := restClient.Get(balanceURL)
-	if err != nil {
-		return fmt.Errorf("failed to query seed balance: %w", err)
-	}
-	defer balanceResp.Body.Close()
-
-	if balanceResp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(balanceResp.Body)
-		return fmt.Errorf("failed to query seed balance: HTTP %d: %s", balanceResp.StatusCode, string(body))
-	}
-
-	var balanceData struct {
-		Balances []struct {
-			Denom  string `json:"denom"`
-			Amount string `json:"amount"`
-		} `json:"balances"`
-	}
-	if err := json.NewDecoder(balanceResp.Body).Decode(&balanceData); err != nil {
-		return fmt.Errorf("failed to decode balance response: %w", err)
-	}
-
-	seedBalance := sdk.NewCoins()
-	for _, bal := range balanceData.Balances {
-		amount, ok := math.NewIntFromString(bal.Amount)
-		if !ok {
-			return fmt.Errorf("invalid amount: %s", bal.Amount)
+		fmt.Printf("Loaded %d addresses from %s\n", len(benchAddrs), cfg.AddressesFile)
+	} else {
+		benchAddrs = make([]sdk.AccAddress, cfg.Workers)
+		for i := 0; i < cfg.Workers; i++ {
+			benchAddrs[i] = sdk.AccAddress(accounts.WorkerPrivKey(i).PubKey().Address())
 		}
-		seedBalance = seedBalance.Add(sdk.NewCoin(bal.Denom, amount))
 	}
-	fmt.Printf("Seed balance: %s\n", seedBalance)
+	numAccounts := int64(len(benchAddrs))
 
-	// Check if seed has enough funds
-	if seedBalance.AmountOf(cfg.Denom).LT(totalRequired.AmountOf(cfg.Denom)) {
-		return fmt.Errorf("insufficient funds: seed has %s, needs %s",
-			seedBalance.AmountOf(cfg.Denom), totalRequired.AmountOf(cfg.Denom))
+	// Calculate total needed
+	totalNeeded := fundCoin.Amount.Mul(math.NewInt(numAccounts))
+	estimatedFees := sdk.NewCoins(sdk.NewCoin(cfg.FeeDenom, math.NewInt(numAccounts*10000))) // ~10k per tx
+	totalFeeFunding := sdk.NewCoin(cfg.FeeDenom, feeFundCoin.Amount.Mul(math.NewInt(numAccounts)))
+	totalRequired := sdk.NewCoins(sdk.NewCoin(cfg.Denom, totalNeeded)).Add(estimatedFees...).Add(totalFeeFunding)
+	for _, coin := range granterFundCoins {
+		totalRequired = totalRequired.Add(sdk.NewCoin(coin.Denom, coin.Amount.MulRaw(int64(cfg.FeeGranters))))
 	}
 
-	// Get seed account info (sequence, account number) via REST API
-	accountURL := fmt.Sprintf("%s/cosmos/auth/v1beta1/accounts/%s", restURL, seedAddr.String())
-	accountResp, err := restClient.Get(accountURL)
-	if err != nil {
-		return fmt.Errorf("failed to query seed account: %w", err)
-	}
-	defer accountResp.Body.Close()
+	fmt.Printf("Total required: %s\n", totalRequired)
 
-	if accountResp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(accountResp.Body)
-		return fmt.Errorf("failed to query seed account: HTTP %d: %s", accountResp.StatusCode, string(body))
-	}
+	restClient := httpclient.New(time.Duration(cfg.HTTPTimeout)*time.Second, cfg.HTTPMaxIdleConns, cfg.Compression)
 
-	var accountData struct {
-		Account struct {
-			Type          string `json:"@type"`
-			Address       string `json:"address"`
-			AccountNumber string `json:"account_number"`
-			Sequence      string `json:"sequence"`
-		} `json:"account"`
-	}
-	if err := json.NewDecoder(accountResp.Body).Decode(&accountData); err != nil {
-		return fmt.Errorf("failed to decode account response: %w", err)
+	// Keys of the wrong algorithm would fund addresses that the load test
+	// never sends from. The chain can only tell by its accounts' public keys,
+	// so the check is skipped if they can't be queried.
+	if typeURL, err := accounts.ChainPubKeyType(restClient, restURLFor(cfg.RPC)); err != nil {
+		fmt.Printf("Unable to check the key algorithm against the chain: %v\n", err)
+	} else if err := accounts.CheckPubKeyType(typeURL); err != nil {
+		return err
 	}
 
-	// Parse account number and sequence
-	accountNum, err := strconv.ParseUint(accountData.Account.AccountNumber, 10, 64)
-	if err != nil {
-		return fmt.Errorf("failed to parse account number: %w", err)
-	}
-	sequence, err := strconv.ParseUint(accountData.Account.Sequence, 10, 64)
+	// Derive the seed key and get the seed account's info (sequence, account
+	// number)
+	_, span := tracer().Start(ctx, "query_account")
+	funder, err := NewFunder(cfg, restClient)
+	endSpan(span, err)
 	if err != nil {
-		return fmt.Errorf("failed to parse sequence: %w", err)
+		return err
 	}
+	seedAddr := funder.Address()
+	fmt.Printf("Seed address: %s\n", seedAddr.String())
 
-	fmt.Printf("Seed account number: %d, sequence: %d\n", accountNum, sequence)
-
-	// Generate bench keys deterministically
-	benchKeys := make([]struct {
-		privKey cryptotypes.PrivKey
-		addr    sdk.AccAddress
-	}, cfg.Workers)
-
-	for i := 0; i < cfg.Workers; i++ {
-		// Generate deterministic key from seed (similar to regen_genesis_addresses.go)
-		seedStr := fmt.Sprintf("bench worker %d seed phrase for load testing account", i)
-		seed := sha256.Sum256([]byte(seedStr))
-		// Use worker index as path for additional determinism
-		adjustedSeed := sha256.Sum256(append(seed[:], byte(i)))
-		privKeyBytes, _ := btcec.PrivKeyFromBytes(adjustedSeed[:])
-		benchKeys[i].privKey = &secp256k1.PrivKey{Key: privKeyBytes.Serialize()}
-		benchKeys[i].addr = sdk.AccAddress(benchKeys[i].privKey.PubKey().Address())
+	// Check seed balance via REST API
+	_, span = tracer().Start(ctx, "check_seed_balance")
+	seedBalance, err := funder.Balance(seedAddr)
+	endSpan(span, err)
+	if err != nil {
+		return fmt.Errorf("failed to query seed balance: %w", err)
 	}
+	fmt.Printf("Seed balance: %s\n", seedBalance)
 
-	// Check which accounts need funding (use REST API to avoid gRPC frame limits)
-	needsFunding := make([]sdk.AccAddress, 0, cfg.Workers)
-	for _, bk := range benchKeys {
-		balanceURL := fmt.Sprintf("%s/cosmos/bank/v1beta1/balances/%s", restURL, bk.addr.String())
-		balanceResp, err := restClient.Get(balanceURL)
-		if err != nil || balanceResp.StatusCode != http.StatusOK {
-			// Account might not exist, assume it needs funding
-			if balanceResp != nil {
-				balanceResp.Body.Close()
-			}
-			needsFunding = append(needsFunding, bk.addr)
-			continue
+	// Check if seed has enough funds (of both the transfer and fee denoms)
+	for _, required := range totalRequired {
+		if seedBalance.AmountOf(required.Denom).LT(required.Amount) {
+			return fmt.Errorf("insufficient funds: seed has %s%s, needs %s",
+				seedBalance.AmountOf(required.Denom), required.Denom, required)
 		}
+	}
 
-		var balanceData struct {
-			Balances []struct {
-				Denom  string `json:"denom"`
-				Amount string `json:"amount"`
-			} `json:"balances"`
-		}
-		if err := json.NewDecoder(balanceResp.Body).Decode(&balanceData); err != nil {
-			balanceResp.Body.Close()
-			needsFunding = append(needsFunding, bk.addr)
-			continue
-		}
-		balanceResp.Body.Close()
+	fmt.Printf("Seed account number: %d, sequence: %d\n", funder.accountNum, funder.sequence)
 
-		balance := sdk.NewCoins()
-		for _, bal := range balanceData.Balances {
-			amount, ok := math.NewIntFromString(bal.Amount)
-			if ok {
-				balance = balance.Add(sdk.NewCoin(bal.Denom, amount))
-			}
-		}
-		if balance.AmountOf(cfg.Denom).LT(fundCoin.Amount) {
-			needsFunding = append(needsFunding, bk.addr)
+	// Check which accounts need funding (use REST API to avoid gRPC frame limits)
+	_, span = tracer().Start(ctx, "check_worker_balances", trace.WithAttributes(attribute.Int("accounts", len(benchAddrs))))
+	needsFunding := make([]sdk.AccAddress, 0, len(benchAddrs))
+	needsFundingIdx := make([]int, 0, len(benchAddrs)) // The index in benchAddrs of each account in needsFunding.
+	for i, addr := range benchAddrs {
+		// If the balance can't be queried, the account might not exist, so
+		// assume it needs funding
+		balance, err := funder.BalanceIn(addr, fundCoins)
+		if err != nil || !balance.IsAllGTE(fundCoins) {
+			needsFunding = append(needsFunding, addr)
+			needsFundingIdx = append(needsFundingIdx, i)
 		}
 	}
+	span.SetAttributes(attribute.Int("needs_funding", len(needsFunding)))
+	span.End()
 
 	if len(needsFunding) == 0 {
 		fmt.Println("All accounts already funded!")
-		return nil
+		return grantWorkers(ctx, cfg, funder, benchAddrs, granterFundCoins)
 	}
 
 	fmt.Printf("Funding %d accounts in batches of %d...\n", len(needsFunding), cfg.BatchSize)
 
 	// Fund accounts in batches
-	currentSeq := sequence
+	totalBatches := (len(needsFunding) + cfg.BatchSize - 1) / cfg.BatchSize
 	for i := 0; i < len(needsFunding); i += cfg.BatchSize {
 		end := i + cfg.BatchSize
 		if end > len(needsFunding) {
 			end = len(needsFunding)
 		}
 		batch := needsFunding[i:end]
-
-		// Build multi-msg transaction
-		msgs := make([]sdk.Msg, 0, len(batch))
-		for _, addr := range batch {
-			msgs = append(msgs, &banktypes.MsgSend{
-				FromAddress: seedAddr.String(),
-				ToAddress:   addr.String(),
-				Amount:      sdk.NewCoins(fundCoin),
-			})
-		}
-
-		// Create and sign transaction
-		txBuilder := encCfg.TxConfig.NewTxBuilder()
-		if err := txBuilder.SetMsgs(msgs...); err != nil {
-			return fmt.Errorf("failed to set messages: %w", err)
-		}
-
-		// Set fees based on gas limit and minimum gas price
-		// Minimum gas price: 25000000000aperpx per unit of gas (from cmd/perpxd/cmd/config.go)
-		// Gas limit: 100k per message
-		gasLimit := 100000 * uint64(len(batch))
-		minGasPrice := math.NewInt(25000000000) // 25 billion aperpx per unit of gas
-		feeAmount := minGasPrice.Mul(math.NewInt(int64(gasLimit)))
-		feeCoins := sdk.NewCoins(sdk.NewCoin(cfg.Denom, feeAmount))
-		txBuilder.SetFeeAmount(feeCoins)
-		txBuilder.SetGasLimit(gasLimit)
-
-		// First round: set empty signatures to gather signer infos (required for SIGN_MODE_DIRECT)
-		sigV2Empty := signing.SignatureV2{
-			PubKey: seedPrivKey.PubKey(),
-			Data: &signing.SingleSignatureData{
-				SignMode:  signing.SignMode_SIGN_MODE_DIRECT,
-				Signature: nil,
-			},
-			Sequence: currentSeq,
-		}
-		if err := txBuilder.SetSignatures(sigV2Empty); err != nil {
-			return fmt.Errorf("failed to set empty signature: %w", err)
-		}
-
-		// Second round: actually sign the transaction
-		signerData := authsigning.SignerData{
-			Address:       seedAddr.String(),
-			ChainID:       cfg.ChainID,
-			AccountNumber: accountNum,
-			Sequence:      currentSeq,
-			PubKey:        seedPrivKey.PubKey(),
-		}
-
-		sigV2, err := tx.SignWithPrivKey(
-			context.Background(),
-			signing.SignMode_SIGN_MODE_DIRECT,
-			signerData,
-			txBuilder,
-			seedPrivKey,
-			encCfg.TxConfig,
-			currentSeq,
-		)
-		if err != nil {
-			return fmt.Errorf("failed to sign: %w", err)
-		}
-
-		if err := txBuilder.SetSignatures(sigV2); err != nil {
-			return fmt.Errorf("failed to set signature: %w", err)
-		}
-
-		// Encode transaction
-		txBytes, err := encCfg.TxConfig.TxEncoder()(txBuilder.GetTx())
-		if err != nil {
-			return fmt.Errorf("failed to encode transaction: %w", err)
-		}
-
-		// Broadcast transaction (using sync mode to ensure it's included)
-		// Use gRPC for broadcasting (convert RPC port to gRPC port: 36657 -> 39090)
-		grpcURL := strings.Replace(cfg.RPC, ":36657", ":39090", 1)
-		if !strings.Contains(grpcURL, ":39090") {
-			grpcURL = strings.Replace(cfg.RPC, ":26657", ":9090", 1)
-			if !strings.Contains(grpcURL, ":9090") {
-				grpcURL = "http://localhost:39090"
-			}
-		}
-		grpcAddr := strings.TrimPrefix(grpcURL, "http://")
-		grpcConn, err := grpc.Dial(
-			grpcAddr,
-			grpc.WithTransportCredentials(insecure.NewCredentials()),
-		)
-		if err != nil {
-			return fmt.Errorf("failed to connect to gRPC for broadcasting: %w", err)
-		}
-		txClient := txtypes.NewServiceClient(grpcConn)
-		// Use BROADCAST_MODE_SYNC (BROADCAST_MODE_BLOCK is deprecated and not supported in SDK v0.47+)
-		broadcastResp, err := txClient.BroadcastTx(context.Background(), &txtypes.BroadcastTxRequest{
-			Mode:    txtypes.BroadcastMode_BROADCAST_MODE_SYNC,
-			TxBytes: txBytes,
-		})
-		if err != nil {
-			grpcConn.Close()
-			return fmt.Errorf("failed to broadcast transaction: %w", err)
-		}
-
-		if broadcastResp.TxResponse.Code != 0 {
-			grpcConn.Close()
-			return fmt.Errorf("transaction failed: %s", broadcastResp.TxResponse.RawLog)
-		}
-
-		txHash := broadcastResp.TxResponse.TxHash
-		fmt.Printf("  Batch %d/%d: broadcasting %d accounts (tx hash: %s)\n",
-			(i/cfg.BatchSize)+1, (len(needsFunding)+cfg.BatchSize-1)/cfg.BatchSize,
-			len(batch), txHash)
-
-		// Wait for transaction to be included in a block
-		// Poll the transaction status until it's found or timeout
-		maxWait := 30 * time.Second
-		startTime := time.Now()
-		txIncluded := false
-		for time.Since(startTime) < maxWait {
-			// Query transaction status via REST API
-			txStatusURL := fmt.Sprintf("%s/cosmos/tx/v1beta1/txs/%s", restURL, txHash)
-			txStatusResp, err := restClient.Get(txStatusURL)
-			if err == nil && txStatusResp.StatusCode == http.StatusOK {
-				var txStatusData struct {
-					TxResponse struct {
-						Height string `json:"height"`
-						Code   int    `json:"code"`
-						RawLog string `json:"raw_log"`
-					} `json:"tx_response"`
-				}
-				if err := json.NewDecoder(txStatusResp.Body).Decode(&txStatusData); err == nil {
-					txStatusResp.Body.Close()
-					if txStatusData.TxResponse.Height != "" && txStatusData.TxResponse.Height != "0" {
-						if txStatusData.TxResponse.Code != 0 {
-							grpcConn.Close()
-							return fmt.Errorf("transaction failed in block %s: code %d, log: %s",
-								txStatusData.TxResponse.Height, txStatusData.TxResponse.Code, txStatusData.TxResponse.RawLog)
-						}
-						txIncluded = true
-						totalBatches := (len(needsFunding) + cfg.BatchSize - 1) / cfg.BatchSize
-						fmt.Printf("  Batch %d/%d: transaction included in block %s\n",
-							(i/cfg.BatchSize)+1, totalBatches, txStatusData.TxResponse.Height)
-						break
-					}
-				} else {
-					txStatusResp.Body.Close()
-				}
-			} else if txStatusResp != nil && txStatusResp.StatusCode == http.StatusNotFound {
-				// Transaction not found yet, continue polling
-				txStatusResp.Body.Close()
-			} else if txStatusResp != nil {
-				// Some other error
-				body, _ := io.ReadAll(txStatusResp.Body)
-				txStatusResp.Body.Close()
-				fmt.Printf("  Warning: error querying tx status: HTTP %d: %s\n", txStatusResp.StatusCode, string(body))
+		if err := fundBatch(ctx, funder, batch, fundCoins, (i/cfg.BatchSize)+1, totalBatches); err != nil {
+			if !cfg.ContinueOnError {
+				return err
 			}
-			if txStatusResp != nil && txStatusResp.StatusCode != http.StatusNotFound {
-				txStatusResp.Body.Close()
+			fmt.Printf("  Warning: batch %d/%d (%s) failed, continuing with the remaining batches: %v\n",
+				(i/cfg.BatchSize)+1, totalBatches, describeAccounts(cfg, benchAddrs, needsFundingIdx[i:end]), err)
+			// The failed transaction may or may not have consumed a sequence
+			// number
+			if err := funder.Sync(); err != nil {
+				return fmt.Errorf("failed to resync seed account after failed batch: %w", err)
 			}
-			time.Sleep(500 * time.Millisecond)
-		}
-		grpcConn.Close()
-
-		if !txIncluded {
-			return fmt.Errorf("transaction %s was not included in a block within %v (transaction may have failed or been rejected)", txHash, maxWait)
 		}
-
-		currentSeq++
 	}
 
 	// Verify all accounts are funded (use REST API)
 	fmt.Println("Verifying account balances...")
-	allFunded := true
+	_, span = tracer().Start(ctx, "verify_balances", trace.WithAttributes(attribute.Int("accounts", len(needsFunding))))
+	defer span.End()
+	var unfunded []int // The indices in benchAddrs of the accounts that remain unfunded.
 	for i, addr := range needsFunding {
-		balanceURL := fmt.Sprintf("%s/cosmos/bank/v1beta1/balances/%s", restURL, addr.String())
-		balanceResp, err := restClient.Get(balanceURL)
-		if err != nil || balanceResp.StatusCode != http.StatusOK {
-			if balanceResp != nil {
-				balanceResp.Body.Close()
-			}
+		balance, err := funder.BalanceIn(addr, fundCoins)
+		if err != nil {
 			fmt.Printf("  Warning: failed to query balance for %s: %v\n", addr.String(), err)
-			allFunded = false
+			unfunded = append(unfunded, needsFundingIdx[i])
 			continue
 		}
-
-		var balanceData struct {
-			Balances []struct {
-				Denom  string `json:"denom"`
-				Amount string `json:"amount"`
-			} `json:"balances"`
-		}
-		if err := json.NewDecoder(balanceResp.Body).Decode(&balanceData); err != nil {
-			balanceResp.Body.Close()
-			fmt.Printf("  Warning: failed to decode balance for %s: %v\n", addr.String(), err)
-			allFunded = false
-			continue
+		if !balance.IsAllGTE(fundCoins) {
+			if !cfg.ContinueOnError && len(cfg.AddressesFile) > 0 {
+				fmt.Printf("  Warning: account %s has insufficient balance: %s\n", addr.String(), balance)
+			} else if !cfg.ContinueOnError {
+				fmt.Printf("  Warning: account %s (worker %d) has insufficient balance: %s\n",
+					addr.String(), needsFundingIdx[i], balance)
+			}
+			unfunded = append(unfunded, needsFundingIdx[i])
 		}
-		balanceResp.Body.Close()
+	}
 
-		balance := sdk.NewCoins()
-		for _, bal := range balanceData.Balances {
-			amount, ok := math.NewIntFromString(bal.Amount)
-			if ok {
-				balance = balance.Add(sdk.NewCoin(bal.Denom, amount))
-			}
+	if len(unfunded) > 0 {
+		if cfg.ContinueOnError {
+			fmt.Printf("%d of %d accounts remain unfunded: %s\n", len(unfunded), len(benchAddrs), describeAccounts(cfg, benchAddrs, unfunded))
+			fmt.Println("Run the seed command again to fund them (accounts that are already funded are skipped)")
 		}
-		if balance.AmountOf(cfg.Denom).LT(fundCoin.Amount) {
-			fmt.Printf("  Warning: account %s (worker %d) has insufficient balance: %s\n",
-				addr.String(), i, balance.AmountOf(cfg.Denom))
-			allFunded = false
+		return fmt.Errorf("some accounts were not properly funded")
+	}
+
+	return grantWorkers(ctx, cfg, funder, benchAddrs, granterFundCoins)
+}
+
+// grantWorkers makes the grants to the funded workers that the load test's
+// --hot-account and --fee-granters rely on, as configured.
+func grantWorkers(ctx context.Context, cfg Config, funder *Funder, workers []sdk.AccAddress, granterFundCoins sdk.Coins) error {
+	if err := grantHotAccount(ctx, cfg, funder, workers); err != nil {
+		return err
+	}
+	return grantFeeAllowances(ctx, cfg, funder, workers, granterFundCoins)
+}
+
+// grantHotAccount authorizes all of the workers to send funds from the seed
+// account via authz, if configured to do so, so that the seed account can
+// serve as the load test's hot account.
+func grantHotAccount(ctx context.Context, cfg Config, funder *Funder, workers []sdk.AccAddress) error {
+	if !cfg.GrantHotAccount {
+		return nil
+	}
+	fmt.Printf("Granting %d accounts authorization to send from hot account %s in batches of %d...\n",
+		len(workers), funder.Address().String(), cfg.BatchSize)
+	totalBatches := (len(workers) + cfg.BatchSize - 1) / cfg.BatchSize
+	for i := 0; i < len(workers); i += cfg.BatchSize {
+		end := i + cfg.BatchSize
+		if end > len(workers) {
+			end = len(workers)
+		}
+		_, span := tracer().Start(ctx, "grant_batch", trace.WithAttributes(
+			attribute.Int("batch", (i/cfg.BatchSize)+1),
+			attribute.Int("accounts", end-i),
+		))
+		txHash, height, err := funder.Grant(workers[i:end])
+		endSpan(span, err)
+		if err != nil {
+			return fmt.Errorf("failed to grant hot account authorization: %w", err)
 		}
+		fmt.Printf("  Batch %d/%d: %d grants included in block %s (tx hash: %s)\n",
+			(i/cfg.BatchSize)+1, totalBatches, end-i, height, txHash)
 	}
+	fmt.Printf("Use --hot-account %s when running the load test\n", funder.Address().String())
+	return nil
+}
 
-	if !allFunded {
-		return fmt.Errorf("some accounts were not properly funded")
+// fundBatch funds a single batch of accounts, tracing the time spent building
+// and signing, broadcasting and confirming its transaction.
+func fundBatch(ctx context.Context, funder *Funder, batch []sdk.AccAddress, amount sdk.Coins, batchNum, totalBatches int) (err error) {
+	ctx, span := tracer().Start(ctx, "batch", trace.WithAttributes(
+		attribute.Int("batch", batchNum),
+		attribute.Int("accounts", len(batch)),
+		attribute.Int64("sequence", int64(funder.sequence)),
+	))
+	defer func() { endSpan(span, err) }()
+
+	_, stepSpan := tracer().Start(ctx, "build_sign")
+	txBytes, err := funder.sign(funder.fundMsgs(batch, amount))
+	endSpan(stepSpan, err)
+	if err != nil {
+		return err
 	}
 
+	_, stepSpan = tracer().Start(ctx, "broadcast")
+	txHash, err := funder.send(txBytes)
+	endSpan(stepSpan, err)
+	if err != nil {
+		return err
+	}
+	span.SetAttributes(attribute.String("tx_hash", txHash))
+	fmt.Printf("  Batch %d/%d: broadcasting %d accounts (tx hash: %s)\n",
+		batchNum, totalBatches, len(batch), txHash)
+
+	// Wait for transaction to be included in a block
+	_, stepSpan = tracer().Start(ctx, "confirm")
+	height, err := funder.waitForTx(txHash)
+	endSpan(stepSpan, err)
+	if err != nil {
+		return err
+	}
+	span.SetAttributes(attribute.String("height", height))
+	fmt.Printf("  Batch %d/%d: transaction included in block %s\n",
+		batchNum, totalBatches, height)
 	return nil
 }
+
+// describeAccounts describes the accounts at the given ascending indices of
+// the accounts being funded: by their worker indices, or by their addresses
+// if they were read from the addresses file.
+func describeAccounts(cfg Config, addrs []sdk.AccAddress, indices []int) string {
+	if len(cfg.AddressesFile) == 0 {
+		return "workers " + formatIndices(indices)
+	}
+	listed := make([]string, len(indices))
+	for i, idx := range indices {
+		listed[i] = addrs[idx].String()
+	}
+	return "addresses " + strings.Join(listed, ", ")
+}
+
+// formatIndices formats the given ascending worker indices compactly, with
+// runs of consecutive indices as ranges (e.g. "3, 7, 12-19").
+func formatIndices(indices []int) string {
+	var parts []string
+	for i := 0; i < len(indices); {
+		j := i
+		for j+1 < len(indices) && indices[j+1] == indices[j]+1 {
+			j++
+		}
+		if j > i {
+			parts = append(parts, fmt.Sprintf("%d-%d", indices[i], indices[j]))
+		} else {
+			parts = append(parts, strconv.Itoa(indices[i]))
+		}
+		i = j + 1
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,75 @@
+package seed
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	tracerName       = "github.com/1119-Labs/perpx-load-test/pkg/seed"
+	tracingService   = "perpx-load-test-seed"
+	tracingFlushWait = 5 * time.Second
+)
+
+// tracer is used for all of the seeder's spans. Until setupTracing installs
+// an exporting provider, the global provider is a no-op, so spans cost
+// next to nothing when tracing is disabled.
+func tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
+
+// setupTracing installs a tracer provider that exports spans via OTLP over
+// HTTP to the given endpoint, which may be a URL (e.g.
+// "http://localhost:4318") or a bare "host:port" (plain HTTP is assumed). It
+// returns a function that flushes any outstanding spans and shuts the provider
+// down. Does nothing if the endpoint is empty.
+func setupTracing(endpoint string) (func(), error) {
+	if len(endpoint) == 0 {
+		return func() {}, nil
+	}
+	var opt otlptracehttp.Option
+	if strings.Contains(endpoint, "://") {
+		opt = otlptracehttp.WithEndpointURL(endpoint)
+	} else {
+		opt = otlptracehttp.WithEndpoint(endpoint)
+	}
+	opts := []otlptracehttp.Option{opt}
+	if !strings.HasPrefix(endpoint, "https://") {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+	exporter, err := otlptracehttp.New(context.Background(), opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP trace exporter: %w", err)
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", tracingService))),
+	)
+	otel.SetTracerProvider(provider)
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), tracingFlushWait)
+		defer cancel()
+		if err := provider.Shutdown(ctx); err != nil {
+			fmt.Printf("  Warning: failed to flush traces to %s: %v\n", endpoint, err)
+		}
+	}, nil
+}
+
+// endSpan records the given error (if any) on the span and ends it.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
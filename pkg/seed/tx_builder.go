@@ -0,0 +1,113 @@
+package seed
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/cosmos/cosmos-sdk/client/tx"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/tx/signing"
+	authsigning "github.com/cosmos/cosmos-sdk/x/auth/signing"
+
+	"cosmossdk.io/math"
+)
+
+// TxParams are the inputs to BuildTx other than the messages: the signing
+// account and the transaction's fee and gas limit.
+type TxParams struct {
+	PubKey        cryptotypes.PubKey
+	Address       string // The account's address, as encoded for the chain.
+	ChainID       string
+	AccountNumber uint64
+	Sequence      uint64
+	FeeCoins      sdk.Coins
+	FeeGranter    sdk.AccAddress // The account that pays the fee via a fee allowance, if any.
+	GasLimit      uint64
+	SignMode      signing.SignMode // Must be the mode the sign function signs in.
+}
+
+// TxSignFunc signs the transaction being built for the given signer.
+type TxSignFunc func(signerData authsigning.SignerData, txBuilder client.TxBuilder) (signing.SignatureV2, error)
+
+// txFee returns the gas limit and fee of a transaction of numMsgs messages:
+// GasPerMsg per message (unless a flat GasLimit was given) at the minimum gas
+// price.
+func txFee(cfg Config, numMsgs int) (gasLimit uint64, feeCoins sdk.Coins) {
+	gasLimit = cfg.GasPerMsg * uint64(numMsgs)
+	if cfg.GasLimit > 0 {
+		gasLimit = cfg.GasLimit
+	}
+	feeAmount := math.NewInt(minGasPrice).Mul(math.NewIntFromUint64(gasLimit))
+	return gasLimit, sdk.NewCoins(sdk.NewCoin(cfg.FeeDenom, feeAmount))
+}
+
+// BuildTx builds a transaction of the given messages, has sign sign it and
+// encodes it. It does no I/O of its own, and since secp256k1 signatures are
+// deterministic (RFC 6979), with PrivKeySigner the same inputs always produce
+// the same bytes.
+func BuildTx(txConfig client.TxConfig, p TxParams, msgs []sdk.Msg, sign TxSignFunc) ([]byte, error) {
+	txBuilder := txConfig.NewTxBuilder()
+	if err := txBuilder.SetMsgs(msgs...); err != nil {
+		return nil, fmt.Errorf("failed to set messages: %w", err)
+	}
+	txBuilder.SetFeeAmount(p.FeeCoins)
+	txBuilder.SetGasLimit(p.GasLimit)
+	if p.FeeGranter != nil {
+		txBuilder.SetFeeGranter(p.FeeGranter)
+	}
+
+	// First round: set empty signatures to gather signer infos (required for SIGN_MODE_DIRECT,
+	// since the signer infos are part of the signed AuthInfo bytes)
+	sigV2Empty := signing.SignatureV2{
+		PubKey: p.PubKey,
+		Data: &signing.SingleSignatureData{
+			SignMode:  p.SignMode,
+			Signature: nil,
+		},
+		Sequence: p.Sequence,
+	}
+	if err := txBuilder.SetSignatures(sigV2Empty); err != nil {
+		return nil, fmt.Errorf("failed to set empty signature: %w", err)
+	}
+
+	// Second round: actually sign the transaction
+	signerData := authsigning.SignerData{
+		Address:       p.Address,
+		ChainID:       p.ChainID,
+		AccountNumber: p.AccountNumber,
+		Sequence:      p.Sequence,
+		PubKey:        p.PubKey,
+	}
+	sigV2, err := sign(signerData, txBuilder)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign: %w", err)
+	}
+	if err := txBuilder.SetSignatures(sigV2); err != nil {
+		return nil, fmt.Errorf("failed to set signature: %w", err)
+	}
+
+	// Encode transaction
+	txBytes, err := txConfig.TxEncoder()(txBuilder.GetTx())
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode transaction: %w", err)
+	}
+	return txBytes, nil
+}
+
+// PrivKeySigner returns a TxSignFunc signing in SIGN_MODE_DIRECT with the
+// given private key.
+func PrivKeySigner(txConfig client.TxConfig, privKey cryptotypes.PrivKey) TxSignFunc {
+	return func(signerData authsigning.SignerData, txBuilder client.TxBuilder) (signing.SignatureV2, error) {
+		return tx.SignWithPrivKey(
+			context.Background(),
+			signing.SignMode_SIGN_MODE_DIRECT,
+			signerData,
+			txBuilder,
+			privKey,
+			txConfig,
+			signerData.Sequence,
+		)
+	}
+}
@@ -0,0 +1,60 @@
+package seed
+
+import (
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/types/tx/signing"
+	"github.com/stretchr/testify/require"
+
+	"cosmossdk.io/math"
+	"github.com/1119-Labs/perpx-chain/protocol/app"
+	"github.com/1119-Labs/perpx-load-test/internal/golden"
+	"github.com/1119-Labs/perpx-load-test/pkg/accounts"
+)
+
+func TestTxFee(t *testing.T) {
+	cfg := Config{GasPerMsg: 100_000, FeeDenom: "aperpx"}
+	gasLimit, fee := txFee(cfg, 3)
+	require.Equal(t, uint64(300_000), gasLimit)
+	require.Equal(t, sdk.NewCoins(sdk.NewCoin("aperpx", math.NewInt(minGasPrice).MulRaw(300_000))), fee)
+
+	cfg.GasLimit = 50_000
+	gasLimit, fee = txFee(cfg, 3)
+	require.Equal(t, uint64(50_000), gasLimit)
+	require.Equal(t, sdk.NewCoins(sdk.NewCoin("aperpx", math.NewInt(minGasPrice).MulRaw(50_000))), fee)
+}
+
+func TestBuildFundTxGolden(t *testing.T) {
+	require.NoError(t, accounts.SetBech32Prefix(accounts.DefaultBech32Prefix))
+	cfg := Config{SeedKey: devKeyName, ChainID: defaultChainID, FeeDenom: defaultDenom, GasPerMsg: defaultGasPerMsg}
+	privKey, err := seedPrivKey(cfg)
+	require.NoError(t, err)
+	txConfig := app.GetEncodingConfig().TxConfig
+
+	// A funder that was never synced, so makes no requests
+	f := &Funder{cfg: cfg, privKey: privKey, pubKey: privKey.PubKey(), addr: sdk.AccAddress(privKey.PubKey().Address())}
+	recipients := []sdk.AccAddress{
+		sdk.AccAddress(accounts.WorkerPrivKey(0).PubKey().Address()),
+		sdk.AccAddress(accounts.WorkerPrivKey(1).PubKey().Address()),
+	}
+	msgs := f.fundMsgs(recipients, sdk.NewCoins(sdk.NewCoin(defaultDenom, math.NewInt(1_000_000))))
+	gasLimit, fee := txFee(cfg, len(msgs))
+	p := TxParams{
+		PubKey:        f.pubKey,
+		Address:       f.addr.String(),
+		ChainID:       cfg.ChainID,
+		AccountNumber: 0,
+		Sequence:      5,
+		FeeCoins:      fee,
+		GasLimit:      gasLimit,
+		SignMode:      signing.SignMode_SIGN_MODE_DIRECT,
+	}
+	txBytes, err := BuildTx(txConfig, p, msgs, PrivKeySigner(txConfig, privKey))
+	require.NoError(t, err)
+	golden.RequireTx(t, "fund_tx.golden", txBytes)
+
+	again, err := BuildTx(txConfig, p, msgs, PrivKeySigner(txConfig, privKey))
+	require.NoError(t, err)
+	require.Equal(t, txBytes, again)
+}
@@ -0,0 +1,140 @@
+package seed
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// txSubscription is a subscription, via the node's WebSockets RPC endpoint,
+// to the event emitted when a specific transaction is committed. It lets us
+// learn of a funding transaction's inclusion as soon as its block is
+// committed, instead of polling for it.
+type txSubscription struct {
+	conn *websocket.Conn
+}
+
+// rpcResponse is a JSON-RPC response (or event notification) from CometBFT.
+type rpcResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Data    string `json:"data"`
+	} `json:"error"`
+}
+
+// wsURLFor converts an RPC URL (e.g. "http://localhost:36657") to the URL of
+// the node's WebSockets endpoint (e.g. "ws://localhost:36657/websocket").
+func wsURLFor(rpc string) string {
+	wsURL := strings.TrimSuffix(rpc, "/")
+	switch {
+	case strings.HasPrefix(wsURL, "https://"):
+		wsURL = "wss://" + strings.TrimPrefix(wsURL, "https://")
+	case strings.HasPrefix(wsURL, "http://"):
+		wsURL = "ws://" + strings.TrimPrefix(wsURL, "http://")
+	}
+	return wsURL + "/websocket"
+}
+
+// subscribeTx subscribes to the commit of the transaction with the given hash
+// via the WebSockets endpoint of the node at the given RPC URL, giving up on
+// connecting and subscribing after the given timeout.
+func subscribeTx(rpc, txHash string, timeout time.Duration) (*txSubscription, error) {
+	dialer := &websocket.Dialer{HandshakeTimeout: timeout}
+	conn, _, err := dialer.Dial(wsURLFor(rpc), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to WebSockets endpoint: %w", err)
+	}
+	sub := &txSubscription{conn: conn}
+	deadline := time.Now().Add(timeout)
+	_ = conn.SetWriteDeadline(deadline)
+	err = conn.WriteJSON(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  "subscribe",
+		"params": map[string]string{
+			"query": fmt.Sprintf("tm.event='Tx' AND tx.hash='%s'", txHash),
+		},
+	})
+	if err != nil {
+		sub.Close()
+		return nil, fmt.Errorf("failed to send subscription request: %w", err)
+	}
+	// The first response acknowledges (or rejects) the subscription
+	_ = conn.SetReadDeadline(deadline)
+	var resp rpcResponse
+	if err := conn.ReadJSON(&resp); err != nil {
+		sub.Close()
+		return nil, fmt.Errorf("failed to read subscription response: %w", err)
+	}
+	if resp.Error != nil {
+		sub.Close()
+		return nil, fmt.Errorf("subscription rejected: %s (%s)", resp.Error.Message, resp.Error.Data)
+	}
+	return sub, nil
+}
+
+// Wait waits until the transaction is committed or the given deadline
+// passes, returning the height of the block in which the transaction was
+// included, or an error if it failed in that block. Failures of the
+// subscription itself (including timeouts) are returned as a
+// *subscriptionError.
+func (s *txSubscription) Wait(deadline time.Time) (string, error) {
+	_ = s.conn.SetReadDeadline(deadline)
+	for {
+		var resp rpcResponse
+		if err := s.conn.ReadJSON(&resp); err != nil {
+			return "", &subscriptionError{Err: err}
+		}
+		if resp.Error != nil {
+			return "", &subscriptionError{Err: fmt.Errorf("%s (%s)", resp.Error.Message, resp.Error.Data)}
+		}
+		var event struct {
+			Data struct {
+				Value struct {
+					TxResult struct {
+						Height string `json:"height"`
+						Result struct {
+							Code uint32 `json:"code"`
+							Log  string `json:"log"`
+						} `json:"result"`
+					} `json:"TxResult"`
+				} `json:"value"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal(resp.Result, &event); err != nil {
+			return "", &subscriptionError{Err: fmt.Errorf("failed to decode event: %w", err)}
+		}
+		txResult := event.Data.Value.TxResult
+		if len(txResult.Height) == 0 {
+			// not a transaction event
+			continue
+		}
+		if txResult.Result.Code != 0 {
+			return "", fmt.Errorf("transaction failed in block %s: code %d, log: %s",
+				txResult.Height, txResult.Result.Code, txResult.Result.Log)
+		}
+		return txResult.Height, nil
+	}
+}
+
+// Close closes the subscription's connection.
+func (s *txSubscription) Close() {
+	_ = s.conn.Close()
+}
+
+// subscriptionError is returned by txSubscription.Wait when the subscription
+// itself failed (or timed out), as opposed to the transaction.
+type subscriptionError struct {
+	Err error
+}
+
+func (e *subscriptionError) Error() string {
+	return fmt.Sprintf("transaction subscription failed: %v", e.Err)
+}
+
+func (e *subscriptionError) Unwrap() error { return e.Err }
@@ -0,0 +1,210 @@
+// Package smoke implements the smoke command, which checks that a chain is
+// live and accepts the load test's transactions by seeding a couple of
+// accounts, running a brief low-rate load test and confirming that its
+// transactions were committed.
+package smoke
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/1119-Labs/perpx-load-test/internal/httpclient"
+	"github.com/1119-Labs/perpx-load-test/pkg/loadtest"
+	"github.com/1119-Labs/perpx-load-test/pkg/seed"
+)
+
+// Config holds the configuration of the smoke command.
+type Config struct {
+	Endpoint      string // The CometBFT WebSockets RPC endpoint to test.
+	Workers       int    // How many worker accounts to seed and send from (one connection each).
+	Time          int    // How long (in seconds) to send transactions for.
+	Rate          int    // How many transactions each worker sends per second.
+	MinCommitted  int    // How many transactions must be confirmed as committed for the test to pass.
+	ClientFactory string // The client factory that generates the load test's transactions.
+}
+
+// DefaultConfig returns the smoke command's default configuration.
+func DefaultConfig() Config {
+	return Config{
+		Workers:      2,
+		Time:         10,
+		Rate:         2,
+		MinCommitted: 5,
+	}
+}
+
+// Result summarizes the outcome of a smoke test.
+type Result struct {
+	Submitted int // Transactions broadcast.
+	Committed int // Transactions confirmed as committed successfully.
+	Failed    int // Transactions that were rejected, failed in their block or weren't committed in time.
+}
+
+// outcomes counts the outcomes of the load test's transactions.
+type outcomes struct {
+	submitted, committed, failed atomic.Int64
+	firstErr                     atomic.Pointer[loadtest.TxError]
+}
+
+func (o *outcomes) OnSubmit(loadtest.TxInfo) { o.submitted.Add(1) }
+
+func (o *outcomes) OnConfirm(loadtest.TxInfo, loadtest.TxConfirmation) { o.committed.Add(1) }
+
+func (o *outcomes) OnError(_ loadtest.TxInfo, err *loadtest.TxError) {
+	o.failed.Add(1)
+	o.firstErr.CompareAndSwap(nil, err)
+}
+
+// Run executes the smoke command, exiting with a non-zero status if the smoke
+// test fails. Transactions are generated by the given client factory, which
+// must have been registered.
+func Run(args []string, clientFactory string) {
+	cfg := parseArgs(args)
+	cfg.ClientFactory = clientFactory
+	if len(cfg.Endpoint) == 0 {
+		fmt.Fprintln(os.Stderr, "Error: --endpoint is required")
+		os.Exit(1)
+	}
+	if cfg.Workers < 1 || cfg.Time < 1 || cfg.Rate < 1 || cfg.MinCommitted < 1 {
+		fmt.Fprintln(os.Stderr, "Error: --workers, --time, --rate and --min-committed must be at least 1")
+		os.Exit(1)
+	}
+
+	result, err := Execute(cfg)
+	fmt.Printf("Submitted: %d, committed: %d, failed: %d\n", result.Submitted, result.Committed, result.Failed)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "✗ Smoke test FAILED: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println("✓ Smoke test passed")
+}
+
+// Execute runs the smoke test: it seeds the worker accounts, sends
+// transactions from them for cfg.Time seconds while confirming every one of
+// them, and returns an error unless at least cfg.MinCommitted of them were
+// committed and none failed.
+func Execute(cfg Config) (Result, error) {
+	seedCfg := seed.DefaultConfig()
+	seedCfg.Workers = cfg.Workers
+	seedCfg.RPC = rpcURL(cfg.Endpoint)
+	fmt.Printf("Seeding %d accounts via %s...\n", cfg.Workers, seedCfg.RPC)
+	if err := seed.Execute(seedCfg); err != nil {
+		return Result{}, fmt.Errorf("failed to seed accounts: %w", err)
+	}
+
+	observed := &outcomes{}
+	ltCfg := loadtest.Config{
+		ClientFactory:        cfg.ClientFactory,
+		Connections:          cfg.Workers,
+		Time:                 cfg.Time,
+		SendPeriod:           1,
+		Rate:                 cfg.Rate,
+		Size:                 250,
+		Count:                -1,
+		MsgsPerTx:            1,
+		BroadcastTxMethod:    "sync",
+		Endpoints:            []string{cfg.Endpoint},
+		EndpointSelectMethod: loadtest.SelectSuppliedEndpoints,
+		MaxReconnectAttempts: 3,
+		PrepareConcurrency:   cfg.Workers,
+		HTTPTimeout:          int(httpclient.DefaultTimeout / time.Second),
+		HTTPMaxIdleConns:     httpclient.DefaultMaxIdleConnsPerHost,
+		UI:                   "plain",
+		Confirm:              true,
+		ConfirmEvery:         1,
+		ConfirmTimeout:       30,
+		NoTrapInterrupts:     true,
+		ResultObserver:       observed,
+	}
+	if err := ltCfg.Validate(); err != nil {
+		return Result{}, fmt.Errorf("invalid load test configuration: %w", err)
+	}
+	fmt.Printf("Sending %d tx/s from each of %d accounts for %ds...\n", cfg.Rate, cfg.Workers, cfg.Time)
+	runErr := loadtest.ExecuteStandalone(ltCfg)
+
+	result := Result{
+		Submitted: int(observed.submitted.Load()),
+		Committed: int(observed.committed.Load()),
+		Failed:    int(observed.failed.Load()),
+	}
+	switch {
+	case runErr != nil:
+		return result, fmt.Errorf("load test failed: %w", runErr)
+	case result.Failed > 0:
+		return result, fmt.Errorf("%d transaction(s) failed, the first with: %v", result.Failed, observed.firstErr.Load())
+	case result.Committed < cfg.MinCommitted:
+		return result, fmt.Errorf("only %d transaction(s) were committed, but at least %d must be", result.Committed, cfg.MinCommitted)
+	}
+	return result, nil
+}
+
+// rpcURL converts a WebSockets RPC endpoint (e.g.
+// "ws://localhost:36657/websocket") to the corresponding HTTP RPC URL.
+func rpcURL(endpoint string) string {
+	url := strings.TrimSuffix(endpoint, "/websocket")
+	if strings.HasPrefix(url, "wss://") {
+		return "https://" + strings.TrimPrefix(url, "wss://")
+	}
+	return "http://" + strings.TrimPrefix(url, "ws://")
+}
+
+func parseArgs(args []string) Config {
+	cfg := DefaultConfig()
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--endpoint", "-e":
+			if i+1 < len(args) {
+				cfg.Endpoint = args[i+1]
+				i++
+			}
+		case "--workers", "-w":
+			if i+1 < len(args) {
+				cfg.Workers, _ = strconv.Atoi(args[i+1])
+				i++
+			}
+		case "--time", "-T":
+			if i+1 < len(args) {
+				cfg.Time, _ = strconv.Atoi(args[i+1])
+				i++
+			}
+		case "--rate", "-r":
+			if i+1 < len(args) {
+				cfg.Rate, _ = strconv.Atoi(args[i+1])
+				i++
+			}
+		case "--min-committed":
+			if i+1 < len(args) {
+				cfg.MinCommitted, _ = strconv.Atoi(args[i+1])
+				i++
+			}
+		case "--help", "-h":
+			printHelp()
+			os.Exit(0)
+		}
+	}
+	return cfg
+}
+
+func printHelp() {
+	fmt.Println(`Usage: perpx-load-test smoke --endpoint URL [OPTIONS]
+
+Checks that the chain is live and accepts the load test's transactions: seeds
+the worker accounts, sends bank sends from them at a low rate, and confirms
+that every transaction was committed. Exits with a non-zero status unless at
+least --min-committed transactions were committed and none failed.
+
+Options:
+  --endpoint, -e URL       CometBFT WebSockets RPC endpoint, e.g. ws://localhost:36657/websocket
+  --workers, -w N          Number of accounts to seed and send from (default: 2)
+  --time, -T N             Seconds for which to send transactions (default: 10)
+  --rate, -r N             Transactions per second sent by each account (default: 2)
+  --min-committed N        Transactions that must be committed for the test to pass (default: 5)
+  --help, -h               Show this help message
+
+The seed account, chain ID, denom and other chain settings are taken from the
+same LOADTEST_* environment variables as for the seed command.`)
+}
@@ -0,0 +1,237 @@
+package strategies
+
+import (
+	"bufio"
+	"fmt"
+	stdmath "math"
+	"math/rand"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"cosmossdk.io/math"
+)
+
+// AmountDistribution generates the amounts (in base units of the denom) sent
+// by bank send messages.
+type AmountDistribution interface {
+	// Sample draws an amount from the distribution. Amounts are always >= 1.
+	Sample(rng *rand.Rand) int64
+	// Mean returns the (approximate) average amount.
+	Mean() float64
+}
+
+// DefaultAmountDistribution sends 1 base unit with every message.
+var DefaultAmountDistribution AmountDistribution = fixedAmount(1)
+
+// ParseAmountDistribution parses an amount distribution specification, which
+// is one of:
+//
+//	fixed:N                               always send N
+//	uniform:min=A,max=B                   uniformly distributed in [A, B]
+//	lognormal:median=M,sigma=S[,max=B]    log-normally distributed around M, optionally capped at B
+//	histogram:FILE                        drawn from the "amount weight" pairs in FILE (one per line)
+//
+// An empty specification results in DefaultAmountDistribution.
+func ParseAmountDistribution(spec string) (AmountDistribution, error) {
+	spec = strings.TrimSpace(spec)
+	if len(spec) == 0 {
+		return DefaultAmountDistribution, nil
+	}
+	kind, params, _ := strings.Cut(spec, ":")
+	switch kind {
+	case "fixed":
+		amount, err := strconv.ParseInt(strings.TrimSpace(params), 10, 64)
+		if err != nil || amount < 1 {
+			return nil, fmt.Errorf("invalid fixed amount %q: must be an integer >= 1", params)
+		}
+		return fixedAmount(amount), nil
+	case "uniform":
+		values, err := parseAmountParams(params, []string{"min", "max"})
+		if err != nil {
+			return nil, err
+		}
+		minAmount, maxAmount := int64(values["min"]), int64(values["max"])
+		if minAmount < 1 || maxAmount < minAmount || float64(minAmount) != values["min"] || float64(maxAmount) != values["max"] {
+			return nil, fmt.Errorf("invalid uniform amounts: min and max must be integers with 1 <= min <= max")
+		}
+		return uniformAmount{min: minAmount, max: maxAmount}, nil
+	case "lognormal":
+		values, err := parseAmountParams(params, []string{"median", "sigma"}, "max")
+		if err != nil {
+			return nil, err
+		}
+		dist := logNormalAmount{median: values["median"], sigma: values["sigma"], max: int64(values["max"])}
+		if dist.median < 1 || dist.sigma <= 0 {
+			return nil, fmt.Errorf("invalid lognormal amounts: median must be >= 1 and sigma > 0")
+		}
+		if _, ok := values["max"]; ok && float64(dist.max) < dist.median {
+			return nil, fmt.Errorf("invalid lognormal amounts: max must be >= median")
+		}
+		return dist, nil
+	case "histogram":
+		return readHistogramAmounts(strings.TrimSpace(params))
+	default:
+		return nil, fmt.Errorf("unknown amount distribution %q (expected fixed, uniform, lognormal or histogram)", kind)
+	}
+}
+
+// parseAmountParams parses comma-separated "key=value" pairs, allowing only
+// the given required and optional keys.
+func parseAmountParams(params string, required []string, optional ...string) (map[string]float64, error) {
+	keys := append(append([]string{}, required...), optional...)
+	values := make(map[string]float64)
+	for _, pair := range strings.Split(params, ",") {
+		pair = strings.TrimSpace(pair)
+		if len(pair) == 0 {
+			continue
+		}
+		key, val, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid amount distribution parameter %q: expected key=value", pair)
+		}
+		known := false
+		for _, k := range keys {
+			known = known || k == key
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown amount distribution parameter %q (expected one of %s)", key, strings.Join(keys, ", "))
+		}
+		v, err := strconv.ParseFloat(strings.TrimSpace(val), 64)
+		if err != nil || stdmath.IsInf(v, 0) || stdmath.IsNaN(v) {
+			return nil, fmt.Errorf("invalid amount distribution parameter %q", pair)
+		}
+		values[key] = v
+	}
+	for _, key := range required {
+		if _, ok := values[key]; !ok {
+			return nil, fmt.Errorf("missing amount distribution parameter %q", key)
+		}
+	}
+	return values, nil
+}
+
+type fixedAmount int64
+
+func (a fixedAmount) Sample(*rand.Rand) int64 { return int64(a) }
+func (a fixedAmount) Mean() float64           { return float64(a) }
+
+type uniformAmount struct {
+	min, max int64
+}
+
+func (a uniformAmount) Sample(rng *rand.Rand) int64 {
+	return a.min + rng.Int63n(a.max-a.min+1)
+}
+
+func (a uniformAmount) Mean() float64 {
+	return (float64(a.min) + float64(a.max)) / 2
+}
+
+type logNormalAmount struct {
+	median float64
+	sigma  float64
+	max    int64 // 0 means uncapped
+}
+
+func (a logNormalAmount) Sample(rng *rand.Rand) int64 {
+	v := stdmath.Round(a.median * stdmath.Exp(a.sigma*rng.NormFloat64()))
+	switch {
+	case v < 1:
+		return 1
+	case a.max > 0 && v > float64(a.max):
+		return a.max
+	case v >= stdmath.MaxInt64:
+		return stdmath.MaxInt64
+	}
+	return int64(v)
+}
+
+func (a logNormalAmount) Mean() float64 {
+	mean := a.median * stdmath.Exp(a.sigma*a.sigma/2)
+	if a.max > 0 && mean > float64(a.max) {
+		return float64(a.max)
+	}
+	return mean
+}
+
+// histogramAmount draws amounts from a discrete set of amounts with relative
+// weights.
+type histogramAmount struct {
+	amounts    []int64
+	cumWeights []float64 // The cumulative weights, in the same order as amounts.
+}
+
+// readHistogramAmounts reads a histogram of amounts from the given file, in
+// which each line holds an amount and its relative weight, separated by
+// whitespace. Blank lines and lines starting with "#" are ignored.
+func readHistogramAmounts(path string) (AmountDistribution, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open amount histogram: %w", err)
+	}
+	defer f.Close()
+
+	h := histogramAmount{}
+	total := 0.0
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if len(text) == 0 || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Fields(text)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%s:%d: expected \"amount weight\"", path, line)
+		}
+		amount, err := strconv.ParseInt(fields[0], 10, 64)
+		if err != nil || amount < 1 {
+			return nil, fmt.Errorf("%s:%d: invalid amount %q: must be an integer >= 1", path, line, fields[0])
+		}
+		weight, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil || weight <= 0 || stdmath.IsInf(weight, 0) || stdmath.IsNaN(weight) {
+			return nil, fmt.Errorf("%s:%d: invalid weight %q: must be > 0", path, line, fields[1])
+		}
+		total += weight
+		h.amounts = append(h.amounts, amount)
+		h.cumWeights = append(h.cumWeights, total)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read amount histogram %s: %w", path, err)
+	}
+	if len(h.amounts) == 0 {
+		return nil, fmt.Errorf("amount histogram %s contains no amounts", path)
+	}
+	return h, nil
+}
+
+func (a histogramAmount) Sample(rng *rand.Rand) int64 {
+	total := a.cumWeights[len(a.cumWeights)-1]
+	r := rng.Float64() * total
+	i := sort.SearchFloat64s(a.cumWeights, r)
+	// SearchFloat64s finds the first cumulative weight >= r, but an amount
+	// only covers the half-open interval up to its cumulative weight
+	for i < len(a.cumWeights)-1 && a.cumWeights[i] <= r {
+		i++
+	}
+	return a.amounts[i]
+}
+
+func (a histogramAmount) Mean() float64 {
+	sum, prev := 0.0, 0.0
+	for i, amount := range a.amounts {
+		sum += float64(amount) * (a.cumWeights[i] - prev)
+		prev = a.cumWeights[i]
+	}
+	return sum / prev
+}
+
+// meanAmount returns the mean of the given distribution, rounded up.
+func meanAmount(dist AmountDistribution) math.Int {
+	mean := stdmath.Ceil(dist.Mean())
+	if mean >= stdmath.MaxInt64 {
+		return math.NewInt(stdmath.MaxInt64)
+	}
+	return math.NewInt(int64(mean))
+}
@@ -0,0 +1,124 @@
+package strategies_test
+
+import (
+	stdmath "math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/1119-Labs/perpx-load-test/pkg/strategies"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeHistogram(t *testing.T, contents string) string {
+	path := filepath.Join(t.TempDir(), "amounts.txt")
+	require.NoError(t, os.WriteFile(path, []byte(contents), 0o600))
+	return path
+}
+
+func TestParseAmountDistribution(t *testing.T) {
+	histogram := writeHistogram(t, "# amount weight\n10 3\n\n1000 1\n")
+	testCases := []struct {
+		spec string
+		mean float64 // Only checked if the spec is valid.
+		err  string  // Empty if the spec is valid.
+	}{
+		{spec: "", mean: 1},
+		{spec: "fixed:250", mean: 250},
+		{spec: " fixed: 7 ", mean: 7},
+		{spec: "fixed:0", err: "must be an integer >= 1"},
+		{spec: "fixed:1.5", err: "must be an integer >= 1"},
+		{spec: "fixed:99999999999999999999", err: "must be an integer >= 1"},
+		{spec: "uniform:min=10,max=20", mean: 15},
+		{spec: "uniform:min=5,max=5", mean: 5},
+		{spec: "uniform:min=20,max=10", err: "1 <= min <= max"},
+		{spec: "uniform:min=0,max=10", err: "1 <= min <= max"},
+		{spec: "uniform:min=1.5,max=10", err: "must be integers"},
+		{spec: "uniform:min=1,max=1e19", err: "must be integers"},
+		{spec: "uniform:min=1", err: `missing amount distribution parameter "max"`},
+		{spec: "uniform:min=1,max=2,mode=3", err: `unknown amount distribution parameter "mode"`},
+		{spec: "uniform:min=1,max", err: "expected key=value"},
+		{spec: "uniform:min=1,max=Inf", err: "invalid amount distribution parameter"},
+		{spec: "lognormal:median=1000,sigma=1", mean: 1000 * stdmath.Exp(0.5)},
+		{spec: "lognormal:median=1000,sigma=2,max=2000", mean: 2000},
+		{spec: "lognormal:median=1000,sigma=0", err: "sigma > 0"},
+		{spec: "lognormal:median=0.5,sigma=1", err: "median must be >= 1"},
+		{spec: "lognormal:median=1000,sigma=1,max=999", err: "max must be >= median"},
+		{spec: "histogram:" + histogram, mean: (10*3 + 1000*1) / 4.0},
+		{spec: "histogram:" + writeHistogram(t, "10\n"), err: `expected "amount weight"`},
+		{spec: "histogram:" + writeHistogram(t, "0 1\n"), err: "invalid amount"},
+		{spec: "histogram:" + writeHistogram(t, "10 0\n"), err: "invalid weight"},
+		{spec: "histogram:" + writeHistogram(t, "# nothing\n"), err: "contains no amounts"},
+		{spec: "histogram:" + filepath.Join(t.TempDir(), "missing.txt"), err: "failed to open amount histogram"},
+		{spec: "pareto:alpha=1", err: "unknown amount distribution"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.spec, func(t *testing.T) {
+			dist, err := strategies.ParseAmountDistribution(tc.spec)
+			if len(tc.err) > 0 {
+				require.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.InDelta(t, tc.mean, dist.Mean(), 1e-9)
+		})
+	}
+}
+
+func TestAmountDistributionBounds(t *testing.T) {
+	testCases := []struct {
+		spec     string
+		min, max int64
+		reachMax bool // Whether samples must reach max, e.g. because they're capped at it.
+		distinct int  // If > 0, the number of distinct amounts that must be sampled.
+	}{
+		{spec: "fixed:3", min: 3, max: 3, reachMax: true, distinct: 1},
+		// both bounds are inclusive
+		{spec: "uniform:min=10,max=20", min: 10, max: 20, reachMax: true, distinct: 11},
+		// median 1 with a wide spread would round most samples down to 0
+		{spec: "lognormal:median=1,sigma=3", min: 1, max: stdmath.MaxInt64},
+		{spec: "lognormal:median=1000,sigma=3,max=1500", min: 1, max: 1500, reachMax: true},
+		// samples that overflow an int64 are clamped
+		{spec: "lognormal:median=1e300,sigma=1", min: stdmath.MaxInt64, max: stdmath.MaxInt64, reachMax: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.spec, func(t *testing.T) {
+			dist, err := strategies.ParseAmountDistribution(tc.spec)
+			require.NoError(t, err)
+			rng := rand.New(rand.NewSource(1))
+			seen := make(map[int64]bool)
+			for i := 0; i < 10000; i++ {
+				amount := dist.Sample(rng)
+				require.GreaterOrEqual(t, amount, tc.min)
+				require.LessOrEqual(t, amount, tc.max)
+				seen[amount] = true
+			}
+			if tc.reachMax {
+				assert.True(t, seen[tc.max])
+			}
+			if tc.distinct > 0 {
+				assert.Len(t, seen, tc.distinct)
+			}
+		})
+	}
+}
+
+func TestHistogramAmountSampling(t *testing.T) {
+	path := writeHistogram(t, "1 1\n2 2\n3 1\n")
+	dist, err := strategies.ParseAmountDistribution("histogram:" + path)
+	require.NoError(t, err)
+	assert.InDelta(t, 2.0, dist.Mean(), 1e-9)
+
+	rng := rand.New(rand.NewSource(42))
+	const samples = 100000
+	counts := make(map[int64]int)
+	for i := 0; i < samples; i++ {
+		counts[dist.Sample(rng)]++
+	}
+	require.Len(t, counts, 3)
+	assert.InDelta(t, 0.25, float64(counts[1])/samples, 0.01)
+	assert.InDelta(t, 0.5, float64(counts[2])/samples, 0.01)
+	assert.InDelta(t, 0.25, float64(counts[3])/samples, 0.01)
+}
@@ -1,7 +1,11 @@
 package strategies
 
 import (
+	"crypto/sha256"
 	"fmt"
+	"math/rand"
+	"sync"
+	"sync/atomic"
 
 	"cosmossdk.io/math"
 	sdk "github.com/cosmos/cosmos-sdk/types"
@@ -13,15 +17,29 @@ type BankSendStrategy struct {
 	chainID  string
 	denom    string
 	sinkAddr string
+	selfSend bool // Send to the sender's own address instead of the sink.
+
+	// If recipientCount > 0, messages are sent to a deterministically generated
+	// space of (initially unfunded) addresses instead of the sink.
+	recipientSalt  string
+	recipientCount uint64
+	nextRecipient  uint64 // Index of the next recipient (atomic).
+
+	// If set, messages are sent to these addresses in turn instead of the
+	// sink (takes precedence over recipientSalt).
+	recipients []string
+
+	// If set, the amount sent by each message is drawn from this
+	// distribution (otherwise DefaultAmountDistribution).
+	amounts AmountDistribution
+	rngMtx  sync.Mutex
+	rng     *rand.Rand
 }
 
 // NewBankSendStrategy creates a new bank send strategy
 func NewBankSendStrategy(chainID, denom, sinkAddr string) (*BankSendStrategy, error) {
-	if chainID == "" {
-		return nil, fmt.Errorf("chain ID cannot be empty")
-	}
-	if denom == "" {
-		return nil, fmt.Errorf("denom cannot be empty")
+	if err := validateBankParams(chainID, denom); err != nil {
+		return nil, err
 	}
 	if sinkAddr == "" {
 		return nil, fmt.Errorf("sink address cannot be empty")
@@ -40,6 +58,145 @@ func NewBankSendStrategy(chainID, denom, sinkAddr string) (*BankSendStrategy, er
 	}, nil
 }
 
+// validateBankParams checks the chain ID and denom shared by all bank send
+// strategies.
+func validateBankParams(chainID, denom string) error {
+	if chainID == "" {
+		return fmt.Errorf("chain ID cannot be empty")
+	}
+	if denom == "" {
+		return fmt.Errorf("denom cannot be empty")
+	}
+	if err := sdk.ValidateDenom(denom); err != nil {
+		return fmt.Errorf("invalid denom %q: %w", denom, err)
+	}
+	return nil
+}
+
+// NewBankSelfSendStrategy creates a bank send strategy in which each message
+// sends funds back to the sender's own address. Balances therefore only ever
+// decrease by the fees paid, which allows for much longer runs than sending
+// to a sink without reseeding.
+func NewBankSelfSendStrategy(chainID, denom string) (*BankSendStrategy, error) {
+	if err := validateBankParams(chainID, denom); err != nil {
+		return nil, err
+	}
+
+	return &BankSendStrategy{
+		chainID:  chainID,
+		denom:    denom,
+		selfSend: true,
+	}, nil
+}
+
+// NewBankFreshRecipientStrategy creates a bank send strategy in which messages
+// cycle through count deterministically generated recipient addresses. The
+// addresses are derived from the given salt and sender ID, so as long as the
+// salt hasn't been used before, none of them exist on chain until the first
+// message sent to them, which exercises account creation.
+func NewBankFreshRecipientStrategy(chainID, denom, salt string, senderID, count int) (*BankSendStrategy, error) {
+	if err := validateBankParams(chainID, denom); err != nil {
+		return nil, err
+	}
+	if count < 1 {
+		return nil, fmt.Errorf("recipient count must be at least 1")
+	}
+
+	return &BankSendStrategy{
+		chainID:        chainID,
+		denom:          denom,
+		recipientSalt:  fmt.Sprintf("%s/%d", salt, senderID),
+		recipientCount: uint64(count),
+	}, nil
+}
+
+// NewBankFileRecipientStrategy creates a bank send strategy in which messages
+// cycle through the given recipient addresses (e.g. as read from a file by
+// ReadRecipientsFile), starting at the given offset. Giving each sender a
+// different offset spreads concurrent sends across the recipients.
+func NewBankFileRecipientStrategy(chainID, denom string, recipients []string, offset int) (*BankSendStrategy, error) {
+	if err := validateBankParams(chainID, denom); err != nil {
+		return nil, err
+	}
+	if len(recipients) == 0 {
+		return nil, fmt.Errorf("at least one recipient is required")
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	return &BankSendStrategy{
+		chainID:        chainID,
+		denom:          denom,
+		recipients:     recipients,
+		recipientCount: uint64(len(recipients)),
+		nextRecipient:  uint64(offset) % uint64(len(recipients)),
+	}, nil
+}
+
+// NewBankWorkerSinksStrategy creates a bank send strategy in which messages
+// cycle through count sink addresses unique to the given sender, modelling a
+// trader who deals with a handful of counterparties. Unlike fresh recipients,
+// the sinks are derived from the sender ID alone, so every run sends to the
+// same ones.
+func NewBankWorkerSinksStrategy(chainID, denom string, senderID, count int) (*BankSendStrategy, error) {
+	if count < 1 {
+		return nil, fmt.Errorf("sink count must be at least 1")
+	}
+	sinks := make([]string, count)
+	for i := range sinks {
+		sinks[i] = workerSinkAddress(senderID, i).String()
+	}
+	return NewBankFileRecipientStrategy(chainID, denom, sinks, 0)
+}
+
+// SetAmountDistribution configures the distribution from which the amount
+// sent by each message is drawn. The random number generator is seeded with
+// the given seed, so that the same amounts are generated on every run. Must be
+// called before CreateMsg.
+func (s *BankSendStrategy) SetAmountDistribution(dist AmountDistribution, seed int64) {
+	s.amounts = dist
+	s.rng = rand.New(rand.NewSource(seed))
+}
+
+func (s *BankSendStrategy) amountDistribution() AmountDistribution {
+	if s.amounts == nil {
+		return DefaultAmountDistribution
+	}
+	return s.amounts
+}
+
+// sampleAmount draws the amount to be sent by the next message.
+func (s *BankSendStrategy) sampleAmount() math.Int {
+	if s.rng == nil {
+		return math.NewInt(s.amountDistribution().Sample(nil))
+	}
+	s.rngMtx.Lock()
+	defer s.rngMtx.Unlock()
+	return math.NewInt(s.amountDistribution().Sample(s.rng))
+}
+
+// freshRecipientAddress derives the address of the recipient with the given
+// index. There is no corresponding private key, so funds sent to these
+// addresses are burned.
+func freshRecipientAddress(salt string, index uint64) sdk.AccAddress {
+	h := sha256.Sum256([]byte(fmt.Sprintf("fresh recipient %s/%d", salt, index)))
+	return sdk.AccAddress(h[:20])
+}
+
+// workerSinkAddress derives the address of the given sender's sink with the
+// given index. As with fresh recipients, there is no corresponding private
+// key, so funds sent to these addresses are burned.
+func workerSinkAddress(senderID, index int) sdk.AccAddress {
+	h := sha256.Sum256([]byte(fmt.Sprintf("worker sink %d/%d", senderID, index)))
+	return sdk.AccAddress(h[:20])
+}
+
+// Name returns the strategy's name
+func (s *BankSendStrategy) Name() string {
+	return BankSendStrategyName
+}
+
 // ChainID returns the chain ID
 func (s *BankSendStrategy) ChainID() string {
 	return s.chainID
@@ -50,6 +207,22 @@ func (s *BankSendStrategy) Denom() string {
 	return s.denom
 }
 
+// AmountPerMsg returns the average amount (in base units of the denom) sent by
+// each message, rounded up
+func (s *BankSendStrategy) AmountPerMsg() math.Int {
+	return meanAmount(s.amountDistribution())
+}
+
+// SelfSend returns whether each message sends funds back to the sender
+func (s *BankSendStrategy) SelfSend() bool {
+	return s.selfSend
+}
+
+// GasEstimate returns the gas limit allotted to each bank send message by default
+func (s *BankSendStrategy) GasEstimate() uint64 {
+	return bankSendGasEstimate
+}
+
 // CreateMsg creates a bank send message from the given address
 func (s *BankSendStrategy) CreateMsg(fromAddr string) (sdk.Msg, error) {
 	// Validate from address
@@ -58,15 +231,26 @@ func (s *BankSendStrategy) CreateMsg(fromAddr string) (sdk.Msg, error) {
 		return nil, fmt.Errorf("invalid from address: %w", err)
 	}
 
-	// Create small amount to send (1 base unit)
-	amount := sdk.NewCoins(sdk.NewCoin(s.denom, math.NewInt(1)))
+	// Create the amount to send (1 base unit, unless configured otherwise)
+	amount := sdk.NewCoins(sdk.NewCoin(s.denom, s.sampleAmount()))
+
+	toAddr := s.sinkAddr
+	switch {
+	case s.selfSend:
+		toAddr = fromAddr
+	case len(s.recipients) > 0:
+		index := (atomic.AddUint64(&s.nextRecipient, 1) - 1) % s.recipientCount
+		toAddr = s.recipients[index]
+	case s.recipientCount > 0:
+		index := (atomic.AddUint64(&s.nextRecipient, 1) - 1) % s.recipientCount
+		toAddr = freshRecipientAddress(s.recipientSalt, index).String()
+	}
 
 	msg := &banktypes.MsgSend{
 		FromAddress: fromAddr,
-		ToAddress:   s.sinkAddr,
+		ToAddress:   toAddr,
 		Amount:      amount,
 	}
 
 	return msg, nil
 }
-
@@ -0,0 +1,142 @@
+package strategies
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// The names of the strategies, under which their gas estimates and gas
+// prices can be overridden.
+const (
+	BankSendStrategyName      = "bank-send"
+	HotAccountStrategyName    = "hot-account"
+	PerpRoundTripStrategyName = "perp-round-trip"
+	WasmExecuteStrategyName   = "wasm-execute"
+)
+
+// Gas limits allotted to each message of the strategies by default.
+const (
+	// A MsgSend, whichever its recipient.
+	bankSendGasEstimate = 200000
+	// Every other message is a MsgExec, which dispatches a MsgSend after
+	// checking the sender's authorization.
+	hotAccountGasEstimate = 250000
+	// Placing an order also matches it against the order book and updates
+	// the subaccount's position.
+	perpOrderGasEstimate = 300000
+	// What an execution costs depends entirely on the contract, so allow for
+	// a moderately complex one (override it with GasOverrides otherwise).
+	wasmExecuteGasEstimate = 500000
+)
+
+// StrategyNames lists the names of all strategies.
+var StrategyNames = []string{BankSendStrategyName, HotAccountStrategyName, PerpRoundTripStrategyName, WasmExecuteStrategyName}
+
+// GasOverrides maps strategy names to the gas limit to allot to each of the
+// strategy's messages instead of its GasEstimate.
+type GasOverrides map[string]uint64
+
+// ParseGasOverrides parses a comma-separated list of strategy=gas pairs, e.g.
+// "bank-send=150000,perp-round-trip=400000".
+func ParseGasOverrides(s string) (GasOverrides, error) {
+	overrides := make(GasOverrides)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if len(pair) == 0 {
+			continue
+		}
+		name, val, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid strategy gas %q: expected strategy=gas", pair)
+		}
+		name, val = strings.TrimSpace(name), strings.TrimSpace(val)
+		if !slices.Contains(StrategyNames, name) {
+			return nil, fmt.Errorf("unknown strategy %q (expected one of %s)", name, strings.Join(StrategyNames, ", "))
+		}
+		if _, dup := overrides[name]; dup {
+			return nil, fmt.Errorf("duplicate strategy gas for %q", name)
+		}
+		gas, err := strconv.ParseUint(val, 10, 64)
+		if err != nil || gas == 0 {
+			return nil, fmt.Errorf("invalid gas %q for strategy %q: must be a positive integer", val, name)
+		}
+		overrides[name] = gas
+	}
+	return overrides, nil
+}
+
+// GasPerMsg returns the gas limit to allot to each of the strategy's
+// messages: its override, if any, and otherwise its GasEstimate. For a
+// SequenceStrategy, it is the largest of its strategies' gas limits.
+func (o GasOverrides) GasPerMsg(strategy Strategy) uint64 {
+	if seq, ok := strategy.(*SequenceStrategy); ok {
+		var gas uint64
+		for _, s := range seq.Strategies() {
+			gas = max(gas, o.GasPerMsg(s))
+		}
+		return gas
+	}
+	if gas, ok := o[strategy.Name()]; ok {
+		return gas
+	}
+	return strategy.GasEstimate()
+}
+
+// GasPrices maps strategy names to the price per unit of gas at which to pay
+// the fees of the strategy's transactions instead of the default one, e.g. to
+// tip some strategies' transactions more than others'.
+type GasPrices map[string]sdk.DecCoin
+
+// ParseGasPrices parses a comma-separated list of strategy=price pairs, e.g.
+// "bank-send=25000000000aperpx,perp-round-trip=50000000000aperpx".
+func ParseGasPrices(s string) (GasPrices, error) {
+	prices := make(GasPrices)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if len(pair) == 0 {
+			continue
+		}
+		name, val, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid strategy gas price %q: expected strategy=price", pair)
+		}
+		name, val = strings.TrimSpace(name), strings.TrimSpace(val)
+		if !slices.Contains(StrategyNames, name) {
+			return nil, fmt.Errorf("unknown strategy %q (expected one of %s)", name, strings.Join(StrategyNames, ", "))
+		}
+		if _, dup := prices[name]; dup {
+			return nil, fmt.Errorf("duplicate strategy gas price for %q", name)
+		}
+		price, err := sdk.ParseDecCoin(val)
+		if err != nil || !price.IsPositive() {
+			return nil, fmt.Errorf("invalid gas price %q for strategy %q: must be a positive amount with a denom, e.g. 25000000000aperpx", val, name)
+		}
+		prices[name] = price
+	}
+	return prices, nil
+}
+
+// GasPrice returns the price per unit of gas of the strategy's transactions:
+// its override, if any, and otherwise the given default. For a
+// SequenceStrategy, it is the highest of its strategies' prices, since all of
+// its transactions are allotted the same gas limit too (see
+// GasOverrides.GasPerMsg). The prices must all be in the default's denom.
+func (p GasPrices) GasPrice(strategy Strategy, defaultPrice sdk.DecCoin) sdk.DecCoin {
+	if seq, ok := strategy.(*SequenceStrategy); ok {
+		price := p.GasPrice(seq.Strategies()[0], defaultPrice)
+		for _, s := range seq.Strategies()[1:] {
+			if other := p.GasPrice(s, defaultPrice); price.IsLT(other) {
+				price = other
+			}
+		}
+		return price
+	}
+	if price, ok := p[strategy.Name()]; ok {
+		return price
+	}
+	return defaultPrice
+}
@@ -0,0 +1,147 @@
+package strategies
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+
+	"cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/authz"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+)
+
+// HotAccountStrategy has each sender alternate between funding and defunding
+// a single "hot" account: every other message sends an amount to the hot
+// account, and the next one sends the same amount back. This keeps balances
+// roughly flat while concentrating as many writes as possible on the hot
+// account's store entries, which isolates single-key write contention.
+//
+// Since the sender signs every transaction, defunding messages are executed
+// via authz (MsgExec wrapping a MsgSend from the hot account), which requires
+// the hot account to have granted each sender a generic authorization for
+// MsgSend (see the seed command's --grant-hot-account flag).
+type HotAccountStrategy struct {
+	chainID string
+	denom   string
+	hotAddr string
+
+	amounts AmountDistribution
+	rng     *rand.Rand
+
+	mtx     sync.Mutex
+	pending math.Int // The amount to send back from the hot account next (nil if the next message funds it).
+}
+
+// NewHotAccountStrategy creates a strategy in which senders alternately fund
+// and defund the given hot account.
+func NewHotAccountStrategy(chainID, denom, hotAddr string) (*HotAccountStrategy, error) {
+	if chainID == "" {
+		return nil, fmt.Errorf("chain ID cannot be empty")
+	}
+	if denom == "" {
+		return nil, fmt.Errorf("denom cannot be empty")
+	}
+	if err := sdk.ValidateDenom(denom); err != nil {
+		return nil, fmt.Errorf("invalid denom %q: %w", denom, err)
+	}
+	if _, err := sdk.AccAddressFromBech32(hotAddr); err != nil {
+		return nil, fmt.Errorf("invalid hot account address: %w", err)
+	}
+
+	return &HotAccountStrategy{
+		chainID: chainID,
+		denom:   denom,
+		hotAddr: hotAddr,
+	}, nil
+}
+
+// SetAmountDistribution configures the distribution from which the amount
+// sent to the hot account by each funding message is drawn (the following
+// defunding message sends the same amount back). Must be called before
+// CreateMsg.
+func (s *HotAccountStrategy) SetAmountDistribution(dist AmountDistribution, seed int64) {
+	s.amounts = dist
+	s.rng = rand.New(rand.NewSource(seed))
+}
+
+func (s *HotAccountStrategy) amountDistribution() AmountDistribution {
+	if s.amounts == nil {
+		return DefaultAmountDistribution
+	}
+	return s.amounts
+}
+
+// Name returns the strategy's name
+func (s *HotAccountStrategy) Name() string {
+	return HotAccountStrategyName
+}
+
+// ChainID returns the chain ID
+func (s *HotAccountStrategy) ChainID() string {
+	return s.chainID
+}
+
+// Denom returns the denomination
+func (s *HotAccountStrategy) Denom() string {
+	return s.denom
+}
+
+// HotAddress returns the address of the hot account
+func (s *HotAccountStrategy) HotAddress() string {
+	return s.hotAddr
+}
+
+// AmountPerMsg returns the average amount sent by each funding message,
+// rounded up. Only every other message reduces the sender's balance, but any
+// single message may be a funding one.
+func (s *HotAccountStrategy) AmountPerMsg() math.Int {
+	return meanAmount(s.amountDistribution())
+}
+
+// SelfSend returns false, since funding messages reduce the sender's balance
+// until the following defunding message is committed.
+func (s *HotAccountStrategy) SelfSend() bool {
+	return false
+}
+
+// GasEstimate returns the gas limit allotted to each funding or defunding message by default
+func (s *HotAccountStrategy) GasEstimate() uint64 {
+	return hotAccountGasEstimate
+}
+
+// CreateMsg creates the next message for the given sender: either a MsgSend
+// to the hot account, or a MsgExec through which the sender has the hot
+// account send the amount of the previous message back.
+func (s *HotAccountStrategy) CreateMsg(fromAddr string) (sdk.Msg, error) {
+	sender, err := sdk.AccAddressFromBech32(fromAddr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid from address: %w", err)
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if s.pending.IsNil() {
+		var amount int64
+		if s.rng == nil {
+			amount = s.amountDistribution().Sample(nil)
+		} else {
+			amount = s.amountDistribution().Sample(s.rng)
+		}
+		s.pending = math.NewInt(amount)
+		return &banktypes.MsgSend{
+			FromAddress: fromAddr,
+			ToAddress:   s.hotAddr,
+			Amount:      sdk.NewCoins(sdk.NewCoin(s.denom, s.pending)),
+		}, nil
+	}
+
+	amount := s.pending
+	s.pending = math.Int{}
+	exec := authz.NewMsgExec(sender, []sdk.Msg{&banktypes.MsgSend{
+		FromAddress: s.hotAddr,
+		ToAddress:   fromAddr,
+		Amount:      sdk.NewCoins(sdk.NewCoin(s.denom, amount)),
+	}})
+	return &exec, nil
+}
@@ -0,0 +1,247 @@
+package strategies
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+
+	"cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+
+	clobtypes "github.com/1119-Labs/perpx-chain/protocol/x/clob/types"
+	satypes "github.com/1119-Labs/perpx-chain/protocol/x/subaccounts/types"
+)
+
+// DefaultPerpGoodTilBlocks is the default number of blocks past the current
+// height for which round-trip orders remain valid.
+const DefaultPerpGoodTilBlocks = 5
+
+// PerpRoundTripParams describes the orders placed by a PerpRoundTripStrategy.
+type PerpRoundTripParams struct {
+	ClobPairID    uint32 // The CLOB pair (market) to trade.
+	Quantums      uint64 // The size of each order, in base quantums.
+	BuySubticks   uint64 // The price at which positions are opened, in subticks.
+	SellSubticks  uint64 // The price at which positions are closed, in subticks.
+	GoodTilBlocks uint32 // How many blocks past the current height orders remain valid.
+}
+
+// ParsePerpRoundTripParams parses a comma-separated list of key=value pairs,
+// e.g. "clob=0,quantums=1000000,buy-subticks=200000,sell-subticks=100000".
+// The optional good-til-blocks key defaults to DefaultPerpGoodTilBlocks.
+func ParsePerpRoundTripParams(s string) (PerpRoundTripParams, error) {
+	params := PerpRoundTripParams{GoodTilBlocks: DefaultPerpGoodTilBlocks}
+	seen := make(map[string]bool)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if len(pair) == 0 {
+			continue
+		}
+		key, val, ok := strings.Cut(pair, "=")
+		if !ok {
+			return PerpRoundTripParams{}, fmt.Errorf("invalid perp round trip option %q: expected key=value", pair)
+		}
+		key, val = strings.TrimSpace(key), strings.TrimSpace(val)
+		var err error
+		switch key {
+		case "clob":
+			params.ClobPairID, err = parseUint32(val)
+		case "quantums":
+			params.Quantums, err = strconv.ParseUint(val, 10, 64)
+		case "buy-subticks":
+			params.BuySubticks, err = strconv.ParseUint(val, 10, 64)
+		case "sell-subticks":
+			params.SellSubticks, err = strconv.ParseUint(val, 10, 64)
+		case "good-til-blocks":
+			params.GoodTilBlocks, err = parseUint32(val)
+		default:
+			return PerpRoundTripParams{}, fmt.Errorf("unknown perp round trip option %q (expected clob, quantums, buy-subticks, sell-subticks or good-til-blocks)", key)
+		}
+		if err != nil {
+			return PerpRoundTripParams{}, fmt.Errorf("invalid perp round trip option %q: %w", pair, err)
+		}
+		seen[key] = true
+	}
+	for _, key := range []string{"clob", "quantums", "buy-subticks", "sell-subticks"} {
+		if !seen[key] {
+			return PerpRoundTripParams{}, fmt.Errorf("missing perp round trip option %q", key)
+		}
+	}
+	if params.Quantums == 0 {
+		return PerpRoundTripParams{}, fmt.Errorf("perp round trip quantums must be > 0")
+	}
+	if params.BuySubticks == 0 || params.SellSubticks == 0 {
+		return PerpRoundTripParams{}, fmt.Errorf("perp round trip subticks must be > 0")
+	}
+	if params.GoodTilBlocks == 0 || params.GoodTilBlocks > clobtypes.ShortBlockWindow {
+		return PerpRoundTripParams{}, fmt.Errorf("perp round trip good-til-blocks must be between 1 and %d, but got %d", clobtypes.ShortBlockWindow, params.GoodTilBlocks)
+	}
+	return params, nil
+}
+
+func parseUint32(s string) (uint32, error) {
+	v, err := strconv.ParseUint(s, 10, 32)
+	return uint32(v), err
+}
+
+// HeightFunc returns the chain's current block height.
+type HeightFunc func() (uint32, error)
+
+// PerpRoundTripStrategy has each sender open a perp position and, with its
+// next message, close it again, producing open/close churn that exercises
+// the settlement and PnL realization code paths that one-directional order
+// flow never reaches. Positions are opened with an immediate-or-cancel buy
+// order and closed with a reduce-only immediate-or-cancel sell order, both
+// placed from the sender's subaccount 0.
+//
+// The orders are short-term orders, which are replay-protected by their
+// good-til-block rather than by the sender's account sequence, so the
+// strategy needs the chain's current height to place them.
+type PerpRoundTripStrategy struct {
+	chainID string
+	denom   string
+	params  PerpRoundTripParams
+	height  HeightFunc
+
+	mtx      sync.Mutex
+	open     bool   // Whether the last message opened a position (so that the next one closes it).
+	clientID uint32 // The client ID of the last order placed.
+}
+
+// NewPerpRoundTripStrategy creates a strategy in which senders alternately
+// open and close positions with the given orders. Transaction fees are paid
+// in the given denom.
+func NewPerpRoundTripStrategy(chainID, denom string, params PerpRoundTripParams, height HeightFunc) (*PerpRoundTripStrategy, error) {
+	if chainID == "" {
+		return nil, fmt.Errorf("chain ID cannot be empty")
+	}
+	if denom == "" {
+		return nil, fmt.Errorf("denom cannot be empty")
+	}
+	if err := sdk.ValidateDenom(denom); err != nil {
+		return nil, fmt.Errorf("invalid denom %q: %w", denom, err)
+	}
+	if height == nil {
+		return nil, fmt.Errorf("height function cannot be nil")
+	}
+
+	return &PerpRoundTripStrategy{
+		chainID: chainID,
+		denom:   denom,
+		params:  params,
+		height:  height,
+	}, nil
+}
+
+// SetAmountDistribution only seeds the client IDs of the sender's orders,
+// since order sizes are fixed. Must be called before CreateMsg.
+func (s *PerpRoundTripStrategy) SetAmountDistribution(_ AmountDistribution, seed int64) {
+	s.mtx.Lock()
+	s.clientID = rand.New(rand.NewSource(seed)).Uint32()
+	s.mtx.Unlock()
+}
+
+// Name returns the strategy's name
+func (s *PerpRoundTripStrategy) Name() string {
+	return PerpRoundTripStrategyName
+}
+
+// ChainID returns the chain ID
+func (s *PerpRoundTripStrategy) ChainID() string {
+	return s.chainID
+}
+
+// Denom returns the denomination
+func (s *PerpRoundTripStrategy) Denom() string {
+	return s.denom
+}
+
+// AmountPerMsg returns zero, since orders don't transfer the denom.
+func (s *PerpRoundTripStrategy) AmountPerMsg() math.Int {
+	return math.ZeroInt()
+}
+
+// SelfSend returns true, since orders don't reduce the sender's balance of
+// the denom.
+func (s *PerpRoundTripStrategy) SelfSend() bool {
+	return true
+}
+
+// GasEstimate returns the gas limit allotted to each order by default
+func (s *PerpRoundTripStrategy) GasEstimate() uint64 {
+	return perpOrderGasEstimate
+}
+
+// SequenceFree returns true, since short-term orders are replay-protected by
+// their good-til-block.
+func (s *PerpRoundTripStrategy) SequenceFree() bool {
+	return true
+}
+
+// CreateMsg creates the next order for the given sender: a buy order opening
+// a position if the previous order closed one (or this is the first order),
+// and a reduce-only sell order closing it otherwise. The state only records
+// which order was created last, not whether it was filled, so an order that
+// fails or finds no liquidity does not throw the alternation off for more
+// than one round trip.
+func (s *PerpRoundTripStrategy) CreateMsg(fromAddr string) (sdk.Msg, error) {
+	if _, err := sdk.AccAddressFromBech32(fromAddr); err != nil {
+		return nil, fmt.Errorf("invalid from address: %w", err)
+	}
+	height, err := s.height()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current block height: %w", err)
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.clientID++
+	order := clobtypes.Order{
+		OrderId: clobtypes.OrderId{
+			SubaccountId: satypes.SubaccountId{Owner: fromAddr, Number: 0},
+			ClientId:     s.clientID,
+			OrderFlags:   clobtypes.OrderIdFlags_ShortTerm,
+			ClobPairId:   s.params.ClobPairID,
+		},
+		Quantums:     s.params.Quantums,
+		GoodTilOneof: &clobtypes.Order_GoodTilBlock{GoodTilBlock: height + s.params.GoodTilBlocks},
+		TimeInForce:  clobtypes.Order_TIME_IN_FORCE_IOC,
+	}
+	if s.open {
+		order.Side = clobtypes.Order_SIDE_SELL
+		order.Subticks = s.params.SellSubticks
+		order.ReduceOnly = true
+	} else {
+		order.Side = clobtypes.Order_SIDE_BUY
+		order.Subticks = s.params.BuySubticks
+	}
+	s.open = !s.open
+	return &clobtypes.MsgPlaceOrder{Order: order}, nil
+}
+
+// Warmup does nothing, since the first order opens a position of its own.
+func (s *PerpRoundTripStrategy) Warmup(MsgBroadcaster) error {
+	return nil
+}
+
+// Teardown closes the position opened by the sender's last order, if any, so
+// that the load test doesn't leave it open. Like CreateMsg, it doesn't know
+// whether that order was filled, in which case the reduce-only closing order
+// is simply rejected or cancelled.
+func (s *PerpRoundTripStrategy) Teardown(b MsgBroadcaster) error {
+	s.mtx.Lock()
+	open := s.open
+	s.mtx.Unlock()
+	if !open {
+		return nil
+	}
+	msg, err := s.CreateMsg(b.Address())
+	if err != nil {
+		return err
+	}
+	if err := b.BroadcastMsgs(msg); err != nil {
+		return fmt.Errorf("failed to close position: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package strategies_test
+
+import (
+	"errors"
+	"testing"
+
+	clobtypes "github.com/1119-Labs/perpx-chain/protocol/x/clob/types"
+	"github.com/1119-Labs/perpx-load-test/pkg/accounts"
+	"github.com/1119-Labs/perpx-load-test/pkg/strategies"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePerpRoundTripParams(t *testing.T) {
+	testCases := []struct {
+		spec   string
+		params strategies.PerpRoundTripParams // Only checked if the spec is valid.
+		err    string                         // Empty if the spec is valid.
+	}{
+		{
+			spec:   "clob=1,quantums=1000000,buy-subticks=200000,sell-subticks=100000",
+			params: strategies.PerpRoundTripParams{ClobPairID: 1, Quantums: 1000000, BuySubticks: 200000, SellSubticks: 100000, GoodTilBlocks: strategies.DefaultPerpGoodTilBlocks},
+		},
+		{
+			spec:   " clob = 0 , quantums=10, buy-subticks=3, sell-subticks=2, good-til-blocks=20,",
+			params: strategies.PerpRoundTripParams{ClobPairID: 0, Quantums: 10, BuySubticks: 3, SellSubticks: 2, GoodTilBlocks: 20},
+		},
+		{spec: "clob=0,quantums=10,buy-subticks=3", err: `missing perp round trip option "sell-subticks"`},
+		{spec: "clob=0,quantums=10,buy-subticks=3,sell-subticks", err: "expected key=value"},
+		{spec: "clob=0,quantums=10,buy-subticks=3,sell-subticks=2,size=1", err: `unknown perp round trip option "size"`},
+		{spec: "clob=-1,quantums=10,buy-subticks=3,sell-subticks=2", err: `invalid perp round trip option "clob=-1"`},
+		{spec: "clob=0,quantums=0,buy-subticks=3,sell-subticks=2", err: "quantums must be > 0"},
+		{spec: "clob=0,quantums=10,buy-subticks=0,sell-subticks=2", err: "subticks must be > 0"},
+		{spec: "clob=0,quantums=10,buy-subticks=3,sell-subticks=2,good-til-blocks=0", err: "good-til-blocks must be between 1 and 40"},
+		{spec: "clob=0,quantums=10,buy-subticks=3,sell-subticks=2,good-til-blocks=41", err: "good-til-blocks must be between 1 and 40"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.spec, func(t *testing.T) {
+			params, err := strategies.ParsePerpRoundTripParams(tc.spec)
+			if len(tc.err) > 0 {
+				require.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.params, params)
+		})
+	}
+}
+
+// recordingBroadcaster records the messages broadcast by a strategy's
+// lifecycle hooks.
+type recordingBroadcaster struct {
+	addr string
+	msgs []sdk.Msg
+}
+
+func (b *recordingBroadcaster) Address() string { return b.addr }
+
+func (b *recordingBroadcaster) BroadcastMsgs(msgs ...sdk.Msg) error {
+	b.msgs = append(b.msgs, msgs...)
+	return nil
+}
+
+func TestPerpRoundTripStrategyCreateMsg(t *testing.T) {
+	require.NoError(t, accounts.SetBech32Prefix(accounts.DefaultBech32Prefix))
+	sender := sdk.AccAddress(accounts.WorkerPrivKey(0).PubKey().Address()).String()
+	params := strategies.PerpRoundTripParams{ClobPairID: 2, Quantums: 1000, BuySubticks: 300, SellSubticks: 100, GoodTilBlocks: 5}
+	height := uint32(100)
+	s, err := strategies.NewPerpRoundTripStrategy("localperpxprotocol", "aperpx", params, func() (uint32, error) {
+		return height, nil
+	})
+	require.NoError(t, err)
+	s.SetAmountDistribution(nil, 1)
+
+	order := func(msg sdk.Msg) clobtypes.Order {
+		placeOrder, ok := msg.(*clobtypes.MsgPlaceOrder)
+		require.True(t, ok, "expected a MsgPlaceOrder, got %T", msg)
+		return placeOrder.Order
+	}
+
+	// the orders alternately open a position and close it again
+	var clientIDs []uint32
+	for i := 0; i < 4; i++ {
+		msg, err := s.CreateMsg(sender)
+		require.NoError(t, err)
+		o := order(msg)
+		assert.Equal(t, sender, o.OrderId.SubaccountId.Owner)
+		assert.Equal(t, uint32(0), o.OrderId.SubaccountId.Number)
+		assert.Equal(t, uint32(clobtypes.OrderIdFlags_ShortTerm), o.OrderId.OrderFlags)
+		assert.Equal(t, uint32(2), o.OrderId.ClobPairId)
+		assert.Equal(t, uint64(1000), o.Quantums)
+		assert.Equal(t, clobtypes.Order_TIME_IN_FORCE_IOC, o.TimeInForce)
+		// valid for good-til-blocks past the current height
+		assert.Equal(t, height+5, o.GetGoodTilBlock())
+		if i%2 == 0 {
+			assert.Equal(t, clobtypes.Order_SIDE_BUY, o.Side)
+			assert.Equal(t, uint64(300), o.Subticks)
+			assert.False(t, o.ReduceOnly)
+		} else {
+			assert.Equal(t, clobtypes.Order_SIDE_SELL, o.Side)
+			assert.Equal(t, uint64(100), o.Subticks)
+			assert.True(t, o.ReduceOnly)
+		}
+		clientIDs = append(clientIDs, o.OrderId.ClientId)
+		height++
+	}
+	// every order gets a client ID of its own
+	for i := 1; i < len(clientIDs); i++ {
+		assert.Equal(t, clientIDs[i-1]+1, clientIDs[i])
+	}
+
+	// nothing is left open after a closing order
+	b := &recordingBroadcaster{addr: sender}
+	require.NoError(t, s.Teardown(b))
+	assert.Empty(t, b.msgs)
+
+	// while an opening order is closed on teardown
+	_, err = s.CreateMsg(sender)
+	require.NoError(t, err)
+	require.NoError(t, s.Teardown(b))
+	require.Len(t, b.msgs, 1)
+	o := order(b.msgs[0])
+	assert.Equal(t, clobtypes.Order_SIDE_SELL, o.Side)
+	assert.True(t, o.ReduceOnly)
+
+	// no order can be placed without the current height
+	s, err = strategies.NewPerpRoundTripStrategy("localperpxprotocol", "aperpx", params, func() (uint32, error) {
+		return 0, errors.New("node unavailable")
+	})
+	require.NoError(t, err)
+	_, err = s.CreateMsg(sender)
+	require.ErrorContains(t, err, "failed to get current block height")
+}
@@ -0,0 +1,63 @@
+package strategies
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// RecipientLineError describes an invalid line in a recipients file.
+type RecipientLineError struct {
+	Line int
+	Err  error
+}
+
+func (e RecipientLineError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+// ReadRecipients reads recipient addresses, one bech32 address per line. Blank
+// lines and lines starting with "#" are ignored. Lines that don't contain a
+// valid address are returned separately, so that the caller can decide
+// whether to skip them or give up.
+func ReadRecipients(r io.Reader) ([]string, []RecipientLineError, error) {
+	var (
+		recipients []string
+		invalid    []RecipientLineError
+	)
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		addr := strings.TrimSpace(scanner.Text())
+		if len(addr) == 0 || strings.HasPrefix(addr, "#") {
+			continue
+		}
+		if _, err := sdk.AccAddressFromBech32(addr); err != nil {
+			invalid = append(invalid, RecipientLineError{Line: line, Err: fmt.Errorf("invalid address %q: %w", addr, err)})
+			continue
+		}
+		recipients = append(recipients, addr)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+	return recipients, invalid, nil
+}
+
+// ReadRecipientsFile reads recipient addresses from the file at the given path
+// (see ReadRecipients).
+func ReadRecipientsFile(path string) ([]string, []RecipientLineError, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open recipients file: %w", err)
+	}
+	defer f.Close()
+	recipients, invalid, err := ReadRecipients(f)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read recipients file %s: %w", path, err)
+	}
+	return recipients, invalid, nil
+}
@@ -0,0 +1,142 @@
+package strategies
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// SequenceStrategyName is the name of a SequenceStrategy. Its gas can't be
+// overridden as such, since each of its messages is allotted the largest gas
+// of all of the strategies it cycles through (see GasOverrides.GasPerMsg).
+const SequenceStrategyName = "sequence"
+
+// SequenceStrategy cycles through other strategies in a fixed order, creating
+// each message with the next of them (e.g. bank-send, hot-account,
+// bank-send, hot-account, ...). Unlike random mixing, every sender sends the
+// same messages in the same order from run to run, which makes for cleaner
+// regression comparisons.
+type SequenceStrategy struct {
+	strategies []Strategy
+
+	mtx  sync.Mutex
+	next int // The index of the strategy that creates the next message.
+}
+
+// NewSequenceStrategy creates a strategy that cycles through the given
+// strategies, which must all send the same denom on the same chain.
+func NewSequenceStrategy(strategies ...Strategy) (*SequenceStrategy, error) {
+	if len(strategies) == 0 {
+		return nil, fmt.Errorf("strategy sequence cannot be empty")
+	}
+	for _, s := range strategies[1:] {
+		if s.ChainID() != strategies[0].ChainID() || s.Denom() != strategies[0].Denom() {
+			return nil, fmt.Errorf("strategy %s sends %s on %s, but %s sends %s on %s",
+				s.Name(), s.Denom(), s.ChainID(), strategies[0].Name(), strategies[0].Denom(), strategies[0].ChainID())
+		}
+	}
+	return &SequenceStrategy{strategies: strategies}, nil
+}
+
+// ParseStrategySequence parses a comma-separated list of strategy names, e.g.
+// "bank-send,hot-account,bank-send". Only bank-send and hot-account may be
+// part of a sequence, and may be repeated. perp-round-trip can't: its orders
+// leave the sender's account sequence unchanged, which the other strategies'
+// messages don't. Neither can wasm-execute, which needs a contract checked
+// against the chain's wasm module and so only runs on its own.
+func ParseStrategySequence(s string) ([]string, error) {
+	var names []string
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		if len(name) == 0 {
+			continue
+		}
+		switch name {
+		case BankSendStrategyName, HotAccountStrategyName:
+		case PerpRoundTripStrategyName:
+			return nil, fmt.Errorf("strategy %q cannot be part of a sequence, since its transactions don't use the sender's account sequence", name)
+		case WasmExecuteStrategyName:
+			return nil, fmt.Errorf("strategy %q cannot be part of a sequence, use --wasm-contract on its own instead", name)
+		default:
+			return nil, fmt.Errorf("unknown strategy %q (expected %s or %s)", name, BankSendStrategyName, HotAccountStrategyName)
+		}
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("strategy sequence cannot be empty")
+	}
+	return names, nil
+}
+
+// Strategies returns the strategies through which the sequence cycles, in
+// order.
+func (s *SequenceStrategy) Strategies() []Strategy {
+	return s.strategies
+}
+
+// SetAmountDistribution configures the amount distribution of every strategy
+// in the sequence.
+func (s *SequenceStrategy) SetAmountDistribution(dist AmountDistribution, seed int64) {
+	for _, strategy := range s.strategies {
+		strategy.SetAmountDistribution(dist, seed)
+	}
+}
+
+// Name returns the strategy's name
+func (s *SequenceStrategy) Name() string {
+	return SequenceStrategyName
+}
+
+// ChainID returns the chain ID
+func (s *SequenceStrategy) ChainID() string {
+	return s.strategies[0].ChainID()
+}
+
+// Denom returns the denomination
+func (s *SequenceStrategy) Denom() string {
+	return s.strategies[0].Denom()
+}
+
+// AmountPerMsg returns the largest of the strategies' amounts per message,
+// since a transaction may consist of the messages of any of them.
+func (s *SequenceStrategy) AmountPerMsg() math.Int {
+	amount := math.ZeroInt()
+	for _, strategy := range s.strategies {
+		amount = math.MaxInt(amount, strategy.AmountPerMsg())
+	}
+	return amount
+}
+
+// SelfSend returns whether every strategy in the sequence only sends funds
+// back to the sender.
+func (s *SequenceStrategy) SelfSend() bool {
+	for _, strategy := range s.strategies {
+		if !strategy.SelfSend() {
+			return false
+		}
+	}
+	return true
+}
+
+// GasEstimate returns the largest of the strategies' gas estimates, since a
+// transaction may consist of the messages of any of them.
+func (s *SequenceStrategy) GasEstimate() uint64 {
+	var gas uint64
+	for _, strategy := range s.strategies {
+		gas = max(gas, strategy.GasEstimate())
+	}
+	return gas
+}
+
+// CreateMsg creates the next message for the given sender with the next
+// strategy in the sequence.
+func (s *SequenceStrategy) CreateMsg(fromAddr string) (sdk.Msg, error) {
+	s.mtx.Lock()
+	strategy := s.strategies[s.next]
+	s.next = (s.next + 1) % len(s.strategies)
+	s.mtx.Unlock()
+	return strategy.CreateMsg(fromAddr)
+}
@@ -0,0 +1,91 @@
+package strategies_test
+
+import (
+	"testing"
+
+	"github.com/1119-Labs/perpx-load-test/pkg/accounts"
+	"github.com/1119-Labs/perpx-load-test/pkg/strategies"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/authz"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseStrategySequence(t *testing.T) {
+	testCases := []struct {
+		spec  string
+		names []string // Only checked if the spec is valid.
+		err   string   // Empty if the spec is valid.
+	}{
+		{spec: "bank-send", names: []string{"bank-send"}},
+		{spec: "bank-send, hot-account,bank-send", names: []string{"bank-send", "hot-account", "bank-send"}},
+		{spec: "hot-account,,", names: []string{"hot-account"}},
+		{spec: "", err: "strategy sequence cannot be empty"},
+		{spec: " , ", err: "strategy sequence cannot be empty"},
+		{spec: "bank-send,perp-round-trip", err: "cannot be part of a sequence"},
+		{spec: "bank-send,wasm-execute", err: "use --wasm-contract on its own"},
+		// there are no staking or governance strategies
+		{spec: "bank-send,delegate,vote", err: `unknown strategy "delegate"`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.spec, func(t *testing.T) {
+			names, err := strategies.ParseStrategySequence(tc.spec)
+			if len(tc.err) > 0 {
+				require.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.names, names)
+		})
+	}
+}
+
+func TestSequenceStrategyCreateMsg(t *testing.T) {
+	require.NoError(t, accounts.SetBech32Prefix(accounts.DefaultBech32Prefix))
+	sender := sdk.AccAddress(accounts.WorkerPrivKey(0).PubKey().Address()).String()
+	sink := sdk.AccAddress(accounts.WorkerPrivKey(1).PubKey().Address()).String()
+	hot := sdk.AccAddress(accounts.WorkerPrivKey(2).PubKey().Address()).String()
+
+	bankSend, err := strategies.NewBankSendStrategy("localperpxprotocol", "aperpx", sink)
+	require.NoError(t, err)
+	hotAccount, err := strategies.NewHotAccountStrategy("localperpxprotocol", "aperpx", hot)
+	require.NoError(t, err)
+	seq, err := strategies.NewSequenceStrategy(bankSend, bankSend, hotAccount)
+	require.NoError(t, err)
+	seq.SetAmountDistribution(strategies.DefaultAmountDistribution, 1)
+
+	// two rounds of the sequence, the hot account alternating between being
+	// funded and sending the funds back
+	var got []string
+	for i := 0; i < 6; i++ {
+		msg, err := seq.CreateMsg(sender)
+		require.NoError(t, err)
+		switch msg := msg.(type) {
+		case *banktypes.MsgSend:
+			switch msg.ToAddress {
+			case sink:
+				got = append(got, "send to sink")
+			case hot:
+				got = append(got, "fund hot account")
+			default:
+				t.Fatalf("unexpected recipient %s", msg.ToAddress)
+			}
+		case *authz.MsgExec:
+			got = append(got, "defund hot account")
+		default:
+			t.Fatalf("unexpected message %T", msg)
+		}
+	}
+	assert.Equal(t, []string{
+		"send to sink", "send to sink", "fund hot account",
+		"send to sink", "send to sink", "defund hot account",
+	}, got)
+
+	_, err = strategies.NewSequenceStrategy()
+	require.Error(t, err)
+	uusdc, err := strategies.NewBankSendStrategy("localperpxprotocol", "uusdc", sink)
+	require.NoError(t, err)
+	_, err = strategies.NewSequenceStrategy(bankSend, uusdc)
+	require.ErrorContains(t, err, "sends uusdc")
+}
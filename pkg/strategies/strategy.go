@@ -0,0 +1,76 @@
+package strategies
+
+import (
+	"cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// Strategy creates the messages that the load test client packs into its
+// transactions.
+type Strategy interface {
+	// Name returns the name of the strategy, under which its gas estimate can
+	// be overridden (see GasOverrides).
+	Name() string
+	// ChainID returns the ID of the chain for which messages are created.
+	ChainID() string
+	// Denom returns the denomination that messages send.
+	Denom() string
+	// AmountPerMsg returns the average amount (in base units of the denom) by
+	// which each message may reduce the sender's balance, rounded up.
+	AmountPerMsg() math.Int
+	// SelfSend returns whether messages only ever send funds back to the
+	// sender, so that they don't reduce its balance at all.
+	SelfSend() bool
+	// GasEstimate returns the gas limit to allot to each message by default.
+	GasEstimate() uint64
+	// SetAmountDistribution configures the distribution from which the amount
+	// sent by each message is drawn, seeding its random number generator with
+	// the given seed. Must be called before CreateMsg.
+	SetAmountDistribution(dist AmountDistribution, seed int64)
+	// CreateMsg creates the next message to be sent by the given address.
+	CreateMsg(fromAddr string) (sdk.Msg, error)
+}
+
+// SequenceFreeStrategy is implemented by strategies whose messages are
+// replay-protected by means other than the sender's account sequence (such as
+// short-term orders' good-til-block), so that the chain neither checks nor
+// increments the sequence of transactions consisting only of them.
+type SequenceFreeStrategy interface {
+	Strategy
+	// SequenceFree returns whether transactions made up of the strategy's
+	// messages leave the sender's sequence unchanged.
+	SequenceFree() bool
+}
+
+// MsgBroadcaster signs and broadcasts messages on behalf of a sender, outside
+// of the load test's measured transactions.
+type MsgBroadcaster interface {
+	// Address returns the sender's address.
+	Address() string
+	// BroadcastMsgs signs the given messages into a single transaction from
+	// the sender and broadcasts it, returning once it has passed CheckTx.
+	BroadcastMsgs(msgs ...sdk.Msg) error
+}
+
+// LifecycleStrategy is implemented by stateful strategies that need to send
+// setup transactions before the load test starts sending their messages (e.g.
+// to place orders to be cancelled later), or clean-up transactions once it
+// stops.
+type LifecycleStrategy interface {
+	Strategy
+	// Warmup is called once per sender, before its first message is created.
+	Warmup(b MsgBroadcaster) error
+	// Teardown is called once per sender after its last message was created,
+	// if Warmup succeeded.
+	Teardown(b MsgBroadcaster) error
+}
+
+// Ensure that our strategies implement Strategy
+var (
+	_ Strategy             = (*BankSendStrategy)(nil)
+	_ Strategy             = (*HotAccountStrategy)(nil)
+	_ Strategy             = (*SequenceStrategy)(nil)
+	_ SequenceFreeStrategy = (*PerpRoundTripStrategy)(nil)
+	_ LifecycleStrategy    = (*PerpRoundTripStrategy)(nil)
+	_ Strategy             = (*WasmExecuteStrategy)(nil)
+)
@@ -0,0 +1,166 @@
+package strategies
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// WasmExecuteStrategy has each sender execute a CosmWasm contract: every
+// message is a MsgExecuteContract with the same JSON message and funds. It
+// only works against chains with the wasm module, which the client checks
+// before the load test starts.
+type WasmExecuteStrategy struct {
+	chainID  string
+	denom    string
+	contract string
+	msg      []byte
+	funds    sdk.Coins
+}
+
+// NewWasmExecuteStrategy creates a strategy that executes the given contract
+// with the given JSON message, attaching the given funds (if any) to each
+// execution.
+func NewWasmExecuteStrategy(chainID, denom, contract, msg string, funds sdk.Coins) (*WasmExecuteStrategy, error) {
+	if chainID == "" {
+		return nil, fmt.Errorf("chain ID cannot be empty")
+	}
+	if denom == "" {
+		return nil, fmt.Errorf("denom cannot be empty")
+	}
+	if err := sdk.ValidateDenom(denom); err != nil {
+		return nil, fmt.Errorf("invalid denom %q: %w", denom, err)
+	}
+	if _, err := sdk.AccAddressFromBech32(contract); err != nil {
+		return nil, fmt.Errorf("invalid contract address: %w", err)
+	}
+	if !json.Valid([]byte(msg)) {
+		return nil, fmt.Errorf("invalid contract message: not well-formed JSON")
+	}
+	if err := funds.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid funds: %w", err)
+	}
+
+	return &WasmExecuteStrategy{
+		chainID:  chainID,
+		denom:    denom,
+		contract: contract,
+		msg:      []byte(msg),
+		funds:    funds,
+	}, nil
+}
+
+// SetAmountDistribution does nothing, since every message attaches the same
+// funds.
+func (s *WasmExecuteStrategy) SetAmountDistribution(AmountDistribution, int64) {}
+
+// Name returns the strategy's name
+func (s *WasmExecuteStrategy) Name() string {
+	return WasmExecuteStrategyName
+}
+
+// ChainID returns the chain ID
+func (s *WasmExecuteStrategy) ChainID() string {
+	return s.chainID
+}
+
+// Denom returns the denomination
+func (s *WasmExecuteStrategy) Denom() string {
+	return s.denom
+}
+
+// Contract returns the address of the executed contract
+func (s *WasmExecuteStrategy) Contract() string {
+	return s.contract
+}
+
+// AmountPerMsg returns the amount of the denom attached to each execution.
+// Funds in other denoms aren't accounted for.
+func (s *WasmExecuteStrategy) AmountPerMsg() math.Int {
+	return s.funds.AmountOf(s.denom)
+}
+
+// SelfSend returns whether executions attach none of the denom, so that they
+// don't reduce the sender's balance of it.
+func (s *WasmExecuteStrategy) SelfSend() bool {
+	return s.AmountPerMsg().IsZero()
+}
+
+// GasEstimate returns the gas limit allotted to each execution by default
+func (s *WasmExecuteStrategy) GasEstimate() uint64 {
+	return wasmExecuteGasEstimate
+}
+
+// CreateMsg creates an execution of the contract by the given sender.
+func (s *WasmExecuteStrategy) CreateMsg(fromAddr string) (sdk.Msg, error) {
+	if _, err := sdk.AccAddressFromBech32(fromAddr); err != nil {
+		return nil, fmt.Errorf("invalid from address: %w", err)
+	}
+	return &MsgExecuteContract{
+		Sender:   fromAddr,
+		Contract: s.contract,
+		Msg:      s.msg,
+		Funds:    s.funds,
+	}, nil
+}
+
+// MsgExecuteContract is CosmWasm's cosmwasm.wasm.v1.MsgExecuteContract. The
+// load test doesn't depend on wasmd (PerpX doesn't run it), so the message
+// encodes itself rather than being generated from its proto definition. That
+// suffices to pack it into transactions and sign them in SIGN_MODE_DIRECT,
+// but transactions containing it can't be decoded locally, since its type
+// isn't registered.
+type MsgExecuteContract struct {
+	Sender   string    // The executing account.
+	Contract string    // The address of the contract.
+	Msg      []byte    // The JSON message passed to the contract.
+	Funds    sdk.Coins // The funds transferred to the contract.
+}
+
+// XXX_MessageName returns the message's fully-qualified proto name, from
+// which its type URL is derived when it is packed into a transaction.
+func (*MsgExecuteContract) XXX_MessageName() string {
+	return "cosmwasm.wasm.v1.MsgExecuteContract"
+}
+
+func (m *MsgExecuteContract) Reset() { *m = MsgExecuteContract{} }
+
+func (m *MsgExecuteContract) String() string {
+	return fmt.Sprintf("MsgExecuteContract{sender: %s, contract: %s, msg: %s, funds: %s}", m.Sender, m.Contract, m.Msg, m.Funds)
+}
+
+func (*MsgExecuteContract) ProtoMessage() {}
+
+// Marshal encodes the message in the wire format of its proto definition:
+//
+//	string sender = 1;
+//	string contract = 2;
+//	bytes msg = 3;
+//	repeated cosmos.base.v1beta1.Coin funds = 5;
+func (m *MsgExecuteContract) Marshal() ([]byte, error) {
+	var b []byte
+	if len(m.Sender) > 0 {
+		b = protowire.AppendTag(b, 1, protowire.BytesType)
+		b = protowire.AppendString(b, m.Sender)
+	}
+	if len(m.Contract) > 0 {
+		b = protowire.AppendTag(b, 2, protowire.BytesType)
+		b = protowire.AppendString(b, m.Contract)
+	}
+	if len(m.Msg) > 0 {
+		b = protowire.AppendTag(b, 3, protowire.BytesType)
+		b = protowire.AppendBytes(b, m.Msg)
+	}
+	for _, coin := range m.Funds {
+		bz, err := coin.Marshal()
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode funds: %w", err)
+		}
+		b = protowire.AppendTag(b, 5, protowire.BytesType)
+		b = protowire.AppendBytes(b, bz)
+	}
+	return b, nil
+}